@@ -0,0 +1,56 @@
+package broker
+
+import (
+	"context"
+	"sync"
+
+	"github.com/okyws/dashboard-backend/domain"
+)
+
+// MemoryBroker fans out stream messages inside a single process, for single node setups and tests
+type MemoryBroker struct {
+	mu          sync.Mutex
+	subscribers map[chan *domain.StreamMessage]struct{}
+}
+
+// NewMemoryBroker creates a new in-memory broker
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{subscribers: make(map[chan *domain.StreamMessage]struct{})}
+}
+
+// Publish hands the message to every current subscriber
+func (b *MemoryBroker) Publish(ctx context.Context, message *domain.StreamMessage) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for subscriber := range b.subscribers {
+		select {
+		case subscriber <- message:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// Subscribe registers a subscriber until the context is cancelled, the returned channel is closed afterwards
+func (b *MemoryBroker) Subscribe(ctx context.Context) (<-chan *domain.StreamMessage, error) {
+	messages := make(chan *domain.StreamMessage, 64)
+
+	b.mu.Lock()
+	b.subscribers[messages] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		b.mu.Lock()
+		delete(b.subscribers, messages)
+		b.mu.Unlock()
+
+		close(messages)
+	}()
+
+	return messages, nil
+}
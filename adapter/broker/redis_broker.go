@@ -0,0 +1,80 @@
+// Package broker contains the adapters fanning out stream messages across replicas
+package broker
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultChannel is the Redis pub/sub channel carrying the stream messages
+const DefaultChannel = "dashboard:stream"
+
+// RedisBroker fans out stream messages to every replica over Redis pub/sub
+type RedisBroker struct {
+	RedisClient *redis.Client
+	Channel     string
+}
+
+// NewRedisBroker creates a new Redis pub/sub broker
+func NewRedisBroker(redisClient *redis.Client, channel string) *RedisBroker {
+	return &RedisBroker{RedisClient: redisClient, Channel: channel}
+}
+
+// Publish sends the message to every subscribed replica
+func (b *RedisBroker) Publish(ctx context.Context, message *domain.StreamMessage) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	return b.RedisClient.Publish(ctx, b.Channel, data).Err()
+}
+
+// Subscribe listens to the channel until the context is cancelled, the returned channel is closed afterwards
+func (b *RedisBroker) Subscribe(ctx context.Context) (<-chan *domain.StreamMessage, error) {
+	pubsub := b.RedisClient.Subscribe(ctx, b.Channel)
+
+	// wait for the confirmation so a broken connection is reported to the caller
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, err
+	}
+
+	messages := make(chan *domain.StreamMessage)
+
+	go func() {
+		defer close(messages)
+		defer pubsub.Close()
+
+		received := pubsub.Channel()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case raw, ok := <-received:
+				if !ok {
+					return
+				}
+
+				var message domain.StreamMessage
+				if err := json.Unmarshal([]byte(raw.Payload), &message); err != nil {
+					utils.Logger(ctx, "broker").Error().Err(err).Str("channel", b.Channel).Msg("Failed to decode stream message")
+					continue
+				}
+
+				select {
+				case messages <- &message:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return messages, nil
+}
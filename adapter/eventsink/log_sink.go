@@ -0,0 +1,35 @@
+package eventsink
+
+import (
+	"context"
+
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/rs/zerolog"
+)
+
+// LogSink writes every event to a zerolog logger, useful for development and tests
+type LogSink struct {
+	Logger zerolog.Logger
+}
+
+// NewLogSink creates a new log sink
+func NewLogSink(logger zerolog.Logger) *LogSink {
+	return &LogSink{Logger: logger}
+}
+
+// Name returns the name of the sink
+func (*LogSink) Name() string {
+	return "log"
+}
+
+// Publish logs the event
+func (s *LogSink) Publish(_ context.Context, event *domain.OutboxEvent) error {
+	s.Logger.Info().
+		Str("event_id", event.ID.String()).
+		Str("event_type", event.EventType).
+		Str("aggregate_id", event.AggregateID.String()).
+		RawJSON("payload", []byte(event.Payload)).
+		Msg("Domain event published")
+
+	return nil
+}
@@ -0,0 +1,46 @@
+// Package eventsink contains the adapters publishing outbox events to external systems
+package eventsink
+
+import (
+	"context"
+	"time"
+
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultStream is the Redis stream receiving the domain events
+const DefaultStream = "dashboard:events"
+
+// RedisStreamSink publishes events to a Redis stream using the existing Redis client
+type RedisStreamSink struct {
+	RedisClient *redis.Client
+	Stream      string
+	MaxLen      int64
+}
+
+// NewRedisStreamSink creates a new Redis stream sink
+func NewRedisStreamSink(redisClient *redis.Client, stream string) *RedisStreamSink {
+	return &RedisStreamSink{RedisClient: redisClient, Stream: stream, MaxLen: 100000}
+}
+
+// Name returns the name of the sink
+func (*RedisStreamSink) Name() string {
+	return "redis"
+}
+
+// Publish appends the event to the stream, trimming it approximately to MaxLen entries
+func (s *RedisStreamSink) Publish(ctx context.Context, event *domain.OutboxEvent) error {
+	return s.RedisClient.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.Stream,
+		MaxLen: s.MaxLen,
+		Approx: true,
+		Values: map[string]interface{}{
+			"id":           event.ID.String(),
+			"type":         event.EventType,
+			"aggregate_id": event.AggregateID.String(),
+			"payload":      event.Payload,
+			"created_at":   event.CreatedAt.Format(time.RFC3339Nano),
+		},
+	}).Err()
+}
@@ -4,11 +4,9 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/okyws/dashboard-backend/constants"
 	"github.com/okyws/dashboard-backend/dto"
 	"github.com/okyws/dashboard-backend/ports"
 	"github.com/okyws/dashboard-backend/utils"
-	"github.com/rs/zerolog/log"
 )
 
 // AuthHandler is the HTTP handler for the authentication service
@@ -23,27 +21,22 @@ func NewAuthHandler(service ports.AuthService) *AuthHandler {
 
 // Login handles the login route for authentication.
 func (h *AuthHandler) Login(c *gin.Context) {
-	if c.Request.Method != http.MethodPost {
-		utils.ErrorResponse(c, http.StatusMethodNotAllowed, constants.MsgNotAllowed)
-		return
-	}
-
-	log.Info().Str("method", c.Request.Method).Str("path", c.Request.URL.Path).Msg("Initializing Login")
+	utils.Logger(c.Request.Context(), "handler").Info().Str("method", c.Request.Method).Str("path", c.Request.URL.Path).Msg("Initializing Login")
 
 	var req dto.UserLoginDTO
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		utils.HandleError(c, err)
 		return
 	}
 
-	data, err := h.Service.LoginAccount(c, req.Username, req.Password)
+	data, err := h.Service.LoginAccount(c.Request.Context(), req.Username, req.Password, c.ClientIP())
 	if err != nil {
-		log.Error().Err(err).Msg("Username or password is incorrect. Failed to login")
-		utils.ErrorResponse(c, http.StatusUnauthorized, "Username or password is incorrect. Failed to login")
+		utils.Logger(c.Request.Context(), "handler").Error().Err(err).Msg("Failed to login")
+		utils.HandleError(c, err)
 
 		return
 	}
 
 	utils.ResponseJSON(c, *data, http.StatusOK, "Login successful")
-	log.Info().Str("username", req.Username).Msg("Login successful")
+	utils.Logger(c.Request.Context(), "handler").Info().Str("username", req.Username).Msg("Login successful")
 }
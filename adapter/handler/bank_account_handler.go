@@ -26,15 +26,10 @@ func NewBankInfoHandler(service *services.BankAccountService) *BankInfoHandlerAd
 
 // HandleCreateBankInfo creates a new bank information
 func (h *BankInfoHandlerAdapter) HandleCreateBankInfo(c *gin.Context) {
-	if c.Request.Method != http.MethodPost {
-		utils.ErrorResponse(c, http.StatusMethodNotAllowed, constants.MsgNotAllowed)
-		return
-	}
-
 	var req dto.BankAccountCreateDTO
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		utils.HandleError(c, err)
 		return
 	}
 
@@ -44,29 +39,32 @@ func (h *BankInfoHandlerAdapter) HandleCreateBankInfo(c *gin.Context) {
 		Balance:     req.Balance,
 	}
 
-	bankInfo, err := h.BankInfoService.CreateBankAccount(bankInfo)
+	bankInfo, err := h.BankInfoService.CreateBankAccount(c.Request.Context(), bankInfo)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		utils.HandleError(c, err)
 		return
 	}
 
 	bankInfoDTO := *domain.MapBankAccountToDTO(bankInfo)
 
-	utils.ResponseJSON(c, bankInfoDTO, http.StatusOK, "Bank information created successfully")
+	// v1 answered 200, its clients keep getting it
+	status := http.StatusCreated
+	if utils.APIVersion(c) == utils.APIV1 {
+		status = http.StatusOK
+	}
+
+	setETag(c, bankInfo.Version)
+	utils.SetLocation(c, bankInfo.ID.String())
+	utils.ResponseJSON(c, bankInfoDTO, status, "Bank information created successfully")
 }
 
 // HandleGetAllBankAccounts returns all bank information
 func (h *BankInfoHandlerAdapter) HandleGetAllBankAccounts(c *gin.Context) {
-	if c.Request.Method != http.MethodGet {
-		utils.ErrorResponse(c, http.StatusMethodNotAllowed, constants.MsgNotAllowed)
-		return
-	}
-
 	limit, offset := utils.GetPaginationParams(c)
 
-	bankInfos, err := h.BankInfoService.GetAllBankAccount(limit, offset)
+	bankInfos, err := h.BankInfoService.GetAllBankAccount(c.Request.Context(), limit, offset)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		utils.HandleError(c, err)
 		return
 	}
 
@@ -80,16 +78,11 @@ func (h *BankInfoHandlerAdapter) HandleGetAllBankAccounts(c *gin.Context) {
 
 // HandleGetBankInfoByID returns the bank information by ID
 func (h *BankInfoHandlerAdapter) HandleGetBankInfoByID(c *gin.Context) {
-	if c.Request.Method != http.MethodGet {
-		utils.ErrorResponse(c, http.StatusMethodNotAllowed, constants.MsgNotAllowed)
-		return
-	}
-
 	id := c.Param("id")
 
-	bankInfo, err := h.BankInfoService.GetBankAccountByID(id)
+	bankInfo, err := h.BankInfoService.GetBankAccountByID(c.Request.Context(), id)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		utils.HandleError(c, err)
 		return
 	}
 
@@ -100,44 +93,73 @@ func (h *BankInfoHandlerAdapter) HandleGetBankInfoByID(c *gin.Context) {
 
 	bankInfoDTO := *domain.MapBankAccountToDTO(bankInfo)
 
+	setETag(c, bankInfo.Version)
 	utils.ResponseJSON(c, bankInfoDTO, http.StatusOK, "Bank information fetched successfully")
 }
 
-// HandleDeleteBankInfo deletes a bank information
-func (h *BankInfoHandlerAdapter) HandleDeleteBankInfo(c *gin.Context) {
-	if c.Request.Method != http.MethodDelete {
-		utils.ErrorResponse(c, http.StatusMethodNotAllowed, constants.MsgNotAllowed)
+// HandleUpdateBankInfoStatus activates or freezes a bank information
+func (h *BankInfoHandlerAdapter) HandleUpdateBankInfoStatus(c *gin.Context) {
+	version, err := ifMatch(c)
+	if err != nil {
+		utils.HandleError(c, err)
 		return
 	}
 
-	id := c.Param("id")
+	var req dto.BankAccountUpdateStatusDTO
 
-	err := h.BankInfoService.DeleteBankAccount(id)
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleError(c, err)
 		return
 	}
 
-	if err == gorm.ErrRecordNotFound {
-		utils.ErrorResponse(c, http.StatusNotFound, constants.MsgNotFound)
+	bankInfo, err := h.BankInfoService.UpdateBankAccountStatus(c.Request.Context(), c.Param("id"), version, *req.AccountStatus)
+	if err != nil {
+		utils.HandleError(c, err)
 		return
 	}
 
-	utils.ResponseJSON(c, nil, http.StatusOK, "Bank information deleted successfully")
+	bankInfoDTO := *domain.MapBankAccountToDTO(bankInfo)
+
+	setETag(c, bankInfo.Version)
+	utils.ResponseJSON(c, bankInfoDTO, http.StatusOK, "Bank information status updated successfully")
 }
 
-// HandleGetBankInfoByUserID returns the bank information for a user
-func (h *BankInfoHandlerAdapter) HandleGetBankInfoByUserID(c *gin.Context) {
-	if c.Request.Method != http.MethodGet {
-		utils.ErrorResponse(c, http.StatusMethodNotAllowed, constants.MsgNotAllowed)
+// HandleDeleteBankInfo deletes a bank information of the caller, or of any user for an admin
+func (h *BankInfoHandlerAdapter) HandleDeleteBankInfo(c *gin.Context) {
+	id := c.Param("id")
+
+	callerID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
+	version, err := ifMatch(c)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	err = h.BankInfoService.DeleteBankAccount(c.Request.Context(), id, version, callerID, currentRole(c))
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	utils.ResponseDeleted(c, "Bank information deleted successfully")
+}
+
+// HandleGetBankInfoByUserID returns the bank information for a user, which is the caller unless they are an admin
+func (h *BankInfoHandlerAdapter) HandleGetBankInfoByUserID(c *gin.Context) {
 	userID := c.Param("user_id")
 
-	bankInfos, err := h.BankInfoService.GetByUserID(userID)
+	callerID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	bankInfos, err := h.BankInfoService.GetByUserID(c.Request.Context(), userID, callerID, currentRole(c))
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		utils.HandleError(c, err)
 		return
 	}
 
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/dto"
+	"github.com/okyws/dashboard-backend/services"
+	"github.com/okyws/dashboard-backend/utils"
+)
+
+// BeneficiaryHandlerAdapter is the HTTP handler for the beneficiary book of the logged in user
+type BeneficiaryHandlerAdapter struct {
+	BeneficiaryService *services.BeneficiaryService
+}
+
+// NewBeneficiaryHandler creates a new beneficiary handler via dependency injection
+func NewBeneficiaryHandler(service *services.BeneficiaryService) *BeneficiaryHandlerAdapter {
+	return &BeneficiaryHandlerAdapter{BeneficiaryService: service}
+}
+
+// HandleCreateBeneficiary saves a verified account number in the beneficiary book of the caller
+func (h *BeneficiaryHandlerAdapter) HandleCreateBeneficiary(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.BeneficiaryCreateDTO
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	beneficiary, err := h.BeneficiaryService.CreateBeneficiary(c.Request.Context(), userID, req.Nickname, req.AccountNumber)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	utils.SetLocation(c, beneficiary.ID.String())
+	utils.ResponseJSON(c, *domain.MapBeneficiaryToDTO(beneficiary), http.StatusCreated, "Beneficiary created successfully")
+}
+
+// HandleGetBeneficiaries returns the beneficiary book of the caller with pagination
+func (h *BeneficiaryHandlerAdapter) HandleGetBeneficiaries(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	limit, offset := utils.GetPaginationParams(c)
+
+	beneficiaries, err := h.BeneficiaryService.GetBeneficiaries(c.Request.Context(), userID, limit, offset)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	beneficiaryDTOs := make([]dto.BeneficiaryDTO, len(beneficiaries))
+	for i := range beneficiaries {
+		beneficiaryDTOs[i] = *domain.MapBeneficiaryToDTO(&beneficiaries[i])
+	}
+
+	utils.ResponseJSON(c, beneficiaryDTOs, http.StatusOK, "Beneficiaries fetched successfully")
+}
+
+// HandleGetBeneficiaryByID returns a beneficiary of the caller
+func (h *BeneficiaryHandlerAdapter) HandleGetBeneficiaryByID(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	beneficiary, err := h.BeneficiaryService.GetBeneficiaryByID(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	utils.ResponseJSON(c, *domain.MapBeneficiaryToDTO(beneficiary), http.StatusOK, "Beneficiary fetched successfully")
+}
+
+// HandleUpdateBeneficiary renames a beneficiary of the caller
+func (h *BeneficiaryHandlerAdapter) HandleUpdateBeneficiary(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.BeneficiaryUpdateDTO
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	beneficiary, err := h.BeneficiaryService.UpdateBeneficiary(c.Request.Context(), userID, c.Param("id"), req.Nickname)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	utils.ResponseJSON(c, *domain.MapBeneficiaryToDTO(beneficiary), http.StatusOK, "Beneficiary updated successfully")
+}
+
+// HandleDeleteBeneficiary removes a beneficiary of the caller
+func (h *BeneficiaryHandlerAdapter) HandleDeleteBeneficiary(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	err := h.BeneficiaryService.DeleteBeneficiary(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	utils.ResponseDeleted(c, "Beneficiary deleted successfully")
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/okyws/dashboard-backend/constants"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/utils"
+)
+
+// currentUserID returns the ID of the logged in user set by the auth middleware, answering 401 when it is missing
+func currentUserID(c *gin.Context) (uuid.UUID, bool) {
+	id, ok := c.Get("id")
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, constants.MsgUnauthorized)
+		return uuid.Nil, false
+	}
+
+	userID, ok := id.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusUnauthorized, constants.MsgUnauthorized)
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
+
+// errInvalidID is returned for path IDs that are not UUIDs
+var errInvalidID = domain.NewError(domain.ErrorValidation, "invalid_id", "id must be a UUID")
+
+// parseID parses the UUID of a path parameter
+func parseID(id string) (uuid.UUID, error) {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil, errInvalidID.WithCause(err).WithDetails(domain.FieldError{Field: "id", Code: "uuid", Message: "must be a UUID"})
+	}
+
+	return parsed, nil
+}
+
+// currentRole returns the role of the logged in user
+func currentRole(c *gin.Context) string {
+	role, _ := c.Get("role")
+	name, _ := role.(string)
+
+	return name
+}
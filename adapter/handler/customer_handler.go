@@ -4,9 +4,9 @@ package handler
 import (
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"github.com/okyws/dashboard-backend/constants"
 	"github.com/okyws/dashboard-backend/domain"
 	"github.com/okyws/dashboard-backend/dto"
@@ -27,15 +27,10 @@ func NewCustomerHandler(service *services.CustomerService) *CustomerHandlerAdapt
 
 // HandleCreateCustomer handles the HTTP request for creating a new customer
 func (h *CustomerHandlerAdapter) HandleCreateCustomer(c *gin.Context) {
-	if c.Request.Method != http.MethodPost {
-		utils.ErrorResponse(c, http.StatusMethodNotAllowed, constants.MsgNotAllowed)
-		return
-	}
-
 	var req dto.CustomerCreateDTO
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		utils.HandleError(c, err)
 		return
 	}
 
@@ -45,7 +40,7 @@ func (h *CustomerHandlerAdapter) HandleCreateCustomer(c *gin.Context) {
 		return
 	}
 
-	customer, err := h.CustomerService.CreateCustomer(&domain.Customer{
+	customer, err := h.CustomerService.CreateCustomer(c.Request.Context(), &domain.Customer{
 		UserID:      req.UserID,
 		FullName:    req.FullName,
 		PhoneNumber: req.PhoneNumber,
@@ -53,33 +48,25 @@ func (h *CustomerHandlerAdapter) HandleCreateCustomer(c *gin.Context) {
 		Address:     req.Address,
 	})
 
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	if err == gorm.ErrRecordNotFound {
-		utils.ErrorResponse(c, http.StatusUnprocessableEntity, constants.MsgUnprocessable)
+	if err != nil {
+		utils.HandleError(c, err)
 		return
 	}
 
 	customerDTO := *domain.MapCustomerToDTO(customer)
 
+	setETag(c, customer.Version)
+	utils.SetLocation(c, customer.ID.String())
 	utils.ResponseJSON(c, customerDTO, http.StatusCreated, "Customer created successfully")
 }
 
 // HandleGetCustomerByID handles the HTTP request for getting a customer by ID
 func (h *CustomerHandlerAdapter) HandleGetCustomerByID(c *gin.Context) {
-	if c.Request.Method != http.MethodGet {
-		utils.ErrorResponse(c, http.StatusMethodNotAllowed, constants.MsgNotAllowed)
-		return
-	}
-
 	id := c.Param("id")
 
-	customer, err := h.CustomerService.GetCustomerByID(id)
+	customer, err := h.CustomerService.GetCustomerByID(c.Request.Context(), id)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		utils.HandleError(c, err)
 		return
 	}
 
@@ -90,21 +77,17 @@ func (h *CustomerHandlerAdapter) HandleGetCustomerByID(c *gin.Context) {
 
 	customerDTO := *domain.MapCustomerToDTO(customer)
 
+	setETag(c, customer.Version)
 	utils.ResponseJSON(c, customerDTO, http.StatusOK, "Customer fetched successfully")
 }
 
 // HandleGetCustomerByUserID handles the HTTP request for getting a customer by UserID
 func (h *CustomerHandlerAdapter) HandleGetCustomerByUserID(c *gin.Context) {
-	if c.Request.Method != http.MethodGet {
-		utils.ErrorResponse(c, http.StatusMethodNotAllowed, constants.MsgNotAllowed)
-		return
-	}
-
 	userID := c.Param("user_id")
 
-	customer, err := h.CustomerService.GetCustomerByUserID(userID)
+	customer, err := h.CustomerService.GetCustomerByUserID(c.Request.Context(), userID)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		utils.HandleError(c, err)
 		return
 	}
 
@@ -120,16 +103,21 @@ func (h *CustomerHandlerAdapter) HandleGetCustomerByUserID(c *gin.Context) {
 
 // HandleUpdateCustomer handles the HTTP request for updating an existing customer
 func (h *CustomerHandlerAdapter) HandleUpdateCustomer(c *gin.Context) {
-	if c.Request.Method != http.MethodPut {
-		utils.ErrorResponse(c, http.StatusMethodNotAllowed, constants.MsgNotAllowed)
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		utils.HandleError(c, err)
 		return
 	}
 
-	id := c.Param("id")
+	version, err := ifMatch(c)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
 
 	var req dto.CustomerUpdateDTO
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		utils.HandleError(c, err)
 		return
 	}
 
@@ -139,16 +127,17 @@ func (h *CustomerHandlerAdapter) HandleUpdateCustomer(c *gin.Context) {
 		return
 	}
 
-	customer, err := h.CustomerService.UpdateCustomer(&domain.Customer{
-		ID:          uuid.MustParse(id),
+	customer, err := h.CustomerService.UpdateCustomer(c.Request.Context(), &domain.Customer{
+		ID:          id,
 		FullName:    req.FullName,
 		PhoneNumber: req.PhoneNumber,
 		DateOfBirth: *formattedDate,
 		Address:     req.Address,
+		Version:     version,
 	})
 
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		utils.HandleError(c, err)
 		return
 	}
 
@@ -159,44 +148,90 @@ func (h *CustomerHandlerAdapter) HandleUpdateCustomer(c *gin.Context) {
 
 	customerDTO := *domain.MapCustomerToDTO(customer)
 
+	setETag(c, customer.Version)
 	utils.ResponseJSON(c, customerDTO, http.StatusOK, "Customer updated successfully")
 }
 
-// HandleDeleteCustomer handles the HTTP request for deleting a customer
-func (h *CustomerHandlerAdapter) HandleDeleteCustomer(c *gin.Context) {
-	if c.Request.Method != http.MethodDelete {
-		utils.ErrorResponse(c, http.StatusMethodNotAllowed, constants.MsgNotAllowed)
+// HandlePatchCustomer handles the HTTP request for updating a customer with a JSON Merge Patch
+func (h *CustomerHandlerAdapter) HandlePatchCustomer(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		utils.HandleError(c, err)
 		return
 	}
 
-	id := c.Param("id")
+	version, err := ifMatch(c)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
 
-	err := h.CustomerService.DeleteCustomer(id)
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	customer, err := h.CustomerService.GetCustomerByID(c.Request.Context(), id.String())
+	if err != nil {
+		utils.HandleError(c, err)
 		return
 	}
 
-	if err == gorm.ErrRecordNotFound {
-		utils.ErrorResponse(c, http.StatusNotFound, constants.MsgNotFound)
+	var req dto.CustomerPatchDTO
+
+	changes, err := bindMergePatch(c, dto.CustomerPatchDTO{
+		FullName:    customer.FullName,
+		PhoneNumber: customer.PhoneNumber,
+		DateOfBirth: customer.DateOfBirth.Format(time.DateOnly),
+		Address:     customer.Address,
+	}, &req)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	// validated as a date by the binding rules
+	if _, ok := changes["date_of_birth"]; ok {
+		formattedDate, err := utils.FormatDate(req.DateOfBirth)
+		if err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		changes["date_of_birth"] = *formattedDate
+	}
+
+	customer, err = h.CustomerService.PatchCustomer(c.Request.Context(), id.String(), version, changes)
+	if err != nil {
+		utils.HandleError(c, err)
 		return
 	}
 
-	utils.ResponseJSON(c, nil, http.StatusOK, "Customer deleted successfully")
+	setETag(c, customer.Version)
+	utils.ResponseJSON(c, *domain.MapCustomerToDTO(customer), http.StatusOK, "Customer updated successfully")
 }
 
-// HandleGetAllCustomers handles the HTTP request for getting all customers with pagination
-func (h *CustomerHandlerAdapter) HandleGetAllCustomers(c *gin.Context) {
-	if c.Request.Method != http.MethodGet {
-		utils.ErrorResponse(c, http.StatusMethodNotAllowed, constants.MsgNotAllowed)
+// HandleDeleteCustomer handles the HTTP request for deleting a customer
+func (h *CustomerHandlerAdapter) HandleDeleteCustomer(c *gin.Context) {
+	id := c.Param("id")
+
+	version, err := ifMatch(c)
+	if err != nil {
+		utils.HandleError(c, err)
 		return
 	}
 
+	err = h.CustomerService.DeleteCustomer(c.Request.Context(), id, version)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	utils.ResponseDeleted(c, "Customer deleted successfully")
+}
+
+// HandleGetAllCustomers handles the HTTP request for getting all customers with pagination
+func (h *CustomerHandlerAdapter) HandleGetAllCustomers(c *gin.Context) {
 	limit, offset := utils.GetPaginationParams(c)
 
-	customers, err := h.CustomerService.GetAllCustomers(limit, offset)
+	customers, err := h.CustomerService.GetAllCustomers(c.Request.Context(), limit, offset)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		utils.HandleError(c, err)
 		return
 	}
 
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/utils"
+)
+
+// errIfMatchRequired is returned for v2 changes of a versioned resource that do not say which version they are based on
+var errIfMatchRequired = domain.NewError(domain.ErrorPreconditionRequired, "if_match_required",
+	"If-Match header with the ETag of the resource is required")
+
+// setETag sets the entity tag of a versioned resource, the version it is at
+func setETag(c *gin.Context, version uint) {
+	c.Header("ETag", `"`+strconv.FormatUint(uint64(version), 10)+`"`)
+}
+
+// ifMatch returns the version the If-Match header expects the resource at. On v2 the header is required, v1 clients
+// that do not send it and the * tag change the resource at any version, which is 0. A tag this API did not issue never
+// matches.
+func ifMatch(c *gin.Context) (uint, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+
+	switch {
+	case header == "" && utils.APIVersion(c) == utils.APIV1, header == "*":
+		return 0, nil
+	case header == "":
+		return 0, errIfMatchRequired
+	}
+
+	tag, opened := strings.CutPrefix(header, `"`)
+	tag, closed := strings.CutSuffix(tag, `"`)
+
+	if !opened || !closed {
+		return 0, domain.ErrVersionMismatch
+	}
+
+	version, err := strconv.ParseUint(tag, 10, 32)
+	if err != nil || version == 0 {
+		return 0, domain.ErrVersionMismatch
+	}
+
+	return uint(version), nil
+}
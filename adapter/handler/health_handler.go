@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/dto"
+	"github.com/okyws/dashboard-backend/services"
+)
+
+// HealthHandler serves the probes of orchestrators and the build information, the responses are plain JSON without
+// the API envelope so probes only need the status code
+type HealthHandler struct {
+	HealthService *services.HealthService
+	Version       dto.VersionResponseDTO
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(healthService *services.HealthService, configuration *domain.Configuration) *HealthHandler {
+	build := domain.GetBuildInfo()
+
+	return &HealthHandler{
+		HealthService: healthService,
+		Version: dto.VersionResponseDTO{
+			AppName:    configuration.AppName,
+			AppVersion: configuration.AppVersion,
+			GitCommit:  build.GitCommit,
+			BuildTime:  build.BuildTime,
+			GoVersion:  build.GoVersion,
+		},
+	}
+}
+
+// HandleHealth reports that the process is alive
+func (h *HealthHandler) HandleHealth(c *gin.Context) {
+	c.JSON(http.StatusOK, dto.HealthResponseDTO{Status: dto.HealthStatusOK})
+}
+
+// HandleReady reports whether every dependency is usable, with 503 while one fails or the server is draining
+func (h *HealthHandler) HandleReady(c *gin.Context) {
+	readiness, ready := h.HealthService.Readiness(c.Request.Context())
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, readiness)
+		return
+	}
+
+	c.JSON(http.StatusOK, readiness)
+}
+
+// HandleVersion reports the application version and build information
+func (h *HealthHandler) HandleVersion(c *gin.Context) {
+	c.JSON(http.StatusOK, h.Version)
+}
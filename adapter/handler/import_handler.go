@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/dto"
+	"github.com/okyws/dashboard-backend/services"
+	"github.com/okyws/dashboard-backend/utils"
+)
+
+// maxImportSize is the largest file accepted by an import
+const maxImportSize = 10 << 20
+
+// importFormats are the formats of the import files by the media type they are sent with
+var importFormats = map[string]string{
+	"text/csv":             domain.ImportFormatCSV,
+	"application/jsonl":    domain.ImportFormatJSONLines,
+	"application/x-ndjson": domain.ImportFormatJSONLines,
+}
+
+var (
+	// errImportMediaType is returned for imports that send neither CSV nor JSON Lines
+	errImportMediaType = domain.NewError(domain.ErrorUnsupportedMedia, "unsupported_media_type",
+		"Content-Type must be text/csv or application/jsonl")
+
+	// errImportTooLarge is returned for imports of a file larger than maxImportSize
+	errImportTooLarge = domain.NewError(domain.ErrorPayloadTooLarge, "import_too_large", "import file must not be larger than 10 MB")
+)
+
+// ImportHandlerAdapter is the HTTP handler for the import service
+type ImportHandlerAdapter struct {
+	ImportService *services.ImportService
+}
+
+// NewImportHandler creates a new import handler via dependency injection
+func NewImportHandler(service *services.ImportService) *ImportHandlerAdapter {
+	return &ImportHandlerAdapter{ImportService: service}
+}
+
+// HandleSubmitImport accepts a CSV or JSON Lines file of users, customers and accounts and answers 202 with the
+// import job, which is run in the background
+func (h *ImportHandlerAdapter) HandleSubmitImport(c *gin.Context) {
+	format, ok := importFormats[c.ContentType()]
+	if !ok {
+		utils.HandleError(c, errImportMediaType)
+		return
+	}
+
+	payload, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize))
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		utils.HandleError(c, errImportTooLarge)
+		return
+	}
+
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	job, err := h.ImportService.Submit(c.Request.Context(), format, c.Query("mode"), payload)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	utils.SetLocation(c, job.ID.String())
+	utils.ResponseJSON(c, *domain.MapImportJobToDTO(job), http.StatusAccepted, "Import submitted successfully")
+}
+
+// HandleGetImport returns an import job with its progress
+func (h *ImportHandlerAdapter) HandleGetImport(c *gin.Context) {
+	job, err := h.ImportService.GetJob(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	utils.ResponseJSON(c, *domain.MapImportJobToDTO(job), http.StatusOK, "Import fetched successfully")
+}
+
+// HandleGetImports returns the import jobs with pagination, the latest first
+func (h *ImportHandlerAdapter) HandleGetImports(c *gin.Context) {
+	limit, offset := utils.GetPaginationParams(c)
+
+	jobs, err := h.ImportService.GetJobs(c.Request.Context(), limit, offset)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	jobDTOs := make([]dto.ImportJobDTO, len(jobs))
+	for i := range jobs {
+		jobDTOs[i] = *domain.MapImportJobToDTO(&jobs[i])
+	}
+
+	utils.ResponseJSON(c, jobDTOs, http.StatusOK, "Imports fetched successfully")
+}
+
+// HandleGetImportErrors downloads the problems with the rows of an import job as CSV
+func (h *ImportHandlerAdapter) HandleGetImportErrors(c *gin.Context) {
+	id := c.Param("id")
+
+	rowErrors, err := h.ImportService.GetRowErrors(c.Request.Context(), id)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	var report bytes.Buffer
+	if err := services.WriteImportReport(&report, rowErrors); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="import-`+id+`-errors.csv"`)
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", report.Bytes())
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/utils"
+)
+
+var (
+	// errPatchMediaType is returned for PATCH requests that do not send a JSON Merge Patch
+	errPatchMediaType = domain.NewError(domain.ErrorUnsupportedMedia, "unsupported_media_type",
+		"Content-Type must be application/merge-patch+json")
+
+	// errPatchNotObject is returned for merge patches that would replace the whole resource
+	errPatchNotObject = domain.NewError(domain.ErrorValidation, "invalid_body", "merge patch must be a JSON object")
+)
+
+// bindMergePatch applies the JSON Merge Patch (RFC 7396) of the request to the current document of the resource and
+// binds the result to target. Only the patched members are validated, so a member the patch leaves untouched never
+// fails the request, and null removes a member, which fails its required rule. It returns the values of the patched
+// members of target keyed by their JSON name, which is the column they are written to.
+func bindMergePatch(c *gin.Context, current, target any) (map[string]any, error) {
+	if contentType := c.ContentType(); contentType != utils.MergePatchContentType && contentType != binding.MIMEJSON {
+		return nil, errPatchMediaType
+	}
+
+	patch, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := utils.ParseMergePatch(patch)
+	if errors.Is(err, utils.ErrPatchNotObject) {
+		return nil, errPatchNotObject
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := mergeInto(current, patch, target); err != nil {
+		return nil, err
+	}
+
+	if err := validatePatched(target, members); err != nil {
+		return nil, err
+	}
+
+	return patchedValues(target, members), nil
+}
+
+// mergeInto applies the patch to the JSON document of current and binds the result to target
+func mergeInto(current any, patch []byte, target any) error {
+	document, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+
+	merged, err := utils.MergePatch(document, patch)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(merged, target)
+}
+
+// validatePatched validates target and keeps only the validation errors of the members the patch sets
+func validatePatched(target any, members map[string]json.RawMessage) error {
+	err := binding.Validator.ValidateStruct(target)
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	patched := make(validator.ValidationErrors, 0, len(validationErrors))
+	for _, fieldErr := range validationErrors {
+		if _, ok := members[fieldErr.Field()]; ok {
+			patched = append(patched, fieldErr)
+		}
+	}
+
+	if len(patched) == 0 {
+		return nil
+	}
+
+	return patched
+}
+
+// patchedValues returns the values of the members of target the patch sets keyed by their JSON name
+func patchedValues(target any, members map[string]json.RawMessage) map[string]any {
+	changes := make(map[string]any, len(members))
+
+	value := reflect.Indirect(reflect.ValueOf(target))
+	for i := range value.NumField() {
+		name, _, _ := strings.Cut(value.Type().Field(i).Tag.Get("json"), ",")
+
+		if _, ok := members[name]; ok {
+			changes[name] = value.Field(i).Interface()
+		}
+	}
+
+	return changes
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/dto"
+	"github.com/okyws/dashboard-backend/services"
+	"github.com/okyws/dashboard-backend/utils"
+)
+
+// NotificationHandlerAdapter is the HTTP handler for the notification center of the logged in user
+type NotificationHandlerAdapter struct {
+	NotificationService *services.NotificationService
+}
+
+// NewNotificationHandler creates a new notification handler via dependency injection
+func NewNotificationHandler(service *services.NotificationService) *NotificationHandlerAdapter {
+	return &NotificationHandlerAdapter{NotificationService: service}
+}
+
+// HandleGetNotifications returns the notifications of the caller with pagination, only the unread ones with ?unread=true
+func (h *NotificationHandlerAdapter) HandleGetNotifications(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	limit, offset := utils.GetPaginationParams(c)
+
+	notifications, err := h.NotificationService.GetNotifications(c.Request.Context(), userID, c.Query("unread") == "true", limit, offset)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	notificationDTOs := make([]dto.NotificationDTO, len(notifications))
+	for i := range notifications {
+		notificationDTOs[i] = *domain.MapNotificationToDTO(&notifications[i])
+	}
+
+	utils.ResponseJSON(c, notificationDTOs, http.StatusOK, "Notifications fetched successfully")
+}
+
+// HandleMarkRead marks a notification of the caller as read
+func (h *NotificationHandlerAdapter) HandleMarkRead(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	notification, err := h.NotificationService.MarkRead(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	utils.ResponseJSON(c, *domain.MapNotificationToDTO(notification), http.StatusOK, "Notification marked as read")
+}
+
+// HandleMarkAllRead marks every notification of the caller as read
+func (h *NotificationHandlerAdapter) HandleMarkAllRead(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	updated, err := h.NotificationService.MarkAllRead(c.Request.Context(), userID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	utils.ResponseJSON(c, dto.NotificationReadAllDTO{Updated: updated}, http.StatusOK, "Notifications marked as read")
+}
+
+// HandleGetPreferences returns the notification preferences of the caller
+func (h *NotificationHandlerAdapter) HandleGetPreferences(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	preferences, err := h.NotificationService.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	utils.ResponseJSON(c, mapNotificationPreferences(preferences), http.StatusOK, "Notification preferences fetched successfully")
+}
+
+// HandleUpdatePreferences changes the channels the caller receives each notification category on
+func (h *NotificationHandlerAdapter) HandleUpdatePreferences(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.NotificationPreferenceUpdateDTO
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	preferences := make([]domain.NotificationPreference, len(req.Preferences))
+	for i, preference := range req.Preferences {
+		preferences[i] = domain.NotificationPreference{
+			Category: preference.Category,
+			InApp:    preference.InApp,
+			Email:    preference.Email,
+			SMS:      preference.SMS,
+		}
+	}
+
+	updated, err := h.NotificationService.UpdatePreferences(c.Request.Context(), userID, preferences)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	utils.ResponseJSON(c, mapNotificationPreferences(updated), http.StatusOK, "Notification preferences updated successfully")
+}
+
+// mapNotificationPreferences maps notification preferences to their DTOs
+func mapNotificationPreferences(preferences []domain.NotificationPreference) []dto.NotificationPreferenceDTO {
+	preferenceDTOs := make([]dto.NotificationPreferenceDTO, len(preferences))
+	for i := range preferences {
+		preferenceDTOs[i] = *domain.MapNotificationPreferenceToDTO(&preferences[i])
+	}
+
+	return preferenceDTOs
+}
@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/okyws/dashboard-backend/adapter/openapi"
+)
+
+// OpenAPIHandler serves the OpenAPI document of the API and the Swagger UI rendering it
+type OpenAPIHandler struct {
+	Document *openapi.Document
+}
+
+// NewOpenAPIHandler creates a new OpenAPI handler
+func NewOpenAPIHandler(document *openapi.Document) *OpenAPIHandler {
+	return &OpenAPIHandler{Document: document}
+}
+
+// HandleSpec returns the OpenAPI document
+func (h *OpenAPIHandler) HandleSpec(c *gin.Context) {
+	c.JSON(http.StatusOK, h.Document)
+}
+
+// HandleDocs returns the Swagger UI page
+func (h *OpenAPIHandler) HandleDocs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", openapi.SwaggerUI)
+}
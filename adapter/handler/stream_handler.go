@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/dto"
+	"github.com/okyws/dashboard-backend/services"
+	"github.com/okyws/dashboard-backend/utils"
+	"gorm.io/gorm"
+)
+
+// StreamHandlerAdapter is the HTTP handler streaming events over Server-Sent Events
+type StreamHandlerAdapter struct {
+	StreamService     *services.StreamService
+	HeartbeatInterval time.Duration
+	ReconnectDelay    time.Duration
+}
+
+// NewStreamHandler creates a new stream handler via dependency injection
+func NewStreamHandler(service *services.StreamService) *StreamHandlerAdapter {
+	return &StreamHandlerAdapter{
+		StreamService:     service,
+		HeartbeatInterval: 15 * time.Second,
+		ReconnectDelay:    3 * time.Second,
+	}
+}
+
+// HandleStream streams the balance, account and transaction events of the caller's own accounts
+func (h *StreamHandlerAdapter) HandleStream(c *gin.Context) {
+	h.stream(c, false)
+}
+
+// HandleFirehose streams every event to admins
+func (h *StreamHandlerAdapter) HandleFirehose(c *gin.Context) {
+	h.stream(c, true)
+}
+
+// stream replays the events missed since Last-Event-ID and then pushes live events with periodic heartbeats
+func (h *StreamHandlerAdapter) stream(c *gin.Context, firehose bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	lastID, ok := lastEventID(c)
+	if !ok {
+		return
+	}
+
+	// subscribe before replaying so no event falls between the replay and the live messages
+	subscription := h.StreamService.Subscribe(userID, firehose)
+	defer h.StreamService.Unsubscribe(subscription)
+
+	missed, resync, err := h.replay(c, lastID, subscription)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	replayed, ok := h.open(c, missed, resync)
+	if !ok {
+		return
+	}
+
+	h.push(c, subscription, replayed)
+}
+
+// lastEventID reads the last event the client received from the Last-Event-ID header or the last_event_id query,
+// answering bad request when it is not an event ID. It is uuid.Nil for a new stream.
+func lastEventID(c *gin.Context) (uuid.UUID, bool) {
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	if lastEventID == "" {
+		return uuid.Nil, true
+	}
+
+	lastID, err := uuid.Parse(lastEventID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid Last-Event-ID")
+		return uuid.Nil, false
+	}
+
+	return lastID, true
+}
+
+// replay fetches the events of the subscription recorded after the last event, the client has to resync when the last
+// event is unknown
+func (h *StreamHandlerAdapter) replay(c *gin.Context, lastID uuid.UUID, subscription *services.StreamSubscription) ([]*domain.StreamMessage, bool, error) {
+	if lastID == uuid.Nil {
+		return nil, false, nil
+	}
+
+	missed, err := h.StreamService.Replay(c.Request.Context(), lastID, subscription)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, true, nil
+	}
+
+	return missed, false, err
+}
+
+// open starts the event stream with the reconnect delay, the resync event and the missed events, it returns the IDs
+// of the replayed events so their live copies are skipped
+func (h *StreamHandlerAdapter) open(c *gin.Context, missed []*domain.StreamMessage, resync bool) (map[uuid.UUID]struct{}, bool) {
+	// the stream outlives the server write timeout
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		utils.Logger(c.Request.Context(), "handler").Warn().Err(err).Msg("Failed to clear the write deadline of the stream")
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", h.ReconnectDelay.Milliseconds())
+
+	// the last event is unknown, the client has to reload its data instead of relying on the replay
+	if resync {
+		fmt.Fprint(c.Writer, "event: resync\ndata: {}\n\n")
+	}
+
+	replayed := make(map[uuid.UUID]struct{}, len(missed))
+
+	for _, message := range missed {
+		replayed[message.ID] = struct{}{}
+
+		if err := writeStreamMessage(c, message); err != nil {
+			return nil, false
+		}
+	}
+
+	c.Writer.Flush()
+
+	return replayed, true
+}
+
+// push writes the live events of the subscription until the client goes away, with a heartbeat when it is quiet.
+// Events that were already replayed are skipped.
+func (h *StreamHandlerAdapter) push(c *gin.Context, subscription *services.StreamSubscription, replayed map[uuid.UUID]struct{}) {
+	heartbeat := time.NewTicker(h.HeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case message, ok := <-subscription.Messages():
+			if !ok {
+				return
+			}
+
+			if _, ok := replayed[message.ID]; ok {
+				delete(replayed, message.ID)
+				continue
+			}
+
+			if err := writeStreamMessage(c, message); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+
+		c.Writer.Flush()
+	}
+}
+
+// writeStreamMessage writes a message as a Server-Sent Event using the webhook event payload
+func writeStreamMessage(c *gin.Context, message *domain.StreamMessage) error {
+	data, err := json.Marshal(dto.WebhookEventDTO{
+		ID:        message.ID,
+		Type:      message.Type,
+		CreatedAt: message.CreatedAt,
+		Data:      message.Data,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(c.Writer, "id: %s\nevent: %s\ndata: %s\n\n", message.ID, message.Type, data)
+
+	return err
+}
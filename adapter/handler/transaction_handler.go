@@ -8,6 +8,7 @@ import (
 	"github.com/okyws/dashboard-backend/constants"
 	"github.com/okyws/dashboard-backend/domain"
 	"github.com/okyws/dashboard-backend/dto"
+	"github.com/okyws/dashboard-backend/i18n"
 	"github.com/okyws/dashboard-backend/services"
 	"github.com/okyws/dashboard-backend/utils"
 	"gorm.io/gorm"
@@ -27,26 +28,33 @@ func NewTransactionHandler(transactionService *services.TransactionService) *Tra
 
 // HandleTransactionProcess implements the HTTP handler for processing a transaction
 func (h *TransactionHandler) HandleTransactionProcess(c *gin.Context) {
-	if c.Request.Method != http.MethodPost {
-		utils.ErrorResponse(c, http.StatusMethodNotAllowed, constants.MsgNotAllowed)
-		return
-	}
-
 	var request dto.TransactionCreateDTO
 
 	if err := c.ShouldBindJSON(&request); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		utils.HandleError(c, err)
 		return
 	}
 
-	err := h.TransactionService.ProcessTransaction(request.FromAccountNumber, request.ToAccountNumber, request.TransactionType, request.Amount)
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
-	if err == gorm.ErrRecordNotFound {
-		utils.ErrorResponse(c, http.StatusUnprocessableEntity, constants.MsgUnprocessable)
+	var err error
+
+	switch {
+	case request.BeneficiaryID != "":
+		err = h.TransactionService.ProcessBeneficiaryTransfer(c.Request.Context(), userID, request.FromAccountNumber, request.BeneficiaryID, request.Amount)
+	case request.TransactionType == "transfer" && request.ToAccountNumber == "":
+		utils.ErrorResponse(c, http.StatusBadRequest, "to_account_number or beneficiary_id is required for a transfer")
+		return
+	default:
+		err = h.TransactionService.ProcessUserTransaction(c.Request.Context(), userID, request.FromAccountNumber, request.ToAccountNumber,
+			request.TransactionType, request.Amount)
+	}
+
+	if err != nil {
+		utils.HandleError(c, err)
 		return
 	}
 
@@ -55,22 +63,17 @@ func (h *TransactionHandler) HandleTransactionProcess(c *gin.Context) {
 
 // HandleGetAllTransactions implements the HTTP handler for getting all transactions
 func (h *TransactionHandler) HandleGetAllTransactions(c *gin.Context) {
-	if c.Request.Method != http.MethodGet {
-		utils.ErrorResponse(c, http.StatusMethodNotAllowed, constants.MsgNotAllowed)
-		return
-	}
-
 	limit, offset := utils.GetPaginationParams(c)
 
-	transactions, err := h.TransactionService.GetAllTransactions(limit, offset)
+	transactions, err := h.TransactionService.GetAllTransactions(c.Request.Context(), limit, offset)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		utils.HandleError(c, err)
 		return
 	}
 
 	transactionDTOs := make([]dto.TransactionDTO, len(transactions))
 	for i, transaction := range transactions {
-		transactionDTOs[i] = *domain.MapTransactionToDTO(&transaction)
+		transactionDTOs[i] = *localizeTransaction(c, domain.MapTransactionToDTO(&transaction))
 	}
 
 	utils.ResponseJSON(c, transactionDTOs, http.StatusOK, "Transactions retrieved successfully")
@@ -78,16 +81,11 @@ func (h *TransactionHandler) HandleGetAllTransactions(c *gin.Context) {
 
 // HandleGetAllTransactionsByAccountID implements the HTTP handler for getting all transactions by account ID
 func (h *TransactionHandler) HandleGetAllTransactionsByAccountID(c *gin.Context) {
-	if c.Request.Method != http.MethodGet {
-		utils.ErrorResponse(c, http.StatusMethodNotAllowed, constants.MsgNotAllowed)
-		return
-	}
-
 	userID := c.Param("account_id")
 
-	transactions, err := h.TransactionService.GetTransactionByAccountID(userID)
+	transactions, err := h.TransactionService.GetTransactionByAccountID(c.Request.Context(), userID)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		utils.HandleError(c, err)
 		return
 	}
 
@@ -98,24 +96,40 @@ func (h *TransactionHandler) HandleGetAllTransactionsByAccountID(c *gin.Context)
 
 	transactionDTOs := make([]dto.TransactionDTO, len(transactions))
 	for i, transaction := range transactions {
-		transactionDTOs[i] = *domain.MapTransactionToDTO(&transaction)
+		transactionDTOs[i] = *localizeTransaction(c, domain.MapTransactionToDTO(&transaction))
 	}
 
 	utils.ResponseJSON(c, transactionDTOs, http.StatusOK, "Transactions retrieved successfully")
 }
 
-// HandleGetTransactionByID implements the HTTP handler for getting a transaction by ID
-func (h *TransactionHandler) HandleGetTransactionByID(c *gin.Context) {
-	if c.Request.Method != http.MethodGet {
-		utils.ErrorResponse(c, http.StatusMethodNotAllowed, constants.MsgNotAllowed)
+// HandleGetAccountTransactions implements the HTTP handler for getting the transactions of an account by its ID
+func (h *TransactionHandler) HandleGetAccountTransactions(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
 		return
 	}
 
+	transactions, err := h.TransactionService.GetAccountTransactions(c.Request.Context(), c.Param("id"), userID, currentRole(c))
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	transactionDTOs := make([]dto.TransactionDTO, len(transactions))
+	for i, transaction := range transactions {
+		transactionDTOs[i] = *localizeTransaction(c, domain.MapTransactionToDTO(&transaction))
+	}
+
+	utils.ResponseJSON(c, transactionDTOs, http.StatusOK, "Transactions retrieved successfully")
+}
+
+// HandleGetTransactionByID implements the HTTP handler for getting a transaction by ID
+func (h *TransactionHandler) HandleGetTransactionByID(c *gin.Context) {
 	id := c.Param("id")
 
-	transaction, err := h.TransactionService.GetTransactionByID(id)
+	transaction, err := h.TransactionService.GetTransactionByID(c.Request.Context(), id)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		utils.HandleError(c, err)
 		return
 	}
 
@@ -124,7 +138,18 @@ func (h *TransactionHandler) HandleGetTransactionByID(c *gin.Context) {
 		return
 	}
 
-	transactionDTO := *domain.MapTransactionToDTO(transaction)
+	transactionDTO := *localizeTransaction(c, domain.MapTransactionToDTO(transaction))
 
 	utils.ResponseJSON(c, transactionDTO, http.StatusOK, "Transaction fetched successfully")
 }
+
+// localizeTransaction formats the amount, date and type of a transaction in the language of the request
+func localizeTransaction(c *gin.Context, transaction *dto.TransactionDTO) *dto.TransactionDTO {
+	language := utils.Language(c)
+
+	transaction.FormattedAmount = i18n.FormatAmount(language, transaction.Amount)
+	transaction.FormattedDate = i18n.FormatDate(language, transaction.CreatedAt)
+	transaction.TransactionTypeLabel = i18n.Translate(language, "transaction_type."+transaction.TransactionType)
+
+	return transaction
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/dto"
+	"github.com/okyws/dashboard-backend/i18n"
+	"github.com/okyws/dashboard-backend/services"
+	"github.com/okyws/dashboard-backend/utils"
+)
+
+// TransferBatchHandler is the HTTP handler for the batch transfer service
+type TransferBatchHandler struct {
+	TransferBatchService *services.TransferBatchService
+}
+
+// NewTransferBatchHandler creates a new batch transfer handler via dependency injection
+func NewTransferBatchHandler(service *services.TransferBatchService) *TransferBatchHandler {
+	return &TransferBatchHandler{TransferBatchService: service}
+}
+
+// HandleCreateTransferBatch makes the transfers of a batch and answers 201 with the outcome of every transfer, a batch
+// that does not pass the validation is refused without making any
+func (h *TransferBatchHandler) HandleCreateTransferBatch(c *gin.Context) {
+	var request dto.TransferBatchCreateDTO
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	batch, err := h.TransferBatchService.Process(c.Request.Context(), userID, &request)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	utils.SetLocation(c, batch.ID.String())
+	utils.ResponseJSON(c, *localizeTransferBatch(c, domain.MapTransferBatchToDTO(batch)), http.StatusCreated, "Batch transfer processed")
+}
+
+// HandleGetTransferBatch returns a batch transfer with the outcome of every transfer, users only see their own batches
+func (h *TransferBatchHandler) HandleGetTransferBatch(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	batch, err := h.TransferBatchService.GetBatch(c.Request.Context(), c.Param("id"), userID, currentRole(c))
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	utils.ResponseJSON(c, *localizeTransferBatch(c, domain.MapTransferBatchToDTO(batch)), http.StatusOK, "Batch transfer fetched successfully")
+}
+
+// localizeTransferBatch translates why the transfers of a batch were not made into the language of the request
+func localizeTransferBatch(c *gin.Context, batch *dto.TransferBatchDTO) *dto.TransferBatchDTO {
+	language := utils.Language(c)
+
+	for i := range batch.Items {
+		if batch.Items[i].Error != "" {
+			batch.Items[i].Error = i18n.Translate(language, batch.Items[i].Error)
+		}
+	}
+
+	return batch
+}
@@ -6,7 +6,6 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"github.com/okyws/dashboard-backend/constants"
 	"github.com/okyws/dashboard-backend/domain"
 	"github.com/okyws/dashboard-backend/dto"
@@ -27,19 +26,14 @@ func NewUserHandler(service *services.UserService) *UserHandlerAdapter {
 
 // HandleCreateUser implements the HTTP handler for creating a new user
 func (h *UserHandlerAdapter) HandleCreateUser(c *gin.Context) {
-	if c.Request.Method != http.MethodPost {
-		utils.ErrorResponse(c, http.StatusMethodNotAllowed, constants.MsgNotAllowed)
-		return
-	}
-
 	var req dto.UserCreateDTO
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		utils.HandleError(c, err)
 		return
 	}
 
-	user, err := h.UserService.CreateUser(&domain.User{
+	user, err := h.UserService.CreateUser(c.Request.Context(), &domain.User{
 		Email:    req.Email,
 		Username: req.Username,
 		Password: req.Password,
@@ -47,7 +41,7 @@ func (h *UserHandlerAdapter) HandleCreateUser(c *gin.Context) {
 	})
 
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusConflict, err.Error())
+		utils.HandleError(c, err)
 		return
 	}
 
@@ -58,21 +52,18 @@ func (h *UserHandlerAdapter) HandleCreateUser(c *gin.Context) {
 		Role:     user.Role,
 	}
 
+	setETag(c, user.Version)
+	utils.SetLocation(c, user.ID.String())
 	utils.ResponseJSON(c, userDTO, http.StatusCreated, "User created successfully")
 }
 
 // HandleGetUserByID implements the HTTP handler for getting a user by ID
 func (h *UserHandlerAdapter) HandleGetUserByID(c *gin.Context) {
-	if c.Request.Method != http.MethodGet {
-		utils.ErrorResponse(c, http.StatusMethodNotAllowed, constants.MsgNotAllowed)
-		return
-	}
-
 	id := c.Param("id")
 
-	user, err := h.UserService.GetUserByID(id)
+	user, err := h.UserService.GetUserByID(c.Request.Context(), id)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		utils.HandleError(c, err)
 		return
 	}
 
@@ -88,21 +79,17 @@ func (h *UserHandlerAdapter) HandleGetUserByID(c *gin.Context) {
 		Role:     user.Role,
 	}
 
+	setETag(c, user.Version)
 	utils.ResponseJSON(c, userDTO, http.StatusOK, "User fetched successfully")
 }
 
 // HandleGetUserByUsername implements the HTTP handler for getting a user by username
 func (h *UserHandlerAdapter) HandleGetUserByUsername(c *gin.Context) {
-	if c.Request.Method != http.MethodGet {
-		utils.ErrorResponse(c, http.StatusMethodNotAllowed, constants.MsgNotAllowed)
-		return
-	}
-
 	username := c.Param("username")
 
-	user, err := h.UserService.GetUserByUsername(username)
+	user, err := h.UserService.GetUserByUsername(c.Request.Context(), username)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		utils.HandleError(c, err)
 		return
 	}
 
@@ -123,16 +110,16 @@ func (h *UserHandlerAdapter) HandleGetUserByUsername(c *gin.Context) {
 
 // HandleGetAllUsers implements the HTTP handler for getting all users with pagination
 func (h *UserHandlerAdapter) HandleGetAllUsers(c *gin.Context) {
-	if c.Request.Method != http.MethodGet {
-		utils.ErrorResponse(c, http.StatusMethodNotAllowed, constants.MsgNotAllowed)
+	if username := c.Query("username"); username != "" {
+		h.handleFindByUsername(c, username)
 		return
 	}
 
 	limit, offset := utils.GetPaginationParams(c)
 
-	users, err := h.UserService.GetAllUsers(limit, offset)
+	users, err := h.UserService.GetAllUsers(c.Request.Context(), limit, offset)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		utils.HandleError(c, err)
 		return
 	}
 
@@ -149,31 +136,58 @@ func (h *UserHandlerAdapter) HandleGetAllUsers(c *gin.Context) {
 	utils.ResponseJSON(c, userDTOs, http.StatusOK, "Users fetched successfully")
 }
 
+// handleFindByUsername answers the user list filtered by username, which holds the user or nothing
+func (h *UserHandlerAdapter) handleFindByUsername(c *gin.Context, username string) {
+	user, err := h.UserService.GetUserByUsername(c.Request.Context(), username)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.HandleError(c, err)
+		return
+	}
+
+	userDTOs := make([]dto.UserDTO, 0, 1)
+	if user != nil {
+		userDTOs = append(userDTOs, dto.UserDTO{
+			ID:       user.ID,
+			Email:    user.Email,
+			Username: user.Username,
+			Role:     user.Role,
+		})
+	}
+
+	utils.ResponseJSON(c, userDTOs, http.StatusOK, "Users fetched successfully")
+}
+
 // HandleUpdateUser implements the HTTP handler for updating a user
 func (h *UserHandlerAdapter) HandleUpdateUser(c *gin.Context) {
-	if c.Request.Method != http.MethodPut {
-		utils.ErrorResponse(c, http.StatusMethodNotAllowed, constants.MsgNotAllowed)
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		utils.HandleError(c, err)
 		return
 	}
 
-	id := c.Param("id")
+	version, err := ifMatch(c)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
 
 	var req dto.UserUpdateDTO
 	if err := c.ShouldBindJSON(&req); err != nil {
-		utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+		utils.HandleError(c, err)
 		return
 	}
 
-	user, err := h.UserService.UpdateUser(&domain.User{
-		ID:       uuid.MustParse(id),
+	user, err := h.UserService.UpdateUser(c.Request.Context(), &domain.User{
+		ID:       id,
 		Email:    req.Email,
 		Username: req.Username,
 		Password: req.Password,
 		Role:     req.Role,
+		Version:  version,
 	})
 
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+		utils.HandleError(c, err)
 		return
 	}
 
@@ -183,34 +197,76 @@ func (h *UserHandlerAdapter) HandleUpdateUser(c *gin.Context) {
 	}
 
 	userDTO := dto.UserDTO{
-		ID:       uuid.MustParse(id),
+		ID:       id,
 		Email:    user.Email,
 		Username: user.Username,
 		Role:     user.Role,
 	}
 
+	setETag(c, user.Version)
 	utils.ResponseJSON(c, userDTO, http.StatusOK, "User updated successfully")
 }
 
-// HandleDeleteUser implements the HTTP handler for deleting a user
-func (h *UserHandlerAdapter) HandleDeleteUser(c *gin.Context) {
-	if c.Request.Method != http.MethodDelete {
-		utils.ErrorResponse(c, http.StatusMethodNotAllowed, constants.MsgNotAllowed)
+// HandlePatchUser implements the HTTP handler for updating a user with a JSON Merge Patch
+func (h *UserHandlerAdapter) HandlePatchUser(c *gin.Context) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		utils.HandleError(c, err)
 		return
 	}
 
+	version, err := ifMatch(c)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	user, err := h.UserService.GetUserByID(c.Request.Context(), id.String())
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	var req dto.UserPatchDTO
+
+	changes, err := bindMergePatch(c, dto.UserPatchDTO{Email: user.Email, Username: user.Username, Role: user.Role}, &req)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	user, err = h.UserService.PatchUser(c.Request.Context(), id.String(), version, changes)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	userDTO := dto.UserDTO{
+		ID:       user.ID,
+		Email:    user.Email,
+		Username: user.Username,
+		Role:     user.Role,
+	}
+
+	setETag(c, user.Version)
+	utils.ResponseJSON(c, userDTO, http.StatusOK, "User updated successfully")
+}
+
+// HandleDeleteUser implements the HTTP handler for deleting a user
+func (h *UserHandlerAdapter) HandleDeleteUser(c *gin.Context) {
 	id := c.Param("id")
 
-	err := h.UserService.DeleteUser(id)
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
+	version, err := ifMatch(c)
+	if err != nil {
+		utils.HandleError(c, err)
 		return
 	}
 
-	if err == gorm.ErrRecordNotFound {
-		utils.ErrorResponse(c, http.StatusNotFound, constants.MsgNotFound)
+	err = h.UserService.DeleteUser(c.Request.Context(), id, version)
+	if err != nil {
+		utils.HandleError(c, err)
 		return
 	}
 
-	utils.ResponseJSON(c, nil, http.StatusOK, "User deleted successfully")
+	utils.ResponseDeleted(c, "User deleted successfully")
 }
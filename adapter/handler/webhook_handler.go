@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/dto"
+	"github.com/okyws/dashboard-backend/services"
+	"github.com/okyws/dashboard-backend/utils"
+)
+
+// WebhookHandlerAdapter is the HTTP handler for the webhook service
+type WebhookHandlerAdapter struct {
+	WebhookService *services.WebhookService
+}
+
+// NewWebhookHandler creates a new webhook handler via dependency injection
+func NewWebhookHandler(service *services.WebhookService) *WebhookHandlerAdapter {
+	return &WebhookHandlerAdapter{WebhookService: service}
+}
+
+// HandleCreateEndpoint registers a new webhook endpoint, the signing secret is only returned here
+func (h *WebhookHandlerAdapter) HandleCreateEndpoint(c *gin.Context) {
+	var req dto.WebhookEndpointCreateDTO
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	endpoint, err := h.WebhookService.CreateEndpoint(c.Request.Context(), &domain.WebhookEndpoint{
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: strings.Join(req.EventTypes, ","),
+	})
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	endpointDTO := *domain.MapWebhookEndpointToDTO(endpoint)
+	endpointDTO.Secret = endpoint.Secret
+
+	utils.SetLocation(c, endpoint.ID.String())
+	utils.ResponseJSON(c, endpointDTO, http.StatusCreated, "Webhook endpoint created successfully")
+}
+
+// HandleGetEndpoint returns a webhook endpoint without its signing secret
+func (h *WebhookHandlerAdapter) HandleGetEndpoint(c *gin.Context) {
+	endpoint, err := h.WebhookService.GetEndpointByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	utils.ResponseJSON(c, *domain.MapWebhookEndpointToDTO(endpoint), http.StatusOK, "Webhook endpoint fetched successfully")
+}
+
+// HandleGetAllEndpoints returns all webhook endpoints with pagination
+func (h *WebhookHandlerAdapter) HandleGetAllEndpoints(c *gin.Context) {
+	limit, offset := utils.GetPaginationParams(c)
+
+	endpoints, err := h.WebhookService.GetAllEndpoints(c.Request.Context(), limit, offset)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	endpointDTOs := make([]dto.WebhookEndpointDTO, len(endpoints))
+	for i := range endpoints {
+		endpointDTOs[i] = *domain.MapWebhookEndpointToDTO(&endpoints[i])
+	}
+
+	utils.ResponseJSON(c, endpointDTOs, http.StatusOK, "Webhook endpoints fetched successfully")
+}
+
+// HandleDeleteEndpoint removes a webhook endpoint
+func (h *WebhookHandlerAdapter) HandleDeleteEndpoint(c *gin.Context) {
+	err := h.WebhookService.DeleteEndpoint(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	utils.ResponseDeleted(c, "Webhook endpoint deleted successfully")
+}
+
+// HandleGetDeliveries returns the delivery history of a webhook endpoint
+func (h *WebhookHandlerAdapter) HandleGetDeliveries(c *gin.Context) {
+	limit, offset := utils.GetPaginationParams(c)
+
+	deliveries, err := h.WebhookService.GetDeliveriesByEndpointID(c.Request.Context(), c.Param("id"), limit, offset)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	deliveryDTOs := make([]dto.WebhookDeliveryDTO, len(deliveries))
+	for i := range deliveries {
+		deliveryDTOs[i] = *domain.MapWebhookDeliveryToDTO(&deliveries[i])
+	}
+
+	utils.ResponseJSON(c, deliveryDTOs, http.StatusOK, "Webhook deliveries fetched successfully")
+}
+
+// HandleRedeliver schedules a delivered or dead-lettered delivery to be sent again
+func (h *WebhookHandlerAdapter) HandleRedeliver(c *gin.Context) {
+	delivery, err := h.WebhookService.Redeliver(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	utils.ResponseJSON(c, *domain.MapWebhookDeliveryToDTO(delivery), http.StatusOK, "Webhook delivery scheduled successfully")
+}
@@ -0,0 +1,33 @@
+// Package healthcheck contains the adapters checking the dependencies of the application for readiness
+package healthcheck
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// DatabaseCheck pings the database
+type DatabaseCheck struct {
+	DB *gorm.DB
+}
+
+// NewDatabaseCheck creates a new database check
+func NewDatabaseCheck(db *gorm.DB) *DatabaseCheck {
+	return &DatabaseCheck{DB: db}
+}
+
+// Name returns the name of the dependency
+func (c *DatabaseCheck) Name() string {
+	return "database"
+}
+
+// Check pings the database
+func (c *DatabaseCheck) Check(ctx context.Context) error {
+	sqlDB, err := c.DB.DB()
+	if err != nil {
+		return err
+	}
+
+	return sqlDB.PingContext(ctx)
+}
@@ -0,0 +1,45 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/okyws/dashboard-backend/database"
+)
+
+// MigrationCheck fails while migrations compiled into the binary have not been applied
+type MigrationCheck struct {
+	Migrator *database.Migrator
+}
+
+// NewMigrationCheck creates a new migration check
+func NewMigrationCheck(migrator *database.Migrator) *MigrationCheck {
+	return &MigrationCheck{Migrator: migrator}
+}
+
+// Name returns the name of the dependency
+func (c *MigrationCheck) Name() string {
+	return "migrations"
+}
+
+// Check reports the pending migrations
+func (c *MigrationCheck) Check(_ context.Context) error {
+	statuses, err := c.Migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	pending := 0
+
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending++
+		}
+	}
+
+	if pending > 0 {
+		return fmt.Errorf("%d pending migrations, run db migrate up", pending)
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package healthcheck
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisCheck pings Redis
+type RedisCheck struct {
+	RedisClient *redis.Client
+}
+
+// NewRedisCheck creates a new Redis check
+func NewRedisCheck(redisClient *redis.Client) *RedisCheck {
+	return &RedisCheck{RedisClient: redisClient}
+}
+
+// Name returns the name of the dependency
+func (c *RedisCheck) Name() string {
+	return "redis"
+}
+
+// Check pings Redis
+func (c *RedisCheck) Check(ctx context.Context) error {
+	return c.RedisClient.Ping(ctx).Err()
+}
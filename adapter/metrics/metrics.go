@@ -0,0 +1,119 @@
+// Package metrics contains the Prometheus adapters recording HTTP, database, Redis and business metrics
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace prefixes the name of every metric of the application
+const Namespace = "dashboard"
+
+// storageBuckets are the latency buckets of database queries and Redis commands, from 0.5ms to about 4s
+var storageBuckets = prometheus.ExponentialBuckets(0.0005, 2, 14)
+
+// Metrics holds the collectors of the application in its own registry
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	httpRequests      *prometheus.CounterVec
+	httpDuration      *prometheus.HistogramVec
+	dbQueryDuration   *prometheus.HistogramVec
+	redisDuration     *prometheus.HistogramVec
+	transactions      *prometheus.CounterVec
+	transactionAmount *prometheus.CounterVec
+	logins            *prometheus.CounterVec
+}
+
+// New creates the collectors together with the Go runtime and process collectors
+func New() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace, Subsystem: "http", Name: "requests_total", Help: "HTTP requests by method, route template and status.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace, Subsystem: "http", Name: "request_duration_seconds", Help: "HTTP request latency by method and route template.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		dbQueryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace, Subsystem: "db", Name: "query_duration_seconds", Help: "Database query latency by operation and table.",
+			Buckets: storageBuckets,
+		}, []string{"operation", "table", "status"}),
+		redisDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace, Subsystem: "redis", Name: "command_duration_seconds", Help: "Redis command latency by command.",
+			Buckets: storageBuckets,
+		}, []string{"command", "status"}),
+		transactions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace, Name: "transactions_total", Help: "Processed transactions by type and status.",
+		}, []string{"type", "status"}),
+		transactionAmount: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace, Name: "transaction_amount_total", Help: "Amount moved by successful transactions by type.",
+		}, []string{"type"}),
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace, Name: "logins_total", Help: "Login attempts by result.",
+		}, []string{"result"}),
+	}
+
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests, m.httpDuration, m.dbQueryDuration, m.redisDuration, m.transactions, m.transactionAmount, m.logins,
+	)
+
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{Registry: m.Registry})
+}
+
+// ObserveHTTPRequest records a served request
+func (m *Metrics) ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	m.httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// TransactionProcessed records a transaction, the amount only counts when it succeeded
+func (m *Metrics) TransactionProcessed(transactionType, status string, amount float64) {
+	m.transactions.WithLabelValues(transactionType, status).Inc()
+
+	if status == domain.TransactionStatusSuccess {
+		m.transactionAmount.WithLabelValues(transactionType).Add(amount)
+	}
+}
+
+// LoginAttempted records a login attempt
+func (m *Metrics) LoginAttempted(success bool) {
+	result := "failure"
+	if success {
+		result = "success"
+	}
+
+	m.logins.WithLabelValues(result).Inc()
+}
+
+// statusLabel is the status label of an operation
+func statusLabel(err error) string {
+	if err != nil {
+		return "error"
+	}
+
+	return "ok"
+}
+
+// Nop discards the business metrics, for tools and tests running the services without a registry
+type Nop struct{}
+
+// TransactionProcessed does nothing
+func (Nop) TransactionProcessed(_, _ string, _ float64) {}
+
+// LoginAttempted does nothing
+func (Nop) LoginAttempted(_ bool) {}
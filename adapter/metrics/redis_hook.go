@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisHook times every Redis command
+type redisHook struct {
+	metrics *Metrics
+}
+
+// InstrumentRedis records the latency of every command sent by the client
+func (m *Metrics) InstrumentRedis(redisClient *redis.Client) {
+	redisClient.AddHook(&redisHook{metrics: m})
+}
+
+// DialHook leaves dialing untouched
+func (h *redisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+// ProcessHook records the latency of a command
+func (h *redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+
+		h.observe(cmd.Name(), start, err)
+
+		return err
+	}
+}
+
+// ProcessPipelineHook records the latency of a pipeline as a whole
+func (h *redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+
+		h.observe("pipeline", start, err)
+
+		return err
+	}
+}
+
+// observe records the latency, a missing key is an answer and not a failure
+func (h *redisHook) observe(command string, start time.Time, err error) {
+	if errors.Is(err, redis.Nil) {
+		err = nil
+	}
+
+	h.metrics.redisDuration.WithLabelValues(command, statusLabel(err)).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,71 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/utils"
+)
+
+// SentMessage is a message recorded by a fake channel
+type SentMessage struct {
+	To      string
+	Title   string
+	Message string
+}
+
+// FakeChannel is a local stand-in for an email or SMS provider, it logs and records every message instead of sending it
+type FakeChannel struct {
+	name    string
+	address func(recipient *domain.NotificationRecipient) string
+
+	mu   sync.Mutex
+	sent []SentMessage
+}
+
+// NewFakeEmailChannel creates a fake email channel sending to the user's email address
+func NewFakeEmailChannel() *FakeChannel {
+	return &FakeChannel{
+		name:    domain.ChannelEmail,
+		address: func(recipient *domain.NotificationRecipient) string { return recipient.Email },
+	}
+}
+
+// NewFakeSMSChannel creates a fake SMS channel sending to the customer's phone number
+func NewFakeSMSChannel() *FakeChannel {
+	return &FakeChannel{
+		name:    domain.ChannelSMS,
+		address: func(recipient *domain.NotificationRecipient) string { return recipient.PhoneNumber },
+	}
+}
+
+// Name returns the name of the channel
+func (c *FakeChannel) Name() string {
+	return c.name
+}
+
+// Send records the message, it fails when the recipient has no address for the channel
+func (c *FakeChannel) Send(ctx context.Context, recipient *domain.NotificationRecipient, notification *domain.Notification) error {
+	to := c.address(recipient)
+	if to == "" {
+		return errors.New("recipient has no " + c.name + " address")
+	}
+
+	c.mu.Lock()
+	c.sent = append(c.sent, SentMessage{To: to, Title: notification.Title, Message: notification.Message})
+	c.mu.Unlock()
+
+	utils.Logger(ctx, "notifier").Info().Str("channel", c.name).Str("to", to).Str("title", notification.Title).Msg("Notification sent")
+
+	return nil
+}
+
+// Sent returns the messages recorded so far
+func (c *FakeChannel) Sent() []SentMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]SentMessage(nil), c.sent...)
+}
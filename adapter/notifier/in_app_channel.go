@@ -0,0 +1,29 @@
+// Package notifier contains the adapters delivering notifications to users
+package notifier
+
+import (
+	"context"
+
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/ports"
+)
+
+// InAppChannel stores notifications for the notification center of the dashboard
+type InAppChannel struct {
+	NotificationRepository ports.NotificationRepository
+}
+
+// NewInAppChannel creates a new in-app notification channel
+func NewInAppChannel(notificationRepo ports.NotificationRepository) *InAppChannel {
+	return &InAppChannel{NotificationRepository: notificationRepo}
+}
+
+// Name returns the name of the channel
+func (*InAppChannel) Name() string {
+	return domain.ChannelInApp
+}
+
+// Send stores the notification
+func (c *InAppChannel) Send(ctx context.Context, _ *domain.NotificationRecipient, notification *domain.Notification) error {
+	return c.NotificationRepository.Create(ctx, notification)
+}
@@ -0,0 +1,229 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	uuidType    = reflect.TypeOf(uuid.UUID{})
+	rawJSONType = reflect.TypeOf(json.RawMessage{})
+)
+
+// typeSchemas holds the schemas of the types that are encoded as strings or any JSON value
+var typeSchemas = map[reflect.Type]Schema{
+	timeType:    {Type: "string", Format: "date-time"},
+	uuidType:    {Type: "string", Format: "uuid"},
+	rawJSONType: {Description: "Any JSON value"},
+}
+
+// kindSchemas holds the schemas of the scalar kinds
+var kindSchemas = map[reflect.Kind]Schema{
+	reflect.Bool:    {Type: "boolean"},
+	reflect.Int:     {Type: "integer", Format: "int32"},
+	reflect.Int8:    {Type: "integer", Format: "int32"},
+	reflect.Int16:   {Type: "integer", Format: "int32"},
+	reflect.Int32:   {Type: "integer", Format: "int32"},
+	reflect.Uint:    {Type: "integer", Format: "int32"},
+	reflect.Uint8:   {Type: "integer", Format: "int32"},
+	reflect.Uint16:  {Type: "integer", Format: "int32"},
+	reflect.Uint32:  {Type: "integer", Format: "int32"},
+	reflect.Int64:   {Type: "integer", Format: "int64"},
+	reflect.Uint64:  {Type: "integer", Format: "int64"},
+	reflect.Float32: {Type: "number", Format: "double"},
+	reflect.Float64: {Type: "number", Format: "double"},
+	reflect.String:  {Type: "string"},
+}
+
+// schemaOf returns the schema of a type, structs are added to the components once and referenced by their name
+func (s *Spec) schemaOf(t reflect.Type) *Schema {
+	if schema, ok := typeSchemas[t]; ok {
+		return &schema
+	}
+
+	if schema, ok := kindSchemas[t.Kind()]; ok {
+		return &schema
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := s.schemaOf(t.Elem())
+		if schema.Ref == "" {
+			schema.Nullable = true
+		}
+
+		return schema
+	case reflect.Slice, reflect.Array:
+		return s.listSchema(t)
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.schemaOf(t.Elem())}
+	case reflect.Struct:
+		return s.structRef(t)
+	}
+
+	return &Schema{}
+}
+
+// listSchema returns the schema of a slice or array, byte slices are encoded as base64 strings
+func (s *Spec) listSchema(t reflect.Type) *Schema {
+	if t.Elem().Kind() == reflect.Uint8 {
+		return &Schema{Type: "string", Format: "byte"}
+	}
+
+	return &Schema{Type: "array", Items: s.schemaOf(t.Elem())}
+}
+
+// structRef adds the schema of a struct to the components and returns a reference to it
+func (s *Spec) structRef(t reflect.Type) *Schema {
+	name := t.Name()
+	ref := &Schema{Ref: "#/components/schemas/" + name}
+
+	if _, ok := s.schemas[name]; ok {
+		return ref
+	}
+
+	// registered before the fields so recursive types end in a reference
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	s.schemas[name] = schema
+
+	s.addFields(schema, t)
+
+	return ref
+}
+
+// addFields adds the JSON fields of a struct to the schema, the fields of embedded structs are promoted like
+// encoding/json does
+func (s *Spec) addFields(schema *Schema, t reflect.Type) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			s.addFields(schema, field.Type)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		property := s.schemaOf(field.Type)
+		if applyBinding(property, field.Tag.Get("binding")) {
+			schema.Required = append(schema.Required, name)
+		}
+
+		schema.Properties[name] = property
+	}
+}
+
+// bindingRules documents the validation rules of binding tags on the schema, rules without an entry are not documented
+var bindingRules = map[string]func(schema *Schema, param string){
+	"oneof": func(schema *Schema, param string) {
+		if schema.Type == "string" {
+			schema.Enum = strings.Fields(param)
+		}
+	},
+	"email":   formatRule("email"),
+	"url":     formatRule("uri"),
+	"uuid":    formatRule("uuid"),
+	"numeric": patternRule("^[0-9]+$"),
+	"e164":    patternRule(`^\+[1-9][0-9]{1,14}$`),
+	"min":     lowerBoundRule,
+	"gte":     lowerBoundRule,
+	"max":     upperBoundRule,
+	"lte":     upperBoundRule,
+	"len": func(schema *Schema, param string) {
+		lowerBoundRule(schema, param)
+		upperBoundRule(schema, param)
+	},
+}
+
+// applyBinding documents the validation rules of a binding tag on the schema and reports whether the field is required
+func applyBinding(schema *Schema, binding string) bool {
+	if binding == "" {
+		return false
+	}
+
+	rules, itemRules, hasItems := strings.Cut(binding, ",dive")
+	if hasItems && schema.Items != nil {
+		applyBinding(schema.Items, strings.TrimPrefix(itemRules, ","))
+	}
+
+	required := false
+
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		required = required || name == "required"
+
+		if apply, ok := bindingRules[name]; ok {
+			apply(schema, param)
+		}
+	}
+
+	return required
+}
+
+// formatRule returns a rule documenting the format of a string
+func formatRule(format string) func(schema *Schema, param string) {
+	return func(schema *Schema, _ string) {
+		schema.Format = format
+	}
+}
+
+// patternRule returns a rule documenting the pattern a string matches
+func patternRule(pattern string) func(schema *Schema, param string) {
+	return func(schema *Schema, _ string) {
+		schema.Pattern = pattern
+	}
+}
+
+// lowerBoundRule documents the minimum of a number, string length or array size
+func lowerBoundRule(schema *Schema, param string) {
+	setBound(schema, param, true)
+}
+
+// upperBoundRule documents the maximum of a number, string length or array size
+func upperBoundRule(schema *Schema, param string) {
+	setBound(schema, param, false)
+}
+
+// setBound sets the lower or upper bound of a number, the length of a string or the size of an array
+func setBound(schema *Schema, param string, lower bool) {
+	value, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+
+	size := int(value)
+
+	switch schema.Type {
+	case "string":
+		if lower {
+			schema.MinLength = &size
+		} else {
+			schema.MaxLength = &size
+		}
+	case "array":
+		if lower {
+			schema.MinItems = &size
+		} else {
+			schema.MaxItems = &size
+		}
+	case "integer", "number":
+		if lower {
+			schema.Minimum = &value
+		} else {
+			schema.Maximum = &value
+		}
+	}
+}
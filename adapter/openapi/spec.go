@@ -0,0 +1,243 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/okyws/dashboard-backend/dto"
+)
+
+// bearerAuth is the name of the security scheme of the routes behind a JWT
+const bearerAuth = "bearerAuth"
+
+// Route describes an operation of the API
+type Route struct {
+	Method  string
+	Path    string // gin path such as /api/v1/users/:id
+	Tag     string
+	Summary string
+	Auth    bool
+	Query   []Parameter
+
+	Request     any    // DTO bound from the JSON body, nil for routes without a body
+	RequestType string // media type of the body, application/json when empty
+	Response    any    // DTO of the data of the success response, nil when the data is null
+	Status      int    // status of the success response, 200 when zero, 204 answers without a body
+
+	// Raw routes answer with the response DTO itself in ContentType, application/json when empty, instead of
+	// wrapping it in the success response
+	Raw         bool
+	ContentType string
+
+	Deprecated bool
+}
+
+// Spec builds an OpenAPI document from the routes
+type Spec struct {
+	document Document
+	schemas  map[string]*Schema
+}
+
+// New creates an empty spec of the API
+func New(info Info) *Spec {
+	schemas := make(map[string]*Schema)
+
+	return &Spec{
+		document: Document{
+			OpenAPI: Version,
+			Info:    info,
+			Paths:   make(map[string]PathItem),
+			Components: Components{
+				Schemas: schemas,
+				SecuritySchemes: map[string]SecurityScheme{
+					bearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT", Description: "Token of POST /api/v2/auth/login"},
+				},
+			},
+		},
+		schemas: schemas,
+	}
+}
+
+// Add adds the operations of the routes
+func (s *Spec) Add(routes ...Route) {
+	for _, route := range routes {
+		path := Path(route.Path)
+
+		item, ok := s.document.Paths[path]
+		if !ok {
+			item = make(PathItem)
+			s.document.Paths[path] = item
+		}
+
+		item[strings.ToLower(route.Method)] = s.operation(route)
+		s.addTag(route.Tag)
+	}
+}
+
+// Document returns the OpenAPI document of the routes added so far
+func (s *Spec) Document() *Document {
+	return &s.document
+}
+
+// addTag adds the tag to the document once, in the order the tags are first used
+func (s *Spec) addTag(name string) {
+	if name == "" {
+		return
+	}
+
+	for _, tag := range s.document.Tags {
+		if tag.Name == name {
+			return
+		}
+	}
+
+	s.document.Tags = append(s.document.Tags, Tag{Name: name})
+}
+
+// operation builds the operation of a route
+func (s *Spec) operation(route Route) *Operation {
+	operation := &Operation{
+		Summary:     route.Summary,
+		OperationID: operationID(route.Method, route.Path),
+		Parameters:  append(pathParameters(route.Path), route.Query...),
+		Responses:   make(map[string]Response),
+		Deprecated:  route.Deprecated,
+	}
+
+	if route.Tag != "" {
+		operation.Tags = []string{route.Tag}
+	}
+
+	if route.Auth {
+		operation.Security = []map[string][]string{{bearerAuth: {}}}
+	}
+
+	if route.Request != nil {
+		requestType := route.RequestType
+		if requestType == "" {
+			requestType = "application/json"
+		}
+
+		operation.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{requestType: {Schema: s.schemaOf(reflect.TypeOf(route.Request))}},
+		}
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	if status == http.StatusNoContent {
+		operation.Responses[strconv.Itoa(status)] = Response{Description: "Success"}
+	} else {
+		operation.Responses[strconv.Itoa(status)] = s.successResponse(route)
+	}
+
+	if route.Raw {
+		return operation
+	}
+
+	errorResponse := Response{
+		Content: map[string]MediaType{"application/json": {Schema: s.schemaOf(reflect.TypeOf(dto.ErrorResponseDTO{}))}},
+	}
+
+	if route.Auth {
+		errorResponse.Description = "Missing or invalid token"
+		operation.Responses[strconv.Itoa(http.StatusUnauthorized)] = errorResponse
+	}
+
+	errorResponse.Description = "Client error, error_code names the problem"
+	operation.Responses["4XX"] = errorResponse
+
+	errorResponse.Description = "Server error"
+	operation.Responses["5XX"] = errorResponse
+
+	return operation
+}
+
+// successResponse returns the success response of a route, wrapped in the success response of the API unless raw
+func (s *Spec) successResponse(route Route) Response {
+	response := Response{Description: "Success"}
+
+	if route.Raw {
+		contentType := route.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+
+		schema := &Schema{Type: "string"}
+		if route.Response != nil {
+			schema = s.schemaOf(reflect.TypeOf(route.Response))
+		}
+
+		response.Content = map[string]MediaType{contentType: {Schema: schema}}
+
+		return response
+	}
+
+	envelope := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"status":  {Type: "string", Enum: []string{"success"}},
+			"code":    {Type: "integer", Format: "int32"},
+			"message": {Type: "string", Description: "Message in the language negotiated from Accept-Language"},
+		},
+		Required: []string{"status", "code", "message"},
+	}
+
+	if route.Response != nil {
+		envelope.Properties["data"] = s.schemaOf(reflect.TypeOf(route.Response))
+		envelope.Required = append(envelope.Required, "data")
+	}
+
+	response.Content = map[string]MediaType{"application/json": {Schema: envelope}}
+
+	return response
+}
+
+var pathParameter = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+// Path converts a gin path such as /api/v1/users/:id to the OpenAPI path /api/v1/users/{id}
+func Path(ginPath string) string {
+	return pathParameter.ReplaceAllString(ginPath, "{$1}")
+}
+
+// pathParameters returns the parameters of the path segments
+func pathParameters(ginPath string) []Parameter {
+	matches := pathParameter.FindAllStringSubmatch(ginPath, -1)
+
+	parameters := make([]Parameter, 0, len(matches))
+	for _, match := range matches {
+		parameters = append(parameters, Parameter{Name: match[1], In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+
+	return parameters
+}
+
+var nonWord = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// operationID derives a unique operation ID from the method and the path, e.g. get_api_v1_users_id
+func operationID(method, ginPath string) string {
+	return strings.ToLower(method) + strings.TrimRight(nonWord.ReplaceAllString(ginPath, "_"), "_")
+}
+
+// Operations returns the method and OpenAPI path of every operation, sorted
+func (d *Document) Operations() []string {
+	operations := make([]string, 0, len(d.Paths))
+
+	for path, item := range d.Paths {
+		for method := range item {
+			operations = append(operations, strings.ToUpper(method)+" "+path)
+		}
+	}
+
+	sort.Strings(operations)
+
+	return operations
+}
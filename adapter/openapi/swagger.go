@@ -0,0 +1,8 @@
+package openapi
+
+import _ "embed"
+
+// SwaggerUI is the page rendering the document of /openapi.json with Swagger UI, the scripts are loaded from unpkg
+//
+//go:embed swagger.html
+var SwaggerUI []byte
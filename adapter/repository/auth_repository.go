@@ -1,15 +1,18 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 	"time"
 
-	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/okyws/dashboard-backend/utils"
 	"github.com/redis/go-redis/v9"
-	"github.com/rs/zerolog/log"
 )
 
+// tokenExpiryLayout is the layout of the token expiration times handed out at login, in local time
+const tokenExpiryLayout = "2006-01-02 15:04:05"
+
 // AuthRepositoryRedis is the implementation of the authentication repository using Redis
 type AuthRepositoryRedis struct {
 	RedisClient *redis.Client
@@ -20,37 +23,51 @@ func NewAuthRepositoryRedis(redisClient *redis.Client) *AuthRepositoryRedis {
 	return &AuthRepositoryRedis{RedisClient: redisClient}
 }
 
-// SaveToken stores the token and its expiration time in Redis
-func (r *AuthRepositoryRedis) SaveToken(ctx *gin.Context, userID uuid.UUID, token, expiresAt string) error {
-	expirationTime, err := time.Parse("2006-01-02 15:04:05", expiresAt)
+// SaveToken stores the user and expiration time of the token in a Redis hash expiring with the token
+func (r *AuthRepositoryRedis) SaveToken(ctx context.Context, userID uuid.UUID, token, expiresAt string) error {
+	expirationTime, err := parseTokenExpiry(expiresAt)
 	if err != nil {
-		return fmt.Errorf("failed to parse expires_at: %v", err)
+		return err
 	}
 
-	err = r.RedisClient.SetEx(ctx, token, userID, time.Until(expirationTime)).Err()
+	_, err = r.RedisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, token, "user_id", userID.String(), "expires_at", expiresAt)
+		pipe.ExpireAt(ctx, token, expirationTime)
+
+		return nil
+	})
 	if err != nil {
 		return fmt.Errorf("failed to save token to Redis: %v", err)
 	}
 
-	log.Info().Str("userID", userID.String()).Msg("Token saved to Redis")
+	utils.Logger(ctx, "repository").Info().Str("userID", userID.String()).Msg("Token saved to Redis")
 
 	return nil
 }
 
 // GetTokenExpiration retrieves the expiration time of a token from Redis
-func (r *AuthRepositoryRedis) GetTokenExpiration(ctx *gin.Context, token string) (*time.Time, error) {
+func (r *AuthRepositoryRedis) GetTokenExpiration(ctx context.Context, token string) (*time.Time, error) {
 	expiresAtStr, err := r.RedisClient.HGet(ctx, token, "expires_at").Result()
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch token expiration from Redis: %v", err)
 	}
 
-	// Parse the expiration time string into a time.Time object
-	expiresAt, err := time.Parse("2006-01-02 15:04:05", expiresAtStr)
+	expiresAt, err := parseTokenExpiry(expiresAtStr)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse expires_at: %v", err)
+		return nil, err
 	}
 
-	log.Info().Msg("Token expiration retrieved from Redis")
+	utils.Logger(ctx, "repository").Info().Msg("Token expiration retrieved from Redis")
 
 	return &expiresAt, nil
 }
+
+// parseTokenExpiry parses a token expiration time
+func parseTokenExpiry(expiresAt string) (time.Time, error) {
+	expirationTime, err := time.ParseInLocation(tokenExpiryLayout, expiresAt, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse expires_at: %v", err)
+	}
+
+	return expirationTime, nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/okyws/dashboard-backend/utils"
+)
+
+// ErrTokenNotFound is returned for tokens that were never saved or have expired
+var ErrTokenNotFound = errors.New("token not found")
+
+// storedToken is a token saved by the in-memory authentication repository
+type storedToken struct {
+	userID    uuid.UUID
+	expiresAt time.Time
+}
+
+// AuthRepositoryMemory is the in-memory implementation of the authentication repository for single node setups,
+// tokens are lost on restart
+type AuthRepositoryMemory struct {
+	mu     sync.Mutex
+	tokens map[string]storedToken
+}
+
+// NewAuthRepositoryMemory creates a new in-memory authentication repository
+func NewAuthRepositoryMemory() *AuthRepositoryMemory {
+	return &AuthRepositoryMemory{tokens: make(map[string]storedToken)}
+}
+
+// SaveToken stores the token until its expiration time
+func (r *AuthRepositoryMemory) SaveToken(ctx context.Context, userID uuid.UUID, token, expiresAt string) error {
+	expirationTime, err := parseTokenExpiry(expiresAt)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// drop expired tokens so the map only grows with the active sessions
+	now := time.Now()
+	for saved, stored := range r.tokens {
+		if !now.Before(stored.expiresAt) {
+			delete(r.tokens, saved)
+		}
+	}
+
+	if now.Before(expirationTime) {
+		r.tokens[token] = storedToken{userID: userID, expiresAt: expirationTime}
+	}
+
+	utils.Logger(ctx, "repository").Info().Str("userID", userID.String()).Msg("Token saved in memory")
+
+	return nil
+}
+
+// GetTokenExpiration retrieves the expiration time of a token that has not expired yet
+func (r *AuthRepositoryMemory) GetTokenExpiration(_ context.Context, token string) (*time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.tokens[token]
+	if !ok || !time.Now().Before(stored.expiresAt) {
+		delete(r.tokens, token)
+		return nil, ErrTokenNotFound
+	}
+
+	expiresAt := stored.expiresAt
+
+	return &expiresAt, nil
+}
@@ -2,28 +2,46 @@
 package repository
 
 import (
+	"context"
+	"errors"
+
 	"github.com/okyws/dashboard-backend/domain"
 	"github.com/okyws/dashboard-backend/ports"
+	"github.com/okyws/dashboard-backend/utils"
 	"gorm.io/gorm"
 )
 
+// ErrAccountNumberExhausted is returned when the serials of a product code ran out
+var ErrAccountNumberExhausted = domain.NewError(domain.ErrorConflict, "account_number_exhausted",
+	"no account number is left for the account type")
+
 // BankAccountRepositoryAdapter is the adapter for the bank information repository
 type BankAccountRepositoryAdapter struct {
-	db *gorm.DB
+	db     *gorm.DB
+	scheme ports.AccountNumberScheme
 }
 
 // NewBankAccountRepositoryAdapter creates a new bank information repository adapter via dependency injection
-func NewBankAccountRepositoryAdapter(db *gorm.DB) ports.BankAccountRepository {
-	return &BankAccountRepositoryAdapter{db: db}
+func NewBankAccountRepositoryAdapter(db *gorm.DB, scheme ports.AccountNumberScheme) ports.BankAccountRepository {
+	return &BankAccountRepositoryAdapter{db: db, scheme: scheme}
 }
 
-// Create inserts a new bank information into the database
-func (r *BankAccountRepositoryAdapter) Create(entity *domain.BankAccount) (*domain.BankAccount, error) {
+// Create inserts a new bank information into the database. When no account number was assigned the next serial of its
+// product code is taken from the sequence, in the same transaction as the account.
+func (r *BankAccountRepositoryAdapter) Create(ctx context.Context, entity *domain.BankAccount) (*domain.BankAccount, error) {
 	var createdData domain.BankAccount
 
-	err := r.db.Transaction(func(tx *gorm.DB) error {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if entity.AccountNumber == "" {
+			accountNumber, err := r.nextAccountNumber(tx, entity.AccountType)
+			if err != nil {
+				return err
+			}
+
+			entity.AccountNumber = accountNumber
+		}
+
 		if err := tx.Create(entity).Error; err != nil {
-			tx.Rollback()
 			return err
 		}
 
@@ -37,33 +55,65 @@ func (r *BankAccountRepositoryAdapter) Create(entity *domain.BankAccount) (*doma
 	return &createdData, nil
 }
 
-// Delete removes a bank information by ID
-func (r *BankAccountRepositoryAdapter) Delete(id string) error {
-	result := r.db.Delete(&domain.BankAccount{}, "id = ?", id)
-	if result.Error != nil {
-		return result.Error
-	}
+// nextAccountNumber takes serials from the sequence of the product code of the account type until its number is free,
+// numbers given out before the sequence existed or assigned by hand are skipped. The sequence row stays locked until
+// the transaction ends, so concurrent accounts of a product never get the same serial.
+func (r *BankAccountRepositoryAdapter) nextAccountNumber(tx *gorm.DB, accountType string) (string, error) {
+	productCode := r.scheme.ProductCode(accountType)
+
+	for {
+		var serial uint64
+
+		err := tx.Raw(`INSERT INTO account_number_sequences (product_code, last_serial) VALUES (?, 1)
+			ON CONFLICT (product_code) DO UPDATE SET last_serial = account_number_sequences.last_serial + 1
+			RETURNING last_serial`, productCode).Scan(&serial).Error
+		if err != nil {
+			return "", err
+		}
+
+		accountNumber, err := r.scheme.Generate(accountType, serial)
+		if errors.Is(err, utils.ErrSerialOutOfRange) {
+			return "", ErrAccountNumberExhausted
+		}
 
-	if result.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
+		if err != nil {
+			return "", err
+		}
+
+		var count int64
+		if err := tx.Model(&domain.BankAccount{}).Unscoped().Where("account_number = ?", accountNumber).Count(&count).Error; err != nil {
+			return "", err
+		}
+
+		if count == 0 {
+			return accountNumber, nil
+		}
 	}
+}
 
-	return nil
+// Delete removes a bank information by ID
+func (r *BankAccountRepositoryAdapter) Delete(ctx context.Context, id string) error {
+	return r.DeleteVersion(ctx, id, 0)
+}
+
+// DeleteVersion removes a bank information by ID when it is still at the version, at any version when it is 0
+func (r *BankAccountRepositoryAdapter) DeleteVersion(ctx context.Context, id string, version uint) error {
+	return deleteVersion(conn(ctx, r.db), &domain.BankAccount{}, id, version)
 }
 
 // GetAll fetches all bank information data with pagination
-func (r *BankAccountRepositoryAdapter) GetAll(limit int, offset int) ([]domain.BankAccount, error) {
+func (r *BankAccountRepositoryAdapter) GetAll(ctx context.Context, limit int, offset int) ([]domain.BankAccount, error) {
 	var result []domain.BankAccount
-	err := r.db.Preload("User").Limit(limit).Offset(offset).Find(&result).Error
+	err := conn(ctx, r.db).Preload("User").Limit(limit).Offset(offset).Find(&result).Error
 
 	return result, err
 }
 
 // GetByID fetches a bank information by ID and returns nil if not found
-func (r *BankAccountRepositoryAdapter) GetByID(id string) (*domain.BankAccount, error) {
+func (r *BankAccountRepositoryAdapter) GetByID(ctx context.Context, id string) (*domain.BankAccount, error) {
 	var result domain.BankAccount
 
-	err := r.db.Preload("User").First(&result, "id = ?", id).Error
+	err := conn(ctx, r.db).Preload("User").First(&result, "id = ?", id).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, gorm.ErrRecordNotFound
@@ -75,20 +125,26 @@ func (r *BankAccountRepositoryAdapter) GetByID(id string) (*domain.BankAccount,
 	return &result, nil
 }
 
-// Update updates a specific bank information
-func (r *BankAccountRepositoryAdapter) Update(entity *domain.BankAccount) (*domain.BankAccount, error) {
+// Update updates a specific bank information when it is still at the version of entity, at any version when it is 0,
+// and bumps the version. A balance computed from an account that was changed in between is refused instead of
+// overwriting the other change.
+func (r *BankAccountRepositoryAdapter) Update(ctx context.Context, entity *domain.BankAccount) (*domain.BankAccount, error) {
 	var updatedData domain.BankAccount
 
-	err := r.db.Transaction(func(tx *gorm.DB) error {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Preload("User").First(&updatedData, "id = ?", entity.ID).Error; err != nil {
 			return err
 		}
 
-		if err := tx.Model(&updatedData).Updates(entity).Error; err != nil {
+		expected, err := expectedVersion(updatedData.Version, entity.Version)
+		if err != nil {
 			return err
 		}
 
-		return nil
+		changes := *entity
+		changes.Version = expected + 1
+
+		return versionWritten(tx.Model(&updatedData).Where("version = ?", expected).Updates(&changes))
 	})
 
 	if err != nil {
@@ -99,29 +155,63 @@ func (r *BankAccountRepositoryAdapter) Update(entity *domain.BankAccount) (*doma
 }
 
 // GetByUserID returns the bank information for a user
-func (r *BankAccountRepositoryAdapter) GetByUserID(userID string) ([]domain.BankAccount, error) {
+func (r *BankAccountRepositoryAdapter) GetByUserID(ctx context.Context, userID string) ([]domain.BankAccount, error) {
 	var BankAccount []domain.BankAccount
 
-	err := r.db.Preload("User").Find(&BankAccount, "user_id = ?", userID).Error
+	err := conn(ctx, r.db).Preload("User").Find(&BankAccount, "user_id = ?", userID).Error
 
 	return BankAccount, err
 }
 
 // CountBankAccount returns the count of main bank accounts
-func (r *BankAccountRepositoryAdapter) CountBankAccount(userID string, accountType string) (int64, error) {
+func (r *BankAccountRepositoryAdapter) CountBankAccount(ctx context.Context, userID string, accountType string) (int64, error) {
 	var count int64
 
-	err := r.db.Model(domain.BankAccount{}).Where("account_type = ? AND user_id = ?", accountType, userID).Count(&count).Error
+	err := conn(ctx, r.db).Model(domain.BankAccount{}).Where("account_type = ? AND user_id = ?", accountType, userID).Count(&count).Error
 
 	return count, err
 }
 
 // GetByAccountNumber fetches a bank account by its account number
-func (r *BankAccountRepositoryAdapter) GetByAccountNumber(accountNumber string) (*domain.BankAccount, error) {
+func (r *BankAccountRepositoryAdapter) GetByAccountNumber(ctx context.Context, accountNumber string) (*domain.BankAccount, error) {
 	var account domain.BankAccount
-	if err := r.db.Where("account_number = ?", accountNumber).First(&account).Error; err != nil {
+	if err := conn(ctx, r.db).Where("account_number = ?", accountNumber).First(&account).Error; err != nil {
 		return nil, err
 	}
 
 	return &account, nil
 }
+
+// UpdateStatus changes the status of a bank account when it is still at the version, at any version when it is 0
+func (r *BankAccountRepositoryAdapter) UpdateStatus(ctx context.Context, id string, version uint, status bool) (*domain.BankAccount, error) {
+	var updatedData domain.BankAccount
+
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Preload("User").First(&updatedData, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		expected, err := expectedVersion(updatedData.Version, version)
+		if err != nil || updatedData.AccountStatus == status {
+			return err
+		}
+
+		// update by ID so the preloaded user is not saved back as an association
+		result := tx.Model(&domain.BankAccount{}).Where("id = ? AND version = ?", updatedData.ID, expected).
+			Updates(map[string]any{"account_status": status, "version": expected + 1})
+		if err := versionWritten(result); err != nil {
+			return err
+		}
+
+		updatedData.AccountStatus = status
+		updatedData.Version = expected + 1
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &updatedData, nil
+}
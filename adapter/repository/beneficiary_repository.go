@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/ports"
+	"gorm.io/gorm"
+)
+
+// BeneficiaryRepositoryAdapter is the adapter for the beneficiary repository
+type BeneficiaryRepositoryAdapter struct {
+	db *gorm.DB
+}
+
+// NewBeneficiaryRepositoryAdapter creates a new beneficiary repository adapter via dependency injection
+func NewBeneficiaryRepositoryAdapter(db *gorm.DB) ports.BeneficiaryRepository {
+	return &BeneficiaryRepositoryAdapter{db: db}
+}
+
+// Create inserts a new beneficiary into the database
+func (r *BeneficiaryRepositoryAdapter) Create(ctx context.Context, beneficiary *domain.Beneficiary) (*domain.Beneficiary, error) {
+	if err := conn(ctx, r.db).Create(beneficiary).Error; err != nil {
+		return nil, err
+	}
+
+	return beneficiary, nil
+}
+
+// GetByUserID fetches the beneficiary book of a user with pagination
+func (r *BeneficiaryRepositoryAdapter) GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]domain.Beneficiary, error) {
+	var beneficiaries []domain.Beneficiary
+	err := conn(ctx, r.db).Where("user_id = ?", userID).Order("nickname ASC").Limit(limit).Offset(offset).Find(&beneficiaries).Error
+
+	return beneficiaries, err
+}
+
+// GetByID fetches a beneficiary of the user by ID
+func (r *BeneficiaryRepositoryAdapter) GetByID(ctx context.Context, userID uuid.UUID, id string) (*domain.Beneficiary, error) {
+	var beneficiary domain.Beneficiary
+
+	if err := conn(ctx, r.db).First(&beneficiary, "id = ? AND user_id = ?", id, userID).Error; err != nil {
+		return nil, err
+	}
+
+	return &beneficiary, nil
+}
+
+// GetByAccountNumber fetches the beneficiary of the user saved for an account number
+func (r *BeneficiaryRepositoryAdapter) GetByAccountNumber(ctx context.Context, userID uuid.UUID, accountNumber string) (*domain.Beneficiary, error) {
+	var beneficiary domain.Beneficiary
+
+	if err := conn(ctx, r.db).First(&beneficiary, "account_number = ? AND user_id = ?", accountNumber, userID).Error; err != nil {
+		return nil, err
+	}
+
+	return &beneficiary, nil
+}
+
+// UpdateNickname renames a beneficiary of the user
+func (r *BeneficiaryRepositoryAdapter) UpdateNickname(ctx context.Context, userID uuid.UUID, id, nickname string) (*domain.Beneficiary, error) {
+	beneficiary, err := r.GetByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn(ctx, r.db).Model(beneficiary).Update("nickname", nickname).Error; err != nil {
+		return nil, err
+	}
+
+	return beneficiary, nil
+}
+
+// Delete removes a beneficiary of the user permanently so the account can be saved again later
+func (r *BeneficiaryRepositoryAdapter) Delete(ctx context.Context, userID uuid.UUID, id string) error {
+	result := conn(ctx, r.db).Unscoped().Delete(&domain.Beneficiary{}, "id = ? AND user_id = ?", id, userID)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
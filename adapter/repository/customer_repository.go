@@ -2,6 +2,9 @@
 package repository
 
 import (
+	"context"
+	"maps"
+
 	"github.com/okyws/dashboard-backend/domain"
 	"github.com/okyws/dashboard-backend/ports"
 	"gorm.io/gorm"
@@ -18,12 +21,11 @@ func NewCustomerRepositoryAdapter(db *gorm.DB) ports.CustomerRepository {
 }
 
 // Create inserts a new customer into the database using a transaction.
-func (r *CustomerRepositoryAdapter) Create(customer *domain.Customer) (*domain.Customer, error) {
+func (r *CustomerRepositoryAdapter) Create(ctx context.Context, customer *domain.Customer) (*domain.Customer, error) {
 	var createdCustomer domain.Customer
 
-	err := r.db.Transaction(func(tx *gorm.DB) error {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Create(customer).Error; err != nil {
-			tx.Rollback()
 			return err
 		}
 
@@ -38,10 +40,10 @@ func (r *CustomerRepositoryAdapter) Create(customer *domain.Customer) (*domain.C
 }
 
 // GetByID fetches a customer by ID, returning nil if not found.
-func (r *CustomerRepositoryAdapter) GetByID(id string) (*domain.Customer, error) {
+func (r *CustomerRepositoryAdapter) GetByID(ctx context.Context, id string) (*domain.Customer, error) {
 	var customer domain.Customer
 
-	err := r.db.Preload("User").First(&customer, "id = ?", id).Error
+	err := conn(ctx, r.db).Preload("User").First(&customer, "id = ?", id).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, gorm.ErrRecordNotFound
@@ -54,10 +56,10 @@ func (r *CustomerRepositoryAdapter) GetByID(id string) (*domain.Customer, error)
 }
 
 // GetCustomerByUserID fetches a customer by associated User ID.
-func (r *CustomerRepositoryAdapter) GetCustomerByUserID(userID string) (*domain.Customer, error) {
+func (r *CustomerRepositoryAdapter) GetCustomerByUserID(ctx context.Context, userID string) (*domain.Customer, error) {
 	var customer domain.Customer
 
-	err := r.db.Preload("User").First(&customer, "user_id = ?", userID).Error
+	err := conn(ctx, r.db).Preload("User").First(&customer, "user_id = ?", userID).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, gorm.ErrRecordNotFound
@@ -70,10 +72,10 @@ func (r *CustomerRepositoryAdapter) GetCustomerByUserID(userID string) (*domain.
 }
 
 // GetCustomerByPhoneNumber fetches a customer by phone number.
-func (r *CustomerRepositoryAdapter) GetCustomerByPhoneNumber(phoneNumber string) (*domain.Customer, error) {
+func (r *CustomerRepositoryAdapter) GetCustomerByPhoneNumber(ctx context.Context, phoneNumber string) (*domain.Customer, error) {
 	var customer domain.Customer
 
-	err := r.db.Preload("User").First(&customer, "phone_number = ?", phoneNumber).Error
+	err := conn(ctx, r.db).Preload("User").First(&customer, "phone_number = ?", phoneNumber).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, gorm.ErrRecordNotFound
@@ -85,17 +87,25 @@ func (r *CustomerRepositoryAdapter) GetCustomerByPhoneNumber(phoneNumber string)
 	return &customer, nil
 }
 
-// Update updates an existing customer in the database.
-func (r *CustomerRepositoryAdapter) Update(customer *domain.Customer) (*domain.Customer, error) {
+// Update updates an existing customer in the database when it is still at the version of customer, at any version when
+// it is 0, and bumps the version.
+func (r *CustomerRepositoryAdapter) Update(ctx context.Context, customer *domain.Customer) (*domain.Customer, error) {
 	var updatedCustomer domain.Customer
 
-	err := r.db.Transaction(func(tx *gorm.DB) error {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Preload("User").First(&updatedCustomer, "id = ?", customer.ID).Error; err != nil {
-			tx.Rollback()
 			return err
 		}
 
-		return tx.Preload("User").Model(&updatedCustomer).Updates(customer).Error
+		expected, err := expectedVersion(updatedCustomer.Version, customer.Version)
+		if err != nil {
+			return err
+		}
+
+		changes := *customer
+		changes.Version = expected + 1
+
+		return versionWritten(tx.Preload("User").Model(&updatedCustomer).Where("version = ?", expected).Updates(&changes))
 	})
 
 	if err != nil {
@@ -105,24 +115,57 @@ func (r *CustomerRepositoryAdapter) Update(customer *domain.Customer) (*domain.C
 	return &updatedCustomer, nil
 }
 
-// Delete removes a customer by ID and ensures that a customer was actually deleted.
-func (r *CustomerRepositoryAdapter) Delete(id string) error {
-	result := r.db.Delete(&domain.Customer{}, "id = ?", id)
-	if result.Error != nil {
-		return result.Error
-	}
+// Patch writes the changed columns of a customer, zero values included, when it is still at the version, at any version
+// when it is 0, and returns the updated customer
+func (r *CustomerRepositoryAdapter) Patch(ctx context.Context, id string, version uint, changes map[string]any) (*domain.Customer, error) {
+	var patchedCustomer domain.Customer
+
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// the user is loaded after the update, gorm would otherwise save it along with the changes
+		if err := tx.First(&patchedCustomer, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		expected, err := expectedVersion(patchedCustomer.Version, version)
+		if err != nil {
+			return err
+		}
+
+		if len(changes) == 0 {
+			return tx.Preload("User").First(&patchedCustomer, "id = ?", id).Error
+		}
+
+		columns := maps.Clone(changes)
+		columns["version"] = expected + 1
+
+		if err := versionWritten(tx.Model(&patchedCustomer).Where("version = ?", expected).Updates(columns)); err != nil {
+			return err
+		}
+
+		return tx.Preload("User").First(&patchedCustomer, "id = ?", id).Error
+	})
 
-	if result.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return &patchedCustomer, nil
+}
+
+// Delete removes a customer by ID and ensures that a customer was actually deleted.
+func (r *CustomerRepositoryAdapter) Delete(ctx context.Context, id string) error {
+	return r.DeleteVersion(ctx, id, 0)
+}
+
+// DeleteVersion removes a customer by ID when it is still at the version, at any version when it is 0.
+func (r *CustomerRepositoryAdapter) DeleteVersion(ctx context.Context, id string, version uint) error {
+	return deleteVersion(conn(ctx, r.db), &domain.Customer{}, id, version)
 }
 
 // GetAll fetches customers with pagination.
-func (r *CustomerRepositoryAdapter) GetAll(limit, offset int) ([]domain.Customer, error) {
+func (r *CustomerRepositoryAdapter) GetAll(ctx context.Context, limit, offset int) ([]domain.Customer, error) {
 	var customers []domain.Customer
-	err := r.db.Preload("User").Limit(limit).Offset(offset).Find(&customers).Error
+	err := conn(ctx, r.db).Preload("User").Limit(limit).Offset(offset).Find(&customers).Error
 
 	return customers, err
 }
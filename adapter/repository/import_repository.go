@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/ports"
+	"gorm.io/gorm"
+)
+
+// rowErrorBatchSize is the number of row errors inserted by a single statement
+const rowErrorBatchSize = 500
+
+// ImportRepositoryAdapter is the adapter for the import job repository
+type ImportRepositoryAdapter struct {
+	db *gorm.DB
+}
+
+// NewImportRepositoryAdapter creates a new import job repository adapter via dependency injection
+func NewImportRepositoryAdapter(db *gorm.DB) ports.ImportRepository {
+	return &ImportRepositoryAdapter{db: db}
+}
+
+// NewImportRepositories creates the repositories the rows of an import are written with
+func NewImportRepositories(db *gorm.DB, scheme ports.AccountNumberScheme) ports.ImportRepositories {
+	return ports.ImportRepositories{
+		Users:        NewUserRepositoryAdapter(db),
+		Customers:    NewCustomerRepositoryAdapter(db),
+		BankAccounts: NewBankAccountRepositoryAdapter(db, scheme),
+		Outbox:       NewOutboxRepositoryAdapter(db),
+	}
+}
+
+// Create inserts a new import job into the database
+func (r *ImportRepositoryAdapter) Create(ctx context.Context, job *domain.ImportJob) (*domain.ImportJob, error) {
+	if err := conn(ctx, r.db).Create(job).Error; err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// GetByID fetches an import job by ID
+func (r *ImportRepositoryAdapter) GetByID(ctx context.Context, id string) (*domain.ImportJob, error) {
+	var job domain.ImportJob
+
+	if err := conn(ctx, r.db).First(&job, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// GetAll fetches import jobs with pagination, the latest first and without their files
+func (r *ImportRepositoryAdapter) GetAll(ctx context.Context, limit, offset int) ([]domain.ImportJob, error) {
+	var jobs []domain.ImportJob
+
+	err := conn(ctx, r.db).Omit("payload").Order("created_at DESC").Limit(limit).Offset(offset).Find(&jobs).Error
+
+	return jobs, err
+}
+
+// ClaimNext marks the oldest pending import job as running and returns it, nil when none is pending or another
+// worker claimed it first
+func (r *ImportRepositoryAdapter) ClaimNext(ctx context.Context) (*domain.ImportJob, error) {
+	var job domain.ImportJob
+
+	err := conn(ctx, r.db).Where("status = ?", domain.ImportStatusPending).Order("created_at ASC").First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	result := conn(ctx, r.db).Model(&domain.ImportJob{}).Where("id = ? AND status = ?", job.ID, domain.ImportStatusPending).
+		Updates(map[string]any{"status": domain.ImportStatusRunning, "started_at": now})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+
+	job.Status = domain.ImportStatusRunning
+	job.StartedAt = &now
+
+	return &job, nil
+}
+
+// UpdateProgress saves the row counts of a running import job
+func (r *ImportRepositoryAdapter) UpdateProgress(ctx context.Context, job *domain.ImportJob) error {
+	return conn(ctx, r.db).Model(job).Select("total_rows", "processed_rows", "imported_rows", "failed_rows").Updates(job).Error
+}
+
+// Finish saves the outcome of an import job together with its row errors and drops the imported file
+func (r *ImportRepositoryAdapter) Finish(ctx context.Context, job *domain.ImportJob, rowErrors []domain.ImportRowError) error {
+	now := time.Now()
+
+	job.FinishedAt = &now
+	job.Payload = ""
+
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if len(rowErrors) > 0 {
+			for i := range rowErrors {
+				rowErrors[i].JobID = job.ID
+			}
+
+			if err := tx.CreateInBatches(rowErrors, rowErrorBatchSize).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(job).Select("status", "payload", "total_rows", "processed_rows", "imported_rows", "failed_rows", "error",
+			"finished_at").Updates(job).Error
+	})
+}
+
+// GetRowErrors fetches the row errors of an import job in the order of the file
+func (r *ImportRepositoryAdapter) GetRowErrors(ctx context.Context, jobID string) ([]domain.ImportRowError, error) {
+	var rowErrors []domain.ImportRowError
+
+	err := conn(ctx, r.db).Where("job_id = ?", jobID).Order("line ASC").Find(&rowErrors).Error
+
+	return rowErrors, err
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/ports"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationRepositoryAdapter is the adapter for the notification repository
+type NotificationRepositoryAdapter struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepositoryAdapter creates a new notification repository adapter via dependency injection
+func NewNotificationRepositoryAdapter(db *gorm.DB) ports.NotificationRepository {
+	return &NotificationRepositoryAdapter{db: db}
+}
+
+// Create stores a notification, skipping it when the user was already notified about the same event
+func (r *NotificationRepositoryAdapter) Create(ctx context.Context, notification *domain.Notification) error {
+	return conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(notification).Error
+}
+
+// GetByUserID fetches the notifications of a user, newest first, with pagination
+func (r *NotificationRepositoryAdapter) GetByUserID(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]domain.Notification, error) {
+	var notifications []domain.Notification
+
+	query := conn(ctx, r.db).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&notifications).Error
+
+	return notifications, err
+}
+
+// MarkRead flags a notification of the user as read
+func (r *NotificationRepositoryAdapter) MarkRead(ctx context.Context, userID uuid.UUID, id string) (*domain.Notification, error) {
+	var notification domain.Notification
+
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&notification, "id = ? AND user_id = ?", id, userID).Error; err != nil {
+			return err
+		}
+
+		if notification.ReadAt != nil {
+			return nil
+		}
+
+		now := time.Now()
+		notification.ReadAt = &now
+
+		return tx.Model(&notification).Update("read_at", now).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &notification, nil
+}
+
+// MarkAllRead flags every unread notification of the user as read and returns how many changed
+func (r *NotificationRepositoryAdapter) MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	result := conn(ctx, r.db).Model(&domain.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Update("read_at", time.Now())
+
+	return result.RowsAffected, result.Error
+}
+
+// GetPreferences fetches the notification preferences the user has saved
+func (r *NotificationRepositoryAdapter) GetPreferences(ctx context.Context, userID uuid.UUID) ([]domain.NotificationPreference, error) {
+	var preferences []domain.NotificationPreference
+	err := conn(ctx, r.db).Where("user_id = ?", userID).Find(&preferences).Error
+
+	return preferences, err
+}
+
+// SavePreferences creates or updates the preferences per user and category
+func (r *NotificationRepositoryAdapter) SavePreferences(ctx context.Context, preferences []domain.NotificationPreference) error {
+	if len(preferences) == 0 {
+		return nil
+	}
+
+	return conn(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "category"}},
+		DoUpdates: clause.AssignmentColumns([]string{"in_app", "email", "sms", "updated_at"}),
+	}).Create(&preferences).Error
+}
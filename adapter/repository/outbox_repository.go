@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/ports"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutboxRepositoryAdapter is the adapter for the outbox repository
+type OutboxRepositoryAdapter struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepositoryAdapter creates a new outbox repository adapter via dependency injection
+func NewOutboxRepositoryAdapter(db *gorm.DB) ports.OutboxRepository {
+	return &OutboxRepositoryAdapter{db: db}
+}
+
+// ClaimPending claims the oldest events that have not been dispatched yet and no other relay holds a claim on, the
+// claim expires after the lease so the events of a relay that stopped are relayed by another one. The events come with
+// the subscribers and sinks they were already delivered to.
+func (r *OutboxRepositoryAdapter) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxEvent, error) {
+	now := time.Now()
+	claim := uuid.New()
+
+	claimable := "dispatched_at IS NULL AND (claimed_until IS NULL OR claimed_until < ?)"
+	pending := conn(ctx, r.db).Model(&domain.OutboxEvent{}).Select("id").Where(claimable, now).Order("created_at ASC").Limit(limit)
+
+	// the condition is checked again by the update, an event another relay claimed in between is left to it
+	err := conn(ctx, r.db).Model(&domain.OutboxEvent{}).Where("id IN (?) AND "+claimable, pending, now).
+		Updates(map[string]any{"claimed_by": claim, "claimed_until": now.Add(lease)}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var events []domain.OutboxEvent
+
+	err = conn(ctx, r.db).Preload("Deliveries").Where("claimed_by = ?", claim).Order("created_at ASC").Find(&events).Error
+
+	return events, err
+}
+
+// MarkDelivered records that an event was delivered to a subscriber or sink
+func (r *OutboxRepositoryAdapter) MarkDelivered(ctx context.Context, id uuid.UUID, target string) error {
+	return conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&domain.OutboxDelivery{EventID: id, Target: target, DeliveredAt: time.Now()}).Error
+}
+
+// MarkDispatched flags an event as handed over to every subscriber and sink
+func (r *OutboxRepositoryAdapter) MarkDispatched(ctx context.Context, id uuid.UUID) error {
+	return conn(ctx, r.db).Model(&domain.OutboxEvent{}).Where("id = ?", id).Update("dispatched_at", time.Now()).Error
+}
+
+// GetAfter fetches the events recorded after the given event in creation order, used to replay missed events
+func (r *OutboxRepositoryAdapter) GetAfter(ctx context.Context, id uuid.UUID, limit int) ([]domain.OutboxEvent, error) {
+	var last domain.OutboxEvent
+
+	if err := conn(ctx, r.db).First(&last, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+
+	var events []domain.OutboxEvent
+
+	err := conn(ctx, r.db).Where("created_at > ? OR (created_at = ? AND id > ?)", last.CreatedAt, last.CreatedAt, last.ID).
+		Order("created_at ASC, id ASC").Limit(limit).Find(&events).Error
+
+	return events, err
+}
+
+// Record stores an event in the outbox, within a unit of work it is committed together with the change it is about
+func (r *OutboxRepositoryAdapter) Record(ctx context.Context, event domain.Event) error {
+	outbox, err := domain.NewOutboxEvent(event)
+	if err != nil {
+		return err
+	}
+
+	return conn(ctx, r.db).Create(outbox).Error
+}
@@ -1,6 +1,10 @@
 package repository
 
 import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
 	"github.com/okyws/dashboard-backend/domain"
 	"gorm.io/gorm"
 )
@@ -16,9 +20,9 @@ func NewTransactionRepositoryAdapter(db *gorm.DB) *TransactionRepositoryAdapter
 }
 
 // GetAll fetches all transactions with pagination
-func (r *TransactionRepositoryAdapter) GetAll(limit, offset int) ([]domain.Transaction, error) {
+func (r *TransactionRepositoryAdapter) GetAll(ctx context.Context, limit, offset int) ([]domain.Transaction, error) {
 	var transactions []domain.Transaction
-	if err := r.db.Limit(limit).Offset(offset).Find(&transactions).Error; err != nil {
+	if err := conn(ctx, r.db).Limit(limit).Offset(offset).Find(&transactions).Error; err != nil {
 		return nil, err
 	}
 
@@ -26,9 +30,9 @@ func (r *TransactionRepositoryAdapter) GetAll(limit, offset int) ([]domain.Trans
 }
 
 // GetByID fetches a transaction by ID
-func (r *TransactionRepositoryAdapter) GetByID(id string) (*domain.Transaction, error) {
+func (r *TransactionRepositoryAdapter) GetByID(ctx context.Context, id string) (*domain.Transaction, error) {
 	var transaction domain.Transaction
-	if err := r.db.First(&transaction, "id = ?", id).Error; err != nil {
+	if err := conn(ctx, r.db).First(&transaction, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
 
@@ -36,18 +40,45 @@ func (r *TransactionRepositoryAdapter) GetByID(id string) (*domain.Transaction,
 }
 
 // GetByAccountNumber fetches transactions by account number
-func (r *TransactionRepositoryAdapter) GetByAccountNumber(accountID string) ([]domain.Transaction, error) {
+func (r *TransactionRepositoryAdapter) GetByAccountNumber(ctx context.Context, accountID string) ([]domain.Transaction, error) {
 	var transactions []domain.Transaction
-	if err := r.db.Where("from_account_number = ? OR to_account_number = ?", accountID, accountID).Find(&transactions).Error; err != nil {
+	if err := conn(ctx, r.db).Where("from_account_number = ? OR to_account_number = ?", accountID, accountID).Find(&transactions).Error; err != nil {
 		return nil, err
 	}
 
 	return transactions, nil
 }
 
+// FirstTransferredAt returns when the accounts of the user first transferred to the account number, nil when they never
+// did
+func (r *TransactionRepositoryAdapter) FirstTransferredAt(ctx context.Context, userID uuid.UUID, toAccountNumber string) (*time.Time, error) {
+	accounts := conn(ctx, r.db).Model(&domain.BankAccount{}).Select("account_number").Where("user_id = ?", userID)
+
+	var first domain.Transaction
+	err := conn(ctx, r.db).Select("created_at").Where("transaction_type = ? AND to_account_number = ?", "transfer", toAccountNumber).
+		Where("from_account_number IN (?)", accounts).Order("created_at ASC").Limit(1).Find(&first).Error
+	if err != nil || first.CreatedAt.IsZero() {
+		return nil, err
+	}
+
+	return &first.CreatedAt, nil
+}
+
+// SumTransferred adds up the transfers from the accounts of the user to the account number made since the time
+func (r *TransactionRepositoryAdapter) SumTransferred(ctx context.Context, userID uuid.UUID, toAccountNumber string, since time.Time) (float64, error) {
+	accounts := conn(ctx, r.db).Model(&domain.BankAccount{}).Select("account_number").Where("user_id = ?", userID)
+
+	var total float64
+	err := conn(ctx, r.db).Model(&domain.Transaction{}).Select("COALESCE(SUM(amount), 0)").
+		Where("transaction_type = ? AND to_account_number = ? AND created_at >= ?", "transfer", toAccountNumber, since).
+		Where("from_account_number IN (?)", accounts).Scan(&total).Error
+
+	return total, err
+}
+
 // Create adds a new transaction to the database
-func (r *TransactionRepositoryAdapter) Create(transaction *domain.Transaction) (*domain.Transaction, error) {
-	if err := r.db.Create(transaction).Error; err != nil {
+func (r *TransactionRepositoryAdapter) Create(ctx context.Context, transaction *domain.Transaction) (*domain.Transaction, error) {
+	if err := conn(ctx, r.db).Create(transaction).Error; err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/ports"
+	"gorm.io/gorm"
+)
+
+// TransferBatchRepositoryAdapter is the adapter for the batch transfer repository
+type TransferBatchRepositoryAdapter struct {
+	db *gorm.DB
+}
+
+// NewTransferBatchRepositoryAdapter creates a new batch transfer repository adapter via dependency injection
+func NewTransferBatchRepositoryAdapter(db *gorm.DB) ports.TransferBatchRepository {
+	return &TransferBatchRepositoryAdapter{db: db}
+}
+
+// Create inserts a new batch transfer together with its transfers
+func (r *TransferBatchRepositoryAdapter) Create(ctx context.Context, batch *domain.TransferBatch) (*domain.TransferBatch, error) {
+	if err := conn(ctx, r.db).Create(batch).Error; err != nil {
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+// GetByID fetches a batch transfer by ID with its transfers in the order they were submitted
+func (r *TransferBatchRepositoryAdapter) GetByID(ctx context.Context, id string) (*domain.TransferBatch, error) {
+	var batch domain.TransferBatch
+
+	err := conn(ctx, r.db).Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	}).First(&batch, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &batch, nil
+}
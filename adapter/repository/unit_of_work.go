@@ -0,0 +1,39 @@
+// Package repository contains the unit of work the repositories share a transaction through
+package repository
+
+import (
+	"context"
+
+	"github.com/okyws/dashboard-backend/ports"
+	"gorm.io/gorm"
+)
+
+// txKey is the context key of the transaction of a unit of work
+type txKey struct{}
+
+// UnitOfWorkAdapter is the adapter for the unit of work
+type UnitOfWorkAdapter struct {
+	db *gorm.DB
+}
+
+// NewUnitOfWork creates a new unit of work over the database via dependency injection
+func NewUnitOfWork(db *gorm.DB) ports.UnitOfWork {
+	return &UnitOfWorkAdapter{db: db}
+}
+
+// Do runs fn in a transaction carried by its context, a unit of work started within another one runs in a savepoint
+// of the outer transaction
+func (u *UnitOfWorkAdapter) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return conn(ctx, u.db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// conn returns the transaction of the unit of work of the context, or db outside of one
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+
+	return db.WithContext(ctx)
+}
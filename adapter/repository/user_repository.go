@@ -2,6 +2,9 @@
 package repository
 
 import (
+	"context"
+	"maps"
+
 	"github.com/okyws/dashboard-backend/domain"
 	"github.com/okyws/dashboard-backend/ports"
 	"gorm.io/gorm"
@@ -18,8 +21,8 @@ func NewUserRepositoryAdapter(db *gorm.DB) ports.UserRepository {
 }
 
 // Create inserts a new user into the database using a transaction.
-func (r *UserRepositoryAdapter) Create(user *domain.User) (*domain.User, error) {
-	err := r.db.Transaction(func(tx *gorm.DB) error {
+func (r *UserRepositoryAdapter) Create(ctx context.Context, user *domain.User) (*domain.User, error) {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Create(user).Error; err != nil {
 			return err
 		}
@@ -35,10 +38,10 @@ func (r *UserRepositoryAdapter) Create(user *domain.User) (*domain.User, error)
 }
 
 // GetByID fetches a user by ID, returning nil if not found.
-func (r *UserRepositoryAdapter) GetByID(id string) (*domain.User, error) {
+func (r *UserRepositoryAdapter) GetByID(ctx context.Context, id string) (*domain.User, error) {
 	var user domain.User
 
-	err := r.db.First(&user, "id = ?", id).Error
+	err := conn(ctx, r.db).First(&user, "id = ?", id).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, gorm.ErrRecordNotFound
@@ -51,10 +54,10 @@ func (r *UserRepositoryAdapter) GetByID(id string) (*domain.User, error) {
 }
 
 // GetUserByEmail fetches a user by email, returning nil if not found.
-func (r *UserRepositoryAdapter) GetUserByEmail(email string) (*domain.User, error) {
+func (r *UserRepositoryAdapter) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
 	var user domain.User
 
-	err := r.db.First(&user, "email = ?", email).Error
+	err := conn(ctx, r.db).First(&user, "email = ?", email).Error
 	if err != nil {
 		return nil, err
 	}
@@ -67,10 +70,10 @@ func (r *UserRepositoryAdapter) GetUserByEmail(email string) (*domain.User, erro
 }
 
 // GetUserByUsername fetches a user by username, returning nil if not found.
-func (r *UserRepositoryAdapter) GetUserByUsername(username string) (*domain.User, error) {
+func (r *UserRepositoryAdapter) GetUserByUsername(ctx context.Context, username string) (*domain.User, error) {
 	var user domain.User
 
-	err := r.db.First(&user, "username = ?", username).Error
+	err := conn(ctx, r.db).First(&user, "username = ?", username).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, gorm.ErrRecordNotFound
@@ -82,17 +85,25 @@ func (r *UserRepositoryAdapter) GetUserByUsername(username string) (*domain.User
 	return &user, nil
 }
 
-// Update updates an existing user in the database.
-func (r *UserRepositoryAdapter) Update(user *domain.User) (*domain.User, error) {
+// Update updates an existing user in the database when it is still at the version of user, at any version when it is 0,
+// and bumps the version.
+func (r *UserRepositoryAdapter) Update(ctx context.Context, user *domain.User) (*domain.User, error) {
 	var updatedUser domain.User
 
-	err := r.db.Transaction(func(tx *gorm.DB) error {
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
 		if err := tx.First(&updatedUser, "id = ?", user.ID).Error; err != nil {
-			tx.Rollback()
 			return err
 		}
 
-		return tx.Model(&updatedUser).Updates(user).Error
+		expected, err := expectedVersion(updatedUser.Version, user.Version)
+		if err != nil {
+			return err
+		}
+
+		changes := *user
+		changes.Version = expected + 1
+
+		return versionWritten(tx.Model(&updatedUser).Where("version = ?", expected).Updates(&changes))
 	})
 
 	if err != nil {
@@ -102,24 +113,52 @@ func (r *UserRepositoryAdapter) Update(user *domain.User) (*domain.User, error)
 	return &updatedUser, nil
 }
 
-// Delete removes a user by ID and ensures that a user was actually deleted.
-func (r *UserRepositoryAdapter) Delete(id string) error {
-	result := r.db.Delete(&domain.User{}, "id = ?", id)
-	if result.Error != nil {
-		return result.Error
-	}
+// Patch writes the changed columns of a user, zero values included, when it is still at the version, at any version
+// when it is 0, and returns the updated user
+func (r *UserRepositoryAdapter) Patch(ctx context.Context, id string, version uint, changes map[string]any) (*domain.User, error) {
+	var patchedUser domain.User
+
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&patchedUser, "id = ?", id).Error; err != nil {
+			return err
+		}
+
+		expected, err := expectedVersion(patchedUser.Version, version)
+		if err != nil || len(changes) == 0 {
+			return err
+		}
 
-	if result.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
+		columns := maps.Clone(changes)
+		columns["version"] = expected + 1
+
+		if err := versionWritten(tx.Model(&patchedUser).Where("version = ?", expected).Updates(columns)); err != nil {
+			return err
+		}
+
+		return tx.First(&patchedUser, "id = ?", id).Error
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return &patchedUser, nil
+}
+
+// Delete removes a user by ID and ensures that a user was actually deleted.
+func (r *UserRepositoryAdapter) Delete(ctx context.Context, id string) error {
+	return r.DeleteVersion(ctx, id, 0)
+}
+
+// DeleteVersion removes a user by ID when it is still at the version, at any version when it is 0.
+func (r *UserRepositoryAdapter) DeleteVersion(ctx context.Context, id string, version uint) error {
+	return deleteVersion(conn(ctx, r.db), &domain.User{}, id, version)
 }
 
 // GetAll fetches users with pagination.
-func (r *UserRepositoryAdapter) GetAll(limit, offset int) ([]domain.User, error) {
+func (r *UserRepositoryAdapter) GetAll(ctx context.Context, limit, offset int) ([]domain.User, error) {
 	var users []domain.User
-	err := r.db.Limit(limit).Offset(offset).Find(&users).Error
+	err := conn(ctx, r.db).Limit(limit).Offset(offset).Find(&users).Error
 
 	return users, err
 }
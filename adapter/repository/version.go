@@ -0,0 +1,64 @@
+// Package repository contains the optimistic concurrency checks shared by the repositories of versioned resources
+package repository
+
+import (
+	"github.com/okyws/dashboard-backend/domain"
+	"gorm.io/gorm"
+)
+
+// expectedVersion returns the version a write of a row read at version read expects, the version read when the caller
+// expects none (0), and domain.ErrVersionMismatch when the row is at another version than the caller expects
+func expectedVersion(read, expected uint) (uint, error) {
+	if expected == 0 {
+		return read, nil
+	}
+
+	if read != expected {
+		return 0, domain.ErrVersionMismatch
+	}
+
+	return expected, nil
+}
+
+// versionWritten checks the result of a write conditioned on WHERE version = ?, a write that matched no row lost the
+// race against a concurrent write of the row read in the same transaction
+func versionWritten(result *gorm.DB) error {
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return domain.ErrVersionMismatch
+	}
+
+	return nil
+}
+
+// deleteVersion deletes the row of model with the ID while it is at the version, any version when it is 0. A missing row
+// is gorm.ErrRecordNotFound and a row at another version domain.ErrVersionMismatch.
+func deleteVersion(db *gorm.DB, model any, id string, version uint) error {
+	query := db.Where("id = ?", id)
+	if version != 0 {
+		query = query.Where("version = ?", version)
+	}
+
+	result := query.Delete(model)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	var count int64
+	if err := db.Model(model).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return domain.ErrVersionMismatch
+}
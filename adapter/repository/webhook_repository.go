@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/ports"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WebhookRepositoryAdapter is the adapter for the webhook repository
+type WebhookRepositoryAdapter struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepositoryAdapter creates a new webhook repository adapter via dependency injection
+func NewWebhookRepositoryAdapter(db *gorm.DB) ports.WebhookRepository {
+	return &WebhookRepositoryAdapter{db: db}
+}
+
+// CreateEndpoint inserts a new webhook endpoint into the database
+func (r *WebhookRepositoryAdapter) CreateEndpoint(ctx context.Context, endpoint *domain.WebhookEndpoint) (*domain.WebhookEndpoint, error) {
+	if err := conn(ctx, r.db).Create(endpoint).Error; err != nil {
+		return nil, err
+	}
+
+	return endpoint, nil
+}
+
+// GetEndpointByID fetches a webhook endpoint by ID
+func (r *WebhookRepositoryAdapter) GetEndpointByID(ctx context.Context, id string) (*domain.WebhookEndpoint, error) {
+	var endpoint domain.WebhookEndpoint
+
+	if err := conn(ctx, r.db).First(&endpoint, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+
+	return &endpoint, nil
+}
+
+// GetAllEndpoints fetches webhook endpoints with pagination
+func (r *WebhookRepositoryAdapter) GetAllEndpoints(ctx context.Context, limit, offset int) ([]domain.WebhookEndpoint, error) {
+	var endpoints []domain.WebhookEndpoint
+	err := conn(ctx, r.db).Limit(limit).Offset(offset).Find(&endpoints).Error
+
+	return endpoints, err
+}
+
+// GetActiveEndpoints fetches every endpoint that is currently receiving events
+func (r *WebhookRepositoryAdapter) GetActiveEndpoints(ctx context.Context) ([]domain.WebhookEndpoint, error) {
+	var endpoints []domain.WebhookEndpoint
+	err := conn(ctx, r.db).Where("active = ?", true).Find(&endpoints).Error
+
+	return endpoints, err
+}
+
+// DeleteEndpoint removes a webhook endpoint by ID
+func (r *WebhookRepositoryAdapter) DeleteEndpoint(ctx context.Context, id string) error {
+	result := conn(ctx, r.db).Delete(&domain.WebhookEndpoint{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// CreateDeliveries stores new deliveries, skipping the ones already scheduled for the same endpoint and event
+func (r *WebhookRepositoryAdapter) CreateDeliveries(ctx context.Context, deliveries []domain.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	return conn(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error
+}
+
+// ClaimDueDeliveries claims the pending deliveries whose next attempt is due and no other dispatcher holds a claim on,
+// the claim expires after the lease so the deliveries of a dispatcher that stopped are sent by another one
+func (r *WebhookRepositoryAdapter) ClaimDueDeliveries(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	claim := uuid.New()
+
+	claimable := "status = ? AND next_attempt_at <= ? AND (claimed_until IS NULL OR claimed_until < ?)"
+	due := conn(ctx, r.db).Model(&domain.WebhookDelivery{}).Select("id").
+		Where(claimable, domain.DeliveryStatusPending, now, now).Order("next_attempt_at ASC").Limit(limit)
+
+	// the condition is checked again by the update, a delivery another dispatcher claimed in between is left to it
+	err := conn(ctx, r.db).Model(&domain.WebhookDelivery{}).Where("id IN (?) AND "+claimable, due, domain.DeliveryStatusPending, now, now).
+		Updates(map[string]any{"claimed_by": claim, "claimed_until": now.Add(lease)}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var deliveries []domain.WebhookDelivery
+
+	err = conn(ctx, r.db).Where("claimed_by = ?", claim).Order("next_attempt_at ASC").Find(&deliveries).Error
+
+	return deliveries, err
+}
+
+// GetDeliveryByID fetches a webhook delivery by ID
+func (r *WebhookRepositoryAdapter) GetDeliveryByID(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+
+	if err := conn(ctx, r.db).First(&delivery, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+// GetDeliveriesByEndpointID fetches the delivery history of an endpoint with pagination
+func (r *WebhookRepositoryAdapter) GetDeliveriesByEndpointID(ctx context.Context, endpointID string, limit, offset int) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+
+	err := conn(ctx, r.db).Where("endpoint_id = ?", endpointID).Order("created_at DESC").Limit(limit).Offset(offset).Find(&deliveries).Error
+
+	return deliveries, err
+}
+
+// UpdateDelivery saves the outcome of a delivery attempt and releases the claim on it
+func (r *WebhookRepositoryAdapter) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	delivery.ClaimedBy = nil
+	delivery.ClaimedUntil = nil
+
+	return conn(ctx, r.db).Model(delivery).
+		Select("status", "attempts", "next_attempt_at", "last_error", "response_code", "claimed_by", "claimed_until").Updates(delivery).Error
+}
@@ -0,0 +1,19 @@
+package tracing
+
+import (
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LogHook adds the trace and span IDs of the span in the context of a log event, set with Event.Ctx
+type LogHook struct{}
+
+// Run adds the IDs when the event context carries a valid span
+func (h LogHook) Run(event *zerolog.Event, _ zerolog.Level, _ string) {
+	spanContext := trace.SpanContextFromContext(event.GetCtx())
+	if !spanContext.IsValid() {
+		return
+	}
+
+	event.Str("trace_id", spanContext.TraceID().String()).Str("span_id", spanContext.SpanID().String())
+}
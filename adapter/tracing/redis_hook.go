@@ -0,0 +1,75 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// redisHook starts a span around every Redis command
+type redisHook struct{}
+
+// InstrumentRedis traces every command sent by the client as a child of the span in the context of the command
+func InstrumentRedis(redisClient *redis.Client) {
+	redisClient.AddHook(&redisHook{})
+}
+
+// DialHook leaves dialing untouched
+func (h *redisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+// ProcessHook traces a command
+func (h *redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		ctx, span := h.start(ctx, "redis."+cmd.Name(), cmd.Name())
+		defer span.End()
+
+		err := next(ctx, cmd)
+		h.end(span, err)
+
+		return err
+	}
+}
+
+// ProcessPipelineHook traces a pipeline as a whole with the names of its commands
+func (h *redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		names := make([]string, len(cmds))
+		for i, cmd := range cmds {
+			names[i] = cmd.Name()
+		}
+
+		ctx, span := h.start(ctx, "redis.pipeline", strings.Join(names, " "))
+		defer span.End()
+
+		err := next(ctx, cmds)
+		h.end(span, err)
+
+		return err
+	}
+}
+
+// start starts the span of a command
+func (h *redisHook) start(ctx context.Context, name, operation string) (context.Context, trace.Span) {
+	return tracer().Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("db.system", "redis"),
+		attribute.String("db.operation", operation),
+	))
+}
+
+// end records the outcome, a missing key is an answer and not a failure
+func (h *redisHook) end(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, redis.Nil) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
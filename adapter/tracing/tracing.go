@@ -0,0 +1,63 @@
+// Package tracing contains the OpenTelemetry adapters tracing HTTP requests, database queries and Redis commands
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/okyws/dashboard-backend/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName names the tracer of the adapters
+const InstrumentationName = "github.com/okyws/dashboard-backend/adapter/tracing"
+
+// tracer starts the spans of the adapters from the global tracer provider
+func tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
+
+// NewProvider builds the tracer provider of the configured exporter and installs it globally together with the W3C
+// trace context and baggage propagators. Spans are still created without an exporter so that logs carry trace IDs.
+func NewProvider(configuration *domain.Configuration) (*sdktrace.TracerProvider, error) {
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(configuration.TracingSampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", configuration.AppName),
+			attribute.String("service.version", configuration.AppVersion),
+			attribute.String("deployment.environment", configuration.AppEnv),
+		)),
+	}
+
+	switch configuration.TracingExporter {
+	case domain.TracingOTLP:
+		exporter, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(configuration.TracingEndpoint))
+		if err != nil {
+			return nil, fmt.Errorf("otlp exporter: %w", err)
+		}
+
+		options = append(options, sdktrace.WithBatcher(exporter))
+	case domain.TracingStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, fmt.Errorf("stdout exporter: %w", err)
+		}
+
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(options...)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider, nil
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/okyws/dashboard-backend/config"
+	"github.com/spf13/cobra"
+)
+
+// newConfigCommand builds the configuration commands
+func (a *application) newConfigCommand() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration",
+	}
+
+	configCmd.AddCommand(&cobra.Command{
+		Use:   "print",
+		Short: "Print the loaded configuration with secrets redacted",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			configuration, err := a.loadConfiguration()
+			if err != nil {
+				return err
+			}
+
+			for _, setting := range config.Settings(configuration) {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s=%s\n", setting.Name, setting.Value)
+			}
+
+			return nil
+		},
+	}, &cobra.Command{
+		Use:   "validate",
+		Short: "Load and validate the configuration, reporting every problem",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if _, err := a.loadConfiguration(); err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), "configuration is valid")
+
+			return nil
+		},
+	})
+
+	return configCmd
+}
@@ -0,0 +1,354 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/okyws/dashboard-backend/adapter/repository"
+	"github.com/okyws/dashboard-backend/config"
+	"github.com/okyws/dashboard-backend/constants"
+	"github.com/okyws/dashboard-backend/database"
+	"github.com/okyws/dashboard-backend/database/seed"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/dto"
+	"github.com/okyws/dashboard-backend/middleware"
+	"github.com/okyws/dashboard-backend/services"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+// ErrProductionDatabase is returned when a destructive database command runs with APP_ENV=production
+var ErrProductionDatabase = errors.New("refusing to drop the database when APP_ENV is production")
+
+// newDBCommand builds the database commands
+func (a *application) newDBCommand() *cobra.Command {
+	db := &cobra.Command{
+		Use:   "db",
+		Short: "Manage the database",
+	}
+
+	db.AddCommand(a.newMigrateCommand(), a.newSeedCommand(), a.newDropCommand(), a.newFreshCommand(), a.newImportCommand())
+
+	return db
+}
+
+// newMigrateCommand builds the commands managing the versioned migrations
+func (a *application) newMigrateCommand() *cobra.Command {
+	migrate := &cobra.Command{
+		Use:   "migrate",
+		Short: "Manage the versioned database migrations",
+	}
+
+	migrate.AddCommand(&cobra.Command{
+		Use:   "up",
+		Short: "Apply every pending migration",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return a.withDatabase(func(_ *domain.Configuration, db *gorm.DB) error {
+				return config.MigrateDB(db)
+			})
+		},
+	}, &cobra.Command{
+		Use:   "down N",
+		Short: "Revert the last N applied migrations",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			steps, err := strconv.Atoi(args[0])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("N must be a positive number, got %q", args[0])
+			}
+
+			return a.withDatabase(func(_ *domain.Configuration, db *gorm.DB) error {
+				migrator, err := config.NewMigrator(db)
+				if err != nil {
+					return err
+				}
+
+				reverted, err := migrator.Down(steps)
+				for _, migration := range reverted {
+					log.Info().Uint64("version", migration.Version).Str("name", migration.Name).Msg("Reverted migration")
+				}
+
+				return err
+			})
+		},
+	}, &cobra.Command{
+		Use:   "status",
+		Short: "List the applied and pending migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return a.withDatabase(func(_ *domain.Configuration, db *gorm.DB) error {
+				migrator, err := config.NewMigrator(db)
+				if err != nil {
+					return err
+				}
+
+				statuses, err := migrator.Status()
+				if err != nil {
+					return err
+				}
+
+				for _, status := range statuses {
+					appliedAt := "pending"
+					if status.AppliedAt != nil {
+						appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+					}
+
+					fmt.Fprintf(cmd.OutOrStdout(), "%04d  %-40s  %s\n", status.Version, status.Name, appliedAt)
+				}
+
+				return nil
+			})
+		},
+	}, &cobra.Command{
+		Use:   "create name",
+		Short: "Create empty up and down files for a new migration",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			up, down, err := database.CreateMigration(database.MigrationsDir, args[0])
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), up)
+			fmt.Fprintln(cmd.OutOrStdout(), down)
+
+			return nil
+		},
+	})
+
+	return migrate
+}
+
+// seedFlags are the flags of the commands seeding the database
+type seedFlags struct {
+	profile      string
+	users        int
+	accounts     int
+	transactions int
+	seed         uint64
+	password     string
+	fixture      string
+}
+
+// register adds the seed flags to the command
+func (f *seedFlags) register(command *cobra.Command) {
+	command.Flags().StringVar(&f.profile, "profile", "demo", "seed profile: minimal, demo or load-test")
+	command.Flags().IntVar(&f.users, "users", 0, "number of generated users besides admin and user, overrides the profile")
+	command.Flags().IntVar(&f.accounts, "accounts", 0, "accounts per user, overrides the profile")
+	command.Flags().IntVar(&f.transactions, "transactions", 0, "random transactions per user, overrides the profile")
+	command.Flags().Uint64Var(&f.seed, "seed", 1, "random seed, the same seed generates the same data")
+	command.Flags().StringVar(&f.password, "password", seed.DefaultSeedPassword, "password of every seeded user")
+	command.Flags().StringVar(&f.fixture, "fixture", "", "YAML or JSON fixture file to load instead of generated data")
+}
+
+// run seeds the fixture file when given, otherwise the generated profile
+func (f *seedFlags) run(command *cobra.Command, configuration *domain.Configuration, db *gorm.DB) error {
+	scheme := config.NewAccountNumberScheme(configuration)
+
+	if f.fixture != "" {
+		fixture, err := seed.LoadFixtureFile(f.fixture)
+		if err != nil {
+			return err
+		}
+
+		result, err := seed.ApplyFixture(command.Context(), db, scheme, fixture)
+		if err != nil {
+			return err
+		}
+
+		log.Info().Int("users", len(result.Users)).Int("accounts", len(result.Accounts)).Int("transactions", len(fixture.Transactions)).
+			Msg(constants.MsgDBSeedSuccess)
+
+		return nil
+	}
+
+	profile, err := seed.GetSeedProfile(f.profile)
+	if err != nil {
+		return err
+	}
+
+	if command.Flags().Changed("users") {
+		profile.Users = f.users
+	}
+
+	if command.Flags().Changed("accounts") {
+		profile.AccountsPerUser = f.accounts
+	}
+
+	if command.Flags().Changed("transactions") {
+		profile.TransactionsPerUser = f.transactions
+	}
+
+	start := time.Now()
+
+	summary, err := seed.NewSeeder(db, scheme, seed.SeedOptions{SeedProfile: profile, Seed: f.seed, Password: f.password}).Run(command.Context())
+	if err != nil {
+		log.Error().Err(err).Msg(constants.MsgDBSeedFail)
+		return err
+	}
+
+	log.Info().Str("profile", f.profile).Uint64("seed", f.seed).Int("users", summary.Users).Int("accounts", summary.Accounts).
+		Int("transactions", summary.Transactions).Str("duration", time.Since(start).String()).Msg(constants.MsgDBSeedSuccess)
+
+	return nil
+}
+
+// newSeedCommand builds the command seeding the database with generated data or a fixture
+func (a *application) newSeedCommand() *cobra.Command {
+	var flags seedFlags
+
+	seedCmd := &cobra.Command{
+		Use:   "seed",
+		Short: "Seed the database with generated data or a fixture",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return a.withDatabase(func(configuration *domain.Configuration, db *gorm.DB) error {
+				return flags.run(cmd, configuration, db)
+			})
+		},
+	}
+
+	flags.register(seedCmd)
+
+	return seedCmd
+}
+
+// newDropCommand builds the command dropping every table
+func (a *application) newDropCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "drop",
+		Short: "Drop the database",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return a.withDatabase(func(configuration *domain.Configuration, db *gorm.DB) error {
+				if configuration.IsProduction() {
+					return ErrProductionDatabase
+				}
+
+				return config.DropDB(db)
+			})
+		},
+	}
+}
+
+// newFreshCommand builds the command recreating the database with dummy data
+func (a *application) newFreshCommand() *cobra.Command {
+	var flags seedFlags
+
+	freshCmd := &cobra.Command{
+		Use:   "fresh",
+		Short: "Drop the database, apply every migration and seed",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return a.withDatabase(func(configuration *domain.Configuration, db *gorm.DB) error {
+				if configuration.IsProduction() {
+					return ErrProductionDatabase
+				}
+
+				if err := config.DropDB(db); err != nil {
+					return err
+				}
+
+				if err := config.MigrateDB(db); err != nil {
+					return err
+				}
+
+				return flags.run(cmd, configuration, db)
+			})
+		},
+	}
+
+	flags.register(freshCmd)
+
+	return freshCmd
+}
+
+// newImportCommand builds the command importing users, customers and accounts from a CSV or JSON Lines file
+func (a *application) newImportCommand() *cobra.Command {
+	var format, mode, report string
+
+	importCmd := &cobra.Command{
+		Use:   "import FILE",
+		Short: "Import users, customers and accounts from a CSV or JSON Lines file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			payload, err := os.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+
+			if format == "" {
+				format = strings.TrimPrefix(strings.ToLower(filepath.Ext(args[0])), ".")
+			}
+
+			return a.withDatabase(func(configuration *domain.Configuration, db *gorm.DB) error {
+				scheme := config.NewAccountNumberScheme(configuration)
+
+				// the rows are validated like the requests of the API, which registers these on start
+				if err := dto.RegisterAccountNumberValidation(scheme.Valid); err != nil {
+					return err
+				}
+
+				dto.RegisterJSONFieldNames()
+
+				importService := services.NewImportService(repository.NewUnitOfWork(db), repository.NewImportRepositoryAdapter(db),
+					repository.NewImportRepositories(db, scheme), middleware.MapError)
+
+				job, err := importService.Import(cmd.Context(), format, mode, payload)
+				if err != nil {
+					return err
+				}
+
+				log.Info().Str("import_id", job.ID.String()).Str("status", job.Status).Int("rows", job.TotalRows).
+					Int("imported", job.ImportedRows).Int("failed", job.FailedRows).Msg("Import finished")
+
+				if report != "" && job.FailedRows > 0 {
+					if err := writeImportReport(cmd, importService, job, report); err != nil {
+						return err
+					}
+				}
+
+				if job.Status == domain.ImportStatusFailed {
+					return fmt.Errorf("import %s failed, %d of %d rows were imported", job.ID, job.ImportedRows, job.TotalRows)
+				}
+
+				return nil
+			})
+		},
+	}
+
+	importCmd.Flags().StringVar(&format, "format", "", "csv or jsonl, taken from the file extension when empty")
+	importCmd.Flags().StringVar(&mode, "mode", domain.ImportModeBestEffort, "best_effort or all_or_nothing")
+	importCmd.Flags().StringVar(&report, "report", "", "CSV file the problems with the rows are written to")
+
+	return importCmd
+}
+
+// writeImportReport writes the problems with the rows of the import to the file at path
+func writeImportReport(cmd *cobra.Command, importService *services.ImportService, job *domain.ImportJob, path string) error {
+	rowErrors, err := importService.GetRowErrors(cmd.Context(), job.ID.String())
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := services.WriteImportReport(file, rowErrors); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "wrote %d problems to %s\n", len(rowErrors), path)
+
+	return file.Close()
+}
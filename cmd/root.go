@@ -0,0 +1,115 @@
+// Package cmd contains the command line interface of the application
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/okyws/dashboard-backend/config"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+// Exit codes of the process
+const (
+	ExitOK      = 0
+	ExitFailure = 1
+)
+
+// application holds the global flags and loads the configuration every command shares
+type application struct {
+	configFile string
+	envFile    string
+	settings   []string
+	overrides  map[string]string
+	root       *cobra.Command
+}
+
+// NewRootCommand builds the command tree, running the server when no subcommand is given
+func NewRootCommand() *cobra.Command {
+	var logLevel string
+
+	app := &application{overrides: make(map[string]string)}
+
+	app.root = &cobra.Command{
+		Use:          "dashboard-backend",
+		Short:        "Bank dashboard backend",
+		SilenceUsage: true,
+		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+			return config.SetLogLevel(logLevel)
+		},
+		RunE: app.runServe,
+	}
+
+	flags := app.root.PersistentFlags()
+	flags.StringVar(&app.configFile, "config", "", "YAML or TOML file to load the configuration from")
+	flags.StringVar(&app.envFile, "env-file", config.DefaultEnvFile, "env file to load the configuration from, skipped when the default is missing")
+	flags.StringArrayVar(&app.settings, "set", nil, "override a setting, e.g. --set SERVER_PORT=9090, may be repeated")
+	flags.StringVar(&logLevel, "log-level", "info", "minimum log level: trace, debug, info, warn or error")
+
+	app.root.AddCommand(app.newServeCommand(), app.newDBCommand(), app.newUserCommand(), app.newConfigCommand())
+
+	return app.root
+}
+
+// Execute runs the command line with the process arguments and returns the exit code
+func Execute() int {
+	if err := NewRootCommand().Execute(); err != nil {
+		return ExitFailure
+	}
+
+	return ExitOK
+}
+
+// loadConfiguration loads the layered configuration: defaults, --config, --env-file, the environment and --set
+func (a *application) loadConfiguration() (*domain.Configuration, error) {
+	overrides := make(map[string]string, len(a.settings)+len(a.overrides))
+
+	for _, setting := range a.settings {
+		name, value, ok := strings.Cut(setting, "=")
+		if !ok {
+			return nil, fmt.Errorf("--set %q must be NAME=value", setting)
+		}
+
+		overrides[name] = value
+	}
+
+	// dedicated flags such as serve --port win over --set
+	for name, value := range a.overrides {
+		overrides[name] = value
+	}
+
+	return config.Load(config.LoadOptions{
+		ConfigFile:      a.configFile,
+		EnvFile:         a.envFile,
+		EnvFileRequired: a.root.PersistentFlags().Changed("env-file"),
+		Overrides:       overrides,
+	})
+}
+
+// openDatabase loads the configuration and connects to its database
+func (a *application) openDatabase() (*domain.Configuration, *gorm.DB, error) {
+	configuration, err := a.loadConfiguration()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db, err := config.NewDBConnectionENV(configuration)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return configuration, db, nil
+}
+
+// withDatabase runs fn with an open database connection and closes it afterwards
+func (a *application) withDatabase(fn func(configuration *domain.Configuration, db *gorm.DB) error) error {
+	configuration, db, err := a.openDatabase()
+	if err != nil {
+		return err
+	}
+	defer config.CloseDatabase(db)
+
+	return fn(configuration, db)
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"strconv"
+
+	"github.com/okyws/dashboard-backend/config"
+	"github.com/okyws/dashboard-backend/routes"
+	"github.com/spf13/cobra"
+)
+
+// newServeCommand builds the command starting the HTTP server
+func (a *application) newServeCommand() *cobra.Command {
+	var port int
+
+	serve := &cobra.Command{
+		Use:   "serve",
+		Short: "Start the HTTP server",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Flags().Changed("port") {
+				a.overrides["SERVER_PORT"] = strconv.Itoa(port)
+			}
+
+			return a.runServe(cmd, args)
+		},
+	}
+
+	serve.Flags().IntVar(&port, "port", 0, "port to listen on, overrides SERVER_PORT")
+
+	return serve
+}
+
+// runServe sends the log to LOG_OUTPUTS and serves HTTP until interrupted
+func (a *application) runServe(_ *cobra.Command, _ []string) error {
+	configuration, err := a.loadConfiguration()
+	if err != nil {
+		return err
+	}
+
+	if err := config.InitiateLog(configuration); err != nil {
+		return err
+	}
+	defer config.CloseLog()
+
+	return routes.RunServer(configuration)
+}
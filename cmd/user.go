@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/okyws/dashboard-backend/adapter/repository"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/services"
+	"github.com/spf13/cobra"
+	"gorm.io/gorm"
+)
+
+// minPasswordLength matches the minimum password length of the user API
+const minPasswordLength = 6
+
+// newUserCommand builds the user administration commands
+func (a *application) newUserCommand() *cobra.Command {
+	user := &cobra.Command{
+		Use:   "user",
+		Short: "Administer users",
+	}
+
+	user.AddCommand(a.newCreateAdminCommand(), a.newResetPasswordCommand())
+
+	return user
+}
+
+// newCreateAdminCommand builds the command creating an admin user, printing a generated password when none is given
+func (a *application) newCreateAdminCommand() *cobra.Command {
+	var username, email, password string
+
+	createAdmin := &cobra.Command{
+		Use:   "create-admin",
+		Short: "Create an admin user",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			password, generated, err := passwordOrGenerate(password)
+			if err != nil {
+				return err
+			}
+
+			return a.withDatabase(func(_ *domain.Configuration, db *gorm.DB) error {
+				userService := services.NewUserService(repository.NewUnitOfWork(db), repository.NewUserRepositoryAdapter(db),
+					repository.NewOutboxRepositoryAdapter(db))
+
+				user, err := userService.CreateUser(cmd.Context(), &domain.User{Username: username, Email: email, Password: password, Role: "admin"})
+				if err != nil {
+					return err
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "created admin %s (%s)\n", user.Username, user.ID)
+
+				if generated {
+					fmt.Fprintf(cmd.OutOrStdout(), "password: %s\n", password)
+				}
+
+				return nil
+			})
+		},
+	}
+
+	createAdmin.Flags().StringVar(&username, "username", "", "username of the admin")
+	createAdmin.Flags().StringVar(&email, "email", "", "email of the admin")
+	createAdmin.Flags().StringVar(&password, "password", "", "password of the admin, generated when empty")
+
+	_ = createAdmin.MarkFlagRequired("username")
+	_ = createAdmin.MarkFlagRequired("email")
+
+	return createAdmin
+}
+
+// newResetPasswordCommand builds the command setting a new password, printing a generated password when none is given
+func (a *application) newResetPasswordCommand() *cobra.Command {
+	var username, password string
+
+	resetPassword := &cobra.Command{
+		Use:   "reset-password",
+		Short: "Set a new password for a user",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			password, generated, err := passwordOrGenerate(password)
+			if err != nil {
+				return err
+			}
+
+			return a.withDatabase(func(_ *domain.Configuration, db *gorm.DB) error {
+				userService := services.NewUserService(repository.NewUnitOfWork(db), repository.NewUserRepositoryAdapter(db),
+					repository.NewOutboxRepositoryAdapter(db))
+
+				user, err := userService.GetUserByUsername(cmd.Context(), username)
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fmt.Errorf("user %q not found", username)
+				}
+
+				if err != nil {
+					return err
+				}
+
+				if _, err := userService.UpdateUser(cmd.Context(), &domain.User{ID: user.ID, Password: password}); err != nil {
+					return err
+				}
+
+				fmt.Fprintf(cmd.OutOrStdout(), "password of %s reset\n", user.Username)
+
+				if generated {
+					fmt.Fprintf(cmd.OutOrStdout(), "password: %s\n", password)
+				}
+
+				return nil
+			})
+		},
+	}
+
+	resetPassword.Flags().StringVar(&username, "username", "", "username of the user")
+	resetPassword.Flags().StringVar(&password, "password", "", "new password, generated when empty")
+
+	_ = resetPassword.MarkFlagRequired("username")
+
+	return resetPassword
+}
+
+// passwordOrGenerate returns the given password when long enough, or a random one when it is empty
+func passwordOrGenerate(password string) (string, bool, error) {
+	if password != "" {
+		if len(password) < minPasswordLength {
+			return "", false, fmt.Errorf("password must have at least %d characters", minPasswordLength)
+		}
+
+		return password, false, nil
+	}
+
+	random := make([]byte, 12)
+	if _, err := rand.Read(random); err != nil {
+		return "", false, err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(random), true, nil
+}
@@ -0,0 +1,16 @@
+package config
+
+import (
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/ports"
+	"github.com/okyws/dashboard-backend/utils"
+)
+
+// NewAccountNumberScheme builds the account number scheme selected in the configuration
+func NewAccountNumberScheme(configuration *domain.Configuration) ports.AccountNumberScheme {
+	if configuration.AccountNumberScheme == "mod97" {
+		return utils.NewMod97AccountNumberScheme(domain.AccountProductCodes)
+	}
+
+	return domain.DefaultAccountNumberScheme
+}
@@ -2,22 +2,25 @@
 package config
 
 import (
-	"time"
-
+	"github.com/glebarez/sqlite"
 	"github.com/okyws/dashboard-backend/constants"
 	"github.com/okyws/dashboard-backend/database"
 	"github.com/okyws/dashboard-backend/domain"
-	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog/log"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-// NewDBConnectionENV initializes a new database connection
+// NewDBConnectionENV initializes a new database connection with the configured driver
 func NewDBConnectionENV(config *domain.Configuration) (*gorm.DB, error) {
-	db, err := gorm.Open(postgres.Open(config.GetDatabaseConfig()), &gorm.Config{})
+	dialector := postgres.Open(config.GetDatabaseConfig())
+	if config.DBDriver == domain.DriverSQLite {
+		dialector = sqlite.Open(config.GetDatabaseConfig())
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{TranslateError: true})
 	if err != nil {
-		log.Fatal().Err(err).Msg(constants.MsgDBConnectFail)
+		log.Error().Err(err).Msg(constants.MsgDBConnectFail)
 		return nil, err
 	}
 
@@ -30,71 +33,57 @@ func NewDBConnectionENV(config *domain.Configuration) (*gorm.DB, error) {
 func CloseDatabase(db *gorm.DB) {
 	sqlDB, err := db.DB()
 	if err != nil {
-		log.Fatal().Err(err).Msg(constants.MsgDBCloseFail)
+		log.Error().Err(err).Msg(constants.MsgDBCloseFail)
+		return
 	}
 
 	err = sqlDB.Close()
 	if err != nil {
-		log.Fatal().Err(err).Msg(constants.MsgDBCloseFail)
+		log.Error().Err(err).Msg(constants.MsgDBCloseFail)
 	} else {
 		log.Info().Msg(constants.MsgDBCloseSuccess)
 	}
 }
 
-// GetDatabaseConnection gets the database connection
-func GetDatabaseConnection() (*gorm.DB, *redis.Client, error) {
-	configuration, err := domain.LoadConfig()
-	if err != nil {
-		log.Fatal().Err(err).Str("error", err.Error()).Msg(constants.MsgConfigLoadFail)
-		return nil, nil, err
-	}
+// NewMigrator creates a migrator for the SQL migrations embedded in the binary
+func NewMigrator(db *gorm.DB) (*database.Migrator, error) {
+	return database.NewEmbeddedMigrator(db)
+}
 
-	db, err := NewDBConnectionENV(configuration)
+// MigrateDB applies every pending migration
+func MigrateDB(db *gorm.DB) error {
+	migrator, err := NewMigrator(db)
 	if err != nil {
-		log.Fatal().Err(err).Str("error", err.Error()).Msg(constants.MsgDBConnectFail)
-		return nil, nil, err
+		return err
 	}
 
-	redisClient := NewRedisClient(configuration)
-
-	log.Info().Msg(constants.MsgDBConnectSuccess)
-
-	return db, redisClient, nil
-}
+	applied, err := migrator.Up()
+	for _, migration := range applied {
+		log.Info().Uint64("version", migration.Version).Str("name", migration.Name).Msg("Applied migration")
+	}
 
-// MigrateDB migrates the database schema
-func MigrateDB(db *gorm.DB) {
-	if err := db.AutoMigrate(&domain.User{}, &domain.Customer{}, &domain.BankAccount{}, &domain.Transaction{}); err != nil {
-		log.Fatal().Err(err).Msg(constants.MsgDBMigrateFail)
+	if err != nil {
+		log.Error().Err(err).Msg(constants.MsgDBMigrateFail)
+		return err
 	}
 
-	log.Info().Msg(constants.MsgDBMigrateSuccess)
+	log.Info().Int("applied", len(applied)).Msg(constants.MsgDBMigrateSuccess)
+
+	return nil
 }
 
-// DropDB drops the database schema
-func DropDB(db *gorm.DB) {
-	if err := db.Migrator().DropTable(&domain.User{}, &domain.Customer{}, &domain.BankAccount{}, &domain.Transaction{}); err != nil {
-		log.Fatal().Err(err).Msg(constants.MsgDBDropFail)
+// DropDB drops the database schema together with the migration history
+func DropDB(db *gorm.DB) error {
+	if err := db.Migrator().DropTable(&domain.User{}, &domain.Customer{}, &domain.BankAccount{}, &domain.Transaction{},
+		&domain.OutboxDelivery{}, &domain.OutboxEvent{}, &domain.WebhookEndpoint{}, &domain.WebhookDelivery{}, &domain.Notification{},
+		&domain.NotificationPreference{}, &domain.Beneficiary{}, &domain.ImportJob{}, &domain.ImportRowError{}, &domain.TransferBatchItem{},
+		&domain.TransferBatch{}, &domain.AccountNumberSequence{},
+		&database.SchemaMigration{}); err != nil {
+		log.Error().Err(err).Msg(constants.MsgDBDropFail)
+		return err
 	}
 
 	log.Info().Msg(constants.MsgDBDropSuccess)
-}
-
-// SeedDB seeds the database with initial data
-func SeedDB(db *gorm.DB) {
-	start := time.Now()
-	users := database.UserSeed()
-	db.CreateInBatches(users, 100)
-
-	customers := database.CustomerSeed(users)
-	db.CreateInBatches(customers, 100)
-
-	bankAccounts := database.BankAccountSeed(users)
-	db.CreateInBatches(bankAccounts, 100)
-
-	transactions := database.TransactionSeed(users, bankAccounts)
-	db.CreateInBatches(transactions, 100)
 
-	duration := time.Since(start)
-	log.Info().Str("duration", duration.String()).Msg(constants.MsgDBSeedSuccess)
+	return nil
 }
@@ -2,7 +2,6 @@ package config
 
 import (
 	"errors"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
@@ -18,14 +17,22 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
-// Secret key for JWT
-var jwtKey = []byte(os.Getenv("JWT_SECRET_KEY"))
+// JWTManager signs and parses the JWT tokens with the configured secret
+type JWTManager struct {
+	secret []byte
+	expiry time.Duration
+}
+
+// NewJWTManager creates a JWT manager issuing tokens valid for expiry
+func NewJWTManager(secret string, expiry time.Duration) *JWTManager {
+	return &JWTManager{secret: []byte(secret), expiry: expiry}
+}
 
 // GenerateJWT for generating JWT token
-func GenerateJWT(id uuid.UUID, username, role string) (string, string, error) {
+func (m *JWTManager) GenerateJWT(id uuid.UUID, username, role string) (string, string, error) {
 	log.Info().Msg("Initializing Generate JWT Token")
 
-	expirationTime := time.Now().Add(2 * time.Hour)
+	expirationTime := time.Now().Add(m.expiry)
 	claims := &Claims{
 		ID:       id,
 		Username: username,
@@ -39,7 +46,7 @@ func GenerateJWT(id uuid.UUID, username, role string) (string, string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	// Sign token with secret key
-	tokenString, err := token.SignedString(jwtKey)
+	tokenString, err := token.SignedString(m.secret)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to generate token")
 		return "", "", err
@@ -51,11 +58,11 @@ func GenerateJWT(id uuid.UUID, username, role string) (string, string, error) {
 }
 
 // ParseToken for validating JWT
-func ParseToken(tokenString string) (*Claims, error) {
+func (m *JWTManager) ParseToken(tokenString string) (*Claims, error) {
 	log.Info().Msg("Initializing Parse JWT Token")
 
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(_ *jwt.Token) (interface{}, error) {
-		return jwtKey, nil
+		return m.secret, nil
 	})
 
 	if err != nil {
@@ -0,0 +1,376 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/okyws/dashboard-backend/constants"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultEnvFile is the env file loaded when it exists
+const DefaultEnvFile = ".env"
+
+// Redacted replaces the value of secret settings when the configuration is printed
+const Redacted = "********"
+
+// ErrInvalidConfiguration wraps every problem found while loading the configuration
+var ErrInvalidConfiguration = errors.New("invalid configuration")
+
+// LoadOptions selects the sources of the configuration, later sources override earlier ones: defaults, the config file,
+// the env file, the process environment and finally the overrides
+type LoadOptions struct {
+	ConfigFile      string            // optional .yaml, .yml or .toml file keyed by the lower case setting names
+	EnvFile         string            // env file, skipped when missing unless EnvFileRequired is set
+	EnvFileRequired bool              // fail when the env file does not exist
+	Overrides       map[string]string // values from command line flags keyed by setting name
+}
+
+// Setting is a configuration value as printed by config print
+type Setting struct {
+	Name  string
+	Value string
+}
+
+// Load builds the configuration from the layered sources and validates it, every problem is reported at once
+func Load(options LoadOptions) (*domain.Configuration, error) {
+	fields := configurationFields()
+
+	values, err := readSettings(options, fields)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfiguration, err)
+	}
+
+	configuration, errs := parseSettings(values, fields)
+
+	// parse errors leave zero values behind, validating them would only repeat the problem
+	if len(errs) == 0 {
+		errs = append(errs, configuration.Validate())
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		log.Error().Err(err).Msg(constants.MsgConfigLoadFail)
+		return nil, fmt.Errorf("%w:\n%w", ErrInvalidConfiguration, err)
+	}
+
+	log.Debug().Str("env", configuration.AppEnv).Msg(constants.MsgConfigLoadSuccess)
+
+	return configuration, nil
+}
+
+// readSettings layers the raw values of the settings from the defaults, the config file, the env file, the process
+// environment and the overrides
+func readSettings(options LoadOptions, fields []reflect.StructField) (map[string]string, error) {
+	values := make(map[string]string)
+
+	for _, field := range fields {
+		if value, ok := field.Tag.Lookup("default"); ok {
+			values[settingName(field)] = value
+		}
+	}
+
+	if options.ConfigFile != "" {
+		fileValues, err := readConfigFile(options.ConfigFile)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := mergeSettings(values, fileValues, fields, options.ConfigFile); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.EnvFile != "" {
+		if err := mergeEnvFile(values, options, fields); err != nil {
+			return nil, err
+		}
+	}
+
+	mergeEnvironment(values, fields)
+
+	return values, mergeSettings(values, options.Overrides, fields, "overrides")
+}
+
+// mergeEnvironment copies the settings set in the process environment into the values
+func mergeEnvironment(values map[string]string, fields []reflect.StructField) {
+	for _, field := range fields {
+		for _, name := range strings.Split(field.Tag.Get("env"), ",") {
+			if value, ok := os.LookupEnv(name); ok {
+				values[settingName(field)] = value
+				break
+			}
+		}
+	}
+}
+
+// mergeEnvFile copies the settings of the env file into the values, a missing file is skipped unless it is required
+func mergeEnvFile(values map[string]string, options LoadOptions, fields []reflect.StructField) error {
+	envValues, err := godotenv.Read(options.EnvFile)
+
+	switch {
+	case errors.Is(err, os.ErrNotExist) && !options.EnvFileRequired:
+		log.Debug().Str("file", options.EnvFile).Msg("No env file, using the environment")
+		return nil
+	case err != nil:
+		return fmt.Errorf("env file %s: %w", options.EnvFile, err)
+	}
+
+	// env files commonly hold variables of other services, so unknown names are ignored
+	for _, field := range fields {
+		if value, ok := lookupSetting(field, envValues); ok {
+			values[settingName(field)] = value
+		}
+	}
+
+	return nil
+}
+
+// parseSettings parses the values into a configuration, returning a problem for every value of the wrong type
+func parseSettings(values map[string]string, fields []reflect.StructField) (*domain.Configuration, []error) {
+	configuration := &domain.Configuration{}
+	target := reflect.ValueOf(configuration).Elem()
+
+	var errs []error
+
+	for i, field := range fields {
+		value, ok := values[settingName(field)]
+		if !ok {
+			continue
+		}
+
+		if err := setField(target.Field(i), value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", settingName(field), err))
+		}
+	}
+
+	return configuration, errs
+}
+
+// Settings returns every setting of the configuration in declaration order with secrets redacted
+func Settings(configuration *domain.Configuration) []Setting {
+	fields := configurationFields()
+	source := reflect.ValueOf(configuration).Elem()
+	settings := make([]Setting, 0, len(fields))
+
+	for i, field := range fields {
+		value := formatField(source.Field(i))
+		if field.Tag.Get("secret") == "true" && value != "" {
+			value = Redacted
+		}
+
+		settings = append(settings, Setting{Name: settingName(field), Value: value})
+	}
+
+	return settings
+}
+
+// configurationFields returns the fields of the configuration struct
+func configurationFields() []reflect.StructField {
+	configurationType := reflect.TypeOf(domain.Configuration{})
+	fields := make([]reflect.StructField, configurationType.NumField())
+
+	for i := range fields {
+		fields[i] = configurationType.Field(i)
+	}
+
+	return fields
+}
+
+// settingName returns the canonical name of the setting, the first name of its env tag
+func settingName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("env"), ",")
+
+	return name
+}
+
+// lookupSetting returns the value of the setting under any of its names, the canonical name first
+func lookupSetting(field reflect.StructField, source map[string]string) (string, bool) {
+	for _, name := range strings.Split(field.Tag.Get("env"), ",") {
+		if value, ok := source[name]; ok {
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+// mergeSettings copies the source into the values, names are case insensitive and unknown names are an error to catch typos
+func mergeSettings(values, source map[string]string, fields []reflect.StructField, origin string) error {
+	known := make(map[string]string)
+
+	for _, field := range fields {
+		for _, name := range strings.Split(field.Tag.Get("env"), ",") {
+			known[name] = settingName(field)
+		}
+	}
+
+	var errs []error
+
+	for name, value := range source {
+		setting, ok := known[strings.ToUpper(name)]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: unknown setting %q", origin, name))
+			continue
+		}
+
+		values[setting] = value
+	}
+
+	return errors.Join(errs...)
+}
+
+// readConfigFile reads the flat settings of a YAML or TOML file as strings
+func readConfigFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	raw := make(map[string]any)
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &raw)
+	case ".toml":
+		err = toml.Unmarshal(content, &raw)
+	default:
+		return nil, fmt.Errorf("config file %s must be a .yaml, .yml or .toml file", path)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string, len(raw))
+
+	for name, value := range raw {
+		switch typed := value.(type) {
+		case []any:
+			items := make([]string, len(typed))
+			for i, item := range typed {
+				items[i] = fmt.Sprint(item)
+			}
+
+			values[name] = strings.Join(items, ",")
+		case map[string]any:
+			return nil, fmt.Errorf("config file %s: %s must be a value, not a table", path, name)
+		case nil:
+			values[name] = ""
+		default:
+			values[name] = fmt.Sprint(typed)
+		}
+	}
+
+	return values, nil
+}
+
+// fieldParser parses the value of a setting into the Go value of its field
+type fieldParser func(value string) (any, error)
+
+// durationType is the type of the settings parsed as durations such as 30s or 2h
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// fieldParsers holds the parser of every kind of field the configuration has, durations are parsed by parseDuration
+var fieldParsers = map[reflect.Kind]fieldParser{
+	reflect.String:  func(value string) (any, error) { return value, nil },
+	reflect.Int:     parseInt,
+	reflect.Float64: parseFloat,
+	reflect.Bool:    parseBool,
+	reflect.Slice:   parseList,
+}
+
+// setField parses the value into the field according to its type
+func setField(field reflect.Value, value string) error {
+	parse, ok := fieldParsers[field.Kind()]
+	if field.Type() == durationType {
+		parse, ok = parseDuration, true
+	}
+
+	if !ok {
+		return fmt.Errorf("unsupported setting type %s", field.Type())
+	}
+
+	parsed, err := parse(strings.TrimSpace(value))
+	if err != nil {
+		return err
+	}
+
+	field.Set(reflect.ValueOf(parsed).Convert(field.Type()))
+
+	return nil
+}
+
+// parseDuration parses a duration such as 30s or 2h
+func parseDuration(value string) (any, error) {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a duration such as 30s or 2h", value)
+	}
+
+	return duration, nil
+}
+
+// parseInt parses a whole number
+func parseInt(value string) (any, error) {
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a whole number", value)
+	}
+
+	return number, nil
+}
+
+// parseFloat parses a number
+func parseFloat(value string) (any, error) {
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a number", value)
+	}
+
+	return number, nil
+}
+
+// parseBool parses true or false
+func parseBool(value string) (any, error) {
+	flag, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not true or false", value)
+	}
+
+	return flag, nil
+}
+
+// parseList parses a comma separated list, skipping empty items
+func parseList(value string) (any, error) {
+	items := make([]string, 0)
+
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items, nil
+}
+
+// formatField formats the field the way it is written in the sources
+func formatField(field reflect.Value) string {
+	switch value := field.Interface().(type) {
+	case []string:
+		return strings.Join(value, ",")
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		return fmt.Sprint(value)
+	}
+}
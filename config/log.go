@@ -2,49 +2,135 @@
 package config
 
 import (
+	"io"
 	"os"
+	"path/filepath"
 	"sync"
 
 	"github.com/okyws/dashboard-backend/constants"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/utils"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 var (
-	logFile   *os.File
-	once      sync.Once
-	closeOnce sync.Once
+	logFile   *lumberjack.Logger
+	logFileMu sync.Mutex
+
+	// logLevel is the level set with SetLogLevel, the global level may be lower for the components of LOG_LEVELS
+	logLevel = zerolog.GlobalLevel()
 )
 
-// InitiateLog sets up the logger safely
-func InitiateLog() {
-	once.Do(func() {
-		if _, err := os.Stat("log"); os.IsNotExist(err) {
-			if err := os.Mkdir("log", os.ModeDir|0750); err != nil {
-				log.Fatal().Err(err).Msg(constants.MsgDirrectoryError)
+// InitiateLog sends the log to the outputs of the configuration with secrets redacted, the level set with SetLogLevel
+// applies to every component without its own level in LOG_LEVELS
+func InitiateLog(configuration *domain.Configuration) error {
+	writers := make([]io.Writer, 0, len(configuration.LogOutputs))
+
+	for _, output := range configuration.LogOutputs {
+		switch output {
+		case domain.LogStdout:
+			writers = append(writers, os.Stdout)
+		case domain.LogConsole:
+			writers = append(writers, zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: "15:04:05.000"})
+		case domain.LogFile:
+			file, err := openLogFile(configuration)
+			if err != nil {
+				log.Error().Err(err).Msg(constants.MsgFileOpenError)
+				return err
 			}
+
+			writers = append(writers, file)
 		}
+	}
+
+	levels, err := componentLevels(configuration)
+	if err != nil {
+		return err
+	}
+
+	// the global level filters before the level of a logger, so it must let through the lowest level of any component
+	lowest := logLevel
+
+	for _, level := range levels {
+		lowest = min(lowest, level)
+	}
+
+	utils.SetComponentLevels(levels)
+	zerolog.SetGlobalLevel(lowest)
+
+	log.Logger = zerolog.New(utils.NewRedactWriter(zerolog.MultiLevelWriter(writers...))).
+		Level(logLevel).With().Timestamp().Logger()
+	log.Info().Strs("outputs", configuration.LogOutputs).Msg(constants.MsgZerologInit)
+
+	return nil
+}
+
+// openLogFile opens the rotating log file, creating its directory
+func openLogFile(configuration *domain.Configuration) (io.Writer, error) {
+	if err := os.MkdirAll(filepath.Dir(configuration.LogFile), 0750); err != nil {
+		return nil, err
+	}
+
+	file := &lumberjack.Logger{
+		Filename:   configuration.LogFile,
+		MaxSize:    configuration.LogFileMaxSize,
+		MaxBackups: configuration.LogFileMaxBackups,
+	}
+
+	// open now so a log file that cannot be written fails the start instead of the first log event
+	if _, err := file.Write(nil); err != nil {
+		return nil, err
+	}
 
-		// process creation log file
-		var err error
+	logFileMu.Lock()
+	defer logFileMu.Unlock()
 
-		logFile, err = os.OpenFile("log/app.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	logFile = file
+
+	return file, nil
+}
+
+// componentLevels parses the levels of LOG_LEVELS
+func componentLevels(configuration *domain.Configuration) (map[string]zerolog.Level, error) {
+	levels := make(map[string]zerolog.Level)
+
+	for component, name := range configuration.ComponentLogLevels() {
+		level, err := zerolog.ParseLevel(name)
 		if err != nil {
-			log.Fatal().Err(err).Msg(constants.MsgFileOpenError)
+			return nil, err
 		}
 
-		log.Logger = zerolog.New(logFile).With().Timestamp().Logger()
-		log.Info().Msg(constants.MsgZerologInit)
-	})
+		levels[component] = level
+	}
+
+	return levels, nil
+}
+
+// SetLogLevel sets the minimum level of the global logger, e.g. debug, info, warn or error
+func SetLogLevel(level string) error {
+	parsed, err := zerolog.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	logLevel = parsed
+	zerolog.SetGlobalLevel(parsed)
+
+	return nil
 }
 
-// CloseLog ensures the log file is closed safely
+// CloseLog closes the log file when the log is written to one
 func CloseLog() {
-	closeOnce.Do(func() {
-		if logFile != nil {
-			if err := logFile.Close(); err != nil {
-				log.Error().Err(err).Msg(constants.MsgFileCloseError)
-			}
+	logFileMu.Lock()
+	defer logFileMu.Unlock()
+
+	if logFile != nil {
+		if err := logFile.Close(); err != nil {
+			log.Error().Err(err).Msg(constants.MsgFileCloseError)
 		}
-	})
+
+		logFile = nil
+	}
 }
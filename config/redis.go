@@ -16,8 +16,12 @@ func NewRedisClient(config *domain.Configuration) *redis.Client {
 	return redisClient
 }
 
-// CloseRedisClient closes the Redis client
+// CloseRedisClient closes the Redis client, nil when the in-memory stores are used
 func CloseRedisClient(redisClient *redis.Client) {
+	if redisClient == nil {
+		return
+	}
+
 	err := redisClient.Close()
 	if err != nil {
 		log.Error().Err(err).Msg(constants.MsgRedisCloseFail)
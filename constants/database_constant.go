@@ -12,6 +12,7 @@ const (
 	MsgDBDropSuccess       = "Successfully dropped database"
 	MsgDBDropFail          = "Failed to drop database"
 	MsgDBSeedSuccess       = "Successfully seeded database"
+	MsgDBSeedFail          = "Failed to seed database"
 	MsgRedisConnectFail    = "Failed to connect to redis"
 	MsgRedisConnectSuccess = "Successfully connected to redis"
 	MsgRedisCloseFail      = "Failed to close redis connection"
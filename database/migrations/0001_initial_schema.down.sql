@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS beneficiaries;
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_endpoints;
+DROP TABLE IF EXISTS outbox_events;
+DROP TABLE IF EXISTS transactions;
+DROP TABLE IF EXISTS bank_accounts;
+DROP TABLE IF EXISTS customers;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,156 @@
+-- baseline of the schema previously created by AutoMigrate, IF NOT EXISTS lets existing databases adopt it
+
+CREATE TABLE IF NOT EXISTS users (
+    id uuid NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    email varchar(100) NOT NULL,
+    username varchar(50) NOT NULL,
+    password varchar(255) NOT NULL,
+    role varchar(20) NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT uni_users_email UNIQUE (email),
+    CONSTRAINT uni_users_username UNIQUE (username)
+);
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE IF NOT EXISTS customers (
+    id uuid NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id uuid NOT NULL,
+    full_name varchar(100) NOT NULL,
+    phone_number varchar(20) NOT NULL,
+    date_of_birth date NOT NULL,
+    address text,
+    PRIMARY KEY (id),
+    CONSTRAINT uni_customers_user_id UNIQUE (user_id),
+    CONSTRAINT uni_customers_phone_number UNIQUE (phone_number),
+    CONSTRAINT fk_customers_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_customers_deleted_at ON customers (deleted_at);
+
+CREATE TABLE IF NOT EXISTS bank_accounts (
+    id uuid NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id uuid NOT NULL,
+    account_type varchar(255) NOT NULL,
+    account_number varchar(255) NOT NULL,
+    balance decimal(10,2) NOT NULL DEFAULT 0,
+    account_status boolean NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT uni_bank_accounts_account_number UNIQUE (account_number),
+    CONSTRAINT fk_bank_accounts_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_bank_accounts_deleted_at ON bank_accounts (deleted_at);
+
+CREATE TABLE IF NOT EXISTS transactions (
+    id uuid NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    from_account_number varchar(20) NOT NULL,
+    to_account_number varchar(20) NOT NULL,
+    amount decimal(10,2) NOT NULL,
+    transaction_type varchar(50) NOT NULL,
+    status varchar(50) NOT NULL,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_transactions_deleted_at ON transactions (deleted_at);
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id uuid NOT NULL,
+    event_type varchar(100) NOT NULL,
+    aggregate_id uuid NOT NULL,
+    payload text NOT NULL,
+    created_at timestamptz NOT NULL,
+    dispatched_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_event_type ON outbox_events (event_type);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_created_at ON outbox_events (created_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_dispatched_at ON outbox_events (dispatched_at);
+
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+    id uuid NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    url varchar(2048) NOT NULL,
+    secret varchar(255) NOT NULL,
+    event_types text NOT NULL,
+    active boolean NOT NULL DEFAULT true,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_endpoints_deleted_at ON webhook_endpoints (deleted_at);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id uuid NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    endpoint_id uuid NOT NULL,
+    event_id uuid NOT NULL,
+    event_type varchar(100) NOT NULL,
+    payload text NOT NULL,
+    status varchar(20) NOT NULL,
+    attempts bigint NOT NULL DEFAULT 0,
+    next_attempt_at timestamptz NOT NULL,
+    last_error text,
+    response_code bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_deleted_at ON webhook_deliveries (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_delivery_event ON webhook_deliveries (endpoint_id, event_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_status ON webhook_deliveries (status);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_next_attempt_at ON webhook_deliveries (next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id uuid NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id uuid NOT NULL,
+    event_id uuid NOT NULL,
+    category varchar(50) NOT NULL,
+    title varchar(255) NOT NULL,
+    message text NOT NULL,
+    read_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_notifications_deleted_at ON notifications (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notification_event ON notifications (user_id, event_id);
+
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    id uuid NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id uuid NOT NULL,
+    category varchar(50) NOT NULL,
+    in_app boolean NOT NULL,
+    email boolean NOT NULL,
+    sms boolean NOT NULL,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_notification_preferences_deleted_at ON notification_preferences (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notification_preference ON notification_preferences (user_id, category);
+
+CREATE TABLE IF NOT EXISTS beneficiaries (
+    id uuid NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    user_id uuid NOT NULL,
+    nickname varchar(50) NOT NULL,
+    account_number varchar(255) NOT NULL,
+    holder_name varchar(100),
+    active_from timestamptz NOT NULL,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_beneficiaries_deleted_at ON beneficiaries (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_beneficiary_account ON beneficiaries (user_id, account_number);
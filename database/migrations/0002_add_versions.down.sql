@@ -0,0 +1,3 @@
+ALTER TABLE bank_accounts DROP COLUMN version;
+ALTER TABLE customers DROP COLUMN version;
+ALTER TABLE users DROP COLUMN version;
@@ -0,0 +1,5 @@
+-- version of the rows clients may change, bumped by every write so a change based on an older read is refused
+
+ALTER TABLE users ADD COLUMN version bigint NOT NULL DEFAULT 1;
+ALTER TABLE customers ADD COLUMN version bigint NOT NULL DEFAULT 1;
+ALTER TABLE bank_accounts ADD COLUMN version bigint NOT NULL DEFAULT 1;
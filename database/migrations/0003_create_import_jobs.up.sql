@@ -0,0 +1,34 @@
+-- bulk imports of users, customers and accounts, run in the background with a per-row error report
+
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id uuid NOT NULL,
+    created_at timestamptz,
+    updated_at timestamptz,
+    deleted_at timestamptz,
+    format varchar(10) NOT NULL,
+    mode varchar(20) NOT NULL,
+    status varchar(30) NOT NULL,
+    language varchar(10) NOT NULL,
+    payload text NOT NULL,
+    total_rows bigint NOT NULL DEFAULT 0,
+    processed_rows bigint NOT NULL DEFAULT 0,
+    imported_rows bigint NOT NULL DEFAULT 0,
+    failed_rows bigint NOT NULL DEFAULT 0,
+    error text,
+    started_at timestamptz,
+    finished_at timestamptz,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_import_jobs_deleted_at ON import_jobs (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_import_jobs_status ON import_jobs (status);
+
+CREATE TABLE IF NOT EXISTS import_row_errors (
+    id uuid NOT NULL,
+    job_id uuid NOT NULL,
+    line bigint NOT NULL,
+    field varchar(50),
+    code varchar(50) NOT NULL,
+    message text NOT NULL,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_import_row_errors_job_id ON import_row_errors (job_id);
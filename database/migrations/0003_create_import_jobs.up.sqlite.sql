@@ -0,0 +1,34 @@
+-- SQLite variant of the import jobs, datetime columns are read back as times by the driver
+
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id uuid NOT NULL,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    format varchar(10) NOT NULL,
+    mode varchar(20) NOT NULL,
+    status varchar(30) NOT NULL,
+    language varchar(10) NOT NULL,
+    payload text NOT NULL,
+    total_rows bigint NOT NULL DEFAULT 0,
+    processed_rows bigint NOT NULL DEFAULT 0,
+    imported_rows bigint NOT NULL DEFAULT 0,
+    failed_rows bigint NOT NULL DEFAULT 0,
+    error text,
+    started_at datetime,
+    finished_at datetime,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_import_jobs_deleted_at ON import_jobs (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_import_jobs_status ON import_jobs (status);
+
+CREATE TABLE IF NOT EXISTS import_row_errors (
+    id uuid NOT NULL,
+    job_id uuid NOT NULL,
+    line bigint NOT NULL,
+    field varchar(50),
+    code varchar(50) NOT NULL,
+    message text NOT NULL,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_import_row_errors_job_id ON import_row_errors (job_id);
@@ -0,0 +1,34 @@
+-- SQLite variant of the batch transfers, datetime columns are read back as times by the driver
+
+CREATE TABLE IF NOT EXISTS transfer_batches (
+    id uuid NOT NULL,
+    created_at datetime,
+    updated_at datetime,
+    deleted_at datetime,
+    user_id uuid NOT NULL,
+    from_account_number varchar(20) NOT NULL,
+    mode varchar(20) NOT NULL,
+    status varchar(30) NOT NULL,
+    total_amount decimal(10,2) NOT NULL,
+    transferred_amount decimal(10,2) NOT NULL DEFAULT 0,
+    succeeded_items bigint NOT NULL DEFAULT 0,
+    failed_items bigint NOT NULL DEFAULT 0,
+    PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS idx_transfer_batches_deleted_at ON transfer_batches (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_transfer_batches_user_id ON transfer_batches (user_id);
+
+CREATE TABLE IF NOT EXISTS transfer_batch_items (
+    id uuid NOT NULL,
+    batch_id uuid NOT NULL,
+    position bigint NOT NULL,
+    to_account_number varchar(20) NOT NULL,
+    amount decimal(10,2) NOT NULL,
+    reference varchar(140),
+    status varchar(20) NOT NULL,
+    error_code varchar(50),
+    error text,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_transfer_batches_items FOREIGN KEY (batch_id) REFERENCES transfer_batches (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_transfer_batch_items_batch_id ON transfer_batch_items (batch_id);
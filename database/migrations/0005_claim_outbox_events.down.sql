@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS outbox_deliveries;
+DROP INDEX IF EXISTS idx_outbox_events_claimed_by;
+ALTER TABLE outbox_events DROP COLUMN claimed_until;
+ALTER TABLE outbox_events DROP COLUMN claimed_by;
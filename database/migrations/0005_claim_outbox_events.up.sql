@@ -0,0 +1,14 @@
+-- claims of the outbox relays, so every event is relayed by one replica at a time, and the subscribers and sinks each
+-- event was delivered to, so a retry only goes to the ones that failed
+
+ALTER TABLE outbox_events ADD COLUMN claimed_by uuid;
+ALTER TABLE outbox_events ADD COLUMN claimed_until timestamptz;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_claimed_by ON outbox_events (claimed_by);
+
+CREATE TABLE IF NOT EXISTS outbox_deliveries (
+    event_id uuid NOT NULL,
+    target varchar(100) NOT NULL,
+    delivered_at timestamptz NOT NULL,
+    PRIMARY KEY (event_id, target),
+    CONSTRAINT fk_outbox_events_deliveries FOREIGN KEY (event_id) REFERENCES outbox_events (id) ON DELETE CASCADE
+);
@@ -0,0 +1,13 @@
+-- SQLite variant of the outbox claims and deliveries, datetime columns are read back as times by the driver
+
+ALTER TABLE outbox_events ADD COLUMN claimed_by uuid;
+ALTER TABLE outbox_events ADD COLUMN claimed_until datetime;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_claimed_by ON outbox_events (claimed_by);
+
+CREATE TABLE IF NOT EXISTS outbox_deliveries (
+    event_id uuid NOT NULL,
+    target varchar(100) NOT NULL,
+    delivered_at datetime NOT NULL,
+    PRIMARY KEY (event_id, target),
+    CONSTRAINT fk_outbox_events_deliveries FOREIGN KEY (event_id) REFERENCES outbox_events (id) ON DELETE CASCADE
+);
@@ -0,0 +1,7 @@
+-- last serial given out per product code, new account numbers take the next one instead of drawing random serials
+
+CREATE TABLE IF NOT EXISTS account_number_sequences (
+    product_code varchar(3) NOT NULL,
+    last_serial bigint NOT NULL,
+    PRIMARY KEY (product_code)
+);
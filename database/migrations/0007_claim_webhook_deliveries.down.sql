@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_webhook_deliveries_claimed_by;
+ALTER TABLE webhook_deliveries DROP COLUMN claimed_until;
+ALTER TABLE webhook_deliveries DROP COLUMN claimed_by;
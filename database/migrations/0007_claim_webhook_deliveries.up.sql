@@ -0,0 +1,5 @@
+-- claims of the webhook dispatchers, so every due delivery is sent by one replica at a time
+
+ALTER TABLE webhook_deliveries ADD COLUMN claimed_by uuid;
+ALTER TABLE webhook_deliveries ADD COLUMN claimed_until timestamptz;
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_claimed_by ON webhook_deliveries (claimed_by);
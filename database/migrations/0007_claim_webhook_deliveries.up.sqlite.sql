@@ -0,0 +1,5 @@
+-- SQLite variant of the webhook delivery claims, datetime columns are read back as times by the driver
+
+ALTER TABLE webhook_deliveries ADD COLUMN claimed_by uuid;
+ALTER TABLE webhook_deliveries ADD COLUMN claimed_until datetime;
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_claimed_by ON webhook_deliveries (claimed_by);
@@ -0,0 +1,343 @@
+// Package database contains the versioned database migrations.
+package database
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MigrationFiles holds the versioned SQL migrations compiled into the binary
+//
+//go:embed migrations/*.sql
+var MigrationFiles embed.FS
+
+// MigrationsDir is the directory of the migration files in the source tree
+const MigrationsDir = "database/migrations"
+
+// migrationFileName matches files named <version>_<name>.<up|down>.sql, or <version>_<name>.<up|down>.<dialect>.sql for
+// SQL that only runs on one database, taking precedence over the shared file
+var migrationFileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)(?:\.([a-z0-9]+))?\.sql$`)
+
+// migrationName matches the name part of a migration file
+var migrationName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// ErrMigrationFile is returned when the migration files are malformed or incomplete
+var ErrMigrationFile = errors.New("invalid migration file")
+
+// SchemaMigration records a migration applied to the database
+type SchemaMigration struct {
+	Version   uint64    `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"type:varchar(255);not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// TableName returns the table that keeps track of the applied migrations
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migration is a versioned schema change with the SQL to apply and to revert it
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus is a migration together with the time it was applied, nil while pending
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Migrator applies and reverts versioned SQL migrations, each in its own transaction
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// NewMigrator creates a migrator for the migration files in the root of files matching the dialect of db
+func NewMigrator(db *gorm.DB, files fs.FS) (*Migrator, error) {
+	migrations, err := LoadMigrations(files, db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// NewEmbeddedMigrator creates a migrator for the migrations compiled into the binary
+func NewEmbeddedMigrator(db *gorm.DB) (*Migrator, error) {
+	files, err := fs.Sub(MigrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMigrator(db, files)
+}
+
+// LoadMigrations reads the migration files for the dialect ordered by version, every version needs an up and a down file
+func LoadMigrations(files fs.FS, dialect string) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	dialectSpecific := make(map[string]bool)
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+
+		file, err := parseMigrationFile(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, err := migrationOf(byVersion, file)
+		if err != nil {
+			return nil, err
+		}
+
+		if !file.selected(dialect, dialectSpecific) {
+			continue
+		}
+
+		content, err := fs.ReadFile(files, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		if file.direction == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	return sortMigrations(byVersion)
+}
+
+// migrationFile is the version, name, direction and optional dialect encoded in the name of a migration file
+type migrationFile struct {
+	version   uint64
+	name      string
+	direction string
+	dialect   string
+}
+
+// parseMigrationFile parses the name of a migration file such as 0001_initial_schema.up.sqlite.sql
+func parseMigrationFile(fileName string) (migrationFile, error) {
+	match := migrationFileName.FindStringSubmatch(fileName)
+	if match == nil {
+		return migrationFile{}, fmt.Errorf("%w: %s", ErrMigrationFile, fileName)
+	}
+
+	version, err := strconv.ParseUint(match[1], 10, 64)
+	if err != nil {
+		return migrationFile{}, fmt.Errorf("%w: %s", ErrMigrationFile, fileName)
+	}
+
+	return migrationFile{version: version, name: match[2], direction: match[3], dialect: match[4]}, nil
+}
+
+// selected reports whether the file is the one to use for its version and direction with the dialect. Files of other
+// dialects are skipped and a shared file never replaces one of this dialect, whatever order the files are read in.
+func (f migrationFile) selected(dialect string, dialectSpecific map[string]bool) bool {
+	key := fmt.Sprintf("%d.%s", f.version, f.direction)
+	if f.dialect != "" && f.dialect != dialect || f.dialect == "" && dialectSpecific[key] {
+		return false
+	}
+
+	dialectSpecific[key] = f.dialect != ""
+
+	return true
+}
+
+// migrationOf returns the migration the file belongs to, every file of a version has to share its name
+func migrationOf(byVersion map[uint64]*Migration, file migrationFile) (*Migration, error) {
+	migration, ok := byVersion[file.version]
+	if !ok {
+		migration = &Migration{Version: file.version, Name: file.name}
+		byVersion[file.version] = migration
+	}
+
+	if migration.Name != file.name {
+		return nil, fmt.Errorf("%w: version %d is used by %s and %s", ErrMigrationFile, file.version, migration.Name, file.name)
+	}
+
+	return migration, nil
+}
+
+// sortMigrations orders the migrations by version once every one of them has an up and a down file
+func sortMigrations(byVersion map[uint64]*Migration) ([]Migration, error) {
+	migrations := make([]Migration, 0, len(byVersion))
+
+	for _, migration := range byVersion {
+		if strings.TrimSpace(migration.Up) == "" || strings.TrimSpace(migration.Down) == "" {
+			return nil, fmt.Errorf("%w: %d_%s needs an up and a down file", ErrMigrationFile, migration.Version, migration.Name)
+		}
+
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Up applies every pending migration in version order and returns the applied ones
+func (m *Migrator) Up() ([]Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migration.Up).Error; err != nil {
+				return err
+			}
+
+			return tx.Create(&SchemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Down reverts the last steps applied migrations, newest first, and returns the reverted ones
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+
+	for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := m.migrations[i]
+
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migration.Down).Error; err != nil {
+				return err
+			}
+
+			return tx.Delete(&SchemaMigration{}, "version = ?", migration.Version).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Status lists every known migration with the time it was applied
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, len(m.migrations))
+
+	for i, migration := range m.migrations {
+		statuses[i] = MigrationStatus{Migration: migration}
+
+		if record, ok := applied[migration.Version]; ok {
+			appliedAt := record.AppliedAt
+			statuses[i].AppliedAt = &appliedAt
+		}
+	}
+
+	return statuses, nil
+}
+
+// applied creates the schema_migrations table when missing and returns the applied migrations by version
+func (m *Migrator) applied() (map[uint64]SchemaMigration, error) {
+	if err := m.db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, err
+	}
+
+	var records []SchemaMigration
+
+	if err := m.db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[uint64]SchemaMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+
+	return applied, nil
+}
+
+// CreateMigration writes empty up and down files for the next version to dir and returns their paths
+func CreateMigration(dir, name string) (string, string, error) {
+	name = strings.ToLower(strings.Join(strings.Fields(name), "_"))
+	if !migrationName.MatchString(name) {
+		return "", "", fmt.Errorf("%w: name %q may only contain letters, digits and underscores", ErrMigrationFile, name)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", "", err
+	}
+
+	var version uint64 = 1
+
+	for _, entry := range entries {
+		if match := migrationFileName.FindStringSubmatch(entry.Name()); match != nil {
+			if current, err := strconv.ParseUint(match[1], 10, 64); err == nil && current >= version {
+				version = current + 1
+			}
+		}
+	}
+
+	base := filepath.Join(dir, fmt.Sprintf("%04d_%s", version, name))
+	up, down := base+".up.sql", base+".down.sql"
+
+	if err := os.WriteFile(up, []byte("-- "+name+"\n"), 0o600); err != nil {
+		return "", "", err
+	}
+
+	if err := os.WriteFile(down, []byte("-- revert "+name+"\n"), 0o600); err != nil {
+		return "", "", err
+	}
+
+	return up, down, nil
+}
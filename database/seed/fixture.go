@@ -0,0 +1,205 @@
+package seed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/okyws/dashboard-backend/adapter/repository"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/ports"
+	"github.com/okyws/dashboard-backend/services"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+// Fixture describes a known data set, accounts are created with their balance as is and transactions then run
+// through the transaction logic
+type Fixture struct {
+	Users        []UserFixture        `json:"users" yaml:"users"`
+	Transactions []TransactionFixture `json:"transactions" yaml:"transactions"`
+}
+
+// UserFixture is a user with its optional customer profile and accounts
+type UserFixture struct {
+	Username string           `json:"username" yaml:"username"`
+	Email    string           `json:"email" yaml:"email"`
+	Password string           `json:"password" yaml:"password"`
+	Role     string           `json:"role" yaml:"role"`
+	Customer *CustomerFixture `json:"customer" yaml:"customer"`
+	Accounts []AccountFixture `json:"accounts" yaml:"accounts"`
+}
+
+// CustomerFixture is the customer profile of a user
+type CustomerFixture struct {
+	FullName    string `json:"full_name" yaml:"full_name"`
+	PhoneNumber string `json:"phone_number" yaml:"phone_number"`
+	DateOfBirth string `json:"date_of_birth" yaml:"date_of_birth"` // 2006-01-02
+	Address     string `json:"address" yaml:"address"`
+}
+
+// AccountFixture is a bank account, the account number is generated when empty
+type AccountFixture struct {
+	AccountType   string  `json:"account_type" yaml:"account_type"`
+	AccountNumber string  `json:"account_number" yaml:"account_number"`
+	Balance       float64 `json:"balance" yaml:"balance"`
+	Frozen        bool    `json:"frozen" yaml:"frozen"`
+}
+
+// TransactionFixture is a deposit, withdrawal or transfer between account numbers of the fixture
+type TransactionFixture struct {
+	Type   string  `json:"type" yaml:"type"`
+	From   string  `json:"from" yaml:"from"`
+	To     string  `json:"to" yaml:"to"`
+	Amount float64 `json:"amount" yaml:"amount"`
+}
+
+// FixtureResult holds the records a fixture created, keyed by username and account number
+type FixtureResult struct {
+	Users    map[string]*domain.User
+	Accounts map[string]*domain.BankAccount
+}
+
+// LoadFixtureFile reads a fixture from a .yaml, .yml or .json file
+func LoadFixtureFile(path string) (*Fixture, error) {
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	var fixture Fixture
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &fixture)
+	case ".json":
+		err = json.Unmarshal(content, &fixture)
+	default:
+		return nil, fmt.Errorf("fixture %s must be a .yaml, .yml or .json file", path)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("fixture %s: %w", path, err)
+	}
+
+	return &fixture, nil
+}
+
+// ApplyFixture writes the fixture to the database
+func ApplyFixture(ctx context.Context, db *gorm.DB, scheme ports.AccountNumberScheme, fixture *Fixture) (*FixtureResult, error) {
+	userRepository := repository.NewUserRepositoryAdapter(db)
+	customerRepository := repository.NewCustomerRepositoryAdapter(db)
+	bankRepository := repository.NewBankAccountRepositoryAdapter(db, scheme)
+	work := repository.NewUnitOfWork(db)
+	validator := services.NewTransactionValidator(repository.NewTransactionRepositoryAdapter(db), bankRepository,
+		repository.NewOutboxRepositoryAdapter(db), nil)
+
+	result := &FixtureResult{Users: make(map[string]*domain.User), Accounts: make(map[string]*domain.BankAccount)}
+
+	for _, userFixture := range fixture.Users {
+		user, err := createUser(ctx, userRepository, userFixture)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Users[user.Username] = user
+
+		if userFixture.Customer != nil {
+			if err := createCustomer(ctx, customerRepository, user, userFixture.Customer); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, accountFixture := range userFixture.Accounts {
+			account, err := createAccount(ctx, bankRepository, user, accountFixture)
+			if err != nil {
+				return nil, err
+			}
+
+			result.Accounts[account.AccountNumber] = account
+		}
+	}
+
+	for i, transactionFixture := range fixture.Transactions {
+		err := work.Do(ctx, func(ctx context.Context) error {
+			return validator.ProcessTransaction(ctx, &domain.Transaction{
+				FromAccountNumber: transactionFixture.From,
+				ToAccountNumber:   transactionFixture.To,
+				Amount:            transactionFixture.Amount,
+				TransactionType:   transactionFixture.Type,
+			})
+		})
+		if err != nil {
+			return nil, fmt.Errorf("fixture transaction %d: %w", i+1, err)
+		}
+	}
+
+	return result, nil
+}
+
+// createUser creates a fixture user, with the default password and the user role unless the fixture sets them
+func createUser(ctx context.Context, userRepository ports.UserRepository, userFixture UserFixture) (*domain.User, error) {
+	password := userFixture.Password
+	if password == "" {
+		password = DefaultSeedPassword
+	}
+
+	role := userFixture.Role
+	if role == "" {
+		role = "user"
+	}
+
+	user, err := userRepository.Create(ctx, &domain.User{Username: userFixture.Username, Email: userFixture.Email, Password: password, Role: role})
+	if err != nil {
+		return nil, fmt.Errorf("fixture user %s: %w", userFixture.Username, err)
+	}
+
+	return user, nil
+}
+
+// createAccount creates a bank account of a fixture user with its balance, freezing it when the fixture says so
+func createAccount(ctx context.Context, bankRepository ports.BankAccountRepository, user *domain.User, accountFixture AccountFixture) (*domain.BankAccount, error) {
+	account, err := bankRepository.Create(ctx, &domain.BankAccount{
+		UserID:        user.ID,
+		AccountType:   accountFixture.AccountType,
+		AccountNumber: accountFixture.AccountNumber,
+		Balance:       accountFixture.Balance,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fixture account of %s: %w", user.Username, err)
+	}
+
+	// new accounts always start active
+	if accountFixture.Frozen {
+		if account, err = bankRepository.UpdateStatus(ctx, account.ID.String(), account.Version, false); err != nil {
+			return nil, fmt.Errorf("fixture account of %s: %w", user.Username, err)
+		}
+	}
+
+	return account, nil
+}
+
+// createCustomer creates the customer profile of a fixture user
+func createCustomer(ctx context.Context, customerRepository ports.CustomerRepository, user *domain.User, customerFixture *CustomerFixture) error {
+	dateOfBirth, err := time.Parse("2006-01-02", customerFixture.DateOfBirth)
+	if err != nil {
+		return fmt.Errorf("fixture customer of %s: %w", user.Username, err)
+	}
+
+	_, err = customerRepository.Create(ctx, &domain.Customer{
+		UserID:      user.ID,
+		FullName:    customerFixture.FullName,
+		PhoneNumber: customerFixture.PhoneNumber,
+		DateOfBirth: dateOfBirth,
+		Address:     customerFixture.Address,
+	})
+	if err != nil {
+		return fmt.Errorf("fixture customer of %s: %w", user.Username, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,315 @@
+// Package seed fills the database with generated data and fixtures.
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/okyws/dashboard-backend/adapter/repository"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/ports"
+	"github.com/okyws/dashboard-backend/services"
+	"github.com/okyws/dashboard-backend/utils"
+	"gorm.io/gorm"
+)
+
+// DefaultSeedPassword is the password of every seeded user unless another one is given
+const DefaultSeedPassword = "password"
+
+// ErrUnknownSeedProfile is returned for a profile name missing from SeedProfiles
+var ErrUnknownSeedProfile = errors.New("unknown seed profile")
+
+// SeedProfile sets how much data is seeded, the admin and user logins are always created on top of Users
+type SeedProfile struct {
+	Users               int
+	AccountsPerUser     int
+	TransactionsPerUser int
+}
+
+// SeedProfiles are the named seed profiles
+var SeedProfiles = map[string]SeedProfile{
+	"minimal":   {Users: 0, AccountsPerUser: 1, TransactionsPerUser: 2},
+	"demo":      {Users: 10, AccountsPerUser: 3, TransactionsPerUser: 10},
+	"load-test": {Users: 1000, AccountsPerUser: 4, TransactionsPerUser: 50},
+}
+
+// GetSeedProfile returns the named seed profile
+func GetSeedProfile(name string) (SeedProfile, error) {
+	profile, ok := SeedProfiles[name]
+	if !ok {
+		names := make([]string, 0, len(SeedProfiles))
+		for name := range SeedProfiles {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		return SeedProfile{}, fmt.Errorf("%w %q, use one of %s", ErrUnknownSeedProfile, name, strings.Join(names, ", "))
+	}
+
+	return profile, nil
+}
+
+// SeedOptions configures a seeding run, the same seed generates the same names, amounts and transaction sequence
+type SeedOptions struct {
+	SeedProfile
+	Seed     uint64
+	Password string
+}
+
+// SeedSummary counts the seeded records
+type SeedSummary struct {
+	Users        int
+	Accounts     int
+	Transactions int
+}
+
+// seedAccountTypes are cycled through for the accounts after the main account
+var seedAccountTypes = []string{"saku", "celengan", "deposito"}
+
+var seedFirstNames = []string{"Andi", "Budi", "Citra", "Dewi", "Eka", "Fajar", "Gita", "Hadi", "Indah", "Joko", "Kartika", "Lestari",
+	"Made", "Nur", "Putri", "Rizky", "Sari", "Taufik", "Wulan", "Yusuf"}
+
+var seedLastNames = []string{"Pratama", "Saputra", "Wijaya", "Hidayat", "Nugroho", "Santoso", "Kusuma", "Siregar", "Lubis", "Halim",
+	"Setiawan", "Gunawan", "Rahman", "Utami", "Permana"}
+
+// Seeder fills the database with generated users, customers, accounts and transactions. Transactions run through
+// the transaction logic, so balances always match the transaction history.
+type Seeder struct {
+	userRepository     ports.UserRepository
+	customerRepository ports.CustomerRepository
+	bankRepository     ports.BankAccountRepository
+	work               ports.UnitOfWork
+	validator          *services.TransactionValidator
+	options            SeedOptions
+	rng                *rand.Rand
+	balances           map[string]float64
+}
+
+// NewSeeder creates a seeder writing to the database
+func NewSeeder(db *gorm.DB, scheme ports.AccountNumberScheme, options SeedOptions) *Seeder {
+	if options.Password == "" {
+		options.Password = DefaultSeedPassword
+	}
+
+	bankRepository := repository.NewBankAccountRepositoryAdapter(db, scheme)
+
+	return &Seeder{
+		userRepository:     repository.NewUserRepositoryAdapter(db),
+		customerRepository: repository.NewCustomerRepositoryAdapter(db),
+		bankRepository:     bankRepository,
+		work:               repository.NewUnitOfWork(db),
+		validator: services.NewTransactionValidator(repository.NewTransactionRepositoryAdapter(db), bankRepository,
+			repository.NewOutboxRepositoryAdapter(db), nil),
+		options:  options,
+		rng:      rand.New(rand.NewPCG(options.Seed, options.Seed)), //nolint:gosec // reproducible seed data, not secrets
+		balances: make(map[string]float64),
+	}
+}
+
+// Run seeds the admin and user logins followed by the generated users
+func (s *Seeder) Run(ctx context.Context) (*SeedSummary, error) {
+	if err := s.options.validate(); err != nil {
+		return nil, err
+	}
+
+	// one hash for every seeded user keeps large profiles fast
+	hash, err := utils.GeneratePasswordHash(s.options.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &SeedSummary{}
+
+	if _, err := s.userRepository.Create(ctx, &domain.User{Username: "admin", Email: "admin@example.com", Password: hash, Role: "admin"}); err != nil {
+		return nil, err
+	}
+
+	summary.Users++
+
+	accounts, err := s.seedUsers(ctx, hash, summary)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.openingDeposits(ctx, accounts, summary); err != nil {
+		return nil, err
+	}
+
+	if err := s.randomTransactions(ctx, accounts, summary); err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+// validate checks that the counts of the options can be seeded
+func (o SeedOptions) validate() error {
+	if o.AccountsPerUser < 1 {
+		return errors.New("every seeded user needs at least one account")
+	}
+
+	if o.Users < 0 || o.TransactionsPerUser < 0 {
+		return errors.New("seed counts cannot be negative")
+	}
+
+	return nil
+}
+
+// seedUsers creates the user login and the generated users, returning the account numbers of every user
+func (s *Seeder) seedUsers(ctx context.Context, hash string, summary *SeedSummary) ([][]string, error) {
+	var accounts [][]string
+
+	for i := 0; i <= s.options.Users; i++ {
+		username := "user"
+		if i > 0 {
+			username = fmt.Sprintf("%s.%s%d", strings.ToLower(s.pick(seedFirstNames)), strings.ToLower(s.pick(seedLastNames)), i)
+		}
+
+		accountNumbers, err := s.seedUser(ctx, i, username, hash)
+		if err != nil {
+			return nil, err
+		}
+
+		accounts = append(accounts, accountNumbers)
+		summary.Users++
+		summary.Accounts += len(accountNumbers)
+	}
+
+	return accounts, nil
+}
+
+// openingDeposits funds the main account of every user
+func (s *Seeder) openingDeposits(ctx context.Context, accounts [][]string, summary *SeedSummary) error {
+	for _, accountNumbers := range accounts {
+		if err := s.process(ctx, "deposit", "", accountNumbers[0], s.amount(1000, 10000)*1000); err != nil {
+			return err
+		}
+
+		summary.Transactions++
+	}
+
+	return nil
+}
+
+// randomTransactions posts the random transactions of every user
+func (s *Seeder) randomTransactions(ctx context.Context, accounts [][]string, summary *SeedSummary) error {
+	for _, accountNumbers := range accounts {
+		for range s.options.TransactionsPerUser {
+			if err := s.randomTransaction(ctx, accountNumbers, accounts); err != nil {
+				return err
+			}
+
+			summary.Transactions++
+		}
+	}
+
+	return nil
+}
+
+// seedUser creates a user with a customer profile and its accounts, returning the account numbers with the main account first
+func (s *Seeder) seedUser(ctx context.Context, index int, username, hash string) ([]string, error) {
+	user, err := s.userRepository.Create(ctx, &domain.User{Username: username, Email: username + "@example.com", Password: hash, Role: "user"})
+	if err != nil {
+		return nil, err
+	}
+
+	firstName, lastName := s.pick(seedFirstNames), s.pick(seedLastNames)
+	dateOfBirth := time.Date(1960+s.rng.IntN(45), time.Month(1+s.rng.IntN(12)), 1+s.rng.IntN(28), 0, 0, 0, 0, time.UTC)
+
+	_, err = s.customerRepository.Create(ctx, &domain.Customer{
+		UserID:      user.ID,
+		FullName:    firstName + " " + lastName,
+		PhoneNumber: fmt.Sprintf("08%010d", index+1),
+		DateOfBirth: dateOfBirth,
+		Address:     fmt.Sprintf("Jl. %s No. %d", s.pick(seedLastNames), 1+s.rng.IntN(200)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	accountNumbers := make([]string, s.options.AccountsPerUser)
+
+	for i := range accountNumbers {
+		accountType := "rekening-utama"
+		if i > 0 {
+			accountType = seedAccountTypes[(i-1)%len(seedAccountTypes)]
+		}
+
+		account, err := s.bankRepository.Create(ctx, &domain.BankAccount{UserID: user.ID, AccountType: accountType})
+		if err != nil {
+			return nil, err
+		}
+
+		accountNumbers[i] = account.AccountNumber
+	}
+
+	return accountNumbers, nil
+}
+
+// randomTransaction posts a deposit, withdrawal or transfer, depositing instead when the source cannot cover the amount
+func (s *Seeder) randomTransaction(ctx context.Context, own []string, accounts [][]string) error {
+	amount := s.amount(10, 500) * 1000
+	from := s.pick(own)
+
+	switch kind := s.rng.IntN(10); {
+	case kind < 5 && s.balances[from] > amount:
+		to := s.pick(s.pickAccounts(accounts))
+		if to == from {
+			to = own[0]
+		}
+
+		if to != from {
+			return s.process(ctx, "transfer", from, to, amount)
+		}
+	case kind < 8 && s.balances[from] >= amount:
+		return s.process(ctx, "withdraw", from, "", amount)
+	}
+
+	return s.process(ctx, "deposit", "", from, amount)
+}
+
+// process posts the transaction through the transaction logic and mirrors the balances it changed
+func (s *Seeder) process(ctx context.Context, transactionType, from, to string, amount float64) error {
+	err := s.work.Do(ctx, func(ctx context.Context) error {
+		return s.validator.ProcessTransaction(ctx, &domain.Transaction{
+			FromAccountNumber: from,
+			ToAccountNumber:   to,
+			Amount:            amount,
+			TransactionType:   transactionType,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("seed %s of %.2f: %w", transactionType, amount, err)
+	}
+
+	if from != "" {
+		s.balances[from] -= amount
+	}
+
+	if to != "" {
+		s.balances[to] += amount
+	}
+
+	return nil
+}
+
+// amount returns a whole number in [lowest, highest]
+func (s *Seeder) amount(lowest, highest int) float64 {
+	return float64(lowest + s.rng.IntN(highest-lowest+1))
+}
+
+// pick returns a random element of values
+func (s *Seeder) pick(values []string) string {
+	return values[s.rng.IntN(len(values))]
+}
+
+// pickAccounts returns the accounts of a random user
+func (s *Seeder) pickAccounts(accounts [][]string) []string {
+	return accounts[s.rng.IntN(len(accounts))]
+}
@@ -0,0 +1,8 @@
+// Package domain contains the account number sequence model
+package domain
+
+// AccountNumberSequence holds the last serial given out to the account numbers of a product code
+type AccountNumberSequence struct {
+	ProductCode string `gorm:"type:varchar(3);primary_key" json:"product_code"`
+	LastSerial  uint64 `gorm:"not null" json:"last_serial"`
+}
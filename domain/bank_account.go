@@ -2,6 +2,8 @@
 package domain
 
 import (
+	"errors"
+
 	"github.com/google/uuid"
 	"github.com/okyws/dashboard-backend/dto"
 	"github.com/okyws/dashboard-backend/utils"
@@ -18,18 +20,31 @@ type BankAccount struct {
 	AccountNumber string    `gorm:"type:varchar(255);unique;not null" json:"account_number"`
 	Balance       float64   `gorm:"type:decimal(10,2);not null;default:0" json:"last_balance"`
 	AccountStatus bool      `gorm:"type:bool;not null" json:"account_status"`
+	Version       uint      `gorm:"not null;default:1" json:"version"` // bumped by every update, the ETag of the account
+}
+
+// AccountProductCodes maps every account type to the product code prefixing its account numbers
+var AccountProductCodes = map[string]string{
+	"rekening-utama": "101",
+	"saku":           "102",
+	"celengan":       "103",
+	"deposito":       "104",
 }
 
+// DefaultAccountNumberScheme is the account number scheme used when none is configured
+var DefaultAccountNumberScheme = utils.NewLuhnAccountNumberScheme(AccountProductCodes)
+
+// ErrAccountNumberMissing is returned when a bank account is created without an account number, the repository
+// assigns one with the configured scheme
+var ErrAccountNumberMissing = errors.New("bank account has no account number")
+
 // BeforeCreate is a GORM hook to generate a UUID for the bank information
-func (b *BankAccount) BeforeCreate(tx *gorm.DB) error {
-	result, err := utils.GenerateAccountNumber(10)
-	if err != nil || result == "" {
-		tx.Rollback()
-		return err
+func (b *BankAccount) BeforeCreate(_ *gorm.DB) error {
+	if b.AccountNumber == "" {
+		return ErrAccountNumberMissing
 	}
 
 	b.ID = uuid.New()
-	b.AccountNumber = result
 	b.AccountStatus = true
 
 	return nil
@@ -0,0 +1,45 @@
+// Package domain contains the beneficiary model and its methods
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/okyws/dashboard-backend/dto"
+	"gorm.io/gorm"
+)
+
+// Beneficiary struct represents a payee saved in the beneficiary book of a user
+type Beneficiary struct {
+	gorm.Model
+	ID            uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	UserID        uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_beneficiary_account" json:"user_id"`
+	Nickname      string    `gorm:"type:varchar(50);not null" json:"nickname"`
+	AccountNumber string    `gorm:"type:varchar(255);not null;uniqueIndex:idx_beneficiary_account" json:"account_number"`
+	HolderName    string    `gorm:"type:varchar(100)" json:"holder_name"` // masked name of the account holder
+	ActiveFrom    time.Time `gorm:"not null" json:"active_from"`          // end of the cooling-off period
+}
+
+// BeforeCreate is a GORM hook to generate a UUID for the beneficiary
+func (b *Beneficiary) BeforeCreate(_ *gorm.DB) error {
+	b.ID = uuid.New()
+	return nil
+}
+
+// CoolingOff reports whether the beneficiary is still in its cooling-off period
+func (b *Beneficiary) CoolingOff(now time.Time) bool {
+	return now.Before(b.ActiveFrom)
+}
+
+// MapBeneficiaryToDTO maps a beneficiary to a BeneficiaryDTO
+func MapBeneficiaryToDTO(beneficiary *Beneficiary) *dto.BeneficiaryDTO {
+	return &dto.BeneficiaryDTO{
+		ID:            beneficiary.ID,
+		Nickname:      beneficiary.Nickname,
+		AccountNumber: beneficiary.AccountNumber,
+		HolderName:    beneficiary.HolderName,
+		ActiveFrom:    beneficiary.ActiveFrom,
+		CoolingOff:    beneficiary.CoolingOff(time.Now()),
+		CreatedAt:     beneficiary.CreatedAt,
+	}
+}
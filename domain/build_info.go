@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Build information injected at build time with
+// -ldflags "-X github.com/okyws/dashboard-backend/domain.GitCommit=<sha> -X github.com/okyws/dashboard-backend/domain.BuildTime=<time>",
+// when empty the version control information Go embeds in the binary is used
+var (
+	GitCommit string
+	BuildTime string
+)
+
+// unknownBuildInfo is reported when neither the flags nor the embedded build information know the value
+const unknownBuildInfo = "unknown"
+
+// BuildInfo describes the running binary
+type BuildInfo struct {
+	GitCommit string
+	BuildTime string
+	GoVersion string
+}
+
+// GetBuildInfo returns the build information of the running binary
+func GetBuildInfo() BuildInfo {
+	info := BuildInfo{GitCommit: GitCommit, BuildTime: BuildTime, GoVersion: runtime.Version()}
+
+	if embedded, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range embedded.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.GitCommit == "":
+				info.GitCommit = setting.Value
+			case setting.Key == "vcs.time" && info.BuildTime == "":
+				info.BuildTime = setting.Value
+			}
+		}
+	}
+
+	if info.GitCommit == "" {
+		info.GitCommit = unknownBuildInfo
+	}
+
+	if info.BuildTime == "" {
+		info.BuildTime = unknownBuildInfo
+	}
+
+	return info
+}
@@ -2,82 +2,406 @@
 package domain
 
 import (
+	"errors"
 	"fmt"
-	"os"
-	"strconv"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
 
-	"github.com/joho/godotenv"
-	"github.com/okyws/dashboard-backend/constants"
 	"github.com/redis/go-redis/v9"
-	"github.com/rs/zerolog/log"
 )
 
-// Configuration struct for application configuration
+// Configuration struct for application configuration, the env tag names the setting in env files, the environment and
+// config files (in lower case), the default tag is used when no source sets it and secret settings are redacted
 type Configuration struct {
-	AppName    string
-	AppVersion string
-	ServerPort string
-	ServerHost string
-	APIKey     string
-	JWTSecret  string
-	DBUser     string
-	DBPassword string
-	DBHost     string
-	DBPort     string
-	DBName     string
-	DBSSLMode  string
-	DBTimeZone string
-	REDISHost  string
-	REDISPort  string
-	REDISDB    string
-	REDISPass  string
-}
-
-// LoadConfig reads configuration values from .env
-func LoadConfig() (*Configuration, error) {
-	err := godotenv.Load(".env")
-	if err != nil {
-		log.Fatal().Err(err).Msg(constants.MsgConfigLoadFail)
-	}
-
-	config := &Configuration{
-		AppName:    os.Getenv("APP_NAME"),
-		AppVersion: os.Getenv("APP_VERSION"),
-		ServerPort: os.Getenv("SERVER_PORT"),
-		ServerHost: os.Getenv("SERVER_HOST"),
-		APIKey:     os.Getenv("API_KEY"),
-		JWTSecret:  os.Getenv("JWT_SECRET_KEY"),
-		DBUser:     os.Getenv("DB_USER"),
-		DBPassword: os.Getenv("DB_PASSWORD"),
-		DBHost:     os.Getenv("DB_HOST"),
-		DBPort:     os.Getenv("DB_PORT"),
-		DBName:     os.Getenv("DB_NAME"),
-		DBSSLMode:  os.Getenv("DB_SSL_MODE"),
-		DBTimeZone: os.Getenv("DB_TIMEZONE"),
-		REDISHost:  os.Getenv("REDIS_HOST"),
-		REDISPort:  os.Getenv("REDIS_PORT"),
-		REDISDB:    os.Getenv("REDIS_DB"),
-		REDISPass:  os.Getenv("REDIS_PASS"),
-	}
-
-	return config, nil
-}
-
-// GetDatabaseConfig returns the database source name
+	AppName    string        `env:"APP_NAME" default:"Dashboard Backend"`
+	AppVersion string        `env:"APP_VERSION" default:"1.0.0"`
+	AppEnv     string        `env:"APP_ENV" default:"development"`
+	ServerPort int           `env:"SERVER_PORT" default:"8080"`
+	ServerHost string        `env:"SERVER_HOST" default:"localhost"`
+	ClientURL  string        `env:"CLIENT_URL" default:"http://localhost:3000"`
+	APIKey     string        `env:"API_KEY" secret:"true"`
+	JWTSecret  string        `env:"JWT_SECRET_KEY" secret:"true"`
+	JWTExpiry  time.Duration `env:"JWT_EXPIRY" default:"2h"`
+	DBDriver   string        `env:"DB_DRIVER" default:"postgres"`
+	DBPath     string        `env:"DB_PATH" default:"dashboard.db"`
+	DBUser     string        `env:"DB_USER"`
+	DBPassword string        `env:"DB_PASSWORD" secret:"true"`
+	DBHost     string        `env:"DB_HOST" default:"localhost"`
+	DBPort     int           `env:"DB_PORT" default:"5432"`
+	DBName     string        `env:"DB_NAME"`
+	DBSSLMode  string        `env:"DB_SSL_MODE" default:"disable"`
+	DBTimeZone string        `env:"DB_TIMEZONE" default:"UTC"`
+	REDISHost  string        `env:"REDIS_HOST" default:"localhost"`
+	REDISPort  int           `env:"REDIS_PORT" default:"6379"`
+	REDISDB    int           `env:"REDIS_DB" default:"0"`
+	REDISPass  string        `env:"REDIS_PASSWORD,REDIS_PASS" secret:"true"`
+	EventSinks []string      `env:"EVENT_SINKS" default:"log"`
+
+	StoreDriver string `env:"STORE_DRIVER" default:"redis"`
+
+	// MetricsPort serves /metrics on a separate admin port, 0 serves it on SERVER_PORT behind METRICS_TOKEN
+	MetricsPort  int    `env:"METRICS_PORT" default:"8081"`
+	MetricsToken string `env:"METRICS_TOKEN" secret:"true"`
+
+	// TracingExporter sends the spans to the OTLP/HTTP collector at TracingEndpoint, prints them to stdout or keeps them
+	// only for the trace IDs of the logs
+	TracingExporter    string  `env:"TRACING_EXPORTER" default:"none"`
+	TracingEndpoint    string  `env:"OTEL_EXPORTER_OTLP_ENDPOINT" default:"http://localhost:4318"`
+	TracingSampleRatio float64 `env:"TRACING_SAMPLE_RATIO" default:"1"`
+
+	// LogOutputs writes the log as JSON to stdout, pretty printed to the console and to LogFile, rotated at
+	// LogFileMaxSize megabytes keeping LogFileMaxBackups old files. LogLevels sets the level per component, e.g.
+	// services=debug,repository=warn, the other components log at the --log-level
+	LogOutputs        []string `env:"LOG_OUTPUTS" default:"stdout"`
+	LogFile           string   `env:"LOG_FILE" default:"log/app.log"`
+	LogFileMaxSize    int      `env:"LOG_FILE_MAX_SIZE" default:"100"`
+	LogFileMaxBackups int      `env:"LOG_FILE_MAX_BACKUPS" default:"5"`
+	LogLevels         []string `env:"LOG_LEVELS"`
+
+	// RequestTimeout is the deadline of every API request except the long-lived streams, 0 disables it
+	RequestTimeout time.Duration `env:"REQUEST_TIMEOUT" default:"10s"`
+
+	// ShutdownDelay is how long the server keeps serving with a failing readiness probe before it shuts down
+	ShutdownDelay time.Duration `env:"SHUTDOWN_DELAY" default:"0s"`
+
+	AccountNumberScheme string `env:"ACCOUNT_NUMBER_SCHEME" default:"luhn"`
+
+	BeneficiaryCoolingOff      time.Duration `env:"BENEFICIARY_COOLING_OFF" default:"0s"`
+	BeneficiaryCoolingOffLimit float64       `env:"BENEFICIARY_COOLING_OFF_LIMIT" default:"1000000"`
+
+	// APIV1Sunset is the date (YYYY-MM-DD) announced in the Sunset header of the deprecated /api/v1 routes
+	APIV1Sunset string `env:"API_V1_SUNSET" default:"2027-06-30"`
+}
+
+// Database drivers selected with DB_DRIVER
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+
+	// SQLiteMemory as DB_PATH keeps the database in memory until the process exits
+	SQLiteMemory = ":memory:"
+)
+
+// Store drivers selected with STORE_DRIVER for the login tokens and the stream fan-out
+const (
+	StoreRedis  = "redis"
+	StoreMemory = "memory"
+)
+
+// Span exporters selected with TRACING_EXPORTER
+const (
+	TracingNone   = "none"
+	TracingStdout = "stdout"
+	TracingOTLP   = "otlp"
+)
+
+// Log outputs selected with LOG_OUTPUTS
+const (
+	LogStdout  = "stdout"
+	LogConsole = "console"
+	LogFile    = "file"
+)
+
+// Settings accepted by the configuration validation
+var (
+	AppEnvironments      = []string{"development", "test", "production"}
+	DatabaseDrivers      = []string{DriverPostgres, DriverSQLite}
+	StoreDrivers         = []string{StoreRedis, StoreMemory}
+	AccountNumberSchemes = []string{"luhn", "mod97"}
+	EventSinkNames       = []string{"log", "redis"}
+	TracingExporters     = []string{TracingNone, TracingStdout, TracingOTLP}
+	LogOutputNames       = []string{LogStdout, LogConsole, LogFile}
+	LogLevelNames        = []string{"trace", "debug", "info", "warn", "error", "disabled"}
+	LogComponents        = []string{"http", "handler", "services", "repository", "broker", "notifier"}
+)
+
+// Validate checks every setting and returns all problems joined, or nil when the configuration is usable
+func (c *Configuration) Validate() error {
+	return errors.Join(slices.Concat(
+		c.validateServer(),
+		c.validateJWT(),
+		c.validateDatabase(),
+		c.validateRedis(),
+		c.validateAccounts(),
+		c.validateMetrics(),
+		c.validateTracing(),
+		c.validateLog(),
+	)...)
+}
+
+// validateServer checks the environment, the port, the timeouts and the sunset of the v1 API
+func (c *Configuration) validateServer() []error {
+	errs := []error{validatePort("SERVER_PORT", c.ServerPort)}
+
+	if !slices.Contains(AppEnvironments, c.AppEnv) {
+		errs = append(errs, fmt.Errorf("APP_ENV must be one of %s, got %q", strings.Join(AppEnvironments, ", "), c.AppEnv))
+	}
+
+	if c.RequestTimeout < 0 {
+		errs = append(errs, fmt.Errorf("REQUEST_TIMEOUT must not be negative, got %s", c.RequestTimeout))
+	}
+
+	if c.ShutdownDelay < 0 {
+		errs = append(errs, fmt.Errorf("SHUTDOWN_DELAY must not be negative, got %s", c.ShutdownDelay))
+	}
+
+	if _, err := time.Parse(time.DateOnly, c.APIV1Sunset); err != nil {
+		errs = append(errs, fmt.Errorf("API_V1_SUNSET must be a date such as 2027-06-30, got %q", c.APIV1Sunset))
+	}
+
+	return errs
+}
+
+// validateJWT checks the secret and the expiry of the tokens
+func (c *Configuration) validateJWT() []error {
+	var errs []error
+
+	if c.JWTSecret == "" {
+		errs = append(errs, errors.New("JWT_SECRET_KEY is required"))
+	}
+
+	if c.JWTExpiry <= 0 {
+		errs = append(errs, fmt.Errorf("JWT_EXPIRY must be positive, got %s", c.JWTExpiry))
+	}
+
+	return errs
+}
+
+// validateDatabase checks the settings the database driver needs
+func (c *Configuration) validateDatabase() []error {
+	switch c.DBDriver {
+	case DriverPostgres:
+		return append(validateRequired([2]string{"DB_HOST", c.DBHost}, [2]string{"DB_USER", c.DBUser}, [2]string{"DB_NAME", c.DBName}),
+			validatePort("DB_PORT", c.DBPort))
+	case DriverSQLite:
+		return validateRequired([2]string{"DB_PATH", c.DBPath})
+	default:
+		return []error{fmt.Errorf("DB_DRIVER must be one of %s, got %q", strings.Join(DatabaseDrivers, ", "), c.DBDriver)}
+	}
+}
+
+// validateRedis checks the store driver, the redis connection it needs and the event sinks relying on it
+func (c *Configuration) validateRedis() []error {
+	var errs []error
+
+	switch c.StoreDriver {
+	case StoreRedis:
+		errs = append(validateRequired([2]string{"REDIS_HOST", c.REDISHost}), validatePort("REDIS_PORT", c.REDISPort))
+	case StoreMemory:
+		if slices.Contains(c.EventSinks, "redis") {
+			errs = append(errs, errors.New("EVENT_SINKS must not contain redis when STORE_DRIVER is memory"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("STORE_DRIVER must be one of %s, got %q", strings.Join(StoreDrivers, ", "), c.StoreDriver))
+	}
+
+	if c.REDISDB < 0 {
+		errs = append(errs, fmt.Errorf("REDIS_DB must not be negative, got %d", c.REDISDB))
+	}
+
+	for _, sink := range c.EventSinks {
+		if !slices.Contains(EventSinkNames, sink) {
+			errs = append(errs, fmt.Errorf("EVENT_SINKS must only contain %s, got %q", strings.Join(EventSinkNames, ", "), sink))
+		}
+	}
+
+	return errs
+}
+
+// validateAccounts checks the account number scheme and the cooling-off period of new beneficiaries
+func (c *Configuration) validateAccounts() []error {
+	var errs []error
+
+	if !slices.Contains(AccountNumberSchemes, c.AccountNumberScheme) {
+		errs = append(errs, fmt.Errorf("ACCOUNT_NUMBER_SCHEME must be one of %s, got %q",
+			strings.Join(AccountNumberSchemes, ", "), c.AccountNumberScheme))
+	}
+
+	if c.BeneficiaryCoolingOff < 0 {
+		errs = append(errs, fmt.Errorf("BENEFICIARY_COOLING_OFF must not be negative, got %s", c.BeneficiaryCoolingOff))
+	}
+
+	if c.BeneficiaryCoolingOffLimit < 0 {
+		errs = append(errs, fmt.Errorf("BENEFICIARY_COOLING_OFF_LIMIT must not be negative, got %g", c.BeneficiaryCoolingOffLimit))
+	}
+
+	return errs
+}
+
+// validateMetrics checks where /metrics is served and that it is protected on the port of the API
+func (c *Configuration) validateMetrics() []error {
+	switch {
+	case c.MetricsPort == 0 && c.MetricsToken == "":
+		return []error{errors.New("METRICS_TOKEN is required when METRICS_PORT is 0 and /metrics is served on SERVER_PORT")}
+	case c.MetricsPort != 0 && c.MetricsPort == c.ServerPort:
+		return []error{fmt.Errorf("METRICS_PORT must differ from SERVER_PORT %d, use 0 to serve /metrics on it", c.ServerPort)}
+	case c.MetricsPort != 0:
+		return []error{validatePort("METRICS_PORT", c.MetricsPort)}
+	}
+
+	return nil
+}
+
+// validateTracing checks the exporter, the endpoint the OTLP exporter sends to and the sample ratio
+func (c *Configuration) validateTracing() []error {
+	var errs []error
+
+	if !slices.Contains(TracingExporters, c.TracingExporter) {
+		errs = append(errs, fmt.Errorf("TRACING_EXPORTER must be one of %s, got %q", strings.Join(TracingExporters, ", "), c.TracingExporter))
+	}
+
+	if c.TracingExporter == TracingOTLP {
+		if endpoint, err := url.Parse(c.TracingEndpoint); err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+			errs = append(errs, fmt.Errorf("OTEL_EXPORTER_OTLP_ENDPOINT must be a URL such as http://localhost:4318, got %q", c.TracingEndpoint))
+		}
+	}
+
+	if c.TracingSampleRatio < 0 || c.TracingSampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("TRACING_SAMPLE_RATIO must be between 0 and 1, got %g", c.TracingSampleRatio))
+	}
+
+	return errs
+}
+
+// validateLog checks the log outputs, the rotation of the log file and the component levels
+func (c *Configuration) validateLog() []error {
+	var errs []error
+
+	if len(c.LogOutputs) == 0 {
+		errs = append(errs, fmt.Errorf("LOG_OUTPUTS must contain at least one of %s", strings.Join(LogOutputNames, ", ")))
+	}
+
+	for _, output := range c.LogOutputs {
+		if !slices.Contains(LogOutputNames, output) {
+			errs = append(errs, fmt.Errorf("LOG_OUTPUTS must only contain %s, got %q", strings.Join(LogOutputNames, ", "), output))
+		}
+	}
+
+	if slices.Contains(c.LogOutputs, LogFile) {
+		errs = append(errs, c.validateLogFile()...)
+	}
+
+	return append(errs, c.validateLogLevels()...)
+}
+
+// validateLogFile checks the path and the rotation of the log file
+func (c *Configuration) validateLogFile() []error {
+	var errs []error
+
+	if c.LogFile == "" {
+		errs = append(errs, errors.New("LOG_FILE is required when LOG_OUTPUTS contains file"))
+	}
+
+	if c.LogFileMaxSize < 1 {
+		errs = append(errs, fmt.Errorf("LOG_FILE_MAX_SIZE must be at least 1 megabyte, got %d", c.LogFileMaxSize))
+	}
+
+	if c.LogFileMaxBackups < 0 {
+		errs = append(errs, fmt.Errorf("LOG_FILE_MAX_BACKUPS must not be negative, got %d", c.LogFileMaxBackups))
+	}
+
+	return errs
+}
+
+// validateLogLevels checks that every entry of LOG_LEVELS names a known component and level
+func (c *Configuration) validateLogLevels() []error {
+	var errs []error
+
+	for _, setting := range c.LogLevels {
+		component, level, ok := strings.Cut(setting, "=")
+
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Errorf("LOG_LEVELS entries must be component=level, got %q", setting))
+		case !slices.Contains(LogComponents, component):
+			errs = append(errs, fmt.Errorf("LOG_LEVELS components must be one of %s, got %q", strings.Join(LogComponents, ", "), component))
+		case !slices.Contains(LogLevelNames, level):
+			errs = append(errs, fmt.Errorf("LOG_LEVELS levels must be one of %s, got %q", strings.Join(LogLevelNames, ", "), level))
+		}
+	}
+
+	return errs
+}
+
+// ComponentLogLevels returns the levels of LOG_LEVELS keyed by component
+func (c *Configuration) ComponentLogLevels() map[string]string {
+	levels := make(map[string]string, len(c.LogLevels))
+
+	for _, setting := range c.LogLevels {
+		if component, level, ok := strings.Cut(setting, "="); ok {
+			levels[component] = level
+		}
+	}
+
+	return levels
+}
+
+// validateRequired returns an error for every setting, given as name and value, that is empty
+func validateRequired(settings ...[2]string) []error {
+	var errs []error
+
+	for _, setting := range settings {
+		if setting[1] == "" {
+			errs = append(errs, fmt.Errorf("%s is required", setting[0]))
+		}
+	}
+
+	return errs
+}
+
+// validatePort checks that the port is a valid TCP port
+func validatePort(name string, port int) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("%s must be between 1 and 65535, got %d", name, port)
+	}
+
+	return nil
+}
+
+// IsProduction reports whether the application runs in production, where destructive database commands are refused
+func (c *Configuration) IsProduction() bool {
+	return c.AppEnv == "production"
+}
+
+// V1SunsetDate returns the date the /api/v1 routes are removed, the zero time when API_V1_SUNSET is not a date
+func (c *Configuration) V1SunsetDate() time.Time {
+	sunset, _ := time.Parse(time.DateOnly, c.APIV1Sunset)
+
+	return sunset
+}
+
+// GetServerAddress returns the address the HTTP server listens on
+func (c *Configuration) GetServerAddress() string {
+	return fmt.Sprintf(":%d", c.ServerPort)
+}
+
+// GetDatabaseConfig returns the database source name of the selected driver
 func (c *Configuration) GetDatabaseConfig() string {
+	if c.DBDriver == DriverSQLite {
+		// foreign keys are off by default in SQLite, the busy timeout lets concurrent writers wait for the lock
+		pragmas := "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+
+		// every connection of the pool shares the same in-memory database
+		if c.DBPath == SQLiteMemory {
+			return "file::memory:?cache=shared&" + pragmas
+		}
+
+		return fmt.Sprintf("file:%s?%s&_pragma=journal_mode(WAL)", c.DBPath, pragmas)
+	}
+
 	return fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=%s",
+		"host=%s user=%s password=%s dbname=%s port=%d sslmode=%s TimeZone=%s",
 		c.DBHost, c.DBUser, c.DBPassword, c.DBName, c.DBPort, c.DBSSLMode, c.DBTimeZone,
 	)
 }
 
 // GetRedisConfig returns the redis configuration
 func (c *Configuration) GetRedisConfig() *redis.Options {
-	db, _ := strconv.Atoi(c.REDISDB)
-
 	return &redis.Options{
-		Addr:     fmt.Sprintf("%s:%s", c.REDISHost, c.REDISPort),
+		Addr:     fmt.Sprintf("%s:%d", c.REDISHost, c.REDISPort),
 		Password: c.REDISPass,
-		DB:       db,
+		DB:       c.REDISDB,
 	}
 }
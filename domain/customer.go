@@ -19,6 +19,7 @@ type Customer struct {
 	PhoneNumber string    `gorm:"type:varchar(20);unique;not null" json:"phone_number"`
 	DateOfBirth time.Time `gorm:"type:date;not null" json:"date_of_birth" time_format:"2006-01-02"`
 	Address     string    `gorm:"type:text" json:"address"`
+	Version     uint      `gorm:"not null;default:1" json:"version"` // bumped by every update, the ETag of the customer
 }
 
 // BeforeCreate is a GORM hook to generate a UUID for the customer
@@ -0,0 +1,92 @@
+package domain
+
+// ErrorKind is the class of a domain error, the HTTP layer answers every kind with its own status code
+type ErrorKind string
+
+// Kinds of domain errors
+const (
+	ErrorValidation           ErrorKind = "validation"
+	ErrorNotFound             ErrorKind = "not_found"
+	ErrorConflict             ErrorKind = "conflict"
+	ErrorForbidden            ErrorKind = "forbidden"
+	ErrorUnauthorized         ErrorKind = "unauthorized"
+	ErrorInsufficientFunds    ErrorKind = "insufficient_funds"
+	ErrorUnprocessable        ErrorKind = "unprocessable"
+	ErrorUnsupportedMedia     ErrorKind = "unsupported_media_type"
+	ErrorPreconditionFailed   ErrorKind = "precondition_failed"
+	ErrorPreconditionRequired ErrorKind = "precondition_required"
+	ErrorPayloadTooLarge      ErrorKind = "payload_too_large"
+)
+
+// ErrVersionMismatch is returned when a resource is changed or deleted at another version than the one expected, it was
+// changed since the client read it and the write would overwrite that change
+var ErrVersionMismatch = NewError(ErrorPreconditionFailed, "version_mismatch", "resource was modified since it was read")
+
+// FieldError is the problem with a single field of a request
+type FieldError struct {
+	Field   string
+	Code    string
+	Message string
+}
+
+// Error is an error the clients are told about. Code is a stable machine readable code such as insufficient_funds and
+// the message is safe to show, the cause is kept for errors.Is and the logs only.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	Details []FieldError
+
+	cause error
+}
+
+// NewError creates a domain error
+func NewError(kind ErrorKind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// Error returns the message followed by the cause
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
+
+	return e.Message
+}
+
+// Unwrap returns the cause
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is reports whether the target is a domain error with the same code, so errors.Is matches the sentinel errors even
+// when they were copied with a cause, message or details
+func (e *Error) Is(target error) bool {
+	other, ok := target.(*Error)
+
+	return ok && other.Code == e.Code
+}
+
+// WithCause returns a copy of the error caused by err
+func (e *Error) WithCause(err error) *Error {
+	copied := *e
+	copied.cause = err
+
+	return &copied
+}
+
+// WithMessage returns a copy of the error with a more specific message
+func (e *Error) WithMessage(message string) *Error {
+	copied := *e
+	copied.Message = message
+
+	return &copied
+}
+
+// WithDetails returns a copy of the error with the problems of the single fields
+func (e *Error) WithDetails(details ...FieldError) *Error {
+	copied := *e
+	copied.Details = append(append([]FieldError(nil), e.Details...), details...)
+
+	return &copied
+}
@@ -0,0 +1,183 @@
+// Package domain contains the domain events recorded by the application
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/okyws/dashboard-backend/dto"
+)
+
+// Event types recorded in the outbox
+const (
+	EventTransactionPosted = "transaction.posted"
+	EventAccountCreated    = "account.created"
+	EventAccountFrozen     = "account.frozen"
+	EventAccountActivated  = "account.activated"
+	EventBalanceChanged    = "account.balance_changed"
+	EventUserCreated       = "user.created"
+	EventLoginFailed       = "user.login_failed"
+	EventCustomerCreated   = "customer.created"
+	EventCustomerUpdated   = "customer.updated"
+)
+
+// EventTypes lists every event type that can be recorded in the outbox
+var EventTypes = []string{
+	EventTransactionPosted, EventAccountCreated, EventAccountFrozen, EventAccountActivated, EventBalanceChanged,
+	EventUserCreated, EventLoginFailed, EventCustomerCreated, EventCustomerUpdated,
+}
+
+// Event is implemented by every typed domain event
+type Event interface {
+	EventType() string
+	AggregateID() uuid.UUID
+}
+
+// EventEnvelope carries a typed event together with the metadata of its outbox record
+type EventEnvelope struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	Event     Event
+}
+
+// UserCreated is emitted when a new user is registered
+type UserCreated struct {
+	dto.UserDTO
+}
+
+// EventType returns the type of the event
+func (UserCreated) EventType() string { return EventUserCreated }
+
+// AggregateID returns the ID of the created user
+func (e UserCreated) AggregateID() uuid.UUID { return e.ID }
+
+// LoginFailed is emitted when a login attempt uses a wrong password for an existing user
+type LoginFailed struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	IPAddress string    `json:"ip_address"`
+}
+
+// EventType returns the type of the event
+func (LoginFailed) EventType() string { return EventLoginFailed }
+
+// AggregateID returns the ID of the user
+func (e LoginFailed) AggregateID() uuid.UUID { return e.UserID }
+
+// CustomerCreated is emitted when a customer profile is created
+type CustomerCreated struct {
+	dto.CustomerDTO
+}
+
+// EventType returns the type of the event
+func (CustomerCreated) EventType() string { return EventCustomerCreated }
+
+// AggregateID returns the ID of the created customer
+func (e CustomerCreated) AggregateID() uuid.UUID { return e.ID }
+
+// CustomerUpdated is emitted when a customer profile is changed
+type CustomerUpdated struct {
+	dto.CustomerDTO
+}
+
+// EventType returns the type of the event
+func (CustomerUpdated) EventType() string { return EventCustomerUpdated }
+
+// AggregateID returns the ID of the updated customer
+func (e CustomerUpdated) AggregateID() uuid.UUID { return e.ID }
+
+// AccountCreated is emitted when a bank account is opened
+type AccountCreated struct {
+	dto.BankAccountDTO
+}
+
+// EventType returns the type of the event
+func (AccountCreated) EventType() string { return EventAccountCreated }
+
+// AggregateID returns the ID of the created bank account
+func (e AccountCreated) AggregateID() uuid.UUID { return e.ID }
+
+// AccountFrozen is emitted when a bank account is deactivated
+type AccountFrozen struct {
+	dto.BankAccountDTO
+}
+
+// EventType returns the type of the event
+func (AccountFrozen) EventType() string { return EventAccountFrozen }
+
+// AggregateID returns the ID of the frozen bank account
+func (e AccountFrozen) AggregateID() uuid.UUID { return e.ID }
+
+// AccountActivated is emitted when a frozen bank account is activated again
+type AccountActivated struct {
+	dto.BankAccountDTO
+}
+
+// EventType returns the type of the event
+func (AccountActivated) EventType() string { return EventAccountActivated }
+
+// AggregateID returns the ID of the activated bank account
+func (e AccountActivated) AggregateID() uuid.UUID { return e.ID }
+
+// BalanceChanged is emitted when the balance of a bank account is updated
+type BalanceChanged struct {
+	dto.BankAccountDTO
+	PreviousBalance float64 `json:"previous_balance"`
+}
+
+// EventType returns the type of the event
+func (BalanceChanged) EventType() string { return EventBalanceChanged }
+
+// AggregateID returns the ID of the bank account
+func (e BalanceChanged) AggregateID() uuid.UUID { return e.ID }
+
+// TransactionPosted is emitted when a deposit, withdraw or transfer is recorded
+type TransactionPosted struct {
+	dto.TransactionDTO
+}
+
+// EventType returns the type of the event
+func (TransactionPosted) EventType() string { return EventTransactionPosted }
+
+// AggregateID returns the ID of the posted transaction
+func (e TransactionPosted) AggregateID() uuid.UUID { return e.ID }
+
+// eventDecoders maps every event type to the function restoring its typed payload
+var eventDecoders = map[string]func(payload []byte) (Event, error){
+	EventUserCreated:       decodeEvent[UserCreated],
+	EventLoginFailed:       decodeEvent[LoginFailed],
+	EventCustomerCreated:   decodeEvent[CustomerCreated],
+	EventCustomerUpdated:   decodeEvent[CustomerUpdated],
+	EventAccountCreated:    decodeEvent[AccountCreated],
+	EventAccountFrozen:     decodeEvent[AccountFrozen],
+	EventAccountActivated:  decodeEvent[AccountActivated],
+	EventBalanceChanged:    decodeEvent[BalanceChanged],
+	EventTransactionPosted: decodeEvent[TransactionPosted],
+}
+
+// decodeEvent unmarshals a payload into the typed event T
+func decodeEvent[T Event](payload []byte) (Event, error) {
+	var event T
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// DecodeEvent restores the typed event stored in an outbox record
+func DecodeEvent(outbox *OutboxEvent) (*EventEnvelope, error) {
+	decoder, ok := eventDecoders[outbox.EventType]
+	if !ok {
+		return nil, fmt.Errorf("unknown event type %q", outbox.EventType)
+	}
+
+	event, err := decoder([]byte(outbox.Payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s event: %v", outbox.EventType, err)
+	}
+
+	return &EventEnvelope{ID: outbox.ID, CreatedAt: outbox.CreatedAt, Event: event}, nil
+}
@@ -0,0 +1,107 @@
+// Package domain contains the bulk import job models
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/okyws/dashboard-backend/dto"
+	"gorm.io/gorm"
+)
+
+// Import job statuses
+const (
+	ImportStatusPending             = "pending"
+	ImportStatusRunning             = "running"
+	ImportStatusCompleted           = "completed"
+	ImportStatusCompletedWithErrors = "completed_with_errors"
+	ImportStatusFailed              = "failed"
+)
+
+// Import modes, an all or nothing import writes no row unless every row can be written
+const (
+	ImportModeBestEffort   = "best_effort"
+	ImportModeAllOrNothing = "all_or_nothing"
+)
+
+// Import file formats
+const (
+	ImportFormatCSV       = "csv"
+	ImportFormatJSONLines = "jsonl"
+)
+
+// Types of the rows of an import
+const (
+	ImportRowUser     = "user"
+	ImportRowCustomer = "customer"
+	ImportRowAccount  = "account"
+)
+
+// ImportJob struct represents a bulk import of users, customers and accounts submitted by an admin
+type ImportJob struct {
+	gorm.Model
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	Format        string     `gorm:"type:varchar(10);not null" json:"format"`
+	Mode          string     `gorm:"type:varchar(20);not null" json:"mode"`
+	Status        string     `gorm:"type:varchar(30);not null;index" json:"status"`
+	Language      string     `gorm:"type:varchar(10);not null" json:"language"` // language of the error report
+	Payload       string     `gorm:"type:text;not null" json:"-"`               // the uploaded file, emptied once the job finished
+	TotalRows     int        `gorm:"not null;default:0" json:"total_rows"`
+	ProcessedRows int        `gorm:"not null;default:0" json:"processed_rows"`
+	ImportedRows  int        `gorm:"not null;default:0" json:"imported_rows"`
+	FailedRows    int        `gorm:"not null;default:0" json:"failed_rows"`
+	Error         string     `gorm:"type:text" json:"error"` // why the job stopped before every row was processed
+	StartedAt     *time.Time `json:"started_at"`
+	FinishedAt    *time.Time `json:"finished_at"`
+}
+
+// BeforeCreate is a GORM hook to generate a UUID for the import job
+func (j *ImportJob) BeforeCreate(_ *gorm.DB) error {
+	j.ID = uuid.New()
+
+	if j.Status == "" {
+		j.Status = ImportStatusPending
+	}
+
+	return nil
+}
+
+// Finished reports whether the job will not change anymore
+func (j *ImportJob) Finished() bool {
+	return j.Status != ImportStatusPending && j.Status != ImportStatusRunning
+}
+
+// ImportRowError struct represents a problem with a row of an import, a row may have one per field
+type ImportRowError struct {
+	ID      uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	JobID   uuid.UUID `gorm:"type:uuid;not null;index" json:"job_id"`
+	Line    int       `gorm:"not null" json:"line"` // line of the file the row starts on
+	Field   string    `gorm:"type:varchar(50)" json:"field"`
+	Code    string    `gorm:"type:varchar(50);not null" json:"code"`
+	Message string    `gorm:"type:text;not null" json:"message"`
+}
+
+// BeforeCreate is a GORM hook to generate a UUID for the import row error
+func (e *ImportRowError) BeforeCreate(_ *gorm.DB) error {
+	e.ID = uuid.New()
+
+	return nil
+}
+
+// MapImportJobToDTO maps an import job to an ImportJobDTO
+func MapImportJobToDTO(job *ImportJob) *dto.ImportJobDTO {
+	return &dto.ImportJobDTO{
+		ID:            job.ID,
+		Format:        job.Format,
+		Mode:          job.Mode,
+		Status:        job.Status,
+		TotalRows:     job.TotalRows,
+		ProcessedRows: job.ProcessedRows,
+		ImportedRows:  job.ImportedRows,
+		FailedRows:    job.FailedRows,
+		Error:         job.Error,
+		CreatedAt:     job.CreatedAt,
+		StartedAt:     job.StartedAt,
+		FinishedAt:    job.FinishedAt,
+	}
+}
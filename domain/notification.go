@@ -0,0 +1,126 @@
+// Package domain contains the notification and notification preference models
+package domain
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/okyws/dashboard-backend/dto"
+	"gorm.io/gorm"
+)
+
+// Notification categories
+const (
+	NotificationDeposit          = "deposit"
+	NotificationIncomingTransfer = "incoming_transfer"
+	NotificationLargeWithdrawal  = "large_withdrawal"
+	NotificationLoginFailed      = "login_failed"
+	NotificationAccountStatus    = "account_status"
+)
+
+// NotificationCategories lists every notification category a user can configure
+var NotificationCategories = []string{
+	NotificationDeposit, NotificationIncomingTransfer, NotificationLargeWithdrawal, NotificationLoginFailed, NotificationAccountStatus,
+}
+
+// Notification channels
+const (
+	ChannelInApp = "in_app"
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+)
+
+// Notification struct represents a message shown in the notification center of a user
+type Notification struct {
+	gorm.Model
+	ID       uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	UserID   uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_notification_event" json:"user_id"`
+	EventID  uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_notification_event" json:"event_id"`
+	Category string     `gorm:"type:varchar(50);not null" json:"category"`
+	Title    string     `gorm:"type:varchar(255);not null" json:"title"`
+	Message  string     `gorm:"type:text;not null" json:"message"`
+	ReadAt   *time.Time `json:"read_at"`
+}
+
+// BeforeCreate is a GORM hook to generate a UUID for the notification
+func (n *Notification) BeforeCreate(_ *gorm.DB) error {
+	n.ID = uuid.New()
+	return nil
+}
+
+// NotificationPreference struct represents the channels a user receives a notification category on
+type NotificationPreference struct {
+	gorm.Model
+	ID       uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	UserID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_notification_preference" json:"user_id"`
+	Category string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_notification_preference" json:"category"`
+	InApp    bool      `gorm:"type:bool;not null" json:"in_app"`
+	Email    bool      `gorm:"type:bool;not null" json:"email"`
+	SMS      bool      `gorm:"type:bool;not null" json:"sms"`
+}
+
+// BeforeCreate is a GORM hook to generate a UUID for the notification preference
+func (p *NotificationPreference) BeforeCreate(_ *gorm.DB) error {
+	p.ID = uuid.New()
+	return nil
+}
+
+// DefaultNotificationPreference returns the preference used until the user changes it, in-app only
+func DefaultNotificationPreference(userID uuid.UUID, category string) NotificationPreference {
+	return NotificationPreference{UserID: userID, Category: category, InApp: true}
+}
+
+// Channels returns the channels enabled by the preference
+func (p *NotificationPreference) Channels() []string {
+	channels := make([]string, 0, 3)
+
+	if p.InApp {
+		channels = append(channels, ChannelInApp)
+	}
+
+	if p.Email {
+		channels = append(channels, ChannelEmail)
+	}
+
+	if p.SMS {
+		channels = append(channels, ChannelSMS)
+	}
+
+	return channels
+}
+
+// IsNotificationCategory reports whether the category is known
+func IsNotificationCategory(category string) bool {
+	return slices.Contains(NotificationCategories, category)
+}
+
+// NotificationRecipient holds the contact details a notification is sent to
+type NotificationRecipient struct {
+	UserID      uuid.UUID
+	Email       string
+	PhoneNumber string
+}
+
+// MapNotificationToDTO maps a notification to a NotificationDTO
+func MapNotificationToDTO(notification *Notification) *dto.NotificationDTO {
+	return &dto.NotificationDTO{
+		ID:        notification.ID,
+		Category:  notification.Category,
+		Title:     notification.Title,
+		Message:   notification.Message,
+		Read:      notification.ReadAt != nil,
+		ReadAt:    notification.ReadAt,
+		CreatedAt: notification.CreatedAt,
+	}
+}
+
+// MapNotificationPreferenceToDTO maps a notification preference to a NotificationPreferenceDTO
+func MapNotificationPreferenceToDTO(preference *NotificationPreference) *dto.NotificationPreferenceDTO {
+	return &dto.NotificationPreferenceDTO{
+		Category: preference.Category,
+		InApp:    preference.InApp,
+		Email:    preference.Email,
+		SMS:      preference.SMS,
+	}
+}
@@ -0,0 +1,59 @@
+// Package domain contains the outbox event model
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxEvent struct represents an event persisted in the same database transaction as the change that caused it
+type OutboxEvent struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	EventType    string     `gorm:"type:varchar(100);not null;index" json:"event_type"`
+	AggregateID  uuid.UUID  `gorm:"type:uuid;not null" json:"aggregate_id"`
+	Payload      string     `gorm:"type:text;not null" json:"payload"`
+	CreatedAt    time.Time  `gorm:"not null;index" json:"created_at"`
+	DispatchedAt *time.Time `gorm:"index" json:"dispatched_at"`
+
+	// the relay that claimed the event until the claim expires, and the subscribers and sinks it was delivered to
+	ClaimedBy    *uuid.UUID       `gorm:"type:uuid;index" json:"-"`
+	ClaimedUntil *time.Time       `json:"-"`
+	Deliveries   []OutboxDelivery `gorm:"foreignKey:EventID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// OutboxDelivery struct records that an outbox event was delivered to a subscriber of the bus or a sink, which is not
+// given the event again when the relay retries it for another one
+type OutboxDelivery struct {
+	EventID     uuid.UUID `gorm:"type:uuid;primary_key" json:"event_id"`
+	Target      string    `gorm:"type:varchar(100);primary_key" json:"target"`
+	DeliveredAt time.Time `gorm:"not null" json:"delivered_at"`
+}
+
+// Delivered reports whether the event was already delivered to the target
+func (e *OutboxEvent) Delivered(target string) bool {
+	for _, delivery := range e.Deliveries {
+		if delivery.Target == target {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewOutboxEvent builds an outbox record with the typed event encoded as JSON
+func NewOutboxEvent(event Event) (*OutboxEvent, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OutboxEvent{
+		ID:          uuid.New(),
+		EventType:   event.EventType(),
+		AggregateID: event.AggregateID(),
+		Payload:     string(data),
+		CreatedAt:   time.Now(),
+	}, nil
+}
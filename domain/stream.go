@@ -0,0 +1,24 @@
+// Package domain contains the real-time stream message model
+package domain
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StreamMessage is a domain event pushed to the connected dashboard clients
+type StreamMessage struct {
+	ID        uuid.UUID       `json:"id"`
+	Type      string          `json:"type"`
+	UserIDs   []uuid.UUID     `json:"user_ids"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// VisibleTo reports whether the message concerns one of the user's accounts
+func (m *StreamMessage) VisibleTo(userID uuid.UUID) bool {
+	return slices.Contains(m.UserIDs, userID)
+}
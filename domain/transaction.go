@@ -7,6 +7,12 @@ import (
 	"gorm.io/gorm"
 )
 
+// Transaction statuses, failed transactions are not stored but counted in the metrics
+const (
+	TransactionStatusSuccess = "success"
+	TransactionStatusFailed  = "failed"
+)
+
 // Transaction struct represents the transaction model
 type Transaction struct {
 	gorm.Model
@@ -21,7 +27,7 @@ type Transaction struct {
 // BeforeCreate is a GORM hook to generate a UUID for the transaction
 func (t *Transaction) BeforeCreate(_ *gorm.DB) error {
 	t.ID = uuid.New()
-	t.Status = "success"
+	t.Status = TransactionStatusSuccess
 
 	return nil
 }
@@ -35,5 +41,6 @@ func MapTransactionToDTO(transaction *Transaction) *dto.TransactionDTO {
 		Amount:            transaction.Amount,
 		TransactionType:   transaction.TransactionType,
 		Status:            transaction.Status,
+		CreatedAt:         transaction.CreatedAt,
 	}
 }
@@ -0,0 +1,101 @@
+// Package domain contains the batch transfer models
+package domain
+
+import (
+	"github.com/google/uuid"
+	"github.com/okyws/dashboard-backend/dto"
+	"gorm.io/gorm"
+)
+
+// Batch transfer statuses, a batch is stored once it was run
+const (
+	TransferBatchStatusCompleted           = "completed"
+	TransferBatchStatusCompletedWithErrors = "completed_with_errors"
+	TransferBatchStatusFailed              = "failed"
+)
+
+// Batch transfer modes, an all or nothing batch transfers nothing unless every transfer can be made
+const (
+	TransferBatchModeAllOrNothing = "all_or_nothing"
+	TransferBatchModeBestEffort   = "best_effort"
+)
+
+// Statuses of the transfers of a batch, a cancelled transfer was not made because another one of an all or nothing
+// batch failed
+const (
+	TransferItemStatusSucceeded = "succeeded"
+	TransferItemStatusFailed    = "failed"
+	TransferItemStatusCancelled = "cancelled"
+)
+
+// TransferBatch struct represents many transfers from one account submitted at once, such as a payroll
+type TransferBatch struct {
+	gorm.Model
+	ID                uuid.UUID           `gorm:"type:uuid;primary_key" json:"id"`
+	UserID            uuid.UUID           `gorm:"type:uuid;not null;index" json:"user_id"` // the user who submitted the batch
+	FromAccountNumber string              `gorm:"type:varchar(20);not null" json:"from_account_number"`
+	Mode              string              `gorm:"type:varchar(20);not null" json:"mode"`
+	Status            string              `gorm:"type:varchar(30);not null" json:"status"`
+	TotalAmount       float64             `gorm:"type:decimal(10,2);not null" json:"total_amount"`
+	TransferredAmount float64             `gorm:"type:decimal(10,2);not null;default:0" json:"transferred_amount"`
+	SucceededItems    int                 `gorm:"not null;default:0" json:"succeeded_items"`
+	FailedItems       int                 `gorm:"not null;default:0" json:"failed_items"`
+	Items             []TransferBatchItem `gorm:"foreignKey:BatchID;constraint:OnDelete:CASCADE" json:"items"`
+}
+
+// BeforeCreate is a GORM hook to generate a UUID for the batch transfer
+func (b *TransferBatch) BeforeCreate(_ *gorm.DB) error {
+	b.ID = uuid.New()
+
+	return nil
+}
+
+// TransferBatchItem struct represents a transfer of a batch with its outcome
+type TransferBatchItem struct {
+	ID              uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	BatchID         uuid.UUID `gorm:"type:uuid;not null;index" json:"batch_id"`
+	Position        int       `gorm:"not null" json:"position"` // index of the transfer in the request
+	ToAccountNumber string    `gorm:"type:varchar(20);not null" json:"to_account_number"`
+	Amount          float64   `gorm:"type:decimal(10,2);not null" json:"amount"`
+	Reference       string    `gorm:"type:varchar(140)" json:"reference"`
+	Status          string    `gorm:"type:varchar(20);not null" json:"status"`
+	ErrorCode       string    `gorm:"type:varchar(50)" json:"error_code"`
+	Error           string    `gorm:"type:text" json:"error"` // why the transfer was not made, translated when it is answered
+}
+
+// BeforeCreate is a GORM hook to generate a UUID for the transfer of a batch
+func (i *TransferBatchItem) BeforeCreate(_ *gorm.DB) error {
+	i.ID = uuid.New()
+
+	return nil
+}
+
+// MapTransferBatchToDTO maps a batch transfer and its transfers to a TransferBatchDTO
+func MapTransferBatchToDTO(batch *TransferBatch) *dto.TransferBatchDTO {
+	items := make([]dto.TransferBatchItemDTO, len(batch.Items))
+	for i, item := range batch.Items {
+		items[i] = dto.TransferBatchItemDTO{
+			Position:        item.Position,
+			ToAccountNumber: item.ToAccountNumber,
+			Amount:          item.Amount,
+			Reference:       item.Reference,
+			Status:          item.Status,
+			ErrorCode:       item.ErrorCode,
+			Error:           item.Error,
+		}
+	}
+
+	return &dto.TransferBatchDTO{
+		ID:                batch.ID,
+		FromAccountNumber: batch.FromAccountNumber,
+		Mode:              batch.Mode,
+		Status:            batch.Status,
+		TotalAmount:       batch.TotalAmount,
+		TransferredAmount: batch.TransferredAmount,
+		TotalItems:        len(batch.Items),
+		SucceededItems:    batch.SucceededItems,
+		FailedItems:       batch.FailedItems,
+		Items:             items,
+		CreatedAt:         batch.CreatedAt,
+	}
+}
@@ -16,6 +16,7 @@ type User struct {
 	Username string    `gorm:"type:varchar(50);unique;not null" json:"username"`
 	Password string    `gorm:"type:varchar(255);not null" json:"password,omitempty"`
 	Role     string    `gorm:"type:varchar(20);not null" json:"role"`
+	Version  uint      `gorm:"not null;default:1" json:"version"` // bumped by every update, the ETag of the user
 }
 
 // BeforeCreate is a GORM hook to generate a UUID for the user
@@ -0,0 +1,103 @@
+// Package domain contains the webhook endpoint and delivery models
+package domain
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/okyws/dashboard-backend/dto"
+	"gorm.io/gorm"
+)
+
+// Webhook delivery statuses
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusDead      = "dead"
+)
+
+// WebhookEndpoint struct represents an endpoint registered by an admin to receive events
+type WebhookEndpoint struct {
+	gorm.Model
+	ID         uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	URL        string    `gorm:"type:varchar(2048);not null" json:"url"`
+	Secret     string    `gorm:"type:varchar(255);not null" json:"-"`
+	EventTypes string    `gorm:"type:text;not null" json:"event_types"` // comma separated list of event types
+	Active     bool      `gorm:"type:bool;not null;default:true" json:"active"`
+}
+
+// BeforeCreate is a GORM hook to generate a UUID for the webhook endpoint
+func (w *WebhookEndpoint) BeforeCreate(_ *gorm.DB) error {
+	w.ID = uuid.New()
+	w.Active = true
+
+	return nil
+}
+
+// Subscribes reports whether the endpoint wants to receive the given event type
+func (w *WebhookEndpoint) Subscribes(eventType string) bool {
+	for _, subscribed := range strings.Split(w.EventTypes, ",") {
+		if strings.TrimSpace(subscribed) == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WebhookDelivery struct represents a single attempt history of sending an event to an endpoint
+type WebhookDelivery struct {
+	gorm.Model
+	ID            uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	EndpointID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_webhook_delivery_event" json:"endpoint_id"`
+	EventID       uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_webhook_delivery_event" json:"event_id"`
+	EventType     string    `gorm:"type:varchar(100);not null" json:"event_type"`
+	Payload       string    `gorm:"type:text;not null" json:"payload"`
+	Status        string    `gorm:"type:varchar(20);not null;index" json:"status"`
+	Attempts      int       `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time `gorm:"not null;index" json:"next_attempt_at"`
+	LastError     string    `gorm:"type:text" json:"last_error"`
+	ResponseCode  int       `gorm:"not null;default:0" json:"response_code"`
+
+	// the dispatcher that claimed the delivery until the claim expires
+	ClaimedBy    *uuid.UUID `gorm:"type:uuid;index" json:"-"`
+	ClaimedUntil *time.Time `json:"-"`
+}
+
+// BeforeCreate is a GORM hook to generate a UUID for the webhook delivery
+func (d *WebhookDelivery) BeforeCreate(_ *gorm.DB) error {
+	d.ID = uuid.New()
+
+	if d.Status == "" {
+		d.Status = DeliveryStatusPending
+	}
+
+	return nil
+}
+
+// MapWebhookEndpointToDTO maps a webhook endpoint to a WebhookEndpointDTO
+func MapWebhookEndpointToDTO(endpoint *WebhookEndpoint) *dto.WebhookEndpointDTO {
+	return &dto.WebhookEndpointDTO{
+		ID:         endpoint.ID,
+		URL:        endpoint.URL,
+		EventTypes: strings.Split(endpoint.EventTypes, ","),
+		Active:     endpoint.Active,
+		CreatedAt:  endpoint.CreatedAt,
+	}
+}
+
+// MapWebhookDeliveryToDTO maps a webhook delivery to a WebhookDeliveryDTO
+func MapWebhookDeliveryToDTO(delivery *WebhookDelivery) *dto.WebhookDeliveryDTO {
+	return &dto.WebhookDeliveryDTO{
+		ID:            delivery.ID,
+		EndpointID:    delivery.EndpointID,
+		EventID:       delivery.EventID,
+		EventType:     delivery.EventType,
+		Status:        delivery.Status,
+		Attempts:      delivery.Attempts,
+		NextAttemptAt: delivery.NextAttemptAt,
+		LastError:     delivery.LastError,
+		ResponseCode:  delivery.ResponseCode,
+	}
+}
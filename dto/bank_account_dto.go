@@ -29,5 +29,5 @@ type BankAccountUpdateDTO struct {
 
 // BankAccountUpdateStatusDTO represents the bank information data transfer object for the API
 type BankAccountUpdateStatusDTO struct {
-	AccountStatus bool `json:"account_status" binding:"required,oneof=true false"`
+	AccountStatus *bool `json:"account_status" binding:"required"`
 }
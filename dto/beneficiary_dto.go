@@ -0,0 +1,29 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BeneficiaryDTO represents the beneficiary data transfer object for the API
+type BeneficiaryDTO struct {
+	ID            uuid.UUID `json:"id"`
+	Nickname      string    `json:"nickname"`
+	AccountNumber string    `json:"account_number"`
+	HolderName    string    `json:"holder_name"`
+	ActiveFrom    time.Time `json:"active_from"`
+	CoolingOff    bool      `json:"cooling_off"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// BeneficiaryCreateDTO represents the beneficiary data transfer object for the API
+type BeneficiaryCreateDTO struct {
+	Nickname      string `json:"nickname" binding:"required,max=50"`
+	AccountNumber string `json:"account_number" binding:"required,numeric,len=10,account_number"`
+}
+
+// BeneficiaryUpdateDTO represents the beneficiary data transfer object for the API
+type BeneficiaryUpdateDTO struct {
+	Nickname string `json:"nickname" binding:"required,max=50"`
+}
@@ -34,3 +34,12 @@ type CustomerUpdateDTO struct {
 	DateOfBirth string `json:"date_of_birth" binding:"required" time_format:"2006-01-02"`
 	Address     string `json:"address" binding:"omitempty,max=255"`
 }
+
+// CustomerPatchDTO represents the customer document a JSON Merge Patch of the API is applied to, only the patched
+// members are validated and written. The address is optional, null clears it.
+type CustomerPatchDTO struct {
+	FullName    string `json:"full_name" binding:"required,min=3,max=100"`
+	PhoneNumber string `json:"phone_number" binding:"required,e164"`
+	DateOfBirth string `json:"date_of_birth" binding:"required,datetime=2006-01-02"`
+	Address     string `json:"address" binding:"omitempty,max=255"`
+}
@@ -0,0 +1,35 @@
+package dto
+
+// Health statuses of the application and its dependencies
+const (
+	HealthStatusOK       = "ok"
+	HealthStatusFail     = "fail"
+	HealthStatusDraining = "draining"
+)
+
+// HealthResponseDTO is the response of the liveness probe
+type HealthResponseDTO struct {
+	Status string `json:"status"`
+}
+
+// ReadinessResponseDTO is the response of the readiness probe with the status of every dependency
+type ReadinessResponseDTO struct {
+	Status string                    `json:"status"`
+	Checks map[string]HealthCheckDTO `json:"checks"`
+}
+
+// HealthCheckDTO is the result of checking one dependency
+type HealthCheckDTO struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// VersionResponseDTO describes the running build
+type VersionResponseDTO struct {
+	AppName    string `json:"app_name"`
+	AppVersion string `json:"app_version"`
+	GitCommit  string `json:"git_commit"`
+	BuildTime  string `json:"build_time"`
+	GoVersion  string `json:"go_version"`
+}
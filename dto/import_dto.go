@@ -0,0 +1,39 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImportJobDTO represents the bulk import job data transfer object for the API
+type ImportJobDTO struct {
+	ID            uuid.UUID  `json:"id"`
+	Format        string     `json:"format"`
+	Mode          string     `json:"mode"`
+	Status        string     `json:"status"`
+	TotalRows     int        `json:"total_rows"`
+	ProcessedRows int        `json:"processed_rows"`
+	ImportedRows  int        `json:"imported_rows"`
+	FailedRows    int        `json:"failed_rows"`
+	Error         string     `json:"error,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	StartedAt     *time.Time `json:"started_at,omitempty"`
+	FinishedAt    *time.Time `json:"finished_at,omitempty"`
+}
+
+// ImportRowDTO represents a row of an import file, a CSV header or a JSON Lines object uses the same names. The type
+// says which create request the row holds, customers and accounts name the user they belong to by the username.
+type ImportRowDTO struct {
+	Type        string  `json:"type"`
+	Email       string  `json:"email"`
+	Username    string  `json:"username"`
+	Password    string  `json:"password"`
+	Role        string  `json:"role"`
+	FullName    string  `json:"full_name"`
+	PhoneNumber string  `json:"phone_number"`
+	DateOfBirth string  `json:"date_of_birth"`
+	Address     string  `json:"address"`
+	AccountType string  `json:"account_type"`
+	Balance     float64 `json:"balance"`
+}
@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationDTO represents the notification data transfer object for the API
+type NotificationDTO struct {
+	ID        uuid.UUID  `json:"id"`
+	Category  string     `json:"category"`
+	Title     string     `json:"title"`
+	Message   string     `json:"message"`
+	Read      bool       `json:"read"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// NotificationPreferenceDTO represents the notification preference data transfer object for the API
+type NotificationPreferenceDTO struct {
+	Category string `json:"category" binding:"required,oneof=deposit incoming_transfer large_withdrawal login_failed account_status"`
+	InApp    bool   `json:"in_app"`
+	Email    bool   `json:"email"`
+	SMS      bool   `json:"sms"`
+}
+
+// NotificationPreferenceUpdateDTO represents the notification preferences update data transfer object for the API
+type NotificationPreferenceUpdateDTO struct {
+	Preferences []NotificationPreferenceDTO `json:"preferences" binding:"required,min=1,dive"`
+}
+
+// NotificationReadAllDTO represents the response of marking every notification as read
+type NotificationReadAllDTO struct {
+	Updated int64 `json:"updated"`
+}
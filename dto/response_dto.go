@@ -9,9 +9,19 @@ type SuccessResponseDTO[T any] struct {
 	Data    T      `json:"data"`
 }
 
-// ErrorResponseDTO is the response structure for failed requests
+// ErrorResponseDTO is the response structure for failed requests, ErrorCode is the machine readable code of the error
+// and Details lists the problems of the single fields of the request
 type ErrorResponseDTO struct {
-	Status  string `json:"status"`
-	Code    int    `json:"code"`
+	Status    string          `json:"status"`
+	Code      int             `json:"code"`
+	ErrorCode string          `json:"error_code"`
+	Message   string          `json:"message"`
+	Details   []FieldErrorDTO `json:"details,omitempty"`
+}
+
+// FieldErrorDTO is the problem with a single field of the request
+type FieldErrorDTO struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
 	Message string `json:"message"`
 }
@@ -1,6 +1,10 @@
 package dto
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 // TransactionDTO represents the transaction data transfer object for the API
 type TransactionDTO struct {
@@ -10,12 +14,19 @@ type TransactionDTO struct {
 	Amount            float64   `json:"amount"`
 	TransactionType   string    `json:"transaction_type"`
 	Status            string    `json:"status"`
+	CreatedAt         time.Time `json:"created_at"`
+
+	// formatted for the statements in the language of the request
+	FormattedAmount      string `json:"formatted_amount,omitempty"`
+	FormattedDate        string `json:"formatted_date,omitempty"`
+	TransactionTypeLabel string `json:"transaction_type_label,omitempty"`
 }
 
 // TransactionCreateDTO represents the transaction data transfer object for the API
 type TransactionCreateDTO struct {
-	FromAccountNumber string  `json:"from_account_number,omitempty" binding:"required_if=TransactionType transfer,required_if=TransactionType withdraw"`
-	ToAccountNumber   string  `json:"to_account_number,omitempty" binding:"required_if=TransactionType transfer,required_if=TransactionType deposit"`
+	FromAccountNumber string  `json:"from_account_number,omitempty" binding:"required_if=TransactionType transfer,required_if=TransactionType withdraw,account_number"`
+	ToAccountNumber   string  `json:"to_account_number,omitempty" binding:"required_if=TransactionType deposit,excluded_with=BeneficiaryID,account_number"` // transfers need it or a beneficiary ID
+	BeneficiaryID     string  `json:"beneficiary_id,omitempty" binding:"omitempty,uuid,excluded_unless=TransactionType transfer"`
 	Amount            float64 `json:"amount" binding:"required,min=10000"`
 	TransactionType   string  `json:"transaction_type" binding:"required,oneof=deposit withdraw transfer"`
 }
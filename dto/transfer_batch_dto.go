@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TransferBatchCreateDTO represents a batch of transfers from one account, such as a payroll. An all_or_nothing batch,
+// the default, makes no transfer unless every one of them can be made, a best_effort batch makes every one it can.
+type TransferBatchCreateDTO struct {
+	FromAccountNumber string                       `json:"from_account_number" binding:"required,account_number"`
+	Mode              string                       `json:"mode,omitempty" binding:"omitempty,oneof=all_or_nothing best_effort"`
+	Items             []TransferBatchItemCreateDTO `json:"items" binding:"required,min=1,max=1000,dive"`
+}
+
+// TransferBatchItemCreateDTO represents a transfer of a batch
+type TransferBatchItemCreateDTO struct {
+	ToAccountNumber string  `json:"to_account_number" binding:"required,account_number"`
+	Amount          float64 `json:"amount" binding:"required,min=10000"`
+	Reference       string  `json:"reference,omitempty" binding:"max=140"` // such as the employee the salary is paid to
+}
+
+// TransferBatchDTO represents the batch transfer data transfer object for the API, failed_items counts the transfers
+// that were not made
+type TransferBatchDTO struct {
+	ID                uuid.UUID              `json:"id"`
+	FromAccountNumber string                 `json:"from_account_number"`
+	Mode              string                 `json:"mode"`
+	Status            string                 `json:"status"`
+	TotalAmount       float64                `json:"total_amount"`
+	TransferredAmount float64                `json:"transferred_amount"`
+	TotalItems        int                    `json:"total_items"`
+	SucceededItems    int                    `json:"succeeded_items"`
+	FailedItems       int                    `json:"failed_items"`
+	Items             []TransferBatchItemDTO `json:"items"`
+	CreatedAt         time.Time              `json:"created_at"`
+}
+
+// TransferBatchItemDTO represents a transfer of a batch with its outcome
+type TransferBatchItemDTO struct {
+	Position        int     `json:"position"`
+	ToAccountNumber string  `json:"to_account_number"`
+	Amount          float64 `json:"amount"`
+	Reference       string  `json:"reference,omitempty"`
+	Status          string  `json:"status"`
+	ErrorCode       string  `json:"error_code,omitempty"`
+	Error           string  `json:"error,omitempty"`
+}
@@ -27,6 +27,15 @@ type UserUpdateDTO struct {
 	Role     string `json:"role,omitempty" binding:"omitempty,oneof=admin user customer"`
 }
 
+// UserPatchDTO represents the user document a JSON Merge Patch of the API is applied to, only the patched members are
+// validated and written. The password is write-only and never part of the current document.
+type UserPatchDTO struct {
+	Email    string `json:"email" binding:"required,email"`
+	Username string `json:"username" binding:"required,min=3,max=50"`
+	Password string `json:"password,omitempty" binding:"required,min=6"`
+	Role     string `json:"role" binding:"required,oneof=admin user customer"`
+}
+
 // UserLoginDTO represents the user data transfer object for the API
 type UserLoginDTO struct {
 	Username string `json:"username" binding:"required"`
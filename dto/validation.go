@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// RegisterAccountNumberValidation registers the account_number binding tag, checking non-empty account numbers with
+// valid so typos are rejected before the request is handled
+func RegisterAccountNumberValidation(valid func(accountNumber string) bool) error {
+	engine, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return nil
+	}
+
+	return engine.RegisterValidation("account_number", func(fl validator.FieldLevel) bool {
+		accountNumber := fl.Field().String()
+
+		return accountNumber == "" || valid(accountNumber)
+	})
+}
+
+// RegisterJSONFieldNames names the fields of validation errors after their JSON keys, so the details of a failed
+// validation name the fields the client sent
+func RegisterJSONFieldNames() {
+	engine, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	engine.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+
+		if name == "" {
+			name, _, _ = strings.Cut(field.Tag.Get("form"), ",")
+		}
+
+		if name == "" {
+			return field.Name
+		}
+
+		return name
+	})
+}
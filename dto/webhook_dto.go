@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookEndpointDTO represents the webhook endpoint data transfer object for the API
+type WebhookEndpointDTO struct {
+	ID         uuid.UUID `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+	Secret     string    `json:"secret,omitempty"`
+}
+
+// WebhookEndpointCreateDTO represents the webhook endpoint data transfer object for the API
+type WebhookEndpointCreateDTO struct {
+	URL        string   `json:"url" binding:"required,url,max=2048"`
+	EventTypes []string `json:"event_types" binding:"required,min=1,dive,oneof=transaction.posted account.created account.frozen account.activated account.balance_changed user.created user.login_failed customer.created customer.updated"`
+	Secret     string   `json:"secret,omitempty" binding:"omitempty,min=16,max=255"`
+}
+
+// WebhookDeliveryDTO represents the webhook delivery data transfer object for the API
+type WebhookDeliveryDTO struct {
+	ID            uuid.UUID `json:"id"`
+	EndpointID    uuid.UUID `json:"endpoint_id"`
+	EventID       uuid.UUID `json:"event_id"`
+	EventType     string    `json:"event_type"`
+	Status        string    `json:"status"`
+	Attempts      int       `json:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	LastError     string    `json:"last_error,omitempty"`
+	ResponseCode  int       `json:"response_code"`
+}
+
+// WebhookEventDTO represents the body posted to a webhook endpoint
+type WebhookEventDTO struct {
+	ID        uuid.UUID       `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
@@ -0,0 +1,45 @@
+package i18n
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FormatAmount formats an amount of rupiah with the currency, separators and decimals of the language,
+// e.g. Rp 1.500.000,00 in Indonesian and IDR 1,500,000.00 in English
+func FormatAmount(language string, amount float64) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", math.Abs(amount)
+	}
+
+	integer, fraction, _ := strings.Cut(strconv.FormatFloat(amount, 'f', 2, 64), ".")
+
+	thousands := Translate(language, "format.thousands_separator")
+
+	var grouped strings.Builder
+
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped.WriteString(thousands)
+		}
+
+		grouped.WriteRune(digit)
+	}
+
+	number := grouped.String() + Translate(language, "format.decimal_separator") + fraction
+
+	return sign + Translate(language, "format.amount", "{amount}", number)
+}
+
+// FormatDate formats a date with the month names and order of the language, e.g. 17 Agustus 2025 in Indonesian
+// and August 17, 2025 in English
+func FormatDate(language string, date time.Time) string {
+	return Translate(language, "format.date",
+		"{day}", strconv.Itoa(date.Day()),
+		"{month}", Translate(language, "month."+strconv.Itoa(int(date.Month()))),
+		"{year}", strconv.Itoa(date.Year()),
+	)
+}
@@ -0,0 +1,147 @@
+// Package i18n contains the message catalogs of the API and the negotiation of the response language.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Languages of the catalogs, English is the language of the message keys and the fallback of every other language
+const (
+	English    = "en"
+	Indonesian = "id"
+
+	Default = English
+)
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// catalogs maps every language to its messages by key. The key of a response message is its English text, so
+// messages without a translation are answered in English, while templates such as validation.required have keys
+// of their own in every catalog.
+var catalogs = loadCatalogs()
+
+// loadCatalogs reads the embedded catalogs, a catalog that cannot be parsed is a build error and panics at start
+func loadCatalogs() map[string]map[string]string {
+	entries, err := locales.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+
+	loaded := make(map[string]map[string]string, len(entries))
+
+	for _, entry := range entries {
+		data, err := locales.ReadFile(path.Join("locales", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+
+		messages := make(map[string]string)
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic("i18n: " + entry.Name() + ": " + err.Error())
+		}
+
+		loaded[strings.TrimSuffix(entry.Name(), ".json")] = messages
+	}
+
+	return loaded
+}
+
+// Languages returns the languages with a catalog
+func Languages() []string {
+	languages := make([]string, 0, len(catalogs))
+	for language := range catalogs {
+		languages = append(languages, language)
+	}
+
+	sort.Strings(languages)
+
+	return languages
+}
+
+// Messages returns a copy of the catalog of the language
+func Messages(language string) map[string]string {
+	messages := make(map[string]string, len(catalogs[language]))
+	for key, message := range catalogs[language] {
+		messages[key] = message
+	}
+
+	return messages
+}
+
+// Has reports whether the key is in the catalog of the default language
+func Has(key string) bool {
+	_, ok := catalogs[Default][key]
+
+	return ok
+}
+
+// Translate returns the message of the key in the language, falling back to English and then to the key itself.
+// The replacements are pairs of placeholders and values, e.g. "{field}", "nickname".
+func Translate(language, key string, replacements ...string) string {
+	message, ok := catalogs[language][key]
+	if !ok {
+		message, ok = catalogs[Default][key]
+	}
+
+	if !ok {
+		message = key
+	}
+
+	if len(replacements) > 1 {
+		message = strings.NewReplacer(replacements...).Replace(message)
+	}
+
+	return message
+}
+
+// Negotiate picks the language of a response from an Accept-Language header such as "id-ID,id;q=0.9,en;q=0.8",
+// the default language is used when the client accepts none of the catalogs
+func Negotiate(acceptLanguage string) string {
+	best, bestQuality := Default, 0.0
+
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+
+			quality = parsed
+		}
+
+		// the region is ignored, id-ID and en-US use the catalogs of id and en
+		primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if _, ok := catalogs[primary]; ok && quality > bestQuality {
+			best, bestQuality = primary, quality
+		}
+	}
+
+	return best
+}
+
+// languageKey is the context key of the response language
+type languageKey struct{}
+
+// WithLanguage returns a copy of the context carrying the response language
+func WithLanguage(ctx context.Context, language string) context.Context {
+	return context.WithValue(ctx, languageKey{}, language)
+}
+
+// FromContext returns the response language of the context, the default language when none was negotiated
+func FromContext(ctx context.Context) string {
+	if language, ok := ctx.Value(languageKey{}).(string); ok {
+		return language
+	}
+
+	return Default
+}
@@ -2,14 +2,11 @@
 package main
 
 import (
-	"github.com/okyws/dashboard-backend/config"
-	"github.com/okyws/dashboard-backend/routes"
+	"os"
+
+	"github.com/okyws/dashboard-backend/cmd"
 )
 
 func main() {
-	config.InitCommand()
-	config.InitiateLog()
-	routes.RunServer()
-
-	defer config.CloseLog()
+	os.Exit(cmd.Execute())
 }
@@ -10,7 +10,7 @@ import (
 )
 
 // AuthMiddleware untuk validasi JWT
-func AuthMiddleware() gin.HandlerFunc {
+func AuthMiddleware(jwtManager *config.JWTManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenString := c.GetHeader("Authorization")
 		if tokenString == "" {
@@ -23,7 +23,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		// Remove "Bearer " prefix
 		tokenString = strings.TrimPrefix(tokenString, "Bearer ")
 
-		claims, err := config.ParseToken(tokenString)
+		claims, err := jwtManager.ParseToken(tokenString)
 		if err != nil {
 			utils.ErrorResponse(c, http.StatusUnauthorized, err.Error())
 			c.Abort()
@@ -39,3 +39,14 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// QueryTokenMiddleware accepts the JWT from the access_token query parameter for clients such as EventSource that cannot set headers
+func QueryTokenMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token := c.Query("access_token"); token != "" && c.GetHeader("Authorization") == "" {
+			c.Request.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		c.Next()
+	}
+}
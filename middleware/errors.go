@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/okyws/dashboard-backend/constants"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/dto"
+	"github.com/okyws/dashboard-backend/i18n"
+	"github.com/okyws/dashboard-backend/utils"
+	"gorm.io/gorm"
+)
+
+// StatusClientClosedRequest is the status of requests the client gave up on before they were answered
+const StatusClientClosedRequest = 499
+
+// errorKindStatus is the status code every kind of domain error is answered with
+var errorKindStatus = map[domain.ErrorKind]int{
+	domain.ErrorValidation:           http.StatusBadRequest,
+	domain.ErrorUnauthorized:         http.StatusUnauthorized,
+	domain.ErrorForbidden:            http.StatusForbidden,
+	domain.ErrorNotFound:             http.StatusNotFound,
+	domain.ErrorConflict:             http.StatusConflict,
+	domain.ErrorInsufficientFunds:    http.StatusUnprocessableEntity,
+	domain.ErrorUnprocessable:        http.StatusUnprocessableEntity,
+	domain.ErrorUnsupportedMedia:     http.StatusUnsupportedMediaType,
+	domain.ErrorPreconditionFailed:   http.StatusPreconditionFailed,
+	domain.ErrorPreconditionRequired: http.StatusPreconditionRequired,
+	domain.ErrorPayloadTooLarge:      http.StatusRequestEntityTooLarge,
+}
+
+// ErrorMiddleware answers the requests whose handler passed an error to utils.HandleError. Domain errors keep their
+// code, message and field details, binding and database errors get codes of their own and every other error is logged
+// and answered with 500 without its text.
+func ErrorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		err := c.Errors.Last().Err
+		status, errorCode, message, details := MapError(utils.Language(c), err)
+
+		if status == http.StatusInternalServerError {
+			utils.Logger(c.Request.Context(), "http").Error().Err(err).Msg("Unexpected error")
+		}
+
+		utils.DetailedErrorResponse(c, status, errorCode, message, details)
+	}
+}
+
+// sentinelError is the answer to a sentinel error of the standard library or GORM
+type sentinelError struct {
+	err     error
+	status  int
+	code    string
+	message string
+}
+
+// sentinelErrors are the sentinel errors answered with a status of their own, every other error is answered with 500
+var sentinelErrors = []sentinelError{
+	{io.EOF, http.StatusBadRequest, "invalid_body", "Request body is not valid JSON"},
+	{io.ErrUnexpectedEOF, http.StatusBadRequest, "invalid_body", "Request body is not valid JSON"},
+	{gorm.ErrRecordNotFound, http.StatusNotFound, "not_found", constants.MsgNotFound},
+	{gorm.ErrDuplicatedKey, http.StatusConflict, "already_exists", "Resource already exists"},
+	{gorm.ErrForeignKeyViolated, http.StatusUnprocessableEntity, "reference_not_found", "Referenced resource not found"},
+	{context.DeadlineExceeded, http.StatusGatewayTimeout, "timeout", "Request timed out"},
+	{context.Canceled, StatusClientClosedRequest, "request_cancelled", "Request cancelled"},
+}
+
+// MapError returns the status code, error code, message and field details the error is answered with, the messages
+// of validation errors are built in the language from the templates of the catalogs
+func MapError(language string, err error) (int, string, string, []dto.FieldErrorDTO) {
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		status, ok := errorKindStatus[domainErr.Kind]
+		if !ok {
+			status = http.StatusInternalServerError
+		}
+
+		return status, domainErr.Code, domainErr.Message, fieldErrors(domainErr.Details)
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return http.StatusBadRequest, "validation_failed", constants.MsgBadRequest, validationDetails(language, validationErrs)
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return http.StatusBadRequest, "invalid_body", "Request body is not valid JSON", nil
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return http.StatusBadRequest, "invalid_body", "Request body is not valid JSON", []dto.FieldErrorDTO{
+			{Field: typeErr.Field, Code: "type", Message: i18n.Translate(language, "validation.type", "{field}", typeErr.Field, "{param}", typeErr.Type.String())},
+		}
+	}
+
+	for _, sentinel := range sentinelErrors {
+		if errors.Is(err, sentinel.err) {
+			return sentinel.status, sentinel.code, sentinel.message, nil
+		}
+	}
+
+	return http.StatusInternalServerError, "internal_error", constants.MsgInternalError, nil
+}
+
+// validationDetails describes every failed rule of a binding in the language
+func validationDetails(language string, validationErrs validator.ValidationErrors) []dto.FieldErrorDTO {
+	details := make([]dto.FieldErrorDTO, 0, len(validationErrs))
+
+	for _, fieldErr := range validationErrs {
+		key := "validation." + fieldErr.Tag()
+		if !i18n.Has(key) {
+			key = "validation.invalid"
+		}
+
+		details = append(details, dto.FieldErrorDTO{
+			Field:   fieldErr.Field(),
+			Code:    fieldErr.Tag(),
+			Message: i18n.Translate(language, key, "{field}", fieldErr.Field(), "{param}", fieldErr.Param()),
+		})
+	}
+
+	return details
+}
+
+// fieldErrors converts the field errors of a domain error to the response DTO
+func fieldErrors(details []domain.FieldError) []dto.FieldErrorDTO {
+	if len(details) == 0 {
+		return nil
+	}
+
+	converted := make([]dto.FieldErrorDTO, 0, len(details))
+	for _, detail := range details {
+		converted = append(converted, dto.FieldErrorDTO(detail))
+	}
+
+	return converted
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/okyws/dashboard-backend/i18n"
+)
+
+// LanguageMiddleware negotiates the response language from the Accept-Language header and stores it in the request
+// context, the messages of the responses are translated to it and the response names it in Content-Language
+func LanguageMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		language := i18n.Negotiate(c.GetHeader("Accept-Language"))
+
+		c.Request = c.Request.WithContext(i18n.WithLanguage(c.Request.Context(), language))
+		c.Header("Content-Language", language)
+		c.Header("Vary", "Accept-Language")
+
+		c.Next()
+	}
+}
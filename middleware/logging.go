@@ -2,26 +2,55 @@
 package middleware
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/rs/zerolog/log"
+	"github.com/okyws/dashboard-backend/utils"
+	"github.com/rs/zerolog"
 )
 
-// ZerologMiddleware logs all HTTP requests in structured JSON format.
+// ZerologMiddleware logs every HTTP request once it is answered with the logger of the request, so the line carries
+// the request ID and, when the request is traced, the trace ID. Server errors log as error and client errors as warn.
 func ZerologMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 
 		c.Next()
 
-		log.Info().
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		status := c.Writer.Status()
+
+		level := zerolog.InfoLevel
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = zerolog.ErrorLevel
+		case status >= http.StatusBadRequest:
+			level = zerolog.WarnLevel
+		}
+
+		event := utils.Logger(c.Request.Context(), "http").WithLevel(level).
 			Str("method", c.Request.Method).
 			Str("path", c.Request.URL.Path).
-			Int("status", c.Writer.Status()).
+			Str("route", route).
+			Int("status", status).
+			Int("size", c.Writer.Size()).
 			Dur("latency", time.Since(start)).
 			Str("client_ip", c.ClientIP()).
-			Str("user_agent", c.Request.UserAgent()).
-			Msg("HTTP Request Logged")
+			Str("user_agent", c.Request.UserAgent())
+
+		if message := c.GetString(utils.ResponseMessageKey); message != "" {
+			event = event.Str("response", message)
+		}
+
+		if len(c.Errors) > 0 {
+			event = event.Str("errors", c.Errors.String())
+		}
+
+		event.Msg("HTTP request")
 	}
 }
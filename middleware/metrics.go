@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/okyws/dashboard-backend/ports"
+	"github.com/okyws/dashboard-backend/utils"
+)
+
+// unmatchedRoute labels requests that match no route, so unknown paths cannot grow the label set
+const unmatchedRoute = "unmatched"
+
+// MetricsMiddleware records every request by its route template, e.g. /api/v1/users/:id, instead of the raw path
+func MetricsMiddleware(recorder ports.HTTPMetrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		recorder.ObserveHTTPRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
+
+// BearerTokenMiddleware only lets requests through that send the token as bearer token
+func BearerTokenMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sent, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid token")
+			c.Abort()
+
+			return
+		}
+
+		c.Next()
+	}
+}
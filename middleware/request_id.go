@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader carries the ID correlating the logs of a request, it is taken from the caller or generated
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDKey is the key of the request ID in the gin context
+const RequestIDKey = "request_id"
+
+// validRequestID accepts the IDs of proxies and callers, anything else could forge or break log lines
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestIDMiddleware propagates the X-Request-ID header of the request, or generates one, and sends it back. The
+// request context is given a logger carrying the ID, which the handlers and services log with.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		c.Set(RequestIDKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		ctx := c.Request.Context()
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("http.request_id", requestID))
+
+		logger := log.Logger.With().Str(RequestIDKey, requestID).Logger()
+		c.Request = c.Request.WithContext(logger.WithContext(ctx))
+
+		c.Next()
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/okyws/dashboard-backend/utils"
+)
+
+// TimeoutMiddleware gives every request a deadline, the queries of the handlers run with the request context and are
+// aborted once it passes. A handler that returns without a response after the deadline is answered with 504.
+func TimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if !c.Writer.Written() && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			utils.ErrorResponse(c, http.StatusGatewayTimeout, "Request timed out")
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/okyws/dashboard-backend/utils"
+)
+
+// APIVersionMiddleware stores the API version of the route group, the handlers follow its status code conventions
+func APIVersionMiddleware(version string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(utils.APIVersionKey, version)
+
+		c.Next()
+	}
+}
+
+// DeprecationMiddleware announces on every response that the routes are deprecated since deprecatedAt (RFC 9745) and
+// are removed at sunset (RFC 8594), successor is the URL of the API replacing them
+func DeprecationMiddleware(deprecatedAt, sunset time.Time, successor string) gin.HandlerFunc {
+	deprecation := "@" + strconv.FormatInt(deprecatedAt.Unix(), 10)
+	sunsetDate := sunset.UTC().Format(http.TimeFormat)
+	link := "<" + successor + `>; rel="successor-version"`
+
+	return func(c *gin.Context) {
+		c.Header("Deprecation", deprecation)
+		c.Header("Sunset", sunsetDate)
+		c.Header("Link", link)
+
+		c.Next()
+	}
+}
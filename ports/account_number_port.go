@@ -0,0 +1,9 @@
+package ports
+
+// AccountNumberScheme is the interface for building and validating account numbers with check digits, the serials
+// come from a sequence per product code
+type AccountNumberScheme interface {
+	ProductCode(accountType string) string
+	Generate(accountType string, serial uint64) (string, error)
+	Valid(accountNumber string) bool
+}
@@ -1,21 +1,21 @@
 package ports
 
 import (
+	"context"
 	"time"
 
-	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/okyws/dashboard-backend/dto"
 )
 
 // AuthRepository is the interface for the authentication repository
 type AuthRepository interface {
-	SaveToken(ctx *gin.Context, userID uuid.UUID, token, expiresAt string) error
-	GetTokenExpiration(ctx *gin.Context, token string) (*time.Time, error)
+	SaveToken(ctx context.Context, userID uuid.UUID, token, expiresAt string) error
+	GetTokenExpiration(ctx context.Context, token string) (*time.Time, error)
 }
 
 // AuthService is the interface for the authentication service
 type AuthService interface {
-	LoginAccount(ctx *gin.Context, username, password string) (*dto.UserLoginResponseDTO, error)
-	ValidateToken(ctx *gin.Context, token string) (bool, error)
+	LoginAccount(ctx context.Context, username, password, ipAddress string) (*dto.UserLoginResponseDTO, error)
+	ValidateToken(ctx context.Context, token string) (bool, error)
 }
@@ -1,19 +1,24 @@
 package ports
 
 import (
+	"context"
+
+	"github.com/google/uuid"
 	"github.com/okyws/dashboard-backend/domain"
 )
 
 // BankAccountRepository is the interface for the bank information repository
 type BankAccountRepository interface {
 	GenericRepository[domain.BankAccount]
-	GetByUserID(userID string) ([]domain.BankAccount, error)
-	GetByAccountNumber(accountNumber string) (*domain.BankAccount, error)
-	CountBankAccount(userID string, accountType string) (int64, error)
+	GetByUserID(ctx context.Context, userID string) ([]domain.BankAccount, error)
+	GetByAccountNumber(ctx context.Context, accountNumber string) (*domain.BankAccount, error)
+	CountBankAccount(ctx context.Context, userID string, accountType string) (int64, error)
+	UpdateStatus(ctx context.Context, id string, version uint, status bool) (*domain.BankAccount, error)
+	DeleteVersion(ctx context.Context, id string, version uint) error
 }
 
 // BankAccountService is the interface for the bank information service
 type BankAccountService interface {
 	GenericService[domain.BankAccount]
-	GetByUserID(userID string) ([]domain.BankAccount, error)
+	GetByUserID(ctx context.Context, userID string, callerID uuid.UUID, role string) ([]domain.BankAccount, error)
 }
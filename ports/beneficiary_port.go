@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/okyws/dashboard-backend/domain"
+)
+
+// BeneficiaryRepository is the interface for the beneficiary repository, every lookup is scoped to the owning user
+type BeneficiaryRepository interface {
+	Create(ctx context.Context, beneficiary *domain.Beneficiary) (*domain.Beneficiary, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]domain.Beneficiary, error)
+	GetByID(ctx context.Context, userID uuid.UUID, id string) (*domain.Beneficiary, error)
+	GetByAccountNumber(ctx context.Context, userID uuid.UUID, accountNumber string) (*domain.Beneficiary, error)
+	UpdateNickname(ctx context.Context, userID uuid.UUID, id, nickname string) (*domain.Beneficiary, error)
+	Delete(ctx context.Context, userID uuid.UUID, id string) error
+}
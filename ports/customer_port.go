@@ -1,17 +1,23 @@
 // Package ports contains the interfaces for repositories and services
 package ports
 
-import "github.com/okyws/dashboard-backend/domain"
+import (
+	"context"
+
+	"github.com/okyws/dashboard-backend/domain"
+)
 
 // CustomerRepository is the interface for the customer repository
 type CustomerRepository interface {
 	GenericRepository[domain.Customer]
-	GetCustomerByUserID(userID string) (*domain.Customer, error)
-	GetCustomerByPhoneNumber(phoneNumber string) (*domain.Customer, error)
+	GetCustomerByUserID(ctx context.Context, userID string) (*domain.Customer, error)
+	GetCustomerByPhoneNumber(ctx context.Context, phoneNumber string) (*domain.Customer, error)
+	Patch(ctx context.Context, id string, version uint, changes map[string]any) (*domain.Customer, error)
+	DeleteVersion(ctx context.Context, id string, version uint) error
 }
 
 // CustomerService is the interface for the customer service
 type CustomerService interface {
 	GenericService[domain.Customer]
-	GetCustomerByUserID(userID string) (*domain.Customer, error)
+	GetCustomerByUserID(ctx context.Context, userID string) (*domain.Customer, error)
 }
@@ -0,0 +1,40 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/okyws/dashboard-backend/domain"
+)
+
+// OutboxRepository is the interface for recording events in the outbox and for relaying them
+type OutboxRepository interface {
+	ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxEvent, error)
+	MarkDelivered(ctx context.Context, id uuid.UUID, target string) error
+	MarkDispatched(ctx context.Context, id uuid.UUID) error
+	GetAfter(ctx context.Context, id uuid.UUID, limit int) ([]domain.OutboxEvent, error)
+	Record(ctx context.Context, event domain.Event) error
+}
+
+// EventHandler handles an event published on the event bus
+type EventHandler func(ctx context.Context, envelope *domain.EventEnvelope) error
+
+// EventSubscriber is a handler subscribed to the event bus, the outbox relay tracks the delivery of every event to each
+// subscriber by its name
+type EventSubscriber struct {
+	Name   string
+	Handle EventHandler
+}
+
+// EventBus is the interface for the in-process domain event bus
+type EventBus interface {
+	Subscribe(eventType, name string, handler EventHandler)
+	Subscribers(eventType string) []EventSubscriber
+}
+
+// EventSink is the interface for external systems receiving the events relayed from the outbox
+type EventSink interface {
+	Name() string
+	Publish(ctx context.Context, event *domain.OutboxEvent) error
+}
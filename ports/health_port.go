@@ -0,0 +1,9 @@
+package ports
+
+import "context"
+
+// HealthCheck is the interface for a dependency the application needs to serve requests
+type HealthCheck interface {
+	Name() string
+	Check(ctx context.Context) error
+}
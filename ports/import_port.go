@@ -0,0 +1,26 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/okyws/dashboard-backend/domain"
+)
+
+// ImportRepository is the interface for the import job repository
+type ImportRepository interface {
+	Create(ctx context.Context, job *domain.ImportJob) (*domain.ImportJob, error)
+	GetByID(ctx context.Context, id string) (*domain.ImportJob, error)
+	GetAll(ctx context.Context, limit, offset int) ([]domain.ImportJob, error)
+	ClaimNext(ctx context.Context) (*domain.ImportJob, error)
+	UpdateProgress(ctx context.Context, job *domain.ImportJob) error
+	Finish(ctx context.Context, job *domain.ImportJob, rowErrors []domain.ImportRowError) error
+	GetRowErrors(ctx context.Context, jobID string) ([]domain.ImportRowError, error)
+}
+
+// ImportRepositories are the repositories the rows of an import are written with
+type ImportRepositories struct {
+	Users        UserRepository
+	Customers    CustomerRepository
+	BankAccounts BankAccountRepository
+	Outbox       OutboxRepository
+}
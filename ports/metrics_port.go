@@ -0,0 +1,14 @@
+package ports
+
+import "time"
+
+// HTTPMetrics is the interface for recording the served HTTP requests
+type HTTPMetrics interface {
+	ObserveHTTPRequest(method, route string, status int, duration time.Duration)
+}
+
+// BusinessMetrics is the interface for recording business events as metrics
+type BusinessMetrics interface {
+	TransactionProcessed(transactionType, status string, amount float64)
+	LoginAttempted(success bool)
+}
@@ -0,0 +1,24 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/okyws/dashboard-backend/domain"
+)
+
+// NotificationRepository is the interface for the notification repository
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *domain.Notification) error
+	GetByUserID(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]domain.Notification, error)
+	MarkRead(ctx context.Context, userID uuid.UUID, id string) (*domain.Notification, error)
+	MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error)
+	GetPreferences(ctx context.Context, userID uuid.UUID) ([]domain.NotificationPreference, error)
+	SavePreferences(ctx context.Context, preferences []domain.NotificationPreference) error
+}
+
+// NotificationChannel is the interface for a channel delivering notifications to a user
+type NotificationChannel interface {
+	Name() string
+	Send(ctx context.Context, recipient *domain.NotificationRecipient, notification *domain.Notification) error
+}
@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/okyws/dashboard-backend/domain"
+)
+
+// StreamBroker is the interface for fanning out stream messages to every replica
+type StreamBroker interface {
+	Publish(ctx context.Context, message *domain.StreamMessage) error
+	Subscribe(ctx context.Context) (<-chan *domain.StreamMessage, error)
+}
@@ -1,19 +1,27 @@
 package ports
 
-import "github.com/okyws/dashboard-backend/domain"
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/okyws/dashboard-backend/domain"
+)
 
 // TransactionRepository is the interface for the transaction repository
 type TransactionRepository interface {
-	GetAll(limit, offset int) ([]domain.Transaction, error)
-	GetByID(id string) (*domain.Transaction, error)
-	GetByAccountNumber(accountID string) ([]domain.Transaction, error)
-	Create(transaction *domain.Transaction) (*domain.Transaction, error)
+	GetAll(ctx context.Context, limit, offset int) ([]domain.Transaction, error)
+	GetByID(ctx context.Context, id string) (*domain.Transaction, error)
+	GetByAccountNumber(ctx context.Context, accountID string) ([]domain.Transaction, error)
+	FirstTransferredAt(ctx context.Context, userID uuid.UUID, toAccountNumber string) (*time.Time, error)
+	SumTransferred(ctx context.Context, userID uuid.UUID, toAccountNumber string, since time.Time) (float64, error)
+	Create(ctx context.Context, transaction *domain.Transaction) (*domain.Transaction, error)
 }
 
 // TransactionService is the interface for the transaction service
 type TransactionService interface {
-	GetAllTransactions(limit, offset int) ([]domain.Transaction, error)
-	GetTransactionByID(id string) (*domain.Transaction, error)
-	GetTransactionByAccountNumber(accountID string) ([]domain.Transaction, error)
-	ProcessTransaction(fromAccountID, toAccountID, transactionType string, amount float64) error
+	GetAllTransactions(ctx context.Context, limit, offset int) ([]domain.Transaction, error)
+	GetTransactionByID(ctx context.Context, id string) (*domain.Transaction, error)
+	GetTransactionByAccountNumber(ctx context.Context, accountID string) ([]domain.Transaction, error)
+	ProcessTransaction(ctx context.Context, fromAccountID, toAccountID, transactionType string, amount float64) error
 }
@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/okyws/dashboard-backend/domain"
+)
+
+// TransferBatchRepository is the interface for the batch transfer repository
+type TransferBatchRepository interface {
+	Create(ctx context.Context, batch *domain.TransferBatch) (*domain.TransferBatch, error)
+	GetByID(ctx context.Context, id string) (*domain.TransferBatch, error)
+}
@@ -0,0 +1,10 @@
+package ports
+
+import "context"
+
+// UnitOfWork is the interface for running the changes of several repositories in a single transaction. The
+// repositories called with the context fn receives join the transaction, which is committed when fn returns nil and
+// rolled back otherwise. A unit of work started within another one joins the outer transaction.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -2,18 +2,22 @@
 package ports
 
 import (
+	"context"
+
 	"github.com/okyws/dashboard-backend/domain"
 )
 
 // UserRepository is the interface for the user repository
 type UserRepository interface {
 	GenericRepository[domain.User]
-	GetUserByEmail(email string) (*domain.User, error)
-	GetUserByUsername(username string) (*domain.User, error)
+	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*domain.User, error)
+	Patch(ctx context.Context, id string, version uint, changes map[string]any) (*domain.User, error)
+	DeleteVersion(ctx context.Context, id string, version uint) error
 }
 
 // UserService is the interface for the user service
 type UserService interface {
 	GenericService[domain.User]
-	GetUserByUsername(username string) (*domain.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*domain.User, error)
 }
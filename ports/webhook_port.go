@@ -0,0 +1,32 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/okyws/dashboard-backend/domain"
+)
+
+// WebhookRepository is the interface for the webhook repository
+type WebhookRepository interface {
+	CreateEndpoint(ctx context.Context, endpoint *domain.WebhookEndpoint) (*domain.WebhookEndpoint, error)
+	GetEndpointByID(ctx context.Context, id string) (*domain.WebhookEndpoint, error)
+	GetAllEndpoints(ctx context.Context, limit, offset int) ([]domain.WebhookEndpoint, error)
+	GetActiveEndpoints(ctx context.Context) ([]domain.WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, id string) error
+	CreateDeliveries(ctx context.Context, deliveries []domain.WebhookDelivery) error
+	ClaimDueDeliveries(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]domain.WebhookDelivery, error)
+	GetDeliveryByID(ctx context.Context, id string) (*domain.WebhookDelivery, error)
+	GetDeliveriesByEndpointID(ctx context.Context, endpointID string, limit, offset int) ([]domain.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+}
+
+// WebhookService is the interface for the webhook service
+type WebhookService interface {
+	CreateEndpoint(ctx context.Context, endpoint *domain.WebhookEndpoint) (*domain.WebhookEndpoint, error)
+	GetEndpointByID(ctx context.Context, id string) (*domain.WebhookEndpoint, error)
+	GetAllEndpoints(ctx context.Context, limit, offset int) ([]domain.WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, id string) error
+	GetDeliveriesByEndpointID(ctx context.Context, endpointID string, limit, offset int) ([]domain.WebhookDelivery, error)
+	Redeliver(ctx context.Context, deliveryID string) (*domain.WebhookDelivery, error)
+}
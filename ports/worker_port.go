@@ -0,0 +1,8 @@
+package ports
+
+import "context"
+
+// BackgroundWorker is the interface for long running jobs started alongside the HTTP server
+type BackgroundWorker interface {
+	Start(ctx context.Context)
+}
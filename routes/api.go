@@ -0,0 +1,184 @@
+package routes
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/okyws/dashboard-backend/adapter/handler"
+	"github.com/okyws/dashboard-backend/config"
+	"github.com/okyws/dashboard-backend/middleware"
+)
+
+// v1DeprecatedAt is the release that deprecated /api/v1 in favour of /api/v2
+var v1DeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+
+// apiHandlers are the HTTP handlers shared by the API versions
+type apiHandlers struct {
+	user          *handler.UserHandlerAdapter
+	customer      *handler.CustomerHandlerAdapter
+	bankInfo      *handler.BankInfoHandlerAdapter
+	transaction   *handler.TransactionHandler
+	transferBatch *handler.TransferBatchHandler
+	auth          *handler.AuthHandler
+	webhook       *handler.WebhookHandlerAdapter
+	stream        *handler.StreamHandlerAdapter
+	notification  *handler.NotificationHandlerAdapter
+	beneficiary   *handler.BeneficiaryHandlerAdapter
+	imports       *handler.ImportHandlerAdapter
+}
+
+// registerStreamRoutes registers the server-sent event streams of an API version
+func registerStreamRoutes(streamRoutes *gin.RouterGroup, h *apiHandlers, jwtManager *config.JWTManager) {
+	streamRoutes.Use(middleware.QueryTokenMiddleware(), middleware.AuthMiddleware(jwtManager))
+
+	streamRoutes.GET("/", middleware.CheckRoleMiddleware("user", "admin"), h.stream.HandleStream)
+	streamRoutes.GET("/all", middleware.CheckRoleMiddleware("admin"), h.stream.HandleFirehose)
+}
+
+// registerV1Routes registers the deprecated v1 routes, which name the action in the path
+func registerV1Routes(apiRoutes *gin.RouterGroup, h *apiHandlers, jwtManager *config.JWTManager) {
+	userRoutes := apiRoutes.Group("/users", middleware.AuthMiddleware(jwtManager))
+
+	userRoutes.GET("/", middleware.CheckRoleMiddleware("admin"), h.user.HandleGetAllUsers)
+	userRoutes.GET("/:id", middleware.CheckRoleMiddleware("admin"), h.user.HandleGetUserByID)
+	userRoutes.POST("/add", middleware.CheckRoleMiddleware("admin"), h.user.HandleCreateUser)
+	userRoutes.GET("/by-username/:username", middleware.CheckRoleMiddleware("admin"), h.user.HandleGetUserByUsername)
+	userRoutes.PUT("/:id/update", middleware.CheckRoleMiddleware("admin"), h.user.HandleUpdateUser)
+	userRoutes.DELETE("/:id/delete", middleware.CheckRoleMiddleware("admin"), h.user.HandleDeleteUser)
+
+	customerRoutes := apiRoutes.Group("/customers", middleware.AuthMiddleware(jwtManager))
+
+	customerRoutes.GET("/", middleware.CheckRoleMiddleware("admin"), h.customer.HandleGetAllCustomers)
+	customerRoutes.POST("/add", middleware.CheckRoleMiddleware("admin"), h.customer.HandleCreateCustomer)
+	customerRoutes.GET("/:id", middleware.CheckRoleMiddleware("admin"), h.customer.HandleGetCustomerByID)
+	customerRoutes.GET("/by-user-id/:user_id", middleware.CheckRoleMiddleware("admin"), h.customer.HandleGetCustomerByUserID)
+	customerRoutes.PUT("/:id/update", middleware.CheckRoleMiddleware("admin"), h.customer.HandleUpdateCustomer)
+	customerRoutes.DELETE("/:id/delete", middleware.CheckRoleMiddleware("admin"), h.customer.HandleDeleteCustomer)
+
+	bankInfoRoutes := apiRoutes.Group("/bank-accounts", middleware.AuthMiddleware(jwtManager))
+
+	bankInfoRoutes.GET("/", middleware.CheckRoleMiddleware("admin"), h.bankInfo.HandleGetAllBankAccounts)
+	bankInfoRoutes.POST("/add", middleware.CheckRoleMiddleware("admin"), h.bankInfo.HandleCreateBankInfo)
+	bankInfoRoutes.GET("/:id", middleware.CheckRoleMiddleware("admin"), h.bankInfo.HandleGetBankInfoByID)
+	bankInfoRoutes.GET("/by-user-id/:user_id", middleware.CheckRoleMiddleware("user", "admin"), h.bankInfo.HandleGetBankInfoByUserID)
+	bankInfoRoutes.PUT("/:id/status", middleware.CheckRoleMiddleware("admin"), h.bankInfo.HandleUpdateBankInfoStatus)
+	bankInfoRoutes.DELETE("/:id/delete", middleware.CheckRoleMiddleware("user", "admin"), h.bankInfo.HandleDeleteBankInfo)
+
+	transactionRoutes := apiRoutes.Group("/transactions", middleware.AuthMiddleware(jwtManager))
+
+	transactionRoutes.GET("/", middleware.CheckRoleMiddleware("admin"), h.transaction.HandleGetAllTransactions)
+	transactionRoutes.POST("/add", middleware.CheckRoleMiddleware("user"), h.transaction.HandleTransactionProcess)
+	transactionRoutes.GET("/by-account-id/:account_id", middleware.CheckRoleMiddleware("user"), h.transaction.HandleGetAllTransactionsByAccountID)
+	transactionRoutes.GET("/:id", middleware.CheckRoleMiddleware("admin"), h.transaction.HandleGetTransactionByID)
+
+	beneficiaryRoutes := apiRoutes.Group("/beneficiaries", middleware.AuthMiddleware(jwtManager), middleware.CheckRoleMiddleware("user"))
+
+	beneficiaryRoutes.GET("/", h.beneficiary.HandleGetBeneficiaries)
+	beneficiaryRoutes.POST("/add", h.beneficiary.HandleCreateBeneficiary)
+	beneficiaryRoutes.GET("/:id", h.beneficiary.HandleGetBeneficiaryByID)
+	beneficiaryRoutes.PUT("/:id/update", h.beneficiary.HandleUpdateBeneficiary)
+	beneficiaryRoutes.DELETE("/:id/delete", h.beneficiary.HandleDeleteBeneficiary)
+
+	webhookRoutes := apiRoutes.Group("/webhooks", middleware.AuthMiddleware(jwtManager), middleware.CheckRoleMiddleware("admin"))
+
+	webhookRoutes.GET("/", h.webhook.HandleGetAllEndpoints)
+	webhookRoutes.POST("/add", h.webhook.HandleCreateEndpoint)
+	webhookRoutes.DELETE("/:id/delete", h.webhook.HandleDeleteEndpoint)
+	webhookRoutes.GET("/:id/deliveries", h.webhook.HandleGetDeliveries)
+	webhookRoutes.POST("/deliveries/:id/redeliver", h.webhook.HandleRedeliver)
+
+	notificationRoutes := apiRoutes.Group("/notifications", middleware.AuthMiddleware(jwtManager), middleware.CheckRoleMiddleware("user", "admin"))
+
+	notificationRoutes.GET("/", h.notification.HandleGetNotifications)
+	notificationRoutes.PUT("/:id/read", h.notification.HandleMarkRead)
+	notificationRoutes.PUT("/read-all", h.notification.HandleMarkAllRead)
+	notificationRoutes.GET("/preferences", h.notification.HandleGetPreferences)
+	notificationRoutes.PUT("/preferences", h.notification.HandleUpdatePreferences)
+
+	authRoutes := apiRoutes.Group("/auth")
+	authRoutes.POST("/login", h.auth.Login)
+}
+
+// registerV2Routes registers the v2 routes, which address resources by their path and act on them by the method:
+// creations answer 201 with a Location header, deletions 204 and PATCH changes only the fields given
+func registerV2Routes(apiRoutes *gin.RouterGroup, h *apiHandlers, jwtManager *config.JWTManager) {
+	userRoutes := apiRoutes.Group("/users", middleware.AuthMiddleware(jwtManager))
+
+	userRoutes.GET("", middleware.CheckRoleMiddleware("admin"), h.user.HandleGetAllUsers)
+	userRoutes.POST("", middleware.CheckRoleMiddleware("admin"), h.user.HandleCreateUser)
+	userRoutes.GET("/:id", middleware.CheckRoleMiddleware("admin"), h.user.HandleGetUserByID)
+	userRoutes.PATCH("/:id", middleware.CheckRoleMiddleware("admin"), h.user.HandlePatchUser)
+	userRoutes.DELETE("/:id", middleware.CheckRoleMiddleware("admin"), h.user.HandleDeleteUser)
+	userRoutes.GET("/:id/customer", middleware.CheckRoleMiddleware("admin"), paramAlias("user_id", "id"), h.customer.HandleGetCustomerByUserID)
+	userRoutes.GET("/:id/accounts", middleware.CheckRoleMiddleware("user", "admin"), paramAlias("user_id", "id"), h.bankInfo.HandleGetBankInfoByUserID)
+
+	customerRoutes := apiRoutes.Group("/customers", middleware.AuthMiddleware(jwtManager), middleware.CheckRoleMiddleware("admin"))
+
+	customerRoutes.GET("", h.customer.HandleGetAllCustomers)
+	customerRoutes.POST("", h.customer.HandleCreateCustomer)
+	customerRoutes.GET("/:id", h.customer.HandleGetCustomerByID)
+	customerRoutes.PATCH("/:id", h.customer.HandlePatchCustomer)
+	customerRoutes.DELETE("/:id", h.customer.HandleDeleteCustomer)
+
+	accountRoutes := apiRoutes.Group("/accounts", middleware.AuthMiddleware(jwtManager))
+
+	accountRoutes.GET("", middleware.CheckRoleMiddleware("admin"), h.bankInfo.HandleGetAllBankAccounts)
+	accountRoutes.POST("", middleware.CheckRoleMiddleware("admin"), h.bankInfo.HandleCreateBankInfo)
+	accountRoutes.GET("/:id", middleware.CheckRoleMiddleware("admin"), h.bankInfo.HandleGetBankInfoByID)
+	accountRoutes.PATCH("/:id", middleware.CheckRoleMiddleware("admin"), h.bankInfo.HandleUpdateBankInfoStatus)
+	accountRoutes.DELETE("/:id", middleware.CheckRoleMiddleware("user", "admin"), h.bankInfo.HandleDeleteBankInfo)
+	accountRoutes.GET("/:id/transactions", middleware.CheckRoleMiddleware("user", "admin"), h.transaction.HandleGetAccountTransactions)
+
+	transactionRoutes := apiRoutes.Group("/transactions", middleware.AuthMiddleware(jwtManager))
+
+	transactionRoutes.GET("", middleware.CheckRoleMiddleware("admin"), h.transaction.HandleGetAllTransactions)
+	transactionRoutes.POST("", middleware.CheckRoleMiddleware("user"), h.transaction.HandleTransactionProcess)
+	transactionRoutes.GET("/:id", middleware.CheckRoleMiddleware("admin"), h.transaction.HandleGetTransactionByID)
+	transactionRoutes.POST("/batch", middleware.CheckRoleMiddleware("user"), h.transferBatch.HandleCreateTransferBatch)
+	transactionRoutes.GET("/batch/:id", middleware.CheckRoleMiddleware("user", "admin"), h.transferBatch.HandleGetTransferBatch)
+
+	beneficiaryRoutes := apiRoutes.Group("/beneficiaries", middleware.AuthMiddleware(jwtManager), middleware.CheckRoleMiddleware("user"))
+
+	beneficiaryRoutes.GET("", h.beneficiary.HandleGetBeneficiaries)
+	beneficiaryRoutes.POST("", h.beneficiary.HandleCreateBeneficiary)
+	beneficiaryRoutes.GET("/:id", h.beneficiary.HandleGetBeneficiaryByID)
+	beneficiaryRoutes.PATCH("/:id", h.beneficiary.HandleUpdateBeneficiary)
+	beneficiaryRoutes.DELETE("/:id", h.beneficiary.HandleDeleteBeneficiary)
+
+	webhookRoutes := apiRoutes.Group("/webhooks", middleware.AuthMiddleware(jwtManager), middleware.CheckRoleMiddleware("admin"))
+
+	webhookRoutes.GET("", h.webhook.HandleGetAllEndpoints)
+	webhookRoutes.POST("", h.webhook.HandleCreateEndpoint)
+	webhookRoutes.GET("/:id", h.webhook.HandleGetEndpoint)
+	webhookRoutes.DELETE("/:id", h.webhook.HandleDeleteEndpoint)
+	webhookRoutes.GET("/:id/deliveries", h.webhook.HandleGetDeliveries)
+	webhookRoutes.POST("/deliveries/:id/redeliveries", h.webhook.HandleRedeliver)
+
+	importRoutes := apiRoutes.Group("/imports", middleware.AuthMiddleware(jwtManager), middleware.CheckRoleMiddleware("admin"))
+
+	importRoutes.GET("", h.imports.HandleGetImports)
+	importRoutes.POST("", h.imports.HandleSubmitImport)
+	importRoutes.GET("/:id", h.imports.HandleGetImport)
+	importRoutes.GET("/:id/errors", h.imports.HandleGetImportErrors)
+
+	notificationRoutes := apiRoutes.Group("/notifications", middleware.AuthMiddleware(jwtManager), middleware.CheckRoleMiddleware("user", "admin"))
+
+	notificationRoutes.GET("", h.notification.HandleGetNotifications)
+	notificationRoutes.PUT("/:id/read", h.notification.HandleMarkRead)
+	notificationRoutes.PUT("/read", h.notification.HandleMarkAllRead)
+	notificationRoutes.GET("/preferences", h.notification.HandleGetPreferences)
+	notificationRoutes.PUT("/preferences", h.notification.HandleUpdatePreferences)
+
+	authRoutes := apiRoutes.Group("/auth")
+	authRoutes.POST("/login", h.auth.Login)
+}
+
+// paramAlias makes the path parameter from available as alias, for the handlers of v1 routes whose parameter is
+// named differently on the nested v2 route
+func paramAlias(alias, from string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Params = append(c.Params, gin.Param{Key: alias, Value: c.Param(from)})
+
+		c.Next()
+	}
+}
@@ -0,0 +1,279 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/okyws/dashboard-backend/adapter/handler"
+	"github.com/okyws/dashboard-backend/adapter/openapi"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/dto"
+	"github.com/okyws/dashboard-backend/utils"
+)
+
+// pagination are the query parameters of the paginated lists
+var pagination = []openapi.Parameter{
+	{Name: "limit", In: "query", Description: "Maximum number of items, 10 by default", Schema: &openapi.Schema{Type: "integer"}},
+	{Name: "offset", In: "query", Description: "Number of items to skip", Schema: &openapi.Schema{Type: "integer"}},
+}
+
+// ifMatch is the precondition of the changes of versioned resources, the ETag their GET answered with
+var ifMatch = []openapi.Parameter{
+	{Name: "If-Match", In: "header", Required: true, Description: "ETag of the version the change is based on, 412 when the resource changed since",
+		Schema: &openapi.Schema{Type: "string"}},
+}
+
+// importQuery are the query parameters of a bulk import
+var importQuery = []openapi.Parameter{
+	{Name: "mode", In: "query", Description: "best_effort imports every valid row, all_or_nothing no row unless every row can be imported",
+		Schema: &openapi.Schema{Type: "string", Enum: []string{domain.ImportModeBestEffort, domain.ImportModeAllOrNothing}}},
+}
+
+// streamQuery are the query parameters of the event streams, browsers cannot set headers on an EventSource
+var streamQuery = []openapi.Parameter{
+	{Name: "token", In: "query", Description: "JWT when the Authorization header cannot be set", Schema: &openapi.Schema{Type: "string"}},
+	{Name: "last_event_id", In: "query", Description: "Replays the events after this one, like the Last-Event-ID header", Schema: &openapi.Schema{Type: "string", Format: "uuid"}},
+}
+
+// NewAPIDocument describes every route of the router in an OpenAPI document, a route registered without an entry
+// here fails the tests
+func NewAPIDocument(configuration *domain.Configuration) *openapi.Document {
+	spec := openapi.New(openapi.Info{
+		Title:       configuration.AppName,
+		Description: "Banking dashboard API. Messages follow Accept-Language (en, id).",
+		Version:     configuration.AppVersion,
+	})
+
+	v2Routes := []openapi.Route{
+		{Method: http.MethodPost, Path: "/api/v2/auth/login", Tag: "auth", Summary: "Log in and get a JWT",
+			Request: dto.UserLoginDTO{}, Response: dto.UserLoginResponseDTO{}},
+
+		{Method: http.MethodGet, Path: "/api/v2/users", Tag: "users", Summary: "List users", Auth: true, Response: []dto.UserDTO{},
+			Query: append([]openapi.Parameter{
+				{Name: "username", In: "query", Description: "Only the user with this username", Schema: &openapi.Schema{Type: "string"}},
+			}, pagination...)},
+		{Method: http.MethodPost, Path: "/api/v2/users", Tag: "users", Summary: "Create a user", Auth: true,
+			Request: dto.UserCreateDTO{}, Response: dto.UserDTO{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: "/api/v2/users/:id", Tag: "users", Summary: "Get a user", Auth: true, Response: dto.UserDTO{}},
+		{Method: http.MethodPatch, Path: "/api/v2/users/:id", Tag: "users", Summary: "Update a user with a JSON Merge Patch", Auth: true,
+			Query: ifMatch, Request: dto.UserPatchDTO{}, RequestType: utils.MergePatchContentType, Response: dto.UserDTO{}},
+		{Method: http.MethodDelete, Path: "/api/v2/users/:id", Tag: "users", Summary: "Delete a user", Auth: true,
+			Query: ifMatch, Status: http.StatusNoContent},
+		{Method: http.MethodGet, Path: "/api/v2/users/:id/customer", Tag: "customers", Summary: "Get the customer profile of a user",
+			Auth: true, Response: dto.CustomerDTO{}},
+		{Method: http.MethodGet, Path: "/api/v2/users/:id/accounts", Tag: "bank accounts", Summary: "List the bank accounts of a user",
+			Auth: true, Response: []dto.BankAccountDTO{}},
+
+		{Method: http.MethodGet, Path: "/api/v2/customers", Tag: "customers", Summary: "List customers", Auth: true,
+			Query: pagination, Response: []dto.CustomerDTO{}},
+		{Method: http.MethodPost, Path: "/api/v2/customers", Tag: "customers", Summary: "Create a customer profile", Auth: true,
+			Request: dto.CustomerCreateDTO{}, Response: dto.CustomerDTO{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: "/api/v2/customers/:id", Tag: "customers", Summary: "Get a customer", Auth: true,
+			Response: dto.CustomerDTO{}},
+		{Method: http.MethodPatch, Path: "/api/v2/customers/:id", Tag: "customers", Summary: "Update a customer with a JSON Merge Patch",
+			Auth: true, Query: ifMatch, Request: dto.CustomerPatchDTO{}, RequestType: utils.MergePatchContentType, Response: dto.CustomerDTO{}},
+		{Method: http.MethodDelete, Path: "/api/v2/customers/:id", Tag: "customers", Summary: "Delete a customer", Auth: true,
+			Query: ifMatch, Status: http.StatusNoContent},
+
+		{Method: http.MethodGet, Path: "/api/v2/accounts", Tag: "bank accounts", Summary: "List bank accounts", Auth: true,
+			Query: pagination, Response: []dto.BankAccountDTO{}},
+		{Method: http.MethodPost, Path: "/api/v2/accounts", Tag: "bank accounts", Summary: "Open a bank account", Auth: true,
+			Request: dto.BankAccountCreateDTO{}, Response: dto.BankAccountDTO{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: "/api/v2/accounts/:id", Tag: "bank accounts", Summary: "Get a bank account", Auth: true,
+			Response: dto.BankAccountDTO{}},
+		{Method: http.MethodPatch, Path: "/api/v2/accounts/:id", Tag: "bank accounts", Summary: "Activate or freeze a bank account",
+			Auth: true, Query: ifMatch, Request: dto.BankAccountUpdateStatusDTO{}, Response: dto.BankAccountDTO{}},
+		{Method: http.MethodDelete, Path: "/api/v2/accounts/:id", Tag: "bank accounts", Summary: "Close a bank account", Auth: true,
+			Query: ifMatch, Status: http.StatusNoContent},
+		{Method: http.MethodGet, Path: "/api/v2/accounts/:id/transactions", Tag: "transactions", Summary: "Statement of an account",
+			Auth: true, Response: []dto.TransactionDTO{}},
+
+		{Method: http.MethodGet, Path: "/api/v2/transactions", Tag: "transactions", Summary: "List transactions", Auth: true,
+			Query: pagination, Response: []dto.TransactionDTO{}},
+		{Method: http.MethodPost, Path: "/api/v2/transactions", Tag: "transactions", Summary: "Deposit, withdraw or transfer",
+			Auth: true, Request: dto.TransactionCreateDTO{}},
+		{Method: http.MethodGet, Path: "/api/v2/transactions/:id", Tag: "transactions", Summary: "Get a transaction", Auth: true,
+			Response: dto.TransactionDTO{}},
+		{Method: http.MethodPost, Path: "/api/v2/transactions/batch", Tag: "transactions",
+			Summary: "Transfer from one account to many, such as a payroll", Auth: true, Request: dto.TransferBatchCreateDTO{},
+			Response: dto.TransferBatchDTO{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: "/api/v2/transactions/batch/:id", Tag: "transactions",
+			Summary: "Get a batch transfer and the outcome of its transfers", Auth: true, Response: dto.TransferBatchDTO{}},
+
+		{Method: http.MethodGet, Path: "/api/v2/beneficiaries", Tag: "beneficiaries", Summary: "List the beneficiaries", Auth: true,
+			Query: pagination, Response: []dto.BeneficiaryDTO{}},
+		{Method: http.MethodPost, Path: "/api/v2/beneficiaries", Tag: "beneficiaries", Summary: "Add a beneficiary", Auth: true,
+			Request: dto.BeneficiaryCreateDTO{}, Response: dto.BeneficiaryDTO{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: "/api/v2/beneficiaries/:id", Tag: "beneficiaries", Summary: "Get a beneficiary", Auth: true,
+			Response: dto.BeneficiaryDTO{}},
+		{Method: http.MethodPatch, Path: "/api/v2/beneficiaries/:id", Tag: "beneficiaries", Summary: "Rename a beneficiary",
+			Auth: true, Request: dto.BeneficiaryUpdateDTO{}, Response: dto.BeneficiaryDTO{}},
+		{Method: http.MethodDelete, Path: "/api/v2/beneficiaries/:id", Tag: "beneficiaries", Summary: "Remove a beneficiary",
+			Auth: true, Status: http.StatusNoContent},
+
+		{Method: http.MethodGet, Path: "/api/v2/webhooks", Tag: "webhooks", Summary: "List webhook endpoints", Auth: true,
+			Query: pagination, Response: []dto.WebhookEndpointDTO{}},
+		{Method: http.MethodPost, Path: "/api/v2/webhooks", Tag: "webhooks", Summary: "Register a webhook endpoint", Auth: true,
+			Request: dto.WebhookEndpointCreateDTO{}, Response: dto.WebhookEndpointDTO{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: "/api/v2/webhooks/:id", Tag: "webhooks", Summary: "Get a webhook endpoint", Auth: true,
+			Response: dto.WebhookEndpointDTO{}},
+		{Method: http.MethodDelete, Path: "/api/v2/webhooks/:id", Tag: "webhooks", Summary: "Remove a webhook endpoint", Auth: true,
+			Status: http.StatusNoContent},
+		{Method: http.MethodGet, Path: "/api/v2/webhooks/:id/deliveries", Tag: "webhooks",
+			Summary: "Delivery history of a webhook endpoint", Auth: true, Query: pagination, Response: []dto.WebhookDeliveryDTO{}},
+		{Method: http.MethodPost, Path: "/api/v2/webhooks/deliveries/:id/redeliveries", Tag: "webhooks",
+			Summary: "Send a delivery again", Auth: true, Response: dto.WebhookDeliveryDTO{}},
+
+		{Method: http.MethodGet, Path: "/api/v2/imports", Tag: "imports", Summary: "List bulk imports, the latest first", Auth: true,
+			Query: pagination, Response: []dto.ImportJobDTO{}},
+		{Method: http.MethodPost, Path: "/api/v2/imports", Tag: "imports", Auth: true, Query: importQuery,
+			Summary: "Import users, customers and accounts from JSON Lines, or from text/csv with these names as header",
+			Request: dto.ImportRowDTO{}, RequestType: "application/jsonl", Response: dto.ImportJobDTO{}, Status: http.StatusAccepted},
+		{Method: http.MethodGet, Path: "/api/v2/imports/:id", Tag: "imports", Summary: "Get a bulk import and its progress",
+			Auth: true, Response: dto.ImportJobDTO{}},
+		{Method: http.MethodGet, Path: "/api/v2/imports/:id/errors", Tag: "imports", Summary: "Download the problems with the rows of a bulk import",
+			Auth: true, Raw: true, ContentType: "text/csv"},
+
+		{Method: http.MethodGet, Path: "/api/v2/notifications", Tag: "notifications", Summary: "List the notifications",
+			Auth: true, Response: []dto.NotificationDTO{}, Query: append([]openapi.Parameter{
+				{Name: "unread", In: "query", Description: "Only unread notifications when true", Schema: &openapi.Schema{Type: "boolean"}},
+			}, pagination...)},
+		{Method: http.MethodPut, Path: "/api/v2/notifications/:id/read", Tag: "notifications",
+			Summary: "Mark a notification as read", Auth: true, Response: dto.NotificationDTO{}},
+		{Method: http.MethodPut, Path: "/api/v2/notifications/read", Tag: "notifications",
+			Summary: "Mark every notification as read", Auth: true, Response: dto.NotificationReadAllDTO{}},
+		{Method: http.MethodGet, Path: "/api/v2/notifications/preferences", Tag: "notifications",
+			Summary: "Get the notification preferences", Auth: true, Response: []dto.NotificationPreferenceDTO{}},
+		{Method: http.MethodPut, Path: "/api/v2/notifications/preferences", Tag: "notifications",
+			Summary: "Update the notification preferences", Auth: true, Request: dto.NotificationPreferenceUpdateDTO{},
+			Response: []dto.NotificationPreferenceDTO{}},
+
+		{Method: http.MethodGet, Path: "/api/v2/stream/", Tag: "stream", Summary: "Server-sent events of the own accounts",
+			Auth: true, Query: streamQuery, Raw: true, ContentType: "text/event-stream"},
+		{Method: http.MethodGet, Path: "/api/v2/stream/all", Tag: "stream", Summary: "Server-sent events of every account",
+			Auth: true, Query: streamQuery, Raw: true, ContentType: "text/event-stream"},
+	}
+
+	// the v1 routes, deprecated in favour of the v2 routes of the same tags
+	v1Routes := []openapi.Route{
+		{Method: http.MethodPost, Path: "/api/v1/auth/login", Tag: "auth", Summary: "Log in and get a JWT",
+			Request: dto.UserLoginDTO{}, Response: dto.UserLoginResponseDTO{}},
+
+		{Method: http.MethodGet, Path: "/api/v1/users/", Tag: "users", Summary: "List users", Auth: true,
+			Query: pagination, Response: []dto.UserDTO{}},
+		{Method: http.MethodGet, Path: "/api/v1/users/:id", Tag: "users", Summary: "Get a user", Auth: true,
+			Response: dto.UserDTO{}},
+		{Method: http.MethodPost, Path: "/api/v1/users/add", Tag: "users", Summary: "Create a user", Auth: true,
+			Request: dto.UserCreateDTO{}, Response: dto.UserDTO{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: "/api/v1/users/by-username/:username", Tag: "users", Summary: "Get a user by username",
+			Auth: true, Response: dto.UserDTO{}},
+		{Method: http.MethodPut, Path: "/api/v1/users/:id/update", Tag: "users", Summary: "Update a user", Auth: true,
+			Request: dto.UserUpdateDTO{}, Response: dto.UserDTO{}},
+		{Method: http.MethodDelete, Path: "/api/v1/users/:id/delete", Tag: "users", Summary: "Delete a user", Auth: true},
+
+		{Method: http.MethodGet, Path: "/api/v1/customers/", Tag: "customers", Summary: "List customers", Auth: true,
+			Query: pagination, Response: []dto.CustomerDTO{}},
+		{Method: http.MethodPost, Path: "/api/v1/customers/add", Tag: "customers", Summary: "Create a customer profile",
+			Auth: true, Request: dto.CustomerCreateDTO{}, Response: dto.CustomerDTO{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: "/api/v1/customers/:id", Tag: "customers", Summary: "Get a customer", Auth: true,
+			Response: dto.CustomerDTO{}},
+		{Method: http.MethodGet, Path: "/api/v1/customers/by-user-id/:user_id", Tag: "customers",
+			Summary: "Get the customer profile of a user", Auth: true, Response: dto.CustomerDTO{}},
+		{Method: http.MethodPut, Path: "/api/v1/customers/:id/update", Tag: "customers", Summary: "Update a customer",
+			Auth: true, Request: dto.CustomerUpdateDTO{}, Response: dto.CustomerDTO{}},
+		{Method: http.MethodDelete, Path: "/api/v1/customers/:id/delete", Tag: "customers", Summary: "Delete a customer",
+			Auth: true},
+
+		{Method: http.MethodGet, Path: "/api/v1/bank-accounts/", Tag: "bank accounts", Summary: "List bank accounts",
+			Auth: true, Query: pagination, Response: []dto.BankAccountDTO{}},
+		{Method: http.MethodPost, Path: "/api/v1/bank-accounts/add", Tag: "bank accounts", Summary: "Open a bank account",
+			Auth: true, Request: dto.BankAccountCreateDTO{}, Response: dto.BankAccountDTO{}},
+		{Method: http.MethodGet, Path: "/api/v1/bank-accounts/:id", Tag: "bank accounts", Summary: "Get a bank account",
+			Auth: true, Response: dto.BankAccountDTO{}},
+		{Method: http.MethodGet, Path: "/api/v1/bank-accounts/by-user-id/:user_id", Tag: "bank accounts",
+			Summary: "List the bank accounts of a user", Auth: true, Response: []dto.BankAccountDTO{}},
+		{Method: http.MethodPut, Path: "/api/v1/bank-accounts/:id/status", Tag: "bank accounts",
+			Summary: "Activate or freeze a bank account", Auth: true, Request: dto.BankAccountUpdateStatusDTO{}, Response: dto.BankAccountDTO{}},
+		{Method: http.MethodDelete, Path: "/api/v1/bank-accounts/:id/delete", Tag: "bank accounts",
+			Summary: "Close a bank account", Auth: true},
+
+		{Method: http.MethodGet, Path: "/api/v1/transactions/", Tag: "transactions", Summary: "List transactions",
+			Auth: true, Query: pagination, Response: []dto.TransactionDTO{}},
+		{Method: http.MethodPost, Path: "/api/v1/transactions/add", Tag: "transactions",
+			Summary: "Deposit, withdraw or transfer", Auth: true, Request: dto.TransactionCreateDTO{}},
+		{Method: http.MethodGet, Path: "/api/v1/transactions/by-account-id/:account_id", Tag: "transactions",
+			Summary: "Statement of an account", Auth: true, Response: []dto.TransactionDTO{}},
+		{Method: http.MethodGet, Path: "/api/v1/transactions/:id", Tag: "transactions", Summary: "Get a transaction",
+			Auth: true, Response: dto.TransactionDTO{}},
+
+		{Method: http.MethodGet, Path: "/api/v1/beneficiaries/", Tag: "beneficiaries", Summary: "List the beneficiaries",
+			Auth: true, Query: pagination, Response: []dto.BeneficiaryDTO{}},
+		{Method: http.MethodPost, Path: "/api/v1/beneficiaries/add", Tag: "beneficiaries", Summary: "Add a beneficiary",
+			Auth: true, Request: dto.BeneficiaryCreateDTO{}, Response: dto.BeneficiaryDTO{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Path: "/api/v1/beneficiaries/:id", Tag: "beneficiaries", Summary: "Get a beneficiary",
+			Auth: true, Response: dto.BeneficiaryDTO{}},
+		{Method: http.MethodPut, Path: "/api/v1/beneficiaries/:id/update", Tag: "beneficiaries",
+			Summary: "Rename a beneficiary", Auth: true, Request: dto.BeneficiaryUpdateDTO{}, Response: dto.BeneficiaryDTO{}},
+		{Method: http.MethodDelete, Path: "/api/v1/beneficiaries/:id/delete", Tag: "beneficiaries",
+			Summary: "Remove a beneficiary", Auth: true},
+
+		{Method: http.MethodGet, Path: "/api/v1/webhooks/", Tag: "webhooks", Summary: "List webhook endpoints",
+			Auth: true, Query: pagination, Response: []dto.WebhookEndpointDTO{}},
+		{Method: http.MethodPost, Path: "/api/v1/webhooks/add", Tag: "webhooks", Summary: "Register a webhook endpoint",
+			Auth: true, Request: dto.WebhookEndpointCreateDTO{}, Response: dto.WebhookEndpointDTO{}, Status: http.StatusCreated},
+		{Method: http.MethodDelete, Path: "/api/v1/webhooks/:id/delete", Tag: "webhooks",
+			Summary: "Remove a webhook endpoint", Auth: true},
+		{Method: http.MethodGet, Path: "/api/v1/webhooks/:id/deliveries", Tag: "webhooks",
+			Summary: "Delivery history of a webhook endpoint", Auth: true, Query: pagination, Response: []dto.WebhookDeliveryDTO{}},
+		{Method: http.MethodPost, Path: "/api/v1/webhooks/deliveries/:id/redeliver", Tag: "webhooks",
+			Summary: "Send a delivery again", Auth: true, Response: dto.WebhookDeliveryDTO{}},
+
+		{Method: http.MethodGet, Path: "/api/v1/notifications/", Tag: "notifications", Summary: "List the notifications",
+			Auth: true, Response: []dto.NotificationDTO{}, Query: append([]openapi.Parameter{
+				{Name: "unread", In: "query", Description: "Only unread notifications when true", Schema: &openapi.Schema{Type: "boolean"}},
+			}, pagination...)},
+		{Method: http.MethodPut, Path: "/api/v1/notifications/:id/read", Tag: "notifications",
+			Summary: "Mark a notification as read", Auth: true, Response: dto.NotificationDTO{}},
+		{Method: http.MethodPut, Path: "/api/v1/notifications/read-all", Tag: "notifications",
+			Summary: "Mark every notification as read", Auth: true, Response: dto.NotificationReadAllDTO{}},
+		{Method: http.MethodGet, Path: "/api/v1/notifications/preferences", Tag: "notifications",
+			Summary: "Get the notification preferences", Auth: true, Response: []dto.NotificationPreferenceDTO{}},
+		{Method: http.MethodPut, Path: "/api/v1/notifications/preferences", Tag: "notifications",
+			Summary: "Update the notification preferences", Auth: true, Request: dto.NotificationPreferenceUpdateDTO{},
+			Response: []dto.NotificationPreferenceDTO{}},
+
+		{Method: http.MethodGet, Path: "/api/v1/stream/", Tag: "stream", Summary: "Server-sent events of the own accounts",
+			Auth: true, Query: streamQuery, Raw: true, ContentType: "text/event-stream"},
+		{Method: http.MethodGet, Path: "/api/v1/stream/all", Tag: "stream", Summary: "Server-sent events of every account",
+			Auth: true, Query: streamQuery, Raw: true, ContentType: "text/event-stream"},
+	}
+
+	for i := range v1Routes {
+		v1Routes[i].Deprecated = true
+	}
+
+	spec.Add(v2Routes...)
+	spec.Add(v1Routes...)
+	spec.Add(
+		openapi.Route{Method: http.MethodGet, Path: "/healthz", Tag: "operations", Summary: "Liveness probe",
+			Raw: true, Response: dto.HealthResponseDTO{}},
+		openapi.Route{Method: http.MethodGet, Path: "/readyz", Tag: "operations", Summary: "Readiness probe, 503 when a dependency fails",
+			Raw: true, Response: dto.ReadinessResponseDTO{}},
+		openapi.Route{Method: http.MethodGet, Path: "/version", Tag: "operations", Summary: "Build information",
+			Raw: true, Response: dto.VersionResponseDTO{}},
+		openapi.Route{Method: http.MethodGet, Path: "/metrics", Tag: "operations",
+			Summary: "Prometheus metrics, served here when METRICS_PORT is 0", Auth: true, Raw: true, ContentType: "text/plain"},
+		openapi.Route{Method: http.MethodGet, Path: "/openapi.json", Tag: "operations", Summary: "This document", Raw: true},
+		openapi.Route{Method: http.MethodGet, Path: "/docs", Tag: "operations", Summary: "Swagger UI", Raw: true, ContentType: "text/html"},
+	)
+
+	return spec.Document()
+}
+
+// RegisterDocsRoutes serves the OpenAPI document and the Swagger UI
+func RegisterDocsRoutes(router *gin.Engine, document *openapi.Document) {
+	openAPIHandler := handler.NewOpenAPIHandler(document)
+
+	router.GET("/openapi.json", openAPIHandler.HandleSpec)
+	router.GET("/docs", openAPIHandler.HandleDocs)
+}
@@ -3,6 +3,8 @@ package routes
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
@@ -11,152 +13,354 @@ import (
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/okyws/dashboard-backend/adapter/broker"
+	"github.com/okyws/dashboard-backend/adapter/eventsink"
 	"github.com/okyws/dashboard-backend/adapter/handler"
+	"github.com/okyws/dashboard-backend/adapter/healthcheck"
+	"github.com/okyws/dashboard-backend/adapter/metrics"
+	"github.com/okyws/dashboard-backend/adapter/notifier"
 	"github.com/okyws/dashboard-backend/adapter/repository"
+	"github.com/okyws/dashboard-backend/adapter/tracing"
 	"github.com/okyws/dashboard-backend/config"
 	"github.com/okyws/dashboard-backend/constants"
 	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/dto"
 	"github.com/okyws/dashboard-backend/middleware"
+	"github.com/okyws/dashboard-backend/ports"
 	"github.com/okyws/dashboard-backend/services"
+	"github.com/okyws/dashboard-backend/utils"
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"gorm.io/gorm"
 )
 
-// RegisterRoutes registers all API routes
-func RegisterRoutes(router *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
+// RegisterRoutes registers all API routes and returns the background workers they depend on
+func RegisterRoutes(router *gin.Engine, db *gorm.DB, redisClient *redis.Client, configuration *domain.Configuration,
+	businessMetrics ports.BusinessMetrics) ([]ports.BackgroundWorker, error) {
 	userRepo := repository.NewUserRepositoryAdapter(db)
 	customerRepo := repository.NewCustomerRepositoryAdapter(db)
-	bankInfoRepo := repository.NewBankAccountRepositoryAdapter(db)
+	accountNumberScheme := config.NewAccountNumberScheme(configuration)
+	bankInfoRepo := repository.NewBankAccountRepositoryAdapter(db, accountNumberScheme)
 	transactionRepo := repository.NewTransactionRepositoryAdapter(db)
-	authRepo := repository.NewAuthRepositoryRedis(redisClient)
+	authRepo, streamBroker := newStores(configuration, redisClient)
+	outboxRepo := repository.NewOutboxRepositoryAdapter(db)
+	webhookRepo := repository.NewWebhookRepositoryAdapter(db)
+	notificationRepo := repository.NewNotificationRepositoryAdapter(db)
+	beneficiaryRepo := repository.NewBeneficiaryRepositoryAdapter(db)
+	importRepo := repository.NewImportRepositoryAdapter(db)
+	transferBatchRepo := repository.NewTransferBatchRepositoryAdapter(db)
+	unitOfWork := repository.NewUnitOfWork(db)
 
-	userService := services.NewUserService(userRepo)
-	customerService := services.NewCustomerService(customerRepo, userRepo)
+	jwtManager := config.NewJWTManager(configuration.JWTSecret, configuration.JWTExpiry)
+
+	if err := dto.RegisterAccountNumberValidation(accountNumberScheme.Valid); err != nil {
+		return nil, err
+	}
+
+	dto.RegisterJSONFieldNames()
+
+	userService := services.NewUserService(unitOfWork, userRepo, outboxRepo)
+	customerService := services.NewCustomerService(unitOfWork, customerRepo, userRepo, outboxRepo)
 	accountValidator := services.NewAccountValidator(userRepo, bankInfoRepo)
-	bankInfoService := services.NewBankAccountService(userRepo, bankInfoRepo, accountValidator)
-	transactionValidator := services.NewTransactionValidator(transactionRepo, bankInfoRepo)
-	transactionService := services.NewTransactionService(db, transactionRepo, bankInfoRepo, transactionValidator)
-	authService := services.NewAuthService(authRepo, userRepo)
-
-	userHandler := handler.NewUserHandler(userService)
-	customerHandler := handler.NewCustomerHandler(customerService)
-	bankInfoHandler := handler.NewBankInfoHandler(bankInfoService)
-	transactionHandler := handler.NewTransactionHandler(transactionService)
-	authHandler := handler.NewAuthHandler(authService)
-
-	apiRoutes := router.Group("/api/v1")
-	userRoutes := apiRoutes.Group("/users", middleware.AuthMiddleware())
-
-	userRoutes.GET("/", middleware.CheckRoleMiddleware("admin"), userHandler.HandleGetAllUsers)
-	userRoutes.GET("/:id", middleware.CheckRoleMiddleware("admin"), userHandler.HandleGetUserByID)
-	userRoutes.POST("/add", middleware.CheckRoleMiddleware("admin"), userHandler.HandleCreateUser)
-	userRoutes.GET("/by-username/:username", middleware.CheckRoleMiddleware("admin"), userHandler.HandleGetUserByUsername)
-	userRoutes.PUT("/:id/update", middleware.CheckRoleMiddleware("admin"), userHandler.HandleUpdateUser)
-	userRoutes.DELETE("/:id/delete", middleware.CheckRoleMiddleware("admin"), userHandler.HandleDeleteUser)
-
-	customerRoutes := apiRoutes.Group("/customers", middleware.AuthMiddleware())
-
-	customerRoutes.GET("/", middleware.CheckRoleMiddleware("admin"), customerHandler.HandleGetAllCustomers)
-	customerRoutes.POST("/add", middleware.CheckRoleMiddleware("admin"), customerHandler.HandleCreateCustomer)
-	customerRoutes.GET("/:id", middleware.CheckRoleMiddleware("admin"), customerHandler.HandleGetCustomerByID)
-	customerRoutes.GET("/by-user-id/:user_id", middleware.CheckRoleMiddleware("admin"), customerHandler.HandleGetCustomerByUserID)
-	customerRoutes.PUT("/:id/update", middleware.CheckRoleMiddleware("admin"), customerHandler.HandleUpdateCustomer)
-	customerRoutes.DELETE("/:id/delete", middleware.CheckRoleMiddleware("admin"), customerHandler.HandleDeleteCustomer)
-
-	bankInfoRoutes := apiRoutes.Group("/bank-accounts", middleware.AuthMiddleware())
-
-	bankInfoRoutes.GET("/", middleware.CheckRoleMiddleware("admin"), bankInfoHandler.HandleGetAllBankAccounts)
-	bankInfoRoutes.POST("/add", middleware.CheckRoleMiddleware("admin"), bankInfoHandler.HandleCreateBankInfo)
-	bankInfoRoutes.GET("/:id", middleware.CheckRoleMiddleware("admin"), bankInfoHandler.HandleGetBankInfoByID)
-	bankInfoRoutes.GET("/by-user-id/:user_id", middleware.CheckRoleMiddleware("user"), bankInfoHandler.HandleGetBankInfoByUserID)
-	bankInfoRoutes.DELETE("/:id/delete", middleware.CheckRoleMiddleware("user", "admin"), bankInfoHandler.HandleDeleteBankInfo)
-
-	transactionRoutes := apiRoutes.Group("/transactions", middleware.AuthMiddleware())
-
-	transactionRoutes.GET("/", middleware.CheckRoleMiddleware("admin"), transactionHandler.HandleGetAllTransactions)
-	transactionRoutes.POST("/add", middleware.CheckRoleMiddleware("user"), transactionHandler.HandleTransactionProcess)
-	transactionRoutes.GET("/by-account-id/:account_id", middleware.CheckRoleMiddleware("user"), transactionHandler.HandleGetAllTransactionsByAccountID)
-	transactionRoutes.GET("/:id", middleware.CheckRoleMiddleware("admin"), transactionHandler.HandleGetTransactionByID)
-
-	authRoutes := apiRoutes.Group("/auth")
-	authRoutes.POST("/login", authHandler.Login)
+	bankInfoService := services.NewBankAccountService(unitOfWork, userRepo, bankInfoRepo, outboxRepo, accountValidator)
+	beneficiaryService := services.NewBeneficiaryService(beneficiaryRepo, bankInfoRepo, customerRepo, transactionRepo,
+		configuration.BeneficiaryCoolingOff, configuration.BeneficiaryCoolingOffLimit)
+	transactionValidator := services.NewTransactionValidator(transactionRepo, bankInfoRepo, outboxRepo, beneficiaryService)
+	transactionService := services.NewTransactionService(unitOfWork, transactionRepo, bankInfoRepo, transactionValidator, beneficiaryService, businessMetrics)
+	authService := services.NewAuthService(authRepo, userRepo, outboxRepo, jwtManager, businessMetrics)
+	webhookService := services.NewWebhookService(webhookRepo)
+	webhookDispatcher := services.NewWebhookDispatcher(webhookRepo)
+	notificationService := services.NewNotificationService(notificationRepo, userRepo, customerRepo, bankInfoRepo,
+		notifier.NewInAppChannel(notificationRepo), notifier.NewFakeEmailChannel(), notifier.NewFakeSMSChannel())
+	streamService := services.NewStreamService(streamBroker, outboxRepo, bankInfoRepo)
+	importService := services.NewImportService(unitOfWork, importRepo, repository.NewImportRepositories(db, accountNumberScheme),
+		middleware.MapError)
+	transferBatchService := services.NewTransferBatchService(unitOfWork, transferBatchRepo, bankInfoRepo, transactionValidator,
+		middleware.MapError, businessMetrics)
+
+	eventBus := services.NewEventBus()
+	eventBus.Subscribe(services.AllEvents, "webhooks", webhookDispatcher.HandleEvent)
+	eventBus.Subscribe(services.AllEvents, "stream", streamService.HandleEvent)
+	eventBus.Subscribe(services.AllEvents, "notifications", notificationService.HandleEvent)
+	outboxRelay := services.NewOutboxRelay(outboxRepo, eventBus, newEventSinks(configuration, redisClient)...)
+
+	handlers := &apiHandlers{
+		user:          handler.NewUserHandler(userService),
+		customer:      handler.NewCustomerHandler(customerService),
+		bankInfo:      handler.NewBankInfoHandler(bankInfoService),
+		transaction:   handler.NewTransactionHandler(transactionService),
+		transferBatch: handler.NewTransferBatchHandler(transferBatchService),
+		auth:          handler.NewAuthHandler(authService),
+		webhook:       handler.NewWebhookHandler(webhookService),
+		stream:        handler.NewStreamHandler(streamService),
+		notification:  handler.NewNotificationHandler(notificationService),
+		beneficiary:   handler.NewBeneficiaryHandler(beneficiaryService),
+		imports:       handler.NewImportHandler(importService),
+	}
+
+	// v1 stays mounted until its sunset, every response points its clients to v2
+	deprecation := middleware.DeprecationMiddleware(v1DeprecatedAt, configuration.V1SunsetDate(), "/api/v2")
+
+	// the stream routes stay outside of the request timeout, they are open as long as the client listens
+	registerStreamRoutes(router.Group("/api/v1/stream", middleware.APIVersionMiddleware(utils.APIV1), deprecation), handlers, jwtManager)
+	registerStreamRoutes(router.Group("/api/v2/stream", middleware.APIVersionMiddleware(utils.APIV2)), handlers, jwtManager)
+
+	registerV1Routes(router.Group("/api/v1", middleware.APIVersionMiddleware(utils.APIV1), deprecation,
+		middleware.TimeoutMiddleware(configuration.RequestTimeout)), handlers, jwtManager)
+	registerV2Routes(router.Group("/api/v2", middleware.APIVersionMiddleware(utils.APIV2),
+		middleware.TimeoutMiddleware(configuration.RequestTimeout)), handlers, jwtManager)
 
 	log.Info().Msg("Successfully configured routes with database " + db.Name())
+
+	return []ports.BackgroundWorker{outboxRelay, webhookDispatcher, streamService, importService}, nil
+}
+
+// newStores builds the login token store and the stream broker selected in the configuration, redisClient is nil
+// unless the store driver is redis
+func newStores(configuration *domain.Configuration, redisClient *redis.Client) (ports.AuthRepository, ports.StreamBroker) {
+	if configuration.StoreDriver == domain.StoreMemory {
+		log.Info().Msg("Using in-memory stores, login tokens and stream messages stay on this node")
+
+		return repository.NewAuthRepositoryMemory(), broker.NewMemoryBroker()
+	}
+
+	return repository.NewAuthRepositoryRedis(redisClient), broker.NewRedisBroker(redisClient, broker.DefaultChannel)
+}
+
+// newEventSinks builds the outbox event sinks selected in the configuration
+func newEventSinks(configuration *domain.Configuration, redisClient *redis.Client) []ports.EventSink {
+	sinks := make([]ports.EventSink, 0)
+
+	for _, name := range configuration.EventSinks {
+		switch name {
+		case "log":
+			sinks = append(sinks, eventsink.NewLogSink(log.Logger))
+		case "redis":
+			sinks = append(sinks, eventsink.NewRedisStreamSink(redisClient, eventsink.DefaultStream))
+		default:
+			log.Warn().Str("sink", name).Msg("Unknown event sink ignored")
+		}
+	}
+
+	return sinks
+}
+
+// RegisterHealthRoutes registers the probes and the build information outside the versioned API
+func RegisterHealthRoutes(router *gin.Engine, healthService *services.HealthService, configuration *domain.Configuration) {
+	healthHandler := handler.NewHealthHandler(healthService, configuration)
+
+	router.GET("/healthz", healthHandler.HandleHealth)
+	router.GET("/readyz", healthHandler.HandleReady)
+	router.GET("/version", healthHandler.HandleVersion)
+}
+
+// newHealthService builds the readiness checks of the database, the pending migrations and Redis when it is used
+func newHealthService(db *gorm.DB, redisClient *redis.Client) (*services.HealthService, error) {
+	migrator, err := config.NewMigrator(db)
+	if err != nil {
+		return nil, err
+	}
+
+	checks := []ports.HealthCheck{healthcheck.NewDatabaseCheck(db), healthcheck.NewMigrationCheck(migrator)}
+	if redisClient != nil {
+		checks = append(checks, healthcheck.NewRedisCheck(redisClient))
+	}
+
+	return services.NewHealthService(checks...), nil
+}
+
+// RegisterMetricsRoute serves the metrics on the router behind the bearer token
+func RegisterMetricsRoute(router *gin.Engine, metricsHandler http.Handler, token string) {
+	router.GET("/metrics", middleware.BearerTokenMiddleware(token), gin.WrapH(metricsHandler))
+}
+
+// newMetricsServer builds the admin server serving only /metrics
+func newMetricsServer(configuration *domain.Configuration, metricsHandler http.Handler) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metricsHandler)
+
+	return &http.Server{
+		Addr:         fmt.Sprintf(":%d", configuration.MetricsPort),
+		Handler:      mux,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+}
+
+// Server is the configured router together with the connections and workers it depends on
+type Server struct {
+	Router  *gin.Engine
+	DB      *gorm.DB
+	Redis   *redis.Client
+	Workers []ports.BackgroundWorker
+	Health  *services.HealthService
+	Metrics *metrics.Metrics
+	Tracing *sdktrace.TracerProvider
+}
+
+// Close closes the database and Redis connections and flushes the pending spans
+func (s *Server) Close() {
+	config.CloseDatabase(s.DB)
+	config.CloseRedisClient(s.Redis)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := s.Tracing.Shutdown(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to flush the pending spans")
+	}
 }
 
 // SetupRouter initializes the Gin router
-func SetupRouter() (*gin.Engine, *gorm.DB, *redis.Client) {
-	router := gin.Default()
+func SetupRouter(configuration *domain.Configuration) (*Server, error) {
+	appMetrics := metrics.New()
+
+	tracerProvider, err := tracing.NewProvider(configuration)
+	if err != nil {
+		return nil, err
+	}
+
+	// gin.Default would add the plain text logger of gin next to the structured one
+	router := gin.New()
+	router.Use(middleware.TracingMiddleware())
+	router.Use(middleware.RequestIDMiddleware())
+	router.Use(middleware.LanguageMiddleware())
+	router.Use(middleware.MetricsMiddleware(appMetrics))
 	router.Use(middleware.ZerologMiddleware())
 	router.Use(gin.Recovery())
+	router.Use(middleware.ErrorMiddleware())
 	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:3000"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowOrigins:     []string{configuration.ClientURL},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "Last-Event-ID", "Traceparent", "Tracestate", "Baggage", "If-Match", middleware.RequestIDHeader},
+		ExposeHeaders:    []string{"Content-Length", "Location", "ETag", "Deprecation", "Sunset", "Link", "Traceparent", middleware.RequestIDHeader},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
 
-	db, redisClient, err := config.GetDatabaseConnection()
+	db, err := config.NewDBConnectionENV(configuration)
 	if err != nil {
-		log.Fatal().Err(err).Str("error", err.Error()).Msg(constants.MsgDBConnectFail)
+		_ = tracerProvider.Shutdown(context.Background())
+		return nil, err
+	}
+
+	server := &Server{Router: router, DB: db, Metrics: appMetrics, Tracing: tracerProvider}
+
+	if configuration.StoreDriver == domain.StoreRedis {
+		server.Redis = config.NewRedisClient(configuration)
+		appMetrics.InstrumentRedis(server.Redis)
+		tracing.InstrumentRedis(server.Redis)
 	}
 
+	if err := errors.Join(appMetrics.InstrumentDB(db), tracing.InstrumentDB(db)); err != nil {
+		server.Close()
+		return nil, err
+	}
+
+	if configuration.MetricsPort == 0 {
+		RegisterMetricsRoute(router, appMetrics.Handler(), configuration.MetricsToken)
+	}
+
+	if server.Health, err = newHealthService(db, server.Redis); err != nil {
+		server.Close()
+		return nil, err
+	}
+
+	RegisterHealthRoutes(router, server.Health, configuration)
+	RegisterDocsRoutes(router, NewAPIDocument(configuration))
+
 	// Register API routes
-	RegisterRoutes(router, db, redisClient)
+	if server.Workers, err = RegisterRoutes(router, db, server.Redis, configuration, appMetrics); err != nil {
+		server.Close()
+		return nil, err
+	}
 
-	return router, db, redisClient
+	return server, nil
 }
 
-// RunServer starts the Gin server
-func RunServer() {
-	router, db, redisClient := SetupRouter()
-
-	configuration, err := domain.LoadConfig()
+// RunServer starts the Gin server and blocks until it is stopped, returning the error that stopped it
+func RunServer(configuration *domain.Configuration) error {
+	app, err := SetupRouter(configuration)
 	if err != nil {
-		log.Fatal().Err(err).Str("error", err.Error()).Msg(constants.MsgConfigLoadFail)
+		return err
 	}
+	defer app.Close()
+
+	// log events given the request context carry the trace and span IDs
+	log.Logger = log.Logger.Hook(tracing.LogHook{})
 
 	server := &http.Server{
-		Addr:         ":" + configuration.ServerPort,
-		Handler:      router,
+		Addr:         configuration.GetServerAddress(),
+		Handler:      app.Router,
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  120 * time.Second,
 	}
 
+	// start background workers, they stop when the server shuts down
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	for _, worker := range app.Workers {
+		go worker.Start(workerCtx)
+	}
+
 	// initialize channels
-	errChan := make(chan error, 1)
+	errChan := make(chan error, 2)
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 
-	go func() {
-		log.Info().Msg("Starting server on port " + configuration.ServerPort)
+	go listen(server, "Starting server", configuration.ServerPort, errChan)
 
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			errChan <- err // Send error to channel
-		}
-	}()
+	var metricsServer *http.Server
+
+	if configuration.MetricsPort != 0 {
+		metricsServer = newMetricsServer(configuration, app.Metrics.Handler())
+
+		go listen(metricsServer, "Starting metrics server", configuration.MetricsPort, errChan)
+	}
+
+	var serveErr error
 
 	select {
 	case <-stop:
 		log.Info().Msg(constants.MsgServerShutdown)
-	case err := <-errChan:
-		log.Error().Err(err).Str("error", err.Error()).Msg(constants.MsgServerError)
+
+		// fail readiness first and give load balancers time to notice before connections are closed
+		app.Health.Drain()
+		time.Sleep(configuration.ShutdownDelay)
+	case serveErr = <-errChan:
+		log.Error().Err(serveErr).Str("error", serveErr.Error()).Msg(constants.MsgServerError)
 	}
 
+	stopWorkers()
+
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	if err := server.Shutdown(shutdownCtx); err != nil {
-		log.Error().Err(err).Str("error", err.Error()).Msg(constants.MsgServerShutdownErr)
-	}
+	shutdown(shutdownCtx, server)
 
-	// Cleanup resources
-	defer cancel()
-	defer config.CloseDatabase(db)
-	defer config.CloseRedisClient(redisClient)
+	// the metrics server stops last so the shutdown can still be scraped
+	if metricsServer != nil {
+		shutdown(shutdownCtx, metricsServer)
+	}
 
 	log.Info().Msg(constants.MsgServerGraceful)
+
+	return serveErr
+}
+
+// listen serves the server until it is shut down, sending the error that stopped it otherwise to errChan
+func listen(server *http.Server, message string, port int, errChan chan<- error) {
+	log.Info().Int("port", port).Msg(message)
+
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		errChan <- err // Send error to channel
+	}
+}
+
+// shutdown stops the server gracefully, logging the connections it could not close in time
+func shutdown(ctx context.Context, server *http.Server) {
+	if err := server.Shutdown(ctx); err != nil {
+		log.Error().Err(err).Str("error", err.Error()).Msg(constants.MsgServerShutdownErr)
+	}
 }
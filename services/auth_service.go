@@ -1,75 +1,117 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
 
-	"github.com/gin-gonic/gin"
 	"github.com/okyws/dashboard-backend/config"
+	"github.com/okyws/dashboard-backend/domain"
 	"github.com/okyws/dashboard-backend/dto"
 	"github.com/okyws/dashboard-backend/ports"
-	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
+// ErrInvalidCredentials is returned when the username does not exist or the password does not match
+var ErrInvalidCredentials = domain.NewError(domain.ErrorUnauthorized, "invalid_credentials", "username or password is incorrect")
+
 // AuthAdapter is the implementation of the authentication service
 type AuthAdapter struct {
-	repo ports.AuthRepository
-	user ports.UserRepository
+	repo    ports.AuthRepository
+	user    ports.UserRepository
+	events  ports.OutboxRepository
+	tokens  *config.JWTManager
+	metrics ports.BusinessMetrics
 }
 
 // NewAuthService creates a new authentication service
-func NewAuthService(repo ports.AuthRepository, user ports.UserRepository) *AuthAdapter {
-	return &AuthAdapter{repo: repo, user: user}
+func NewAuthService(repo ports.AuthRepository, user ports.UserRepository, events ports.OutboxRepository, tokens *config.JWTManager,
+	metrics ports.BusinessMetrics) *AuthAdapter {
+	return &AuthAdapter{repo: repo, user: user, events: events, tokens: tokens, metrics: metrics}
 }
 
 // LoginAccount logs in a user
-func (u *AuthAdapter) LoginAccount(ctx *gin.Context, username, password string) (*dto.UserLoginResponseDTO, error) {
-	log.Info().Str("username", username).Msg("LoginAccount started")
+func (u *AuthAdapter) LoginAccount(ctx context.Context, username, password, ipAddress string) (*dto.UserLoginResponseDTO, error) {
+	ctx, span := tracer.Start(ctx, "AuthService.LoginAccount", trace.WithAttributes(attribute.String("user.name", username)))
+	defer span.End()
+
+	logger(ctx).Info().Str("username", username).Msg("LoginAccount started")
 
-	user, err := u.user.GetUserByUsername(username)
+	user, err := u.user.GetUserByUsername(ctx, username)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user by username: %v", err)
+		failSpan(span, err)
+		u.metrics.LoginAttempted(false)
+
+		// an unknown username is answered like a wrong password so the usernames cannot be probed
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidCredentials.WithCause(err)
+		}
+
+		return nil, fmt.Errorf("failed to get user by username: %w", err)
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		log.Error().Err(err).Msg("Username or password is incorrect. Failed to login")
-		return nil, errors.New("username or password is incorrect")
+	if err := u.comparePassword(ctx, user.Password, password); err != nil {
+		logger(ctx).Error().Err(err).Msg("Username or password is incorrect. Failed to login")
+		span.SetStatus(codes.Error, "username or password is incorrect")
+		u.metrics.LoginAttempted(false)
+
+		event := domain.LoginFailed{UserID: user.ID, Username: user.Username, IPAddress: ipAddress}
+		// the failed login is recorded even when the client hangs up right after the attempt
+		if err := u.events.Record(context.WithoutCancel(ctx), event); err != nil {
+			logger(ctx).Error().Err(err).Msg("Failed to record failed login")
+		}
+
+		return nil, ErrInvalidCredentials
 	}
 
-	token, expiresAt, err := config.GenerateJWT(user.ID, user.Username, user.Role)
+	token, expiresAt, err := u.tokens.GenerateJWT(user.ID, user.Username, user.Role)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to generate token")
+		logger(ctx).Error().Err(err).Msg("Failed to generate token")
 		return nil, errors.New("could not generate token")
 	}
 
 	err = u.repo.SaveToken(ctx, user.ID, token, expiresAt)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to save token")
+		logger(ctx).Error().Err(err).Msg("Failed to save token")
+		failSpan(span, err)
+
 		return nil, fmt.Errorf("failed to save token: %v", err)
 	}
 
-	log.Info().Str("username", user.Username).Str("expiresAt", expiresAt).Msg("Login success")
+	logger(ctx).Info().Str("username", user.Username).Str("expiresAt", expiresAt).Msg("Login success")
+	u.metrics.LoginAttempted(true)
 
 	return &dto.UserLoginResponseDTO{Username: user.Username, UserID: user.ID.String(), Role: user.Role, Token: token, ExpiresAt: expiresAt}, nil
 }
 
+// comparePassword checks the password against the bcrypt hash in a span of its own, bcrypt is slow by design
+func (u *AuthAdapter) comparePassword(ctx context.Context, hash, password string) error {
+	_, span := tracer.Start(ctx, "bcrypt.CompareHashAndPassword")
+	defer span.End()
+
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+}
+
 // ValidateToken validates a token and returns true if it is valid
-func (u *AuthAdapter) ValidateToken(ctx *gin.Context, token string) (bool, error) {
+func (u *AuthAdapter) ValidateToken(ctx context.Context, token string) (bool, error) {
 	expiresAt, err := u.repo.GetTokenExpiration(ctx, token)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to validate token")
+		logger(ctx).Error().Err(err).Msg("Failed to validate token")
 		return false, fmt.Errorf("failed to validate token: %v", err)
 	}
 
 	// Check if the token is expired
 	if expiresAt.Before(time.Now()) {
-		log.Info().Str("token", token).Msg("Token is expired")
+		logger(ctx).Info().Msg("Token is expired")
 		return false, nil
 	}
 
-	log.Info().Msg("Token is valid")
+	logger(ctx).Info().Msg("Token is valid")
 
 	return true, nil
 }
@@ -2,71 +2,138 @@
 package services
 
 import (
-	"errors"
+	"context"
 
+	"github.com/google/uuid"
 	"github.com/okyws/dashboard-backend/domain"
 	"github.com/okyws/dashboard-backend/ports"
 )
 
+var (
+	// ErrBankAccountNotFound is returned when deleting a bank account that does not exist
+	ErrBankAccountNotFound = domain.NewError(domain.ErrorNotFound, "bank_account_not_found", "bank information not found")
+
+	// ErrMainAccountDelete is returned when a main bank account is deleted, only an admin may close it
+	ErrMainAccountDelete = domain.NewError(domain.ErrorForbidden, "main_account_delete", "contact admin to delete main bank account")
+)
+
 // BankAccountService is the implementation of the bank information service
 type BankAccountService struct {
+	work               ports.UnitOfWork
 	UserRepository     ports.UserRepository
 	BankInfoRepository ports.BankAccountRepository
+	OutboxRepository   ports.OutboxRepository
 	AccountValidator   *AccountValidator
 }
 
 // NewBankAccountService creates a new bank information service
-func NewBankAccountService(userRepo ports.UserRepository, bankInfoRepo ports.BankAccountRepository, validator *AccountValidator) *BankAccountService {
-	return &BankAccountService{UserRepository: userRepo, BankInfoRepository: bankInfoRepo, AccountValidator: validator}
+func NewBankAccountService(work ports.UnitOfWork, userRepo ports.UserRepository, bankInfoRepo ports.BankAccountRepository,
+	outboxRepo ports.OutboxRepository, validator *AccountValidator) *BankAccountService {
+	return &BankAccountService{
+		work:               work,
+		UserRepository:     userRepo,
+		BankInfoRepository: bankInfoRepo,
+		OutboxRepository:   outboxRepo,
+		AccountValidator:   validator,
+	}
 }
 
-// CreateBankAccount creates a new bank information
-func (s *BankAccountService) CreateBankAccount(bankInfo *domain.BankAccount) (*domain.BankAccount, error) {
-	if err := s.AccountValidator.validateUser(bankInfo.UserID.String()); err != nil {
+// CreateBankAccount creates a new bank information together with its account.created event
+func (s *BankAccountService) CreateBankAccount(ctx context.Context, bankInfo *domain.BankAccount) (*domain.BankAccount, error) {
+	if err := s.AccountValidator.validateUser(ctx, bankInfo.UserID.String()); err != nil {
 		return nil, err
 	}
 
-	if err := s.AccountValidator.validateAccountType(bankInfo); err != nil {
+	if err := s.AccountValidator.validateAccountType(ctx, bankInfo); err != nil {
 		return nil, err
 	}
 
-	return s.BankInfoRepository.Create(bankInfo)
+	return recordChange(ctx, s.work, s.OutboxRepository, func(ctx context.Context) (*domain.BankAccount, domain.Event, error) {
+		account, err := s.BankInfoRepository.Create(ctx, bankInfo)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return account, domain.AccountCreated{BankAccountDTO: *domain.MapBankAccountToDTO(account)}, nil
+	})
+}
+
+// UpdateBankAccount updates a specific bank information and records the account.balance_changed event when the balance
+// moved
+func (s *BankAccountService) UpdateBankAccount(ctx context.Context, bankInfo *domain.BankAccount) (*domain.BankAccount, error) {
+	return recordChange(ctx, s.work, s.OutboxRepository, func(ctx context.Context) (*domain.BankAccount, domain.Event, error) {
+		current, err := s.BankInfoRepository.GetByID(ctx, bankInfo.ID.String())
+		if err != nil {
+			return nil, nil, err
+		}
+
+		account, err := s.BankInfoRepository.Update(ctx, bankInfo)
+		if err != nil || account.Balance == current.Balance {
+			return account, nil, err
+		}
+
+		return account, domain.BalanceChanged{BankAccountDTO: *domain.MapBankAccountToDTO(account), PreviousBalance: current.Balance}, nil
+	})
 }
 
-// UpdateBankAccount updates a specific bank information
-func (s *BankAccountService) UpdateBankAccount(bankInfo *domain.BankAccount) (*domain.BankAccount, error) {
-	return s.BankInfoRepository.Update(bankInfo)
+// UpdateBankAccountStatus activates or freezes a specific bank information at the version, any version when it is 0,
+// and records whether it was frozen or activated
+func (s *BankAccountService) UpdateBankAccountStatus(ctx context.Context, id string, version uint, status bool) (*domain.BankAccount, error) {
+	return recordChange(ctx, s.work, s.OutboxRepository, func(ctx context.Context) (*domain.BankAccount, domain.Event, error) {
+		current, err := s.BankInfoRepository.GetByID(ctx, id)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		account, err := s.BankInfoRepository.UpdateStatus(ctx, id, version, status)
+		if err != nil || current.AccountStatus == status {
+			return account, nil, err
+		}
+
+		if status {
+			return account, domain.AccountActivated{BankAccountDTO: *domain.MapBankAccountToDTO(account)}, nil
+		}
+
+		return account, domain.AccountFrozen{BankAccountDTO: *domain.MapBankAccountToDTO(account)}, nil
+	})
 }
 
-// DeleteBankAccount deletes a specific bank information
-func (s *BankAccountService) DeleteBankAccount(id string) error {
-	exist, err := s.GetBankAccountByID(id)
+// DeleteBankAccount deletes a specific bank information at the version, any version when it is 0. Users only delete
+// their own accounts and admins those of every user.
+func (s *BankAccountService) DeleteBankAccount(ctx context.Context, id string, version uint, userID uuid.UUID, role string) error {
+	exist, err := s.GetBankAccountByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	if exist == nil {
-		return errors.New("bank information not found")
+	// the account of another user is answered like a missing one, so its ID is not confirmed
+	if exist == nil || (exist.UserID != userID && role != "admin") {
+		return ErrBankAccountNotFound
 	}
 
 	if exist.AccountType == "rekening-utama" {
-		return errors.New("contact admin to delete main bank account")
+		return ErrMainAccountDelete
 	}
 
-	return s.BankInfoRepository.Delete(id)
+	return s.BankInfoRepository.DeleteVersion(ctx, id, version)
 }
 
 // GetBankAccountByID fetches a bank information by ID and returns nil if not found
-func (s *BankAccountService) GetBankAccountByID(id string) (*domain.BankAccount, error) {
-	return s.BankInfoRepository.GetByID(id)
+func (s *BankAccountService) GetBankAccountByID(ctx context.Context, id string) (*domain.BankAccount, error) {
+	return s.BankInfoRepository.GetByID(ctx, id)
 }
 
 // GetAllBankAccount fetches all bank information data with pagination
-func (s *BankAccountService) GetAllBankAccount(limit int, offset int) ([]domain.BankAccount, error) {
-	return s.BankInfoRepository.GetAll(limit, offset)
+func (s *BankAccountService) GetAllBankAccount(ctx context.Context, limit int, offset int) ([]domain.BankAccount, error) {
+	return s.BankInfoRepository.GetAll(ctx, limit, offset)
 }
 
-// GetByUserID returns the bank information for a user
-func (s *BankAccountService) GetByUserID(userID string) ([]domain.BankAccount, error) {
-	return s.BankInfoRepository.GetByUserID(userID)
+// GetByUserID returns the bank information for a user, users only see their own accounts and admins those of every
+// user
+func (s *BankAccountService) GetByUserID(ctx context.Context, userID string, callerID uuid.UUID, role string) ([]domain.BankAccount, error) {
+	if owner, err := uuid.Parse(userID); (err != nil || owner != callerID) && role != "admin" {
+		return nil, ErrBankAccountNotFound
+	}
+
+	return s.BankInfoRepository.GetByUserID(ctx, userID)
 }
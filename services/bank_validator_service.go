@@ -2,13 +2,27 @@
 package services
 
 import (
-	"errors"
+	"context"
 
 	"github.com/okyws/dashboard-backend/domain"
 	"github.com/okyws/dashboard-backend/ports"
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrInvalidAccountType is returned for account types other than rekening-utama, saku and deposito
+	ErrInvalidAccountType = domain.NewError(domain.ErrorValidation, "invalid_account_type", "invalid account type")
+
+	// ErrMainAccountExists is returned when the user already has a main bank account
+	ErrMainAccountExists = domain.NewError(domain.ErrorConflict, "main_account_exists", "user already has a main bank account")
+
+	// ErrMainAccountRequired is returned when a secondary account is opened before the main account
+	ErrMainAccountRequired = domain.NewError(domain.ErrorUnprocessable, "main_account_required", "user must have a main bank account")
+
+	// ErrAccountLimitReached is returned when the user has as many accounts of the type as allowed
+	ErrAccountLimitReached = domain.NewError(domain.ErrorConflict, "account_limit_reached", "account limit reached")
+)
+
 // AccountValidator is a struct responsible for validating bank accounts.
 type AccountValidator struct {
 	UserRepository     ports.UserRepository
@@ -21,58 +35,58 @@ func NewAccountValidator(userRepo ports.UserRepository, bankInfoRepo ports.BankA
 }
 
 // validateUser checks if the user ID exists
-func (v *AccountValidator) validateUser(userID string) error {
-	_, err := v.UserRepository.GetByID(userID)
+func (v *AccountValidator) validateUser(ctx context.Context, userID string) error {
+	_, err := v.UserRepository.GetByID(ctx, userID)
 	if err != nil && err == gorm.ErrRecordNotFound {
-		return errors.New("user ID does not exist")
+		return ErrUserNotFound.WithCause(err)
 	}
 
 	return nil
 }
 
 // validateAccountType checks if the account type is valid
-func (v *AccountValidator) validateAccountType(bankInfo *domain.BankAccount) error {
+func (v *AccountValidator) validateAccountType(ctx context.Context, bankInfo *domain.BankAccount) error {
 	switch bankInfo.AccountType {
 	case "rekening-utama":
-		return v.validateMainAccount(bankInfo.UserID.String())
+		return v.validateMainAccount(ctx, bankInfo.UserID.String())
 	case "saku", "deposito":
-		return v.validateSecondaryAccount(bankInfo.UserID.String(), bankInfo.AccountType)
+		return v.validateSecondaryAccount(ctx, bankInfo.UserID.String(), bankInfo.AccountType)
 	default:
-		return errors.New("invalid account type")
+		return ErrInvalidAccountType
 	}
 }
 
 // validateMainAccount checks if the user already has a main bank account
-func (v *AccountValidator) validateMainAccount(userID string) error {
-	count, err := v.BankInfoRepository.CountBankAccount(userID, "rekening-utama")
+func (v *AccountValidator) validateMainAccount(ctx context.Context, userID string) error {
+	count, err := v.BankInfoRepository.CountBankAccount(ctx, userID, "rekening-utama")
 	if err != nil {
 		return err
 	}
 
 	if count >= 1 {
-		return errors.New("user already has a main bank account")
+		return ErrMainAccountExists
 	}
 
 	return nil
 }
 
 // validateSecondaryAccount checks if the user has a main bank account
-func (v *AccountValidator) validateSecondaryAccount(userID string, accountType string) error {
-	count, err := v.BankInfoRepository.CountBankAccount(userID, "rekening-utama")
+func (v *AccountValidator) validateSecondaryAccount(ctx context.Context, userID string, accountType string) error {
+	count, err := v.BankInfoRepository.CountBankAccount(ctx, userID, "rekening-utama")
 	if err != nil {
 		return err
 	}
 
 	if count == 0 {
-		return errors.New("user must have a main bank account to create a " + accountType + " account")
+		return ErrMainAccountRequired.WithMessage("user must have a main bank account to create a " + accountType + " account")
 	}
 
 	if accountType == "saku" && count >= 8 {
-		return errors.New("saku account limit reached")
+		return ErrAccountLimitReached.WithMessage("saku account limit reached")
 	}
 
 	if accountType == "deposito" && count <= 3 {
-		return errors.New("deposito account limit reached")
+		return ErrAccountLimitReached.WithMessage("deposito account limit reached")
 	}
 
 	return nil
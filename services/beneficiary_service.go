@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/ports"
+	"github.com/okyws/dashboard-backend/utils"
+	"gorm.io/gorm"
+)
+
+// ErrBeneficiaryExists is returned when the account is already in the user's beneficiary book
+var ErrBeneficiaryExists = domain.NewError(domain.ErrorConflict, "beneficiary_exists", "beneficiary already exists")
+
+// ErrBeneficiaryCoolingOff is returned when a new beneficiary would receive more in total than allowed during its
+// cooling-off period
+var ErrBeneficiaryCoolingOff = domain.NewError(domain.ErrorForbidden, "beneficiary_cooling_off",
+	"beneficiary is in its cooling-off period, the total transferred exceeds the allowed limit")
+
+// ErrBeneficiaryNotFound is returned when a transfer refers to a beneficiary that is not in the user's beneficiary book
+var ErrBeneficiaryNotFound = domain.NewError(domain.ErrorUnprocessable, "beneficiary_not_found", "beneficiary not found")
+
+// BeneficiaryService manages the beneficiary book of a user
+type BeneficiaryService struct {
+	BeneficiaryRepository ports.BeneficiaryRepository
+	BankInfoRepository    ports.BankAccountRepository
+	CustomerRepository    ports.CustomerRepository
+	TransactionRepository ports.TransactionRepository
+	CoolingOffPeriod      time.Duration // disabled when zero
+	CoolingOffLimit       float64       // largest total a beneficiary in cooling-off may receive
+}
+
+// NewBeneficiaryService creates a new beneficiary service
+func NewBeneficiaryService(beneficiaryRepo ports.BeneficiaryRepository, bankInfoRepo ports.BankAccountRepository, customerRepo ports.CustomerRepository,
+	transactionRepo ports.TransactionRepository, coolingOffPeriod time.Duration, coolingOffLimit float64) *BeneficiaryService {
+	return &BeneficiaryService{
+		BeneficiaryRepository: beneficiaryRepo,
+		BankInfoRepository:    bankInfoRepo,
+		CustomerRepository:    customerRepo,
+		TransactionRepository: transactionRepo,
+		CoolingOffPeriod:      coolingOffPeriod,
+		CoolingOffLimit:       coolingOffLimit,
+	}
+}
+
+// CreateBeneficiary verifies the account number and saves it with the masked name of its holder,
+// accounts of the user themselves skip the cooling-off period
+func (s *BeneficiaryService) CreateBeneficiary(ctx context.Context, userID uuid.UUID, nickname, accountNumber string) (*domain.Beneficiary, error) {
+	account, err := s.BankInfoRepository.GetByAccountNumber(ctx, accountNumber)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAccountNotFound.WithCause(err)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.BeneficiaryRepository.GetByAccountNumber(ctx, userID, accountNumber)
+	if err == nil {
+		return nil, ErrBeneficiaryExists
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	holderName, err := s.holderName(ctx, account.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	activeFrom := time.Now()
+	if account.UserID != userID {
+		activeFrom = activeFrom.Add(s.CoolingOffPeriod)
+	}
+
+	return s.BeneficiaryRepository.Create(ctx, &domain.Beneficiary{
+		UserID:        userID,
+		Nickname:      nickname,
+		AccountNumber: account.AccountNumber,
+		HolderName:    holderName,
+		ActiveFrom:    activeFrom,
+	})
+}
+
+// GetBeneficiaries retrieves the beneficiary book of a user with pagination
+func (s *BeneficiaryService) GetBeneficiaries(ctx context.Context, userID uuid.UUID, limit, offset int) ([]domain.Beneficiary, error) {
+	return s.BeneficiaryRepository.GetByUserID(ctx, userID, limit, offset)
+}
+
+// GetBeneficiaryByID retrieves a beneficiary of the user
+func (s *BeneficiaryService) GetBeneficiaryByID(ctx context.Context, userID uuid.UUID, id string) (*domain.Beneficiary, error) {
+	return s.BeneficiaryRepository.GetByID(ctx, userID, id)
+}
+
+// UpdateBeneficiary renames a beneficiary of the user
+func (s *BeneficiaryService) UpdateBeneficiary(ctx context.Context, userID uuid.UUID, id, nickname string) (*domain.Beneficiary, error) {
+	return s.BeneficiaryRepository.UpdateNickname(ctx, userID, id, nickname)
+}
+
+// DeleteBeneficiary removes a beneficiary of the user
+func (s *BeneficiaryService) DeleteBeneficiary(ctx context.Context, userID uuid.UUID, id string) error {
+	return s.BeneficiaryRepository.Delete(ctx, userID, id)
+}
+
+// ResolveTransfer returns the account number of the beneficiary, the cooling-off limit is enforced when the transfer
+// is made
+func (s *BeneficiaryService) ResolveTransfer(ctx context.Context, userID uuid.UUID, beneficiaryID string) (string, error) {
+	beneficiary, err := s.BeneficiaryRepository.GetByID(ctx, userID, beneficiaryID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", ErrBeneficiaryNotFound.WithCause(err)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return beneficiary.AccountNumber, nil
+}
+
+// CheckCoolingOff enforces the cooling-off limit on a transfer of the user to the account, whether it names a
+// beneficiary or not. The period starts with the first transfer of the user's accounts to the account, the amount is
+// added to what they sent it since. The user's own accounts are exempt.
+func (s *BeneficiaryService) CheckCoolingOff(ctx context.Context, userID uuid.UUID, toAccount *domain.BankAccount, amount float64) error {
+	if s.CoolingOffPeriod == 0 || toAccount.UserID == userID {
+		return nil
+	}
+
+	first, err := s.TransactionRepository.FirstTransferredAt(ctx, userID, toAccount.AccountNumber)
+	if err != nil {
+		return err
+	}
+
+	var transferred float64
+
+	if first != nil {
+		if !time.Now().Before(first.Add(s.CoolingOffPeriod)) {
+			return nil
+		}
+
+		if transferred, err = s.TransactionRepository.SumTransferred(ctx, userID, toAccount.AccountNumber, *first); err != nil {
+			return err
+		}
+	}
+
+	if transferred+amount > s.CoolingOffLimit {
+		return ErrBeneficiaryCoolingOff
+	}
+
+	return nil
+}
+
+// holderName returns the masked full name of the account holder, empty when the holder has no customer profile
+func (s *BeneficiaryService) holderName(ctx context.Context, userID uuid.UUID) (string, error) {
+	customer, err := s.CustomerRepository.GetCustomerByUserID(ctx, userID.String())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return utils.MaskName(customer.FullName), nil
+}
@@ -1,88 +1,132 @@
 package services
 
 import (
-	"errors"
+	"context"
 
 	"github.com/okyws/dashboard-backend/domain"
 	"github.com/okyws/dashboard-backend/ports"
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrCustomerExists is returned when the user already has a customer profile
+	ErrCustomerExists = domain.NewError(domain.ErrorConflict, "customer_exists", "user already has a customer profile")
+
+	// ErrCustomerNotFound is returned when updating a customer that does not exist
+	ErrCustomerNotFound = domain.NewError(domain.ErrorNotFound, "customer_not_found", "customer not found")
+
+	// ErrCustomerIDRequired is returned when updating a customer without its ID
+	ErrCustomerIDRequired = domain.NewError(domain.ErrorValidation, "customer_id_required", "customer ID is required")
+)
+
 // CustomerService is the implementation of the customer service
 type CustomerService struct {
+	work               ports.UnitOfWork
 	CustomerRepository ports.CustomerRepository
 	UserRepository     ports.UserRepository // To validate UserID before creating a customer
+	OutboxRepository   ports.OutboxRepository
 }
 
 // NewCustomerService creates a new customer service via dependency injection
-func NewCustomerService(customerRepo ports.CustomerRepository, userRepo ports.UserRepository) *CustomerService {
+func NewCustomerService(work ports.UnitOfWork, customerRepo ports.CustomerRepository, userRepo ports.UserRepository,
+	outboxRepo ports.OutboxRepository) *CustomerService {
 	return &CustomerService{
+		work:               work,
 		CustomerRepository: customerRepo,
 		UserRepository:     userRepo,
+		OutboxRepository:   outboxRepo,
 	}
 }
 
-// CreateCustomer inserts a new customer into the database with UserID validation
-func (s *CustomerService) CreateCustomer(customer *domain.Customer) (*domain.Customer, error) {
-	existingUser, err := s.UserRepository.GetByID(customer.UserID.String())
+// CreateCustomer inserts a new customer into the database with UserID validation, together with its customer.created
+// event
+func (s *CustomerService) CreateCustomer(ctx context.Context, customer *domain.Customer) (*domain.Customer, error) {
+	existingUser, err := s.UserRepository.GetByID(ctx, customer.UserID.String())
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, gorm.ErrRecordNotFound
+			return nil, ErrUserNotFound.WithCause(err)
 		}
 
 		return nil, err
 	}
 
 	if existingUser == nil {
-		return nil, errors.New("user ID does not exist")
+		return nil, ErrUserNotFound
 	}
 
-	existingCustomer, err := s.CustomerRepository.GetCustomerByUserID(customer.UserID.String())
+	existingCustomer, err := s.CustomerRepository.GetCustomerByUserID(ctx, customer.UserID.String())
 	if err != nil && err != gorm.ErrRecordNotFound {
 		return nil, err
 	}
 
 	if existingCustomer != nil {
-		return nil, errors.New("user already has a customer profile")
+		return nil, ErrCustomerExists
 	}
 
-	return s.CustomerRepository.Create(customer)
+	return recordChange(ctx, s.work, s.OutboxRepository, func(ctx context.Context) (*domain.Customer, domain.Event, error) {
+		createdCustomer, err := s.CustomerRepository.Create(ctx, customer)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return createdCustomer, domain.CustomerCreated{CustomerDTO: *domain.MapCustomerToDTO(createdCustomer)}, nil
+	})
 }
 
 // GetCustomerByID fetches a customer by ID
-func (s *CustomerService) GetCustomerByID(id string) (*domain.Customer, error) {
-	return s.CustomerRepository.GetByID(id)
+func (s *CustomerService) GetCustomerByID(ctx context.Context, id string) (*domain.Customer, error) {
+	return s.CustomerRepository.GetByID(ctx, id)
 }
 
 // GetCustomerByUserID fetches a customer by UserID
-func (s *CustomerService) GetCustomerByUserID(userID string) (*domain.Customer, error) {
-	return s.CustomerRepository.GetCustomerByUserID(userID)
+func (s *CustomerService) GetCustomerByUserID(ctx context.Context, userID string) (*domain.Customer, error) {
+	return s.CustomerRepository.GetCustomerByUserID(ctx, userID)
 }
 
-// UpdateCustomer updates an existing customer
-func (s *CustomerService) UpdateCustomer(customer *domain.Customer) (*domain.Customer, error) {
+// UpdateCustomer updates an existing customer together with its customer.updated event
+func (s *CustomerService) UpdateCustomer(ctx context.Context, customer *domain.Customer) (*domain.Customer, error) {
 	if customer.ID.String() == "" {
-		return nil, errors.New("customer ID is required")
+		return nil, ErrCustomerIDRequired
 	}
 
-	existingCustomer, err := s.CustomerRepository.GetByID(customer.ID.String())
+	existingCustomer, err := s.CustomerRepository.GetByID(ctx, customer.ID.String())
 	if err != nil && err != gorm.ErrRecordNotFound {
 		return nil, err
 	}
 
 	if existingCustomer == nil {
-		return nil, errors.New("customer not found")
+		return nil, ErrCustomerNotFound
 	}
 
-	return s.CustomerRepository.Update(customer)
+	return recordChange(ctx, s.work, s.OutboxRepository, func(ctx context.Context) (*domain.Customer, domain.Event, error) {
+		updatedCustomer, err := s.CustomerRepository.Update(ctx, customer)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return updatedCustomer, domain.CustomerUpdated{CustomerDTO: *domain.MapCustomerToDTO(updatedCustomer)}, nil
+	})
+}
+
+// PatchCustomer writes the changed columns of a customer, zero values included, at the version, any version when it is
+// 0, and records the customer.updated event when a column changed
+func (s *CustomerService) PatchCustomer(ctx context.Context, id string, version uint, changes map[string]any) (*domain.Customer, error) {
+	return recordChange(ctx, s.work, s.OutboxRepository, func(ctx context.Context) (*domain.Customer, domain.Event, error) {
+		patchedCustomer, err := s.CustomerRepository.Patch(ctx, id, version, changes)
+		if err != nil || len(changes) == 0 {
+			return patchedCustomer, nil, err
+		}
+
+		return patchedCustomer, domain.CustomerUpdated{CustomerDTO: *domain.MapCustomerToDTO(patchedCustomer)}, nil
+	})
 }
 
-// DeleteCustomer removes a customer
-func (s *CustomerService) DeleteCustomer(id string) error {
-	return s.CustomerRepository.Delete(id)
+// DeleteCustomer removes a customer at the version, any version when it is 0
+func (s *CustomerService) DeleteCustomer(ctx context.Context, id string, version uint) error {
+	return s.CustomerRepository.DeleteVersion(ctx, id, version)
 }
 
 // GetAllCustomers fetches all customers with pagination
-func (s *CustomerService) GetAllCustomers(limit, offset int) ([]domain.Customer, error) {
-	return s.CustomerRepository.GetAll(limit, offset)
+func (s *CustomerService) GetAllCustomers(ctx context.Context, limit, offset int) ([]domain.Customer, error) {
+	return s.CustomerRepository.GetAll(ctx, limit, offset)
 }
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"sync"
+
+	"github.com/okyws/dashboard-backend/ports"
+)
+
+// AllEvents subscribes a handler to every event type
+const AllEvents = "*"
+
+// EventBus is the in-process implementation of the domain event bus
+type EventBus struct {
+	mu          sync.RWMutex
+	subscribers map[string][]ports.EventSubscriber
+}
+
+// NewEventBus creates a new in-process event bus
+func NewEventBus() *EventBus {
+	return &EventBus{subscribers: make(map[string][]ports.EventSubscriber)}
+}
+
+// Subscribe registers a handler for an event type, or for every event with AllEvents. The name identifies the handler
+// across restarts, so it has to be unique and stay the same.
+func (b *EventBus) Subscribe(eventType, name string, handler ports.EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subscribers[eventType] = append(b.subscribers[eventType], ports.EventSubscriber{Name: name, Handle: handler})
+}
+
+// Subscribers returns the handlers subscribed to the event type, those subscribed to every event last
+func (b *EventBus) Subscribers(eventType string) []ports.EventSubscriber {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	subscribers := make([]ports.EventSubscriber, 0, len(b.subscribers[eventType])+len(b.subscribers[AllEvents]))
+	subscribers = append(subscribers, b.subscribers[eventType]...)
+
+	return append(subscribers, b.subscribers[AllEvents]...)
+}
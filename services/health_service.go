@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/okyws/dashboard-backend/dto"
+	"github.com/okyws/dashboard-backend/ports"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultHealthCheckTimeout bounds how long a single dependency check may take
+const DefaultHealthCheckTimeout = 2 * time.Second
+
+// HealthService checks the dependencies for the readiness probe, readiness fails once the server starts draining
+type HealthService struct {
+	checks   []ports.HealthCheck
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+// NewHealthService creates a new health service for the checks
+func NewHealthService(checks ...ports.HealthCheck) *HealthService {
+	return &HealthService{checks: checks, timeout: DefaultHealthCheckTimeout}
+}
+
+// Drain makes the readiness probe fail so load balancers stop routing before the server shuts down
+func (s *HealthService) Drain() {
+	if !s.draining.Swap(true) {
+		log.Info().Msg("Readiness set to draining")
+	}
+}
+
+// Readiness runs every check concurrently and reports whether the application can serve requests
+func (s *HealthService) Readiness(ctx context.Context) (*dto.ReadinessResponseDTO, bool) {
+	response := &dto.ReadinessResponseDTO{Status: dto.HealthStatusOK, Checks: make(map[string]dto.HealthCheckDTO, len(s.checks))}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	for _, check := range s.checks {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, s.timeout)
+			defer cancel()
+
+			start := time.Now()
+			result := dto.HealthCheckDTO{Status: dto.HealthStatusOK}
+
+			if err := check.Check(checkCtx); err != nil {
+				logger(ctx).Warn().Err(err).Str("check", check.Name()).Msg("Readiness check failed")
+
+				result.Status = dto.HealthStatusFail
+				result.Error = err.Error()
+			}
+
+			result.Duration = time.Since(start).String()
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			response.Checks[check.Name()] = result
+			if result.Status != dto.HealthStatusOK {
+				response.Status = dto.HealthStatusFail
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if s.draining.Load() {
+		response.Status = dto.HealthStatusDraining
+	}
+
+	return response, response.Status == dto.HealthStatusOK
+}
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/dto"
+)
+
+// maxImportLine is the longest line of a JSON Lines import
+const maxImportLine = 1 << 20
+
+var (
+	// ErrImportFile is returned for an import file that cannot be read as a whole
+	ErrImportFile = domain.NewError(domain.ErrorValidation, "invalid_import_file", "import file could not be read")
+
+	// ErrImportRow is returned for a row of an import that cannot be read
+	ErrImportRow = domain.NewError(domain.ErrorValidation, "invalid_row", "row could not be read")
+)
+
+// importRow is a row of an import file with the line it starts on, err says why the row could not be read
+type importRow struct {
+	line int
+	row  dto.ImportRowDTO
+	err  error
+}
+
+// importColumnSetter sets the field of a row a CSV column holds
+type importColumnSetter func(row *dto.ImportRowDTO, value string) error
+
+// importColumns set the field of a row named by a CSV column
+var importColumns = map[string]importColumnSetter{
+	"type":          func(row *dto.ImportRowDTO, value string) error { row.Type = value; return nil },
+	"email":         func(row *dto.ImportRowDTO, value string) error { row.Email = value; return nil },
+	"username":      func(row *dto.ImportRowDTO, value string) error { row.Username = value; return nil },
+	"password":      func(row *dto.ImportRowDTO, value string) error { row.Password = value; return nil },
+	"role":          func(row *dto.ImportRowDTO, value string) error { row.Role = value; return nil },
+	"full_name":     func(row *dto.ImportRowDTO, value string) error { row.FullName = value; return nil },
+	"phone_number":  func(row *dto.ImportRowDTO, value string) error { row.PhoneNumber = value; return nil },
+	"date_of_birth": func(row *dto.ImportRowDTO, value string) error { row.DateOfBirth = value; return nil },
+	"address":       func(row *dto.ImportRowDTO, value string) error { row.Address = value; return nil },
+	"account_type":  func(row *dto.ImportRowDTO, value string) error { row.AccountType = value; return nil },
+	"balance": func(row *dto.ImportRowDTO, value string) error {
+		if value == "" {
+			return nil
+		}
+
+		balance, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			// reported like a string balance of a JSON row
+			return &json.UnmarshalTypeError{Value: "string", Type: reflect.TypeOf(balance), Field: "balance"}
+		}
+
+		row.Balance = balance
+
+		return nil
+	},
+}
+
+// readImportRows reads the rows of an import file. A file that cannot be read as a whole is ErrImportFile, a single
+// row that cannot be read keeps its error.
+func readImportRows(format string, payload []byte) ([]importRow, error) {
+	var (
+		rows []importRow
+		err  error
+	)
+
+	switch format {
+	case domain.ImportFormatCSV:
+		rows, err = readCSVRows(payload)
+	case domain.ImportFormatJSONLines:
+		rows, err = readJSONLines(payload)
+	default:
+		return nil, ErrImportFile.WithMessage("import format must be csv or jsonl")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, ErrImportFile.WithMessage("import file has no rows")
+	}
+
+	return rows, nil
+}
+
+// readCSVRows reads a CSV file whose header names the fields of the rows, every column must be known
+func readCSVRows(payload []byte) ([]importRow, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(payload, []byte("\ufeff"))))
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, ErrImportFile.WithMessage("import file is not valid CSV").WithCause(err)
+	}
+
+	setters, err := csvColumnSetters(header)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]importRow, 0)
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+
+		// a record with too many or too few fields is still returned, every other error stops the reader
+		if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+			return nil, ErrImportFile.WithMessage("import file is not valid CSV").WithCause(err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, parseCSVRecord(line, record, setters, err))
+	}
+}
+
+// csvColumnSetters maps the columns of the header to the setters of the fields they hold
+func csvColumnSetters(header []string) ([]importColumnSetter, error) {
+	setters := make([]importColumnSetter, len(header))
+
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(column))
+
+		setter, ok := importColumns[column]
+		if !ok {
+			return nil, ErrImportFile.WithDetails(domain.FieldError{Field: column, Code: "unknown_column", Message: "column is not known"})
+		}
+
+		setters[i] = setter
+	}
+
+	return setters, nil
+}
+
+// parseCSVRecord sets the fields of a row from the values of a record, stopping at the first value that does not parse.
+// A record with the wrong number of fields is a failed row.
+func parseCSVRecord(line int, record []string, setters []importColumnSetter, fieldCountErr error) importRow {
+	row := importRow{line: line}
+
+	if fieldCountErr != nil {
+		row.err = ErrImportRow.WithMessage("row does not have a value for every column").WithCause(fieldCountErr)
+		return row
+	}
+
+	for i := 0; i < len(record) && i < len(setters) && row.err == nil; i++ {
+		row.err = setters[i](&row.row, strings.TrimSpace(record[i]))
+	}
+
+	return row
+}
+
+// readJSONLines reads a file of one JSON object per line, blank lines are skipped
+func readJSONLines(payload []byte) ([]importRow, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(payload))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLine)
+
+	rows := make([]importRow, 0)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		row := importRow{line: line}
+
+		var typeErr *json.UnmarshalTypeError
+		if err := json.Unmarshal(text, &row.row); errors.As(err, &typeErr) {
+			row.err = err
+		} else if err != nil {
+			row.err = ErrImportRow.WithMessage("row is not a valid JSON object").WithCause(err)
+		}
+
+		rows = append(rows, row)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, ErrImportFile.WithMessage("import file is not valid JSON Lines").WithCause(err)
+	}
+
+	return rows, nil
+}
+
+// importRowType is the type of a row, which picks the create request it is validated as
+type importRowType struct {
+	Type string `json:"type" binding:"required,oneof=user customer account"`
+}
+
+// importRowOwner is the user a customer or account row belongs to
+type importRowOwner struct {
+	Username string `json:"username" binding:"required"`
+}
+
+// importRowBirthDate is the date of birth of a customer row, parsed by the create request handler
+type importRowBirthDate struct {
+	DateOfBirth string `json:"date_of_birth" binding:"omitempty,datetime=2006-01-02"`
+}
+
+// validateImportRow checks a row with the rules of the create request of its type. Customers and accounts name their
+// user by the username instead of the user_id of the requests, the user may be a row of the same file.
+func validateImportRow(row *dto.ImportRowDTO) error {
+	if err := binding.Validator.ValidateStruct(importRowType{Type: row.Type}); err != nil {
+		return err
+	}
+
+	failed := make(validator.ValidationErrors, 0)
+
+	for _, request := range importRequests(row) {
+		err := binding.Validator.ValidateStruct(request)
+
+		var validationErrs validator.ValidationErrors
+		if err != nil && !errors.As(err, &validationErrs) {
+			return err
+		}
+
+		failed = append(failed, withoutUserID(validationErrs)...)
+	}
+
+	if len(failed) > 0 {
+		return failed
+	}
+
+	return nil
+}
+
+// importRequests returns the requests a row of its type is validated as
+func importRequests(row *dto.ImportRowDTO) []any {
+	switch row.Type {
+	case domain.ImportRowUser:
+		return []any{newImportUser(row)}
+	case domain.ImportRowCustomer:
+		return []any{importRowOwner{Username: row.Username}, newImportCustomer(row, uuid.Nil), importRowBirthDate{DateOfBirth: row.DateOfBirth}}
+	case domain.ImportRowAccount:
+		return []any{importRowOwner{Username: row.Username}, newImportAccount(row, uuid.Nil)}
+	}
+
+	return nil
+}
+
+// withoutUserID drops the errors of the user_id of a request, rows name their user by username instead
+func withoutUserID(validationErrs validator.ValidationErrors) validator.ValidationErrors {
+	kept := make(validator.ValidationErrors, 0, len(validationErrs))
+
+	for _, fieldErr := range validationErrs {
+		if fieldErr.Field() != "user_id" {
+			kept = append(kept, fieldErr)
+		}
+	}
+
+	return kept
+}
+
+// newImportUser returns the create request of a user row
+func newImportUser(row *dto.ImportRowDTO) dto.UserCreateDTO {
+	return dto.UserCreateDTO{Email: row.Email, Username: row.Username, Password: row.Password, Role: row.Role}
+}
+
+// newImportCustomer returns the create request of a customer row of the user
+func newImportCustomer(row *dto.ImportRowDTO, userID uuid.UUID) dto.CustomerCreateDTO {
+	return dto.CustomerCreateDTO{UserID: userID, FullName: row.FullName, PhoneNumber: row.PhoneNumber, DateOfBirth: row.DateOfBirth,
+		Address: row.Address}
+}
+
+// newImportAccount returns the create request of an account row of the user
+func newImportAccount(row *dto.ImportRowDTO, userID uuid.UUID) dto.BankAccountCreateDTO {
+	return dto.BankAccountCreateDTO{UserID: userID, AccountType: row.AccountType, Balance: row.Balance}
+}
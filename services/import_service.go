@@ -0,0 +1,397 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/okyws/dashboard-backend/constants"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/dto"
+	"github.com/okyws/dashboard-backend/i18n"
+	"github.com/okyws/dashboard-backend/ports"
+	"github.com/okyws/dashboard-backend/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrImportNotFound is returned when no import job has the ID
+	ErrImportNotFound = domain.NewError(domain.ErrorNotFound, "import_not_found", "import not found")
+
+	// ErrImportMode is returned for an import mode other than best_effort and all_or_nothing
+	ErrImportMode = domain.NewError(domain.ErrorValidation, "invalid_import_mode", "mode must be best_effort or all_or_nothing")
+)
+
+// msgImportInterrupted is the error of an import stopped by the shutdown of its worker
+const msgImportInterrupted = "import was interrupted, the rows after the processed ones were not imported"
+
+// ErrorMapper returns the status code, error code, message and field details an error is answered with, the report of
+// an import describes its failed rows the same way
+type ErrorMapper func(language string, err error) (int, string, string, []dto.FieldErrorDTO)
+
+// ImportService imports users, customers and accounts in bulk. Submitted imports are run in the background by Start,
+// a best effort import writes every valid row on its own and an all or nothing import writes its rows in a single
+// transaction once all of them passed the validation, so its progress is only known when it finished.
+type ImportService struct {
+	work             ports.UnitOfWork
+	ImportRepository ports.ImportRepository
+	repositories     ports.ImportRepositories
+	mapError         ErrorMapper
+	PollInterval     time.Duration
+	ProgressEvery    int
+}
+
+// NewImportService creates a new import service via dependency injection, the rows of an all or nothing import are
+// written with the repositories in a single unit of work
+func NewImportService(work ports.UnitOfWork, importRepo ports.ImportRepository, repositories ports.ImportRepositories,
+	mapError ErrorMapper) *ImportService {
+	return &ImportService{
+		work:             work,
+		ImportRepository: importRepo,
+		repositories:     repositories,
+		mapError:         mapError,
+		PollInterval:     2 * time.Second,
+		ProgressEvery:    50,
+	}
+}
+
+// Submit stores an import of the file to be run in the background. The file is read first, a file that cannot be read
+// is refused right away and the rows are only validated by the import.
+func (s *ImportService) Submit(ctx context.Context, format, mode string, payload []byte) (*domain.ImportJob, error) {
+	job, err := newImportJob(ctx, format, mode, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.ImportRepository.Create(ctx, job)
+}
+
+// Import runs an import of the file right away and returns it finished
+func (s *ImportService) Import(ctx context.Context, format, mode string, payload []byte) (*domain.ImportJob, error) {
+	job, err := newImportJob(ctx, format, mode, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	// running from the start, so no worker claims it
+	now := time.Now()
+	job.Status = domain.ImportStatusRunning
+	job.StartedAt = &now
+
+	if job, err = s.ImportRepository.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	return job, s.Run(ctx, job)
+}
+
+// newImportJob returns the job of an import of the file, best effort unless the mode says otherwise
+func newImportJob(ctx context.Context, format, mode string, payload []byte) (*domain.ImportJob, error) {
+	if mode == "" {
+		mode = domain.ImportModeBestEffort
+	}
+
+	if mode != domain.ImportModeBestEffort && mode != domain.ImportModeAllOrNothing {
+		return nil, ErrImportMode
+	}
+
+	rows, err := readImportRows(format, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.ImportJob{
+		Format:    format,
+		Mode:      mode,
+		Language:  i18n.FromContext(ctx),
+		Payload:   string(payload),
+		TotalRows: len(rows),
+	}, nil
+}
+
+// GetJob fetches an import job by ID
+func (s *ImportService) GetJob(ctx context.Context, id string) (*domain.ImportJob, error) {
+	job, err := s.ImportRepository.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrImportNotFound.WithCause(err)
+	}
+
+	return job, err
+}
+
+// GetJobs fetches the import jobs with pagination, the latest first
+func (s *ImportService) GetJobs(ctx context.Context, limit, offset int) ([]domain.ImportJob, error) {
+	return s.ImportRepository.GetAll(ctx, limit, offset)
+}
+
+// GetRowErrors fetches the problems with the rows of an import job, complete once the job finished
+func (s *ImportService) GetRowErrors(ctx context.Context, id string) ([]domain.ImportRowError, error) {
+	if _, err := s.GetJob(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.ImportRepository.GetRowErrors(ctx, id)
+}
+
+// Start runs the submitted imports until the context is cancelled
+func (s *ImportService) Start(ctx context.Context) {
+	logger(ctx).Info().Msg("Import worker started")
+
+	ticker := time.NewTicker(s.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			logger(ctx).Info().Msg("Import worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce runs the submitted imports one after another until none is left
+func (s *ImportService) RunOnce(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := s.ImportRepository.ClaimNext(ctx)
+		if err != nil {
+			logger(ctx).Error().Err(err).Msg("Failed to claim the next import")
+			return
+		}
+
+		if job == nil {
+			return
+		}
+
+		if err := s.Run(ctx, job); err != nil {
+			logger(ctx).Error().Err(err).Str("import_id", job.ID.String()).Msg("Failed to record the outcome of the import")
+		}
+	}
+}
+
+// Run imports the rows of a running job and records its outcome and the problems with its rows
+func (s *ImportService) Run(ctx context.Context, job *domain.ImportJob) error {
+	var rowErrors []domain.ImportRowError
+
+	rows, err := readImportRows(job.Format, []byte(job.Payload))
+	if err != nil {
+		// the file was read when it was submitted, it only fails here when it was changed since
+		job.Error = i18n.Translate(job.Language, ErrImportFile.Message)
+	} else if job.Mode == domain.ImportModeAllOrNothing {
+		rowErrors = s.importAll(ctx, job, rows)
+	} else {
+		rowErrors = s.importEach(ctx, job, rows)
+	}
+
+	switch {
+	case job.Error != "", job.ImportedRows == 0:
+		job.Status = domain.ImportStatusFailed
+	case job.FailedRows > 0:
+		job.Status = domain.ImportStatusCompletedWithErrors
+	default:
+		job.Status = domain.ImportStatusCompleted
+	}
+
+	// the outcome is recorded even when the worker stops during the import
+	if err := s.ImportRepository.Finish(context.WithoutCancel(ctx), job, rowErrors); err != nil {
+		return err
+	}
+
+	logger(ctx).Info().Str("import_id", job.ID.String()).Str("mode", job.Mode).Str("status", job.Status).Int("imported", job.ImportedRows).
+		Int("failed", job.FailedRows).Msg("Import finished")
+
+	return nil
+}
+
+// importEach writes every valid row on its own and returns the problems with the others
+func (s *ImportService) importEach(ctx context.Context, job *domain.ImportJob, rows []importRow) []domain.ImportRowError {
+	writer := newImportWriter(s.work, s.repositories)
+	rowErrors := make([]domain.ImportRowError, 0)
+
+	for i := range rows {
+		if ctx.Err() != nil {
+			job.Error = i18n.Translate(job.Language, msgImportInterrupted)
+			break
+		}
+
+		err := rows[i].err
+		if err == nil {
+			err = validateImportRow(&rows[i].row)
+		}
+
+		if err == nil {
+			err = writer.write(ctx, &rows[i].row)
+		}
+
+		if err != nil {
+			rowErrors = append(rowErrors, s.rowErrors(ctx, job, rows[i].line, err)...)
+			job.FailedRows++
+		} else {
+			job.ImportedRows++
+		}
+
+		job.ProcessedRows++
+
+		if s.ProgressEvery > 0 && job.ProcessedRows%s.ProgressEvery == 0 {
+			if err := s.ImportRepository.UpdateProgress(ctx, job); err != nil {
+				logger(ctx).Warn().Err(err).Str("import_id", job.ID.String()).Msg("Failed to save the progress of the import")
+			}
+		}
+	}
+
+	return rowErrors
+}
+
+// importAll validates every row and writes them in a single transaction when all of them are valid. The first row that
+// cannot be written rolls back the rows written before it, the rows after it are not tried.
+func (s *ImportService) importAll(ctx context.Context, job *domain.ImportJob, rows []importRow) []domain.ImportRowError {
+	rowErrors := make([]domain.ImportRowError, 0)
+
+	for i := range rows {
+		err := rows[i].err
+		if err == nil {
+			err = validateImportRow(&rows[i].row)
+		}
+
+		if err != nil {
+			rowErrors = append(rowErrors, s.rowErrors(ctx, job, rows[i].line, err)...)
+			job.FailedRows++
+		}
+	}
+
+	if len(rowErrors) > 0 {
+		job.ProcessedRows = len(rows)
+		return rowErrors
+	}
+
+	writer := newImportWriter(s.work, s.repositories)
+
+	err := s.work.Do(ctx, func(ctx context.Context) error {
+
+		for i := range rows {
+			job.ProcessedRows = i + 1
+
+			if err := writer.write(ctx, &rows[i].row); err != nil {
+				rowErrors = s.rowErrors(ctx, job, rows[i].line, err)
+				job.FailedRows = 1
+
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	switch {
+	case err == nil:
+		job.ImportedRows = len(rows)
+	case ctx.Err() != nil:
+		job.Error = i18n.Translate(job.Language, msgImportInterrupted)
+	case len(rowErrors) == 0:
+		// the rows were written but not committed
+		logger(ctx).Error().Err(err).Str("import_id", job.ID.String()).Msg("Failed to commit the import")
+		job.Error = i18n.Translate(job.Language, constants.MsgInternalError)
+	}
+
+	return rowErrors
+}
+
+// rowErrors describes the error of the row at the line like the API answers it, one row error per field
+func (s *ImportService) rowErrors(ctx context.Context, job *domain.ImportJob, line int, err error) []domain.ImportRowError {
+	status, code, message, details := s.mapError(job.Language, err)
+	if status == http.StatusInternalServerError {
+		logger(ctx).Error().Err(err).Str("import_id", job.ID.String()).Int("line", line).Msg("Unexpected error importing a row")
+	}
+
+	if len(details) == 0 {
+		return []domain.ImportRowError{{Line: line, Code: code, Message: i18n.Translate(job.Language, message)}}
+	}
+
+	rowErrors := make([]domain.ImportRowError, len(details))
+	for i, detail := range details {
+		rowErrors[i] = domain.ImportRowError{Line: line, Field: detail.Field, Code: detail.Code,
+			Message: i18n.Translate(job.Language, detail.Message)}
+	}
+
+	return rowErrors
+}
+
+// WriteImportReport writes the problems with the rows of an import as CSV, one line per row and field
+func WriteImportReport(w io.Writer, rowErrors []domain.ImportRowError) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write([]string{"line", "field", "code", "message"}); err != nil {
+		return err
+	}
+
+	for _, rowErr := range rowErrors {
+		if err := writer.Write([]string{strconv.Itoa(rowErr.Line), rowErr.Field, rowErr.Code, rowErr.Message}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// importWriter creates the users, customers and accounts of the rows with the rules of their services
+type importWriter struct {
+	users     *UserService
+	customers *CustomerService
+	accounts  *BankAccountService
+}
+
+// newImportWriter creates the services of an import over its repositories
+func newImportWriter(work ports.UnitOfWork, repositories ports.ImportRepositories) *importWriter {
+	return &importWriter{
+		users:     NewUserService(work, repositories.Users, repositories.Outbox),
+		customers: NewCustomerService(work, repositories.Customers, repositories.Users, repositories.Outbox),
+		accounts: NewBankAccountService(work, repositories.Users, repositories.BankAccounts, repositories.Outbox,
+			NewAccountValidator(repositories.Users, repositories.BankAccounts)),
+	}
+}
+
+// write creates the user, customer or account of a valid row
+func (w *importWriter) write(ctx context.Context, row *dto.ImportRowDTO) error {
+	if row.Type == domain.ImportRowUser {
+		_, err := w.users.CreateUser(ctx, &domain.User{Email: row.Email, Username: row.Username, Password: row.Password, Role: row.Role})
+
+		return err
+	}
+
+	owner, err := w.users.GetUserByUsername(ctx, row.Username)
+	if errors.Is(err, gorm.ErrRecordNotFound) || err == nil && owner == nil {
+		return ErrUserNotFound.WithDetails(domain.FieldError{Field: "username", Code: "user_not_found", Message: "no user has this username"})
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if row.Type == domain.ImportRowAccount {
+		account := newImportAccount(row, owner.ID)
+		_, err = w.accounts.CreateBankAccount(ctx, &domain.BankAccount{UserID: account.UserID, AccountType: account.AccountType,
+			Balance: account.Balance})
+
+		return err
+	}
+
+	customer := newImportCustomer(row, owner.ID)
+
+	dateOfBirth, err := utils.FormatDate(customer.DateOfBirth)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.customers.CreateCustomer(ctx, &domain.Customer{UserID: customer.UserID, FullName: customer.FullName,
+		PhoneNumber: customer.PhoneNumber, DateOfBirth: *dateOfBirth, Address: customer.Address})
+
+	return err
+}
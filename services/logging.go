@@ -0,0 +1,13 @@
+package services
+
+import (
+	"context"
+
+	"github.com/okyws/dashboard-backend/utils"
+	"github.com/rs/zerolog"
+)
+
+// logger returns the logger of the request in the context, the services log as the services component of LOG_LEVELS
+func logger(ctx context.Context) *zerolog.Logger {
+	return utils.Logger(ctx, "services")
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/ports"
+	"gorm.io/gorm"
+)
+
+// ErrUnknownCategory is returned when preferences are saved for a category that does not exist
+var ErrUnknownCategory = domain.NewError(domain.ErrorValidation, "unknown_category", "unknown notification category")
+
+// NotificationService creates notifications from domain events and manages the notification center of a user
+type NotificationService struct {
+	NotificationRepository   ports.NotificationRepository
+	UserRepository           ports.UserRepository
+	CustomerRepository       ports.CustomerRepository
+	BankInfoRepository       ports.BankAccountRepository
+	Channels                 map[string]ports.NotificationChannel
+	LargeWithdrawalThreshold float64
+}
+
+// NewNotificationService creates a new notification service delivering through the given channels
+func NewNotificationService(notificationRepo ports.NotificationRepository, userRepo ports.UserRepository, customerRepo ports.CustomerRepository,
+	bankInfoRepo ports.BankAccountRepository, channels ...ports.NotificationChannel) *NotificationService {
+	service := &NotificationService{
+		NotificationRepository:   notificationRepo,
+		UserRepository:           userRepo,
+		CustomerRepository:       customerRepo,
+		BankInfoRepository:       bankInfoRepo,
+		Channels:                 make(map[string]ports.NotificationChannel, len(channels)),
+		LargeWithdrawalThreshold: 5000000,
+	}
+
+	for _, channel := range channels {
+		service.Channels[channel.Name()] = channel
+	}
+
+	return service
+}
+
+// HandleEvent is the event bus subscriber turning domain events into notifications
+func (s *NotificationService) HandleEvent(ctx context.Context, envelope *domain.EventEnvelope) error {
+	switch e := envelope.Event.(type) {
+	case domain.TransactionPosted:
+		return s.handleTransaction(ctx, envelope.ID, e)
+	case domain.AccountFrozen:
+		return s.notify(ctx, envelope.ID, e.UserID, domain.NotificationAccountStatus, "Account frozen",
+			fmt.Sprintf("Your %s account %s has been frozen.", e.AccountType, e.AccountNumber))
+	case domain.AccountActivated:
+		return s.notify(ctx, envelope.ID, e.UserID, domain.NotificationAccountStatus, "Account activated",
+			fmt.Sprintf("Your %s account %s is active again.", e.AccountType, e.AccountNumber))
+	case domain.LoginFailed:
+		return s.notify(ctx, envelope.ID, e.UserID, domain.NotificationLoginFailed, "Failed login attempt",
+			fmt.Sprintf("Someone tried to log in as %s with a wrong password from %s.", e.Username, e.IPAddress))
+	default:
+		return nil
+	}
+}
+
+// handleTransaction notifies about deposits, incoming transfers and large withdrawals
+func (s *NotificationService) handleTransaction(ctx context.Context, eventID uuid.UUID, transaction domain.TransactionPosted) error {
+	switch transaction.TransactionType {
+	case "deposit":
+		return s.notifyAccountOwner(ctx, eventID, transaction.ToAccountNumber, domain.NotificationDeposit, "Deposit received",
+			fmt.Sprintf("%.2f was deposited to account %s.", transaction.Amount, transaction.ToAccountNumber))
+	case "transfer":
+		return s.notifyAccountOwner(ctx, eventID, transaction.ToAccountNumber, domain.NotificationIncomingTransfer, "Incoming transfer",
+			fmt.Sprintf("You received %.2f from account %s on account %s.", transaction.Amount, transaction.FromAccountNumber, transaction.ToAccountNumber))
+	case "withdraw":
+		if transaction.Amount < s.LargeWithdrawalThreshold {
+			return nil
+		}
+
+		return s.notifyAccountOwner(ctx, eventID, transaction.FromAccountNumber, domain.NotificationLargeWithdrawal, "Large withdrawal",
+			fmt.Sprintf("%.2f was withdrawn from account %s.", transaction.Amount, transaction.FromAccountNumber))
+	default:
+		return nil
+	}
+}
+
+// notifyAccountOwner notifies the user holding the account
+func (s *NotificationService) notifyAccountOwner(ctx context.Context, eventID uuid.UUID, accountNumber, category, title, message string) error {
+	account, err := s.BankInfoRepository.GetByAccountNumber(ctx, accountNumber)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return s.notify(ctx, eventID, account.UserID, category, title, message)
+}
+
+// notify sends a notification on every channel the user enabled for the category.
+// Only failures to store the in-app notification are returned so the event is retried,
+// email and SMS failures are logged to avoid sending the same message again on retry.
+func (s *NotificationService) notify(ctx context.Context, eventID, userID uuid.UUID, category, title, message string) error {
+	preference, err := s.preference(ctx, userID, category)
+	if err != nil {
+		return err
+	}
+
+	channels := preference.Channels()
+	if len(channels) == 0 {
+		return nil
+	}
+
+	recipient, err := s.recipient(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	notification := &domain.Notification{UserID: userID, EventID: eventID, Category: category, Title: title, Message: message}
+
+	for _, name := range channels {
+		channel, ok := s.Channels[name]
+		if !ok {
+			continue
+		}
+
+		if err := channel.Send(ctx, recipient, notification); err != nil {
+			if name == domain.ChannelInApp {
+				return err
+			}
+
+			logger(ctx).Error().Err(err).Str("channel", name).Str("user_id", userID.String()).Msg("Failed to send notification")
+		}
+	}
+
+	return nil
+}
+
+// recipient collects the contact details of a user, the phone number comes from the customer profile when there is one
+func (s *NotificationService) recipient(ctx context.Context, userID uuid.UUID) (*domain.NotificationRecipient, error) {
+	user, err := s.UserRepository.GetByID(ctx, userID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	recipient := &domain.NotificationRecipient{UserID: user.ID, Email: user.Email}
+
+	customer, err := s.CustomerRepository.GetCustomerByUserID(ctx, userID.String())
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if customer != nil {
+		recipient.PhoneNumber = customer.PhoneNumber
+	}
+
+	return recipient, nil
+}
+
+// preference returns the saved preference of the category or the default one
+func (s *NotificationService) preference(ctx context.Context, userID uuid.UUID, category string) (*domain.NotificationPreference, error) {
+	preferences, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range preferences {
+		if preferences[i].Category == category {
+			return &preferences[i], nil
+		}
+	}
+
+	preference := domain.DefaultNotificationPreference(userID, category)
+
+	return &preference, nil
+}
+
+// GetNotifications retrieves the notifications of a user with pagination
+func (s *NotificationService) GetNotifications(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit, offset int) ([]domain.Notification, error) {
+	return s.NotificationRepository.GetByUserID(ctx, userID, unreadOnly, limit, offset)
+}
+
+// MarkRead marks a notification of the user as read
+func (s *NotificationService) MarkRead(ctx context.Context, userID uuid.UUID, id string) (*domain.Notification, error) {
+	return s.NotificationRepository.MarkRead(ctx, userID, id)
+}
+
+// MarkAllRead marks every notification of the user as read
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return s.NotificationRepository.MarkAllRead(ctx, userID)
+}
+
+// GetPreferences returns the preference of every category, using the default for categories the user never changed
+func (s *NotificationService) GetPreferences(ctx context.Context, userID uuid.UUID) ([]domain.NotificationPreference, error) {
+	saved, err := s.NotificationRepository.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences := make([]domain.NotificationPreference, 0, len(domain.NotificationCategories))
+
+	for _, category := range domain.NotificationCategories {
+		preference := domain.DefaultNotificationPreference(userID, category)
+
+		for i := range saved {
+			if saved[i].Category == category {
+				preference = saved[i]
+				break
+			}
+		}
+
+		preferences = append(preferences, preference)
+	}
+
+	return preferences, nil
+}
+
+// UpdatePreferences saves the preferences of a user and returns the resulting preference of every category
+func (s *NotificationService) UpdatePreferences(ctx context.Context, userID uuid.UUID, preferences []domain.NotificationPreference) ([]domain.NotificationPreference, error) {
+	for i := range preferences {
+		if !domain.IsNotificationCategory(preferences[i].Category) {
+			return nil, ErrUnknownCategory.WithDetails(domain.FieldError{Field: "category", Code: "oneof", Message: "must be a notification category"})
+		}
+
+		preferences[i].UserID = userID
+	}
+
+	if err := s.NotificationRepository.SavePreferences(ctx, preferences); err != nil {
+		return nil, err
+	}
+
+	return s.GetPreferences(ctx, userID)
+}
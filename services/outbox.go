@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/ports"
+)
+
+// recordChange runs a change in a unit of work and records the event it returns in the outbox, so the event is
+// committed together with the change or not at all. A change that returns no event records none.
+func recordChange[T any](ctx context.Context, work ports.UnitOfWork, outbox ports.OutboxRepository,
+	change func(ctx context.Context) (T, domain.Event, error)) (T, error) {
+	var result T
+
+	err := work.Do(ctx, func(ctx context.Context) error {
+		changed, event, err := change(ctx)
+		if err != nil {
+			return err
+		}
+
+		result = changed
+
+		if event == nil {
+			return nil
+		}
+
+		return outbox.Record(ctx, event)
+	})
+
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/ports"
+)
+
+// OutboxRelay publishes committed outbox events to the subscribers of the in-process bus and to the configured sinks.
+// Every replica may run one, an event is claimed by a single relay at a time and a failed delivery is retried once the
+// claim expired, only for the subscribers and sinks that did not get the event yet.
+type OutboxRelay struct {
+	OutboxRepository ports.OutboxRepository
+	EventBus         ports.EventBus
+	Sinks            []ports.EventSink
+	PollInterval     time.Duration
+	BatchSize        int
+	ClaimLease       time.Duration
+}
+
+// NewOutboxRelay creates a new outbox relay
+func NewOutboxRelay(outboxRepo ports.OutboxRepository, bus ports.EventBus, sinks ...ports.EventSink) *OutboxRelay {
+	return &OutboxRelay{
+		OutboxRepository: outboxRepo,
+		EventBus:         bus,
+		Sinks:            sinks,
+		PollInterval:     time.Second,
+		BatchSize:        100,
+		ClaimLease:       30 * time.Second,
+	}
+}
+
+// Start runs the relay until the context is cancelled
+func (r *OutboxRelay) Start(ctx context.Context) {
+	logger(ctx).Info().Msg("Outbox relay started")
+
+	ticker := time.NewTicker(r.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		r.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			logger(ctx).Info().Msg("Outbox relay stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce relays the pending events it claimed in creation order, an event stays pending until every subscriber and
+// sink accepted it
+func (r *OutboxRelay) RunOnce(ctx context.Context) {
+	events, err := r.OutboxRepository.ClaimPending(ctx, r.BatchSize, r.ClaimLease)
+	if err != nil {
+		logger(ctx).Error().Err(err).Msg("Failed to fetch pending outbox events")
+		return
+	}
+
+	for i := range events {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err := r.relay(ctx, &events[i]); err != nil {
+			logger(ctx).Error().Err(err).Str("event_id", events[i].ID.String()).Str("event_type", events[i].EventType).Msg("Failed to relay outbox event")
+			continue
+		}
+
+		if err := r.OutboxRepository.MarkDispatched(ctx, events[i].ID); err != nil {
+			logger(ctx).Error().Err(err).Str("event_id", events[i].ID.String()).Msg("Failed to mark outbox event dispatched")
+		}
+	}
+}
+
+// relay hands a single event to every subscriber and sink it was not delivered to yet
+func (r *OutboxRelay) relay(ctx context.Context, event *domain.OutboxEvent) error {
+	envelope, err := domain.DecodeEvent(event)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	for _, subscriber := range r.EventBus.Subscribers(event.EventType) {
+		err := r.deliver(ctx, event, "subscriber:"+subscriber.Name, func() error {
+			return subscriber.Handle(ctx, envelope)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s handler failed: %v", subscriber.Name, err))
+		}
+	}
+
+	for _, sink := range r.Sinks {
+		err := r.deliver(ctx, event, "sink:"+sink.Name(), func() error {
+			return sink.Publish(ctx, event)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s sink failed: %v", sink.Name(), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// deliver hands the event to a subscriber or sink with publish unless it already got it, and records the delivery
+func (r *OutboxRelay) deliver(ctx context.Context, event *domain.OutboxEvent, target string, publish func() error) error {
+	if event.Delivered(target) {
+		return nil
+	}
+
+	if err := publish(); err != nil {
+		return err
+	}
+
+	return r.OutboxRepository.MarkDelivered(ctx, event.ID, target)
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/ports"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// StreamSubscription is a connected client receiving stream messages
+type StreamSubscription struct {
+	UserID   uuid.UUID
+	Firehose bool
+	messages chan *domain.StreamMessage
+}
+
+// Messages returns the channel delivering the messages, it is closed when the client falls behind or the service stops
+func (s *StreamSubscription) Messages() <-chan *domain.StreamMessage {
+	return s.messages
+}
+
+// Accepts reports whether the message is delivered to the subscription
+func (s *StreamSubscription) Accepts(message *domain.StreamMessage) bool {
+	return s.Firehose || message.VisibleTo(s.UserID)
+}
+
+// StreamService pushes balance, account and transaction events to the connected clients of every replica
+type StreamService struct {
+	Broker             ports.StreamBroker
+	OutboxRepository   ports.OutboxRepository
+	BankInfoRepository ports.BankAccountRepository
+	ReplayLimit        int
+	ClientBuffer       int
+	RetryInterval      time.Duration
+
+	mu      sync.Mutex
+	clients map[*StreamSubscription]struct{}
+}
+
+// NewStreamService creates a new stream service
+func NewStreamService(broker ports.StreamBroker, outboxRepo ports.OutboxRepository, bankInfoRepo ports.BankAccountRepository) *StreamService {
+	return &StreamService{
+		Broker:             broker,
+		OutboxRepository:   outboxRepo,
+		BankInfoRepository: bankInfoRepo,
+		ReplayLimit:        500,
+		ClientBuffer:       64,
+		RetryInterval:      5 * time.Second,
+		clients:            make(map[*StreamSubscription]struct{}),
+	}
+}
+
+// HandleEvent is the event bus subscriber publishing every event to the broker
+func (s *StreamService) HandleEvent(ctx context.Context, envelope *domain.EventEnvelope) error {
+	message, err := s.toMessage(ctx, envelope)
+	if err != nil {
+		return err
+	}
+
+	return s.Broker.Publish(ctx, message)
+}
+
+// Start fans out the broker messages to the local clients until the context is cancelled
+func (s *StreamService) Start(ctx context.Context) {
+	logger(ctx).Info().Msg("Stream service started")
+
+	for ctx.Err() == nil {
+		messages, err := s.Broker.Subscribe(ctx)
+		if err != nil {
+			logger(ctx).Error().Err(err).Msg("Failed to subscribe to the stream broker")
+		} else {
+			for message := range messages {
+				s.fanOut(message)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(s.RetryInterval):
+		}
+	}
+
+	s.mu.Lock()
+	for client := range s.clients {
+		s.remove(client)
+	}
+	s.mu.Unlock()
+
+	logger(ctx).Info().Msg("Stream service stopped")
+}
+
+// Subscribe registers a client for the messages of the user's accounts, or for every message with the firehose
+func (s *StreamService) Subscribe(userID uuid.UUID, firehose bool) *StreamSubscription {
+	subscription := &StreamSubscription{
+		UserID:   userID,
+		Firehose: firehose,
+		messages: make(chan *domain.StreamMessage, s.ClientBuffer),
+	}
+
+	s.mu.Lock()
+	s.clients[subscription] = struct{}{}
+	s.mu.Unlock()
+
+	return subscription
+}
+
+// Unsubscribe removes a client
+func (s *StreamService) Unsubscribe(subscription *StreamSubscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.clients[subscription]; ok {
+		s.remove(subscription)
+	}
+}
+
+// Replay returns the messages recorded after the last event the client received
+func (s *StreamService) Replay(ctx context.Context, lastEventID uuid.UUID, subscription *StreamSubscription) ([]*domain.StreamMessage, error) {
+	events, err := s.OutboxRepository.GetAfter(ctx, lastEventID, s.ReplayLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]*domain.StreamMessage, 0, len(events))
+
+	for i := range events {
+		envelope, err := domain.DecodeEvent(&events[i])
+		if err != nil {
+			return nil, err
+		}
+
+		message, err := s.toMessage(ctx, envelope)
+		if err != nil {
+			return nil, err
+		}
+
+		if subscription.Accepts(message) {
+			messages = append(messages, message)
+		}
+	}
+
+	return messages, nil
+}
+
+// fanOut delivers a message to the local clients, a client that fell behind is disconnected and replays on reconnect
+func (s *StreamService) fanOut(message *domain.StreamMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for client := range s.clients {
+		if !client.Accepts(message) {
+			continue
+		}
+
+		select {
+		case client.messages <- message:
+		default:
+			log.Warn().Str("user_id", client.UserID.String()).Msg("Stream client fell behind, disconnecting")
+			s.remove(client)
+		}
+	}
+}
+
+// remove drops a client and closes its channel, the caller holds the lock
+func (s *StreamService) remove(client *StreamSubscription) {
+	delete(s.clients, client)
+	close(client.messages)
+}
+
+// toMessage builds the stream message of an event together with the users owning the affected accounts
+func (s *StreamService) toMessage(ctx context.Context, envelope *domain.EventEnvelope) (*domain.StreamMessage, error) {
+	data, err := json.Marshal(envelope.Event)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs, err := s.owners(ctx, envelope.Event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.StreamMessage{
+		ID:        envelope.ID,
+		Type:      envelope.Event.EventType(),
+		UserIDs:   userIDs,
+		CreatedAt: envelope.CreatedAt,
+		Data:      data,
+	}, nil
+}
+
+// owners returns the users allowed to see an event on their own stream
+func (s *StreamService) owners(ctx context.Context, event domain.Event) ([]uuid.UUID, error) {
+	switch e := event.(type) {
+	case domain.UserCreated:
+		return []uuid.UUID{e.ID}, nil
+	case domain.LoginFailed:
+		return []uuid.UUID{e.UserID}, nil
+	case domain.CustomerCreated:
+		return []uuid.UUID{e.UserID}, nil
+	case domain.CustomerUpdated:
+		return []uuid.UUID{e.UserID}, nil
+	case domain.AccountCreated:
+		return []uuid.UUID{e.UserID}, nil
+	case domain.AccountFrozen:
+		return []uuid.UUID{e.UserID}, nil
+	case domain.AccountActivated:
+		return []uuid.UUID{e.UserID}, nil
+	case domain.BalanceChanged:
+		return []uuid.UUID{e.UserID}, nil
+	case domain.TransactionPosted:
+		return s.accountOwners(ctx, e.FromAccountNumber, e.ToAccountNumber)
+	default:
+		return nil, nil
+	}
+}
+
+// accountOwners resolves the users holding the given account numbers
+func (s *StreamService) accountOwners(ctx context.Context, accountNumbers ...string) ([]uuid.UUID, error) {
+	userIDs := make([]uuid.UUID, 0, len(accountNumbers))
+
+	for _, accountNumber := range accountNumbers {
+		if accountNumber == "" {
+			continue
+		}
+
+		account, err := s.BankInfoRepository.GetByAccountNumber(ctx, accountNumber)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !slices.Contains(userIDs, account.UserID) {
+			userIDs = append(userIDs, account.UserID)
+		}
+	}
+
+	return userIDs, nil
+}
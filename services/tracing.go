@@ -0,0 +1,16 @@
+package services
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer starts the spans of the business operations as children of the span in the context they are given
+var tracer = otel.Tracer("github.com/okyws/dashboard-backend/services")
+
+// failSpan marks the span as failed with the error
+func failSpan(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -1,32 +1,56 @@
 package services
 
 import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
 	"github.com/okyws/dashboard-backend/domain"
 	"github.com/okyws/dashboard-backend/ports"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 )
 
+// ErrTransactionConflict is returned when the accounts of a transaction kept being changed by concurrent transactions
+var ErrTransactionConflict = domain.NewError(domain.ErrorConflict, "transaction_conflict", "accounts were changed by another transaction, try again")
+
+// maxConflictAttempts is the number of times a transaction is tried while an account it updates is changed concurrently
+const maxConflictAttempts = 3
+
 // TransactionService is the implementation of the transaction service
 type TransactionService struct {
-	db                    *gorm.DB
+	work                  ports.UnitOfWork
 	TransactionRepository ports.TransactionRepository
 	BankInfoRepository    ports.BankAccountRepository
 	TransactionValidator  *TransactionValidator
+	BeneficiaryService    *BeneficiaryService
+	metrics               ports.BusinessMetrics
 }
 
 // NewTransactionService creates a new transaction service
-func NewTransactionService(db *gorm.DB, transactionRepo ports.TransactionRepository, bankInfoRepo ports.BankAccountRepository, validator *TransactionValidator) *TransactionService {
+func NewTransactionService(work ports.UnitOfWork, transactionRepo ports.TransactionRepository, bankInfoRepo ports.BankAccountRepository, validator *TransactionValidator,
+	beneficiaryService *BeneficiaryService, metrics ports.BusinessMetrics) *TransactionService {
 	return &TransactionService{
-		db:                    db,
+		work:                  work,
 		TransactionRepository: transactionRepo,
 		BankInfoRepository:    bankInfoRepo,
 		TransactionValidator:  validator,
+		BeneficiaryService:    beneficiaryService,
+		metrics:               metrics,
 	}
 }
 
-// ProcessTransaction processes a transaction based on its type
-func (s *TransactionService) ProcessTransaction(fromAccountNumber, toAccountNumber, transactionType string, amount float64) error {
-	err := s.db.Transaction(func(_ *gorm.DB) error {
+// ProcessTransaction processes a transaction based on its type, the balance updates, the transaction and their events
+// are committed together or not at all. A transaction that lost the race against a concurrent one is tried again.
+func (s *TransactionService) ProcessTransaction(ctx context.Context, fromAccountNumber, toAccountNumber, transactionType string, amount float64) error {
+	ctx, span := tracer.Start(ctx, "TransactionService.ProcessTransaction", trace.WithAttributes(
+		attribute.String("transaction.type", transactionType),
+		attribute.Float64("transaction.amount", amount),
+	))
+	defer span.End()
+
+	err := retryConflicts(ctx, s.work, func(ctx context.Context) error {
 		transaction := domain.Transaction{
 			FromAccountNumber: fromAccountNumber,
 			ToAccountNumber:   toAccountNumber,
@@ -34,31 +58,113 @@ func (s *TransactionService) ProcessTransaction(fromAccountNumber, toAccountNumb
 			TransactionType:   transactionType,
 		}
 
-		if err := s.TransactionValidator.ProcessTransaction(&transaction); err != nil {
+		if err := s.TransactionValidator.ProcessTransaction(ctx, &transaction); err != nil {
 			return err
 		}
 
 		return nil
 	})
 
+	if err != nil {
+		failSpan(span, err)
+		s.metrics.TransactionProcessed(transactionType, domain.TransactionStatusFailed, amount)
+
+		return err
+	}
+
+	s.metrics.TransactionProcessed(transactionType, domain.TransactionStatusSuccess, amount)
+
+	return nil
+}
+
+// ProcessUserTransaction processes a transaction requested by the user, money is only moved from their own accounts
+func (s *TransactionService) ProcessUserTransaction(ctx context.Context, userID uuid.UUID, fromAccountNumber, toAccountNumber, transactionType string,
+	amount float64) error {
+	if fromAccountNumber != "" {
+		if err := s.checkOwner(ctx, userID, fromAccountNumber); err != nil {
+			return err
+		}
+	}
+
+	return s.ProcessTransaction(ctx, fromAccountNumber, toAccountNumber, transactionType, amount)
+}
+
+// ProcessBeneficiaryTransfer transfers from an account of the user to a beneficiary saved by the user
+func (s *TransactionService) ProcessBeneficiaryTransfer(ctx context.Context, userID uuid.UUID, fromAccountNumber, beneficiaryID string, amount float64) error {
+	toAccountNumber, err := s.BeneficiaryService.ResolveTransfer(ctx, userID, beneficiaryID)
+	if err != nil {
+		return err
+	}
+
+	return s.ProcessUserTransaction(ctx, userID, fromAccountNumber, toAccountNumber, "transfer", amount)
+}
+
+// checkOwner checks that the account money is moved from belongs to the user
+func (s *TransactionService) checkOwner(ctx context.Context, userID uuid.UUID, fromAccountNumber string) error {
+	account, err := s.BankInfoRepository.GetByAccountNumber(ctx, fromAccountNumber)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return withField(ErrAccountNotFound.WithCause(err), "from_account_number")
+	}
+
 	if err != nil {
 		return err
 	}
 
+	if account.UserID != userID {
+		return withField(ErrAccountNotOwned, "from_account_number")
+	}
+
 	return nil
 }
 
 // GetAllTransactions retrieves all transactions with pagination
-func (s *TransactionService) GetAllTransactions(limit, offset int) ([]domain.Transaction, error) {
-	return s.TransactionRepository.GetAll(limit, offset)
+func (s *TransactionService) GetAllTransactions(ctx context.Context, limit, offset int) ([]domain.Transaction, error) {
+	return s.TransactionRepository.GetAll(ctx, limit, offset)
 }
 
 // GetTransactionByID retrieves a specific transaction by its ID
-func (s *TransactionService) GetTransactionByID(id string) (*domain.Transaction, error) {
-	return s.TransactionRepository.GetByID(id)
+func (s *TransactionService) GetTransactionByID(ctx context.Context, id string) (*domain.Transaction, error) {
+	return s.TransactionRepository.GetByID(ctx, id)
 }
 
 // GetTransactionByAccountID retrieves transactions by account ID
-func (s *TransactionService) GetTransactionByAccountID(accountID string) ([]domain.Transaction, error) {
-	return s.TransactionRepository.GetByAccountNumber(accountID)
+func (s *TransactionService) GetTransactionByAccountID(ctx context.Context, accountID string) ([]domain.Transaction, error) {
+	return s.TransactionRepository.GetByAccountNumber(ctx, accountID)
+}
+
+// GetAccountTransactions retrieves the transactions of the account with the ID, users only see those of their own
+// accounts and admins those of every account
+func (s *TransactionService) GetAccountTransactions(ctx context.Context, accountID string, userID uuid.UUID, role string) ([]domain.Transaction, error) {
+	account, err := s.BankInfoRepository.GetByID(ctx, accountID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrBankAccountNotFound.WithCause(err)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the account of another user is answered like a missing one, so its ID is not confirmed
+	if account.UserID != userID && role != "admin" {
+		return nil, ErrBankAccountNotFound
+	}
+
+	return s.TransactionRepository.GetByAccountNumber(ctx, account.AccountNumber)
+}
+
+// retryConflicts runs fn in a unit of work again while an account it updated was changed by a concurrent transaction
+// in between, the client sent no version so the conflict is not a failed precondition but a 409 once the attempts ran out
+func retryConflicts(ctx context.Context, work ports.UnitOfWork, fn func(ctx context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		err := work.Do(ctx, fn)
+		if !errors.Is(err, domain.ErrVersionMismatch) {
+			return err
+		}
+
+		if attempt == maxConflictAttempts {
+			return ErrTransactionConflict.WithCause(err)
+		}
+
+		logger(ctx).Warn().Int("attempt", attempt).Msg("Retrying a transaction that conflicted with a concurrent one")
+	}
 }
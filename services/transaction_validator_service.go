@@ -1,109 +1,143 @@
 package services
 
 import (
+	"context"
 	"errors"
 
 	"github.com/okyws/dashboard-backend/domain"
 	"github.com/okyws/dashboard-backend/ports"
+	"gorm.io/gorm"
 )
 
-// TransactionValidator is a struct responsible for validating transactions.
+var (
+	// ErrInvalidTransactionType is returned for transaction types other than transfer, deposit and withdraw
+	ErrInvalidTransactionType = domain.NewError(domain.ErrorValidation, "invalid_transaction_type", "invalid transaction type")
+
+	// ErrSameAccount is returned when the source and the destination of a transfer are the same account
+	ErrSameAccount = domain.NewError(domain.ErrorValidation, "same_account", "cannot transfer to the same account")
+
+	// ErrInsufficientFunds is returned when the balance does not cover the amount
+	ErrInsufficientFunds = domain.NewError(domain.ErrorInsufficientFunds, "insufficient_funds", "insufficient balance")
+
+	// ErrAccountNotFound is returned when a request refers to an account number that does not exist
+	ErrAccountNotFound = domain.NewError(domain.ErrorUnprocessable, "account_not_found", "account number not found")
+
+	// ErrAccountNotOwned is returned when a user moves money from an account of another user
+	ErrAccountNotOwned = domain.NewError(domain.ErrorForbidden, "account_not_owned", "account does not belong to the user")
+)
+
+// TransactionValidator is a struct responsible for validating transactions. It records the events of the balances and
+// the transaction it writes, the caller runs it in a unit of work so they are committed together.
 type TransactionValidator struct {
 	TransactionRepository ports.TransactionRepository
 	BankInfoRepository    ports.BankAccountRepository
+	OutboxRepository      ports.OutboxRepository
+	BeneficiaryService    *BeneficiaryService // enforces the cooling-off limit of transfers, none when nil
 }
 
 // NewTransactionValidator creates a new TransactionValidator instance.
-func NewTransactionValidator(transactionRepo ports.TransactionRepository, bankInfoRepo ports.BankAccountRepository) *TransactionValidator {
-	return &TransactionValidator{TransactionRepository: transactionRepo, BankInfoRepository: bankInfoRepo}
+func NewTransactionValidator(transactionRepo ports.TransactionRepository, bankInfoRepo ports.BankAccountRepository,
+	outboxRepo ports.OutboxRepository, beneficiaryService *BeneficiaryService) *TransactionValidator {
+	return &TransactionValidator{TransactionRepository: transactionRepo, BankInfoRepository: bankInfoRepo, OutboxRepository: outboxRepo,
+		BeneficiaryService: beneficiaryService}
 }
 
 // ProcessTransaction processes a transaction based on its type.
-func (s *TransactionValidator) ProcessTransaction(transaction *domain.Transaction) error {
+func (s *TransactionValidator) ProcessTransaction(ctx context.Context, transaction *domain.Transaction) error {
 	switch transaction.TransactionType {
 	case "transfer":
-		return s.processTransfer(transaction.FromAccountNumber, transaction.ToAccountNumber, transaction.Amount)
+		return s.processTransfer(ctx, transaction.FromAccountNumber, transaction.ToAccountNumber, transaction.Amount)
 	case "deposit":
-		return s.processDeposit(transaction.ToAccountNumber, transaction.Amount)
+		return s.processDeposit(ctx, transaction.ToAccountNumber, transaction.Amount)
 	case "withdraw":
-		return s.processWithdraw(transaction.FromAccountNumber, transaction.Amount)
+		return s.processWithdraw(ctx, transaction.FromAccountNumber, transaction.Amount)
 	default:
-		return errors.New("invalid transaction type")
+		return ErrInvalidTransactionType
 	}
 }
 
+// getAccount fetches the account of a transaction, an unknown account number is an ErrAccountNotFound
+func (s *TransactionValidator) getAccount(ctx context.Context, accountNumber string) (*domain.BankAccount, error) {
+	account, err := s.BankInfoRepository.GetByAccountNumber(ctx, accountNumber)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAccountNotFound.WithCause(err)
+	}
+
+	return account, err
+}
+
 // Helper function to process a transfer transaction
-func (s *TransactionValidator) processTransfer(fromAccountNumber, toAccountNumber string, amount float64) error {
-	fromAccount, err := s.BankInfoRepository.GetByAccountNumber(fromAccountNumber)
+func (s *TransactionValidator) processTransfer(ctx context.Context, fromAccountNumber, toAccountNumber string, amount float64) error {
+	fromAccount, err := s.getAccount(ctx, fromAccountNumber)
 	if err != nil {
 		return err
 	}
 
-	toAccount, err := s.BankInfoRepository.GetByAccountNumber(toAccountNumber)
+	toAccount, err := s.getAccount(ctx, toAccountNumber)
 	if err != nil {
 		return err
 	}
 
 	if fromAccount.AccountNumber == toAccount.AccountNumber {
-		return errors.New("cannot transfer to the same account")
+		return ErrSameAccount
 	}
 
 	if fromAccount.Balance <= amount {
-		return errors.New("insufficient balance")
+		return ErrInsufficientFunds
 	}
 
-	fromAccount.Balance -= amount
-	toAccount.Balance += amount
+	if s.BeneficiaryService != nil {
+		// checked in the transaction of the transfer so it counts the transfers committed before it
+		if err := s.BeneficiaryService.CheckCoolingOff(ctx, fromAccount.UserID, toAccount, amount); err != nil {
+			return err
+		}
+	}
 
-	if _, err := s.BankInfoRepository.Update(fromAccount); err != nil {
+	if err := s.changeBalance(ctx, fromAccount, -amount); err != nil {
 		return err
 	}
 
-	if _, err := s.BankInfoRepository.Update(toAccount); err != nil {
+	if err := s.changeBalance(ctx, toAccount, amount); err != nil {
 		return err
 	}
 
-	return s.createTransaction(fromAccountNumber, toAccountNumber, amount, "transfer")
+	return s.createTransaction(ctx, fromAccountNumber, toAccountNumber, amount, "transfer")
 }
 
 // Helper function to process a deposit transaction
-func (s *TransactionValidator) processDeposit(toAccountNumber string, amount float64) error {
-	toAccount, err := s.BankInfoRepository.GetByAccountNumber(toAccountNumber)
+func (s *TransactionValidator) processDeposit(ctx context.Context, toAccountNumber string, amount float64) error {
+	toAccount, err := s.getAccount(ctx, toAccountNumber)
 	if err != nil {
 		return err
 	}
 
-	toAccount.Balance += amount
-
-	if _, err := s.BankInfoRepository.Update(toAccount); err != nil {
+	if err := s.changeBalance(ctx, toAccount, amount); err != nil {
 		return err
 	}
 
-	return s.createTransaction("", toAccountNumber, amount, "deposit")
+	return s.createTransaction(ctx, "", toAccountNumber, amount, "deposit")
 }
 
 // Helper function to process a withdraw transaction
-func (s *TransactionValidator) processWithdraw(fromAccountNumber string, amount float64) error {
-	fromAccount, err := s.BankInfoRepository.GetByAccountNumber(fromAccountNumber)
+func (s *TransactionValidator) processWithdraw(ctx context.Context, fromAccountNumber string, amount float64) error {
+	fromAccount, err := s.getAccount(ctx, fromAccountNumber)
 	if err != nil {
 		return err
 	}
 
 	if fromAccount.Balance < amount {
-		return errors.New("insufficient balance")
+		return ErrInsufficientFunds
 	}
 
-	fromAccount.Balance -= amount
-
-	if _, err := s.BankInfoRepository.Update(fromAccount); err != nil {
+	if err := s.changeBalance(ctx, fromAccount, -amount); err != nil {
 		return err
 	}
 
-	return s.createTransaction(fromAccount.AccountNumber, "", amount, "withdraw")
+	return s.createTransaction(ctx, fromAccount.AccountNumber, "", amount, "withdraw")
 }
 
 // helper function to create a transaction record
-func (s *TransactionValidator) createTransaction(fromAccountNumber, toAccountNumber string, amount float64, transactionType string) error {
+func (s *TransactionValidator) createTransaction(ctx context.Context, fromAccountNumber, toAccountNumber string, amount float64, transactionType string) error {
 	transaction := domain.Transaction{
 		FromAccountNumber: fromAccountNumber,
 		ToAccountNumber:   toAccountNumber,
@@ -111,9 +145,25 @@ func (s *TransactionValidator) createTransaction(fromAccountNumber, toAccountNum
 		TransactionType:   transactionType,
 	}
 
-	if _, err := s.TransactionRepository.Create(&transaction); err != nil {
+	if _, err := s.TransactionRepository.Create(ctx, &transaction); err != nil {
+		return err
+	}
+
+	return s.OutboxRepository.Record(ctx, domain.TransactionPosted{TransactionDTO: *domain.MapTransactionToDTO(&transaction)})
+}
+
+// changeBalance moves the balance of the account by amount and records the account.balance_changed event
+func (s *TransactionValidator) changeBalance(ctx context.Context, account *domain.BankAccount, amount float64) error {
+	previousBalance := account.Balance
+	account.Balance += amount
+
+	updated, err := s.BankInfoRepository.Update(ctx, account)
+	if err != nil {
 		return err
 	}
 
-	return nil
+	return s.OutboxRepository.Record(ctx, domain.BalanceChanged{
+		BankAccountDTO:  *domain.MapBankAccountToDTO(updated),
+		PreviousBalance: previousBalance,
+	})
 }
@@ -0,0 +1,383 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/dto"
+	"github.com/okyws/dashboard-backend/i18n"
+	"github.com/okyws/dashboard-backend/ports"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrTransferBatchNotFound is returned when no batch transfer has the ID
+	ErrTransferBatchNotFound = domain.NewError(domain.ErrorNotFound, "transfer_batch_not_found", "batch transfer not found")
+
+	// ErrTransferBatchRefused is returned for an all or nothing batch with transfers that cannot be made, the details
+	// name them
+	ErrTransferBatchRefused = domain.NewError(domain.ErrorUnprocessable, "invalid_transfer_batch", "batch has transfers that cannot be made")
+
+	// ErrAccountFrozen is returned when money is moved from or to a frozen account
+	ErrAccountFrozen = domain.NewError(domain.ErrorUnprocessable, "account_frozen", "account is frozen")
+)
+
+// msgTransferCancelled is the error of a transfer of an all or nothing batch that was not made because another failed
+const msgTransferCancelled = "not transferred because another transfer of the batch failed"
+
+// TransferBatchService makes many transfers from one account at once. A batch is validated up front: the source and
+// every destination must exist and be active, and the balance must cover the total. An all or nothing batch then makes
+// its transfers in a single transaction, a best effort batch makes every transfer in its own and skips the invalid ones.
+type TransferBatchService struct {
+	work                    ports.UnitOfWork
+	TransferBatchRepository ports.TransferBatchRepository
+	BankInfoRepository      ports.BankAccountRepository
+	TransactionValidator    *TransactionValidator
+	mapError                ErrorMapper
+	metrics                 ports.BusinessMetrics
+}
+
+// NewTransferBatchService creates a new batch transfer service via dependency injection, the transfers are made with
+// the validator in the unit of work of the batch or of a single transfer
+func NewTransferBatchService(work ports.UnitOfWork, batchRepo ports.TransferBatchRepository, bankInfoRepo ports.BankAccountRepository,
+	validator *TransactionValidator, mapError ErrorMapper, metrics ports.BusinessMetrics) *TransferBatchService {
+	return &TransferBatchService{
+		work:                    work,
+		TransferBatchRepository: batchRepo,
+		BankInfoRepository:      bankInfoRepo,
+		TransactionValidator:    validator,
+		mapError:                mapError,
+		metrics:                 metrics,
+	}
+}
+
+// Process validates the batch of the user and makes its transfers. A batch that does not pass the validation is
+// refused, every other one is stored with the outcome of each transfer.
+func (s *TransferBatchService) Process(ctx context.Context, userID uuid.UUID, request *dto.TransferBatchCreateDTO) (*domain.TransferBatch, error) {
+	ctx, span := tracer.Start(ctx, "TransferBatchService.Process", trace.WithAttributes(
+		attribute.String("batch.mode", request.Mode),
+		attribute.Int("batch.items", len(request.Items)),
+	))
+	defer span.End()
+
+	batch, err := s.prepare(ctx, userID, request)
+	if err != nil {
+		failSpan(span, err)
+		return nil, err
+	}
+
+	// once the transfers started the batch is finished, so its report matches the transfers that were made
+	ctx = context.WithoutCancel(ctx)
+
+	if batch.Mode == domain.TransferBatchModeAllOrNothing {
+		err = s.transferAll(ctx, batch)
+	} else {
+		err = s.transferEach(ctx, batch)
+	}
+
+	if err != nil {
+		failSpan(span, err)
+		return nil, err
+	}
+
+	logger(ctx).Info().Str("batch_id", batch.ID.String()).Str("mode", batch.Mode).Str("status", batch.Status).
+		Int("succeeded", batch.SucceededItems).Int("failed", batch.FailedItems).Msg("Batch transfer finished")
+
+	return batch, nil
+}
+
+// GetBatch fetches a batch transfer with the outcome of its transfers, users only see their own batches and admins
+// those of every user
+func (s *TransferBatchService) GetBatch(ctx context.Context, id string, userID uuid.UUID, role string) (*domain.TransferBatch, error) {
+	batch, err := s.TransferBatchRepository.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTransferBatchNotFound.WithCause(err)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the batch of another user is answered like a missing one, so its ID is not confirmed
+	if batch.UserID != userID && role != "admin" {
+		return nil, ErrTransferBatchNotFound
+	}
+
+	return batch, nil
+}
+
+// prepare validates the source account, which must belong to the user, every destination and the total of the batch.
+// The invalid transfers of a best effort batch are marked failed, those of an all or nothing batch refuse it.
+func (s *TransferBatchService) prepare(ctx context.Context, userID uuid.UUID, request *dto.TransferBatchCreateDTO) (*domain.TransferBatch, error) {
+	batch := &domain.TransferBatch{
+		UserID:            userID,
+		FromAccountNumber: request.FromAccountNumber,
+		Mode:              request.Mode,
+		Items:             make([]domain.TransferBatchItem, len(request.Items)),
+	}
+
+	if batch.Mode == "" {
+		batch.Mode = domain.TransferBatchModeAllOrNothing
+	}
+
+	source, err := s.activeAccount(ctx, request.FromAccountNumber)
+	if err != nil {
+		return nil, withField(err, "from_account_number")
+	}
+
+	if source.UserID != userID {
+		return nil, withField(ErrAccountNotOwned, "from_account_number")
+	}
+
+	payable, err := s.prepareItems(ctx, source, batch, request.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	// like a single transfer, the balance has to stay above zero
+	if payable > 0 && source.Balance <= payable {
+		return nil, ErrInsufficientFunds.WithMessage("balance does not cover the total of the batch")
+	}
+
+	return batch, nil
+}
+
+// prepareItems fills the items of the batch from the requested transfers and returns the total of the payable ones
+func (s *TransferBatchService) prepareItems(ctx context.Context, source *domain.BankAccount, batch *domain.TransferBatch,
+	requests []dto.TransferBatchItemCreateDTO) (float64, error) {
+	var (
+		details  []domain.FieldError
+		payable  float64
+		accounts = make(map[string]error)
+	)
+
+	for i, requested := range requests {
+		item := &batch.Items[i]
+		*item = domain.TransferBatchItem{Position: i, ToAccountNumber: requested.ToAccountNumber, Amount: requested.Amount,
+			Reference: requested.Reference}
+		batch.TotalAmount += item.Amount
+
+		invalid, err := s.validateDestination(ctx, source, item.ToAccountNumber, accounts)
+		if err != nil {
+			return 0, err
+		}
+
+		switch {
+		case invalid == nil:
+			payable += item.Amount
+		case batch.Mode == domain.TransferBatchModeBestEffort:
+			s.fail(ctx, item, invalid)
+		default:
+			details = append(details, domain.FieldError{Field: fmt.Sprintf("items[%d].to_account_number", i), Code: invalid.Code,
+				Message: invalid.Message})
+		}
+	}
+
+	if len(details) > 0 {
+		return 0, ErrTransferBatchRefused.WithDetails(details...)
+	}
+
+	return payable, nil
+}
+
+// validateDestination checks a destination once per batch, remembering the outcome in checked. It returns the domain
+// error the transfer fails with when the destination is invalid, any other error stops the batch.
+func (s *TransferBatchService) validateDestination(ctx context.Context, source *domain.BankAccount, accountNumber string,
+	checked map[string]error) (*domain.Error, error) {
+	err, ok := checked[accountNumber]
+	if !ok {
+		err = s.checkDestination(ctx, source, accountNumber)
+		checked[accountNumber] = err
+	}
+
+	var domainErr *domain.Error
+	if err != nil && !errors.As(err, &domainErr) {
+		return nil, err
+	}
+
+	return domainErr, nil
+}
+
+// activeAccount fetches an account money is moved from or to, which must exist and not be frozen
+func (s *TransferBatchService) activeAccount(ctx context.Context, accountNumber string) (*domain.BankAccount, error) {
+	account, err := s.BankInfoRepository.GetByAccountNumber(ctx, accountNumber)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAccountNotFound.WithCause(err)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !account.AccountStatus {
+		return nil, ErrAccountFrozen
+	}
+
+	return account, nil
+}
+
+// checkDestination checks that money can be transferred from the source to the account
+func (s *TransferBatchService) checkDestination(ctx context.Context, source *domain.BankAccount, accountNumber string) error {
+	if accountNumber == source.AccountNumber {
+		return ErrSameAccount
+	}
+
+	_, err := s.activeAccount(ctx, accountNumber)
+
+	return err
+}
+
+// withField names the field of the request a domain error is about
+func withField(err error, field string) error {
+	var domainErr *domain.Error
+	if !errors.As(err, &domainErr) {
+		return err
+	}
+
+	return domainErr.WithDetails(domain.FieldError{Field: field, Code: domainErr.Code, Message: domainErr.Message})
+}
+
+// transferAll makes the transfers of the batch in a single transaction, when one of them fails none is made and the
+// batch is stored as failed
+func (s *TransferBatchService) transferAll(ctx context.Context, batch *domain.TransferBatch) error {
+	failed := -1
+
+	err := retryConflicts(ctx, s.work, func(ctx context.Context) error {
+		failed = -1
+
+		for i := range batch.Items {
+			if err := s.transfer(ctx, batch, &batch.Items[i]); err != nil {
+				failed = i
+				return err
+			}
+		}
+
+		tallyBatch(batch)
+
+		_, err := s.TransferBatchRepository.Create(ctx, batch)
+
+		return err
+	})
+
+	if err == nil {
+		for _, item := range batch.Items {
+			s.metrics.TransactionProcessed("transfer", domain.TransactionStatusSuccess, item.Amount)
+		}
+
+		return nil
+	}
+
+	if failed < 0 {
+		// the transfers were made but the batch was not stored or not committed
+		return err
+	}
+
+	for i := range batch.Items {
+		item := &batch.Items[i]
+
+		if i == failed {
+			s.fail(ctx, item, err)
+			s.metrics.TransactionProcessed("transfer", domain.TransactionStatusFailed, item.Amount)
+
+			continue
+		}
+
+		*item = domain.TransferBatchItem{Position: item.Position, ToAccountNumber: item.ToAccountNumber, Amount: item.Amount,
+			Reference: item.Reference, Status: domain.TransferItemStatusCancelled, ErrorCode: "transfer_cancelled",
+			Error: msgTransferCancelled}
+	}
+
+	tallyBatch(batch)
+
+	_, err = s.TransferBatchRepository.Create(ctx, batch)
+
+	return err
+}
+
+// transferEach makes every transfer of the batch that passed the validation in its own transaction
+func (s *TransferBatchService) transferEach(ctx context.Context, batch *domain.TransferBatch) error {
+	for i := range batch.Items {
+		item := &batch.Items[i]
+		if item.Status == domain.TransferItemStatusFailed {
+			continue
+		}
+
+		err := retryConflicts(ctx, s.work, func(ctx context.Context) error {
+			return s.transfer(ctx, batch, item)
+		})
+
+		if err != nil {
+			s.fail(ctx, item, err)
+			s.metrics.TransactionProcessed("transfer", domain.TransactionStatusFailed, item.Amount)
+
+			continue
+		}
+
+		s.metrics.TransactionProcessed("transfer", domain.TransactionStatusSuccess, item.Amount)
+	}
+
+	tallyBatch(batch)
+
+	_, err := s.TransferBatchRepository.Create(ctx, batch)
+
+	return err
+}
+
+// transfer makes a transfer of the batch with the validator and marks it succeeded
+func (s *TransferBatchService) transfer(ctx context.Context, batch *domain.TransferBatch, item *domain.TransferBatchItem) error {
+	err := s.TransactionValidator.ProcessTransaction(ctx, &domain.Transaction{
+		FromAccountNumber: batch.FromAccountNumber,
+		ToAccountNumber:   item.ToAccountNumber,
+		Amount:            item.Amount,
+		TransactionType:   "transfer",
+	})
+	if err != nil {
+		return err
+	}
+
+	item.Status = domain.TransferItemStatusSucceeded
+
+	return nil
+}
+
+// fail marks a transfer failed with the code and message the API answers the error with, the message is translated
+// when the batch is answered
+func (s *TransferBatchService) fail(ctx context.Context, item *domain.TransferBatchItem, err error) {
+	status, code, message, _ := s.mapError(i18n.Default, err)
+	if status == http.StatusInternalServerError {
+		logger(ctx).Error().Err(err).Int("position", item.Position).Msg("Unexpected error making a transfer of a batch")
+	}
+
+	item.Status = domain.TransferItemStatusFailed
+	item.ErrorCode = code
+	item.Error = message
+}
+
+// tallyBatch counts the transfers of the batch that were made and sets its status
+func tallyBatch(batch *domain.TransferBatch) {
+	batch.SucceededItems, batch.FailedItems, batch.TransferredAmount = 0, 0, 0
+
+	for _, item := range batch.Items {
+		if item.Status == domain.TransferItemStatusSucceeded {
+			batch.SucceededItems++
+			batch.TransferredAmount += item.Amount
+		} else {
+			batch.FailedItems++
+		}
+	}
+
+	switch {
+	case batch.SucceededItems == 0:
+		batch.Status = domain.TransferBatchStatusFailed
+	case batch.FailedItems > 0:
+		batch.Status = domain.TransferBatchStatusCompletedWithErrors
+	default:
+		batch.Status = domain.TransferBatchStatusCompleted
+	}
+}
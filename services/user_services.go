@@ -2,7 +2,7 @@
 package services
 
 import (
-	"errors"
+	"context"
 
 	"github.com/okyws/dashboard-backend/domain"
 	"github.com/okyws/dashboard-backend/ports"
@@ -10,32 +10,52 @@ import (
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrPasswordRequired is returned when a user is created or patched without a password
+	ErrPasswordRequired = domain.NewError(domain.ErrorValidation, "password_required", "password cannot be empty")
+
+	// ErrUsernameTaken is returned when the username belongs to another user
+	ErrUsernameTaken = domain.NewError(domain.ErrorConflict, "username_taken", "username already exists")
+
+	// ErrUserNotFound is returned when a request refers to a user that does not exist
+	ErrUserNotFound = domain.NewError(domain.ErrorUnprocessable, "user_not_found", "user ID does not exist")
+)
+
 // UserService is the implementation of the user service
 type UserService struct {
-	UserRepository ports.UserRepository
+	work             ports.UnitOfWork
+	UserRepository   ports.UserRepository
+	OutboxRepository ports.OutboxRepository
 }
 
 // NewUserService creates a new user service via dependency injection
-func NewUserService(userRepository ports.UserRepository) *UserService {
-	return &UserService{UserRepository: userRepository}
+func NewUserService(work ports.UnitOfWork, userRepository ports.UserRepository, outboxRepo ports.OutboxRepository) *UserService {
+	return &UserService{work: work, UserRepository: userRepository, OutboxRepository: outboxRepo}
 }
 
-// CreateUser inserts a new user into the database
-func (s *UserService) CreateUser(user *domain.User) (*domain.User, error) {
+// CreateUser inserts a new user into the database together with its user.created event
+func (s *UserService) CreateUser(ctx context.Context, user *domain.User) (*domain.User, error) {
 	if user.Password == "" {
-		return nil, errors.New("password cannot be empty")
+		return nil, ErrPasswordRequired
 	}
 
-	existingUser, err := s.GetUserByUsername(user.Username)
+	existingUser, err := s.GetUserByUsername(ctx, user.Username)
 	if err != nil && err != gorm.ErrRecordNotFound {
 		return nil, err
 	}
 
 	if existingUser != nil {
-		return nil, errors.New("username already exists")
+		return nil, ErrUsernameTaken
 	}
 
-	createdUser, err := s.UserRepository.Create(user)
+	createdUser, err := recordChange(ctx, s.work, s.OutboxRepository, func(ctx context.Context) (*domain.User, domain.Event, error) {
+		createdUser, err := s.UserRepository.Create(ctx, user)
+		if err != nil || createdUser == nil {
+			return nil, nil, err
+		}
+
+		return createdUser, domain.UserCreated{UserDTO: *domain.MapUserToDTO(createdUser)}, nil
+	})
 	if err != nil || createdUser == nil {
 		return nil, err
 	}
@@ -46,8 +66,8 @@ func (s *UserService) CreateUser(user *domain.User) (*domain.User, error) {
 }
 
 // GetUserByID fetches a user by ID
-func (s *UserService) GetUserByID(id string) (*domain.User, error) {
-	user, err := s.UserRepository.GetByID(id)
+func (s *UserService) GetUserByID(ctx context.Context, id string) (*domain.User, error) {
+	user, err := s.UserRepository.GetByID(ctx, id)
 	if err != nil || user == nil {
 		return nil, err
 	}
@@ -58,8 +78,8 @@ func (s *UserService) GetUserByID(id string) (*domain.User, error) {
 }
 
 // GetUserByUsername fetches a user by username
-func (s *UserService) GetUserByUsername(username string) (*domain.User, error) {
-	user, err := s.UserRepository.GetUserByUsername(username)
+func (s *UserService) GetUserByUsername(ctx context.Context, username string) (*domain.User, error) {
+	user, err := s.UserRepository.GetUserByUsername(ctx, username)
 	if err != nil || user == nil {
 		return nil, err
 	}
@@ -70,7 +90,7 @@ func (s *UserService) GetUserByUsername(username string) (*domain.User, error) {
 }
 
 // UpdateUser updates an existing user
-func (s *UserService) UpdateUser(user *domain.User) (*domain.User, error) {
+func (s *UserService) UpdateUser(ctx context.Context, user *domain.User) (*domain.User, error) {
 	if user.Password != "" {
 		hash, err := utils.GeneratePasswordHash(user.Password)
 		if err != nil {
@@ -80,7 +100,7 @@ func (s *UserService) UpdateUser(user *domain.User) (*domain.User, error) {
 		user.Password = hash
 	}
 
-	updatedUser, err := s.UserRepository.Update(user)
+	updatedUser, err := s.UserRepository.Update(ctx, user)
 	if err != nil {
 		return nil, err
 	}
@@ -90,12 +110,49 @@ func (s *UserService) UpdateUser(user *domain.User) (*domain.User, error) {
 	return updatedUser, nil
 }
 
-// DeleteUser removes a user
-func (s *UserService) DeleteUser(id string) error {
-	return s.UserRepository.Delete(id)
+// PatchUser writes the changed columns of a user at the version, any version when it is 0, a new password is hashed
+// and an empty one is refused
+func (s *UserService) PatchUser(ctx context.Context, id string, version uint, changes map[string]any) (*domain.User, error) {
+	if password, ok := changes["password"].(string); ok {
+		if password == "" {
+			return nil, ErrPasswordRequired
+		}
+
+		hash, err := utils.GeneratePasswordHash(password)
+		if err != nil {
+			return nil, err
+		}
+
+		changes["password"] = hash
+	}
+
+	if username, ok := changes["username"].(string); ok {
+		existingUser, err := s.GetUserByUsername(ctx, username)
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return nil, err
+		}
+
+		if existingUser != nil && existingUser.ID.String() != id {
+			return nil, ErrUsernameTaken
+		}
+	}
+
+	patchedUser, err := s.UserRepository.Patch(ctx, id, version, changes)
+	if err != nil {
+		return nil, err
+	}
+
+	patchedUser.Password = ""
+
+	return patchedUser, nil
+}
+
+// DeleteUser removes a user at the version, any version when it is 0
+func (s *UserService) DeleteUser(ctx context.Context, id string, version uint) error {
+	return s.UserRepository.DeleteVersion(ctx, id, version)
 }
 
 // GetAllUsers fetches all users
-func (s *UserService) GetAllUsers(limit, offset int) ([]domain.User, error) {
-	return s.UserRepository.GetAll(limit, offset)
+func (s *UserService) GetAllUsers(ctx context.Context, limit, offset int) ([]domain.User, error) {
+	return s.UserRepository.GetAll(ctx, limit, offset)
 }
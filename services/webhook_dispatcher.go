@@ -0,0 +1,245 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/dto"
+	"github.com/okyws/dashboard-backend/ports"
+)
+
+// Webhook request headers
+const (
+	HeaderWebhookEvent     = "X-Webhook-Event"
+	HeaderWebhookID        = "X-Webhook-ID"
+	HeaderWebhookTimestamp = "X-Webhook-Timestamp"
+	HeaderWebhookSignature = "X-Webhook-Signature"
+)
+
+// WebhookDispatcher turns domain events into deliveries for the subscribed endpoints and sends them with retries
+type WebhookDispatcher struct {
+	WebhookRepository ports.WebhookRepository
+	Client            *http.Client
+	PollInterval      time.Duration
+	BatchSize         int
+	ClaimLease        time.Duration
+	MaxAttempts       int
+	BaseBackoff       time.Duration
+	MaxBackoff        time.Duration
+}
+
+// NewWebhookDispatcher creates a new webhook dispatcher with the default retry policy
+func NewWebhookDispatcher(webhookRepo ports.WebhookRepository) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		WebhookRepository: webhookRepo,
+		Client:            &http.Client{Timeout: 10 * time.Second},
+		PollInterval:      5 * time.Second,
+		BatchSize:         100,
+		ClaimLease:        time.Minute,
+		MaxAttempts:       8,
+		BaseBackoff:       30 * time.Second,
+		MaxBackoff:        time.Hour,
+	}
+}
+
+// Start runs the delivery loop until the context is cancelled
+func (d *WebhookDispatcher) Start(ctx context.Context) {
+	logger(ctx).Info().Msg("Webhook dispatcher started")
+
+	ticker := time.NewTicker(d.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			logger(ctx).Info().Msg("Webhook dispatcher stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce sends every delivery that is currently due
+func (d *WebhookDispatcher) RunOnce(ctx context.Context) {
+	d.deliverDue(ctx)
+}
+
+// HandleEvent is the event bus subscriber scheduling one delivery per endpoint subscribed to the event
+func (d *WebhookDispatcher) HandleEvent(ctx context.Context, envelope *domain.EventEnvelope) error {
+	endpoints, err := d.WebhookRepository.GetActiveEndpoints(ctx)
+	if err != nil {
+		return err
+	}
+
+	deliveries, err := d.buildDeliveries(envelope, endpoints)
+	if err != nil {
+		return err
+	}
+
+	return d.WebhookRepository.CreateDeliveries(ctx, deliveries)
+}
+
+// buildDeliveries creates the deliveries of an event for the endpoints subscribed to it
+func (d *WebhookDispatcher) buildDeliveries(envelope *domain.EventEnvelope, endpoints []domain.WebhookEndpoint) ([]domain.WebhookDelivery, error) {
+	eventType := envelope.Event.EventType()
+	deliveries := make([]domain.WebhookDelivery, 0)
+
+	data, err := json.Marshal(envelope.Event)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(dto.WebhookEventDTO{
+		ID:        envelope.ID,
+		Type:      eventType,
+		CreatedAt: envelope.CreatedAt,
+		Data:      data,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range endpoints {
+		if !endpoints[i].Subscribes(eventType) {
+			continue
+		}
+
+		deliveries = append(deliveries, domain.WebhookDelivery{
+			EndpointID:    endpoints[i].ID,
+			EventID:       envelope.ID,
+			EventType:     eventType,
+			Payload:       string(body),
+			NextAttemptAt: time.Now(),
+		})
+	}
+
+	return deliveries, nil
+}
+
+// deliverDue claims and sends every delivery whose next attempt is due, the claim keeps the other replicas from sending
+// the same delivery
+func (d *WebhookDispatcher) deliverDue(ctx context.Context) {
+	deliveries, err := d.WebhookRepository.ClaimDueDeliveries(ctx, time.Now(), d.BatchSize, d.ClaimLease)
+	if err != nil {
+		logger(ctx).Error().Err(err).Msg("Failed to fetch due webhook deliveries")
+		return
+	}
+
+	for i := range deliveries {
+		if ctx.Err() != nil {
+			return
+		}
+
+		d.attempt(ctx, &deliveries[i])
+	}
+}
+
+// attempt sends a single delivery and records the outcome, moving it to the dead-letter state once retries run out
+func (d *WebhookDispatcher) attempt(ctx context.Context, delivery *domain.WebhookDelivery) {
+	delivery.Attempts++
+
+	// the outcome is recorded even when the dispatcher stops during the request
+	recordCtx := context.WithoutCancel(ctx)
+
+	endpoint, err := d.WebhookRepository.GetEndpointByID(ctx, delivery.EndpointID.String())
+	if err != nil {
+		d.fail(recordCtx, delivery, 0, fmt.Errorf("endpoint not available: %v", err))
+		return
+	}
+
+	code, err := d.send(ctx, endpoint, delivery)
+	if err != nil {
+		d.fail(recordCtx, delivery, code, err)
+		return
+	}
+
+	delivery.Status = domain.DeliveryStatusDelivered
+	delivery.ResponseCode = code
+	delivery.LastError = ""
+
+	if err := d.WebhookRepository.UpdateDelivery(recordCtx, delivery); err != nil {
+		logger(ctx).Error().Err(err).Str("delivery_id", delivery.ID.String()).Msg("Failed to update webhook delivery")
+	}
+
+	logger(ctx).Info().Str("delivery_id", delivery.ID.String()).Str("event_type", delivery.EventType).Msg("Webhook delivered")
+}
+
+// fail records a failed attempt and schedules the next one with exponential backoff
+func (d *WebhookDispatcher) fail(ctx context.Context, delivery *domain.WebhookDelivery, code int, cause error) {
+	delivery.ResponseCode = code
+	delivery.LastError = cause.Error()
+
+	if delivery.Attempts >= d.MaxAttempts {
+		delivery.Status = domain.DeliveryStatusDead
+	} else {
+		delivery.NextAttemptAt = time.Now().Add(d.backoff(delivery.Attempts))
+	}
+
+	if err := d.WebhookRepository.UpdateDelivery(ctx, delivery); err != nil {
+		logger(ctx).Error().Err(err).Str("delivery_id", delivery.ID.String()).Msg("Failed to update webhook delivery")
+	}
+
+	logger(ctx).Warn().Err(cause).Str("delivery_id", delivery.ID.String()).Int("attempts", delivery.Attempts).
+		Str("status", delivery.Status).Msg("Webhook delivery failed")
+}
+
+// backoff returns the wait before the next attempt, doubling after every failure
+func (d *WebhookDispatcher) backoff(attempts int) time.Duration {
+	wait := d.BaseBackoff
+	for i := 1; i < attempts && wait < d.MaxBackoff; i++ {
+		wait *= 2
+	}
+
+	if wait > d.MaxBackoff {
+		return d.MaxBackoff
+	}
+
+	return wait
+}
+
+// send posts the signed payload to the endpoint and treats any non 2xx answer as a failure
+func (d *WebhookDispatcher) send(ctx context.Context, endpoint *domain.WebhookEndpoint, delivery *domain.WebhookDelivery) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderWebhookEvent, delivery.EventType)
+	req.Header.Set(HeaderWebhookID, delivery.EventID.String())
+	req.Header.Set(HeaderWebhookTimestamp, timestamp)
+	req.Header.Set(HeaderWebhookSignature, "sha256="+SignWebhookPayload(endpoint.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// SignWebhookPayload computes the HMAC-SHA256 signature of "timestamp.payload" with the endpoint secret
+func SignWebhookPayload(secret, timestamp, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + payload))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
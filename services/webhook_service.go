@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/ports"
+)
+
+// ErrDeliveryAlreadyScheduled is returned when redelivering a delivery that is still pending
+var ErrDeliveryAlreadyScheduled = domain.NewError(domain.ErrorConflict, "delivery_already_scheduled", "delivery is already scheduled")
+
+// WebhookService is the implementation of the webhook service
+type WebhookService struct {
+	WebhookRepository ports.WebhookRepository
+}
+
+// NewWebhookService creates a new webhook service via dependency injection
+func NewWebhookService(webhookRepo ports.WebhookRepository) *WebhookService {
+	return &WebhookService{WebhookRepository: webhookRepo}
+}
+
+// CreateEndpoint registers a new webhook endpoint and generates a signing secret when none is given
+func (s *WebhookService) CreateEndpoint(ctx context.Context, endpoint *domain.WebhookEndpoint) (*domain.WebhookEndpoint, error) {
+	if endpoint.Secret == "" {
+		secret, err := generateWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+
+		endpoint.Secret = secret
+	}
+
+	return s.WebhookRepository.CreateEndpoint(ctx, endpoint)
+}
+
+// GetEndpointByID fetches a webhook endpoint by ID
+func (s *WebhookService) GetEndpointByID(ctx context.Context, id string) (*domain.WebhookEndpoint, error) {
+	return s.WebhookRepository.GetEndpointByID(ctx, id)
+}
+
+// GetAllEndpoints fetches all webhook endpoints with pagination
+func (s *WebhookService) GetAllEndpoints(ctx context.Context, limit, offset int) ([]domain.WebhookEndpoint, error) {
+	return s.WebhookRepository.GetAllEndpoints(ctx, limit, offset)
+}
+
+// DeleteEndpoint removes a webhook endpoint
+func (s *WebhookService) DeleteEndpoint(ctx context.Context, id string) error {
+	return s.WebhookRepository.DeleteEndpoint(ctx, id)
+}
+
+// GetDeliveriesByEndpointID fetches the delivery history of an endpoint
+func (s *WebhookService) GetDeliveriesByEndpointID(ctx context.Context, endpointID string, limit, offset int) ([]domain.WebhookDelivery, error) {
+	if _, err := s.WebhookRepository.GetEndpointByID(ctx, endpointID); err != nil {
+		return nil, err
+	}
+
+	return s.WebhookRepository.GetDeliveriesByEndpointID(ctx, endpointID, limit, offset)
+}
+
+// Redeliver schedules a delivery to be sent again as soon as possible, including dead-lettered ones
+func (s *WebhookService) Redeliver(ctx context.Context, deliveryID string) (*domain.WebhookDelivery, error) {
+	delivery, err := s.WebhookRepository.GetDeliveryByID(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if delivery.Status == domain.DeliveryStatusPending {
+		return nil, ErrDeliveryAlreadyScheduled
+	}
+
+	delivery.Status = domain.DeliveryStatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	delivery.LastError = ""
+
+	if err := s.WebhookRepository.UpdateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// generateWebhookSecret returns a random hex encoded secret used to sign payloads
+func generateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,222 @@
+package database_test
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/glebarez/sqlite"
+	"github.com/okyws/dashboard-backend/database"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+var models = []interface{}{
+	&domain.User{}, &domain.Customer{}, &domain.BankAccount{}, &domain.Transaction{}, &domain.OutboxEvent{},
+	&domain.WebhookEndpoint{}, &domain.WebhookDelivery{}, &domain.Notification{}, &domain.NotificationPreference{}, &domain.Beneficiary{},
+	&domain.ImportJob{}, &domain.ImportRowError{}, &domain.TransferBatch{}, &domain.TransferBatchItem{}, &domain.OutboxDelivery{},
+	&domain.AccountNumberSequence{},
+}
+
+func newTestDB(t *testing.T) *gorm.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	assert.NoError(t, err)
+
+	db.SetMaxOpenConns(1)
+
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	gormDB, err := gorm.Open(&sqlite.Dialector{Conn: db}, &gorm.Config{})
+	assert.NoError(t, err)
+
+	return gormDB
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	gormDB := newTestDB(t)
+
+	migrator, err := database.NewEmbeddedMigrator(gormDB)
+	assert.NoError(t, err)
+
+	applied, err := migrator.Up()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, applied)
+
+	t.Run("creates every column of the models", func(t *testing.T) {
+		for _, model := range models {
+			stmt := &gorm.Statement{DB: gormDB}
+			assert.NoError(t, stmt.Parse(model))
+
+			for _, field := range stmt.Schema.Fields {
+				if field.DBName == "" {
+					continue
+				}
+
+				assert.True(t, gormDB.Migrator().HasColumn(model, field.DBName), "%s.%s", stmt.Schema.Table, field.DBName)
+			}
+		}
+	})
+
+	t.Run("is a no-op when up to date", func(t *testing.T) {
+		applied, err := migrator.Up()
+		assert.NoError(t, err)
+		assert.Empty(t, applied)
+
+		statuses, err := migrator.Status()
+		assert.NoError(t, err)
+
+		for _, status := range statuses {
+			assert.NotNil(t, status.AppliedAt, status.Name)
+		}
+	})
+
+	t.Run("reverts every migration", func(t *testing.T) {
+		reverted, err := migrator.Down(len(applied))
+		assert.NoError(t, err)
+		assert.Len(t, reverted, len(applied))
+
+		for _, model := range models {
+			assert.False(t, gormDB.Migrator().HasTable(model))
+		}
+
+		statuses, err := migrator.Status()
+		assert.NoError(t, err)
+
+		for _, status := range statuses {
+			assert.Nil(t, status.AppliedAt, status.Name)
+		}
+	})
+}
+
+func TestEmbeddedMigrationsAdoptAutoMigratedSchema(t *testing.T) {
+	gormDB := newTestDB(t)
+	assert.NoError(t, gormDB.AutoMigrate(models...))
+
+	// AutoMigrate created the schema of the baseline, the columns of the later migrations did not exist yet
+	for _, model := range []interface{}{&domain.User{}, &domain.Customer{}, &domain.BankAccount{}} {
+		assert.NoError(t, gormDB.Migrator().DropColumn(model, "Version"))
+	}
+
+	for _, column := range []string{"ClaimedBy", "ClaimedUntil"} {
+		assert.NoError(t, gormDB.Migrator().DropColumn(&domain.OutboxEvent{}, column))
+		assert.NoError(t, gormDB.Migrator().DropColumn(&domain.WebhookDelivery{}, column))
+	}
+
+	migrator, err := database.NewEmbeddedMigrator(gormDB)
+	assert.NoError(t, err)
+
+	_, err = migrator.Up()
+	assert.NoError(t, err)
+}
+
+func TestMigrator(t *testing.T) {
+	files := fstest.MapFS{
+		"0001_create_items.up.sql":     {Data: []byte("CREATE TABLE items (id integer PRIMARY KEY);")},
+		"0001_create_items.down.sql":   {Data: []byte("DROP TABLE items;")},
+		"0002_add_item_name.up.sql":    {Data: []byte("ALTER TABLE items ADD COLUMN name text;")},
+		"0002_add_item_name.down.sql":  {Data: []byte("ALTER TABLE items DROP COLUMN name;")},
+		"0003_broken.up.sql":           {Data: []byte("CREATE TABLE broken (id integer); INSERT INTO missing VALUES (1);")},
+		"0003_broken.down.sql":         {Data: []byte("DROP TABLE broken;")},
+		"README.md":                    {Data: []byte("ignored")},
+		"0002_add_item_name.extra.txt": {Data: []byte("ignored")},
+	}
+
+	t.Run("applies in order and stops at a failing migration", func(t *testing.T) {
+		gormDB := newTestDB(t)
+
+		migrator, err := database.NewMigrator(gormDB, files)
+		assert.NoError(t, err)
+
+		applied, err := migrator.Up()
+		assert.Error(t, err)
+		assert.Len(t, applied, 2)
+		assert.True(t, gormDB.Migrator().HasColumn("items", "name"))
+		assert.False(t, gormDB.Migrator().HasTable("broken"), "the failed migration is rolled back")
+
+		statuses, err := migrator.Status()
+		assert.NoError(t, err)
+		assert.Len(t, statuses, 3)
+		assert.NotNil(t, statuses[1].AppliedAt)
+		assert.Nil(t, statuses[2].AppliedAt)
+
+		reverted, err := migrator.Down(1)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(2), reverted[0].Version)
+		assert.False(t, gormDB.Migrator().HasColumn("items", "name"))
+		assert.True(t, gormDB.Migrator().HasTable("items"))
+	})
+
+	t.Run("requires a down file for every up file", func(t *testing.T) {
+		_, err := database.LoadMigrations(fstest.MapFS{
+			"0001_create_items.up.sql": {Data: []byte("CREATE TABLE items (id integer);")},
+		}, "sqlite")
+		assert.ErrorIs(t, err, database.ErrMigrationFile)
+	})
+
+	t.Run("rejects badly named files", func(t *testing.T) {
+		_, err := database.LoadMigrations(fstest.MapFS{
+			"create_items.sql": {Data: []byte("CREATE TABLE items (id integer);")},
+		}, "sqlite")
+		assert.ErrorIs(t, err, database.ErrMigrationFile)
+	})
+}
+
+func TestMigrationDialects(t *testing.T) {
+	files := fstest.MapFS{
+		"0001_create_items.up.sql":             {Data: []byte("CREATE TABLE items (id uuid);")},
+		"0001_create_items.up.sqlite.sql":      {Data: []byte("CREATE TABLE items (id text);")},
+		"0001_create_items.down.sql":           {Data: []byte("DROP TABLE items;")},
+		"0002_index_items.up.postgres.sql":     {Data: []byte("CREATE INDEX CONCURRENTLY idx_items ON items (id);")},
+		"0002_index_items.down.postgres.sql":   {Data: []byte("DROP INDEX idx_items;")},
+		"0002_index_items.up.sql":              {Data: []byte("CREATE INDEX idx_items ON items (id);")},
+		"0002_index_items.down.sql":            {Data: []byte("DROP INDEX idx_items;")},
+		"0003_postgres_only.up.postgres.sql":   {Data: []byte("SELECT 1;")},
+		"0003_postgres_only.down.postgres.sql": {Data: []byte("SELECT 1;")},
+	}
+
+	postgres, err := database.LoadMigrations(files, "postgres")
+	assert.NoError(t, err)
+	assert.Len(t, postgres, 3)
+	assert.Equal(t, "CREATE TABLE items (id uuid);", postgres[0].Up)
+	assert.Equal(t, "CREATE INDEX CONCURRENTLY idx_items ON items (id);", postgres[1].Up)
+
+	delete(files, "0003_postgres_only.up.postgres.sql")
+	delete(files, "0003_postgres_only.down.postgres.sql")
+
+	sqlite, err := database.LoadMigrations(files, "sqlite")
+	assert.NoError(t, err)
+	assert.Len(t, sqlite, 2)
+	assert.Equal(t, "CREATE TABLE items (id text);", sqlite[0].Up)
+	assert.Equal(t, "DROP TABLE items;", sqlite[0].Down)
+	assert.Equal(t, "CREATE INDEX idx_items ON items (id);", sqlite[1].Up)
+
+	files["0003_postgres_only.up.postgres.sql"] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+	files["0003_postgres_only.down.postgres.sql"] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+
+	_, err = database.LoadMigrations(files, "sqlite")
+	assert.ErrorIs(t, err, database.ErrMigrationFile, "every version needs SQL for every dialect")
+}
+
+func TestCreateMigration(t *testing.T) {
+	dir := t.TempDir()
+
+	up, down, err := database.CreateMigration(dir, "create items")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "0001_create_items.up.sql"), up)
+	assert.Equal(t, filepath.Join(dir, "0001_create_items.down.sql"), down)
+
+	up, _, err = database.CreateMigration(dir, "add_item_name")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "0002_add_item_name.up.sql"), up)
+
+	_, err = os.Stat(up)
+	assert.NoError(t, err)
+
+	_, _, err = database.CreateMigration(dir, "drop items; --")
+	assert.ErrorIs(t, err, database.ErrMigrationFile)
+}
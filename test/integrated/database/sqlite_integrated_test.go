@@ -0,0 +1,84 @@
+package database_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/okyws/dashboard-backend/adapter/metrics"
+	"github.com/okyws/dashboard-backend/adapter/repository"
+	"github.com/okyws/dashboard-backend/config"
+	"github.com/okyws/dashboard-backend/database/seed"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/services"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newSQLiteConfiguration(t *testing.T, path string) *domain.Configuration {
+	configuration, err := config.Load(config.LoadOptions{Overrides: map[string]string{
+		"DB_DRIVER":      domain.DriverSQLite,
+		"DB_PATH":        path,
+		"JWT_SECRET_KEY": "secret",
+	}})
+	assert.NoError(t, err)
+
+	return configuration
+}
+
+func TestSQLiteBackend(t *testing.T) {
+	for name, path := range map[string]string{"file": filepath.Join(t.TempDir(), "dashboard.db"), "memory": domain.SQLiteMemory} {
+		t.Run(name, func(t *testing.T) {
+			configuration := newSQLiteConfiguration(t, path)
+
+			db, err := config.NewDBConnectionENV(configuration)
+			assert.NoError(t, err)
+			t.Cleanup(func() { config.CloseDatabase(db) })
+
+			assert.NoError(t, config.MigrateDB(db))
+
+			scheme := config.NewAccountNumberScheme(configuration)
+			profile := seed.SeedProfile{Users: 2, AccountsPerUser: 2, TransactionsPerUser: 3}
+
+			summary, err := seed.NewSeeder(db, scheme, seed.SeedOptions{SeedProfile: profile, Seed: 1}).Run(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, 4, summary.Users)
+
+			userRepository := repository.NewUserRepositoryAdapter(db)
+			bankRepository := repository.NewBankAccountRepositoryAdapter(db, scheme)
+			transactionRepository := repository.NewTransactionRepositoryAdapter(db)
+
+			user, err := userRepository.GetUserByUsername(context.Background(), "user")
+			assert.NoError(t, err)
+			assert.False(t, user.CreatedAt.IsZero(), "timestamps are read back as times")
+
+			t.Run("rejects duplicates with the translated error", func(t *testing.T) {
+				_, err := userRepository.Create(context.Background(), &domain.User{Username: "user", Email: "other@example.com", Password: "password", Role: "user"})
+				assert.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+			})
+
+			t.Run("processes transactions across pooled connections", func(t *testing.T) {
+				accounts, err := bankRepository.GetByUserID(context.Background(), user.ID.String())
+				assert.NoError(t, err)
+				assert.NotEmpty(t, accounts)
+
+				transactionService := services.NewTransactionService(repository.NewUnitOfWork(db), transactionRepository, bankRepository,
+					services.NewTransactionValidator(transactionRepository, bankRepository, repository.NewOutboxRepositoryAdapter(db), nil), nil, metrics.Nop{})
+
+				assert.NoError(t, transactionService.ProcessTransaction(context.Background(), "", accounts[0].AccountNumber, "deposit", 50))
+
+				account, err := bankRepository.GetByAccountNumber(context.Background(), accounts[0].AccountNumber)
+				assert.NoError(t, err)
+				assert.InDelta(t, accounts[0].Balance+50, account.Balance, 0.001)
+			})
+
+			t.Run("drops every table", func(t *testing.T) {
+				assert.NoError(t, config.DropDB(db))
+
+				for _, model := range models {
+					assert.False(t, db.Migrator().HasTable(model))
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package repository_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/okyws/dashboard-backend/adapter/broker"
+	"github.com/okyws/dashboard-backend/adapter/repository"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/ports"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// store builds the stores of one driver, every driver must pass the same contract
+type store struct {
+	auth   func(t *testing.T) ports.AuthRepository
+	broker func(t *testing.T) ports.StreamBroker
+}
+
+func newRedisClient(t *testing.T) *redis.Client {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+
+	t.Cleanup(func() {
+		assert.NoError(t, client.Close())
+	})
+
+	return client
+}
+
+var stores = map[string]store{
+	domain.StoreMemory: {
+		auth:   func(_ *testing.T) ports.AuthRepository { return repository.NewAuthRepositoryMemory() },
+		broker: func(_ *testing.T) ports.StreamBroker { return broker.NewMemoryBroker() },
+	},
+	domain.StoreRedis: {
+		auth: func(t *testing.T) ports.AuthRepository { return repository.NewAuthRepositoryRedis(newRedisClient(t)) },
+		broker: func(t *testing.T) ports.StreamBroker {
+			return broker.NewRedisBroker(newRedisClient(t), broker.DefaultChannel)
+		},
+	},
+}
+
+func TestAuthRepositoryContract(t *testing.T) {
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			t.Run("returns the expiration of a saved token", func(t *testing.T) {
+				authRepository := store.auth(t)
+				expiresAt := time.Now().Add(time.Hour).Format("2006-01-02 15:04:05")
+
+				assert.NoError(t, authRepository.SaveToken(ctx, uuid.New(), "token", expiresAt))
+
+				saved, err := authRepository.GetTokenExpiration(ctx, "token")
+				assert.NoError(t, err)
+				assert.Equal(t, expiresAt, saved.Format("2006-01-02 15:04:05"))
+				assert.WithinDuration(t, time.Now().Add(time.Hour), *saved, 2*time.Second)
+			})
+
+			t.Run("does not know unsaved tokens", func(t *testing.T) {
+				_, err := store.auth(t).GetTokenExpiration(ctx, "unknown")
+				assert.Error(t, err)
+			})
+
+			t.Run("forgets expired tokens", func(t *testing.T) {
+				authRepository := store.auth(t)
+				expiresAt := time.Now().Add(-time.Minute).Format("2006-01-02 15:04:05")
+
+				assert.NoError(t, authRepository.SaveToken(ctx, uuid.New(), "expired", expiresAt))
+
+				_, err := authRepository.GetTokenExpiration(ctx, "expired")
+				assert.Error(t, err)
+			})
+
+			t.Run("rejects malformed expiration times", func(t *testing.T) {
+				assert.Error(t, store.auth(t).SaveToken(ctx, uuid.New(), "token", "tomorrow"))
+			})
+		})
+	}
+}
+
+func TestStreamBrokerContract(t *testing.T) {
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			streamBroker := store.broker(t)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			first, err := streamBroker.Subscribe(ctx)
+			assert.NoError(t, err)
+
+			second, err := streamBroker.Subscribe(ctx)
+			assert.NoError(t, err)
+
+			message := &domain.StreamMessage{ID: uuid.New(), Type: "transaction.completed", UserIDs: []uuid.UUID{uuid.New()},
+				CreatedAt: time.Now().UTC().Truncate(time.Second), Data: json.RawMessage(`{"amount":10}`)}
+
+			publishCtx, stop := context.WithTimeout(context.Background(), time.Second)
+			defer stop()
+
+			// the redis broker hands the message over without buffering, publish while the subscribers read
+			go func() {
+				assert.NoError(t, streamBroker.Publish(publishCtx, message))
+			}()
+
+			for _, messages := range []<-chan *domain.StreamMessage{first, second} {
+				select {
+				case received := <-messages:
+					assert.Equal(t, message.ID, received.ID)
+					assert.Equal(t, message.UserIDs, received.UserIDs)
+					assert.JSONEq(t, string(message.Data), string(received.Data))
+				case <-time.After(time.Second):
+					t.Fatal("message not delivered to every subscriber")
+				}
+			}
+
+			cancel()
+
+			select {
+			case _, open := <-first:
+				assert.False(t, open, "the channel is closed once the subscription ends")
+			case <-time.After(time.Second):
+				t.Fatal("subscription not closed")
+			}
+		})
+	}
+}
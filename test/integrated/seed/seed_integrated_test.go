@@ -0,0 +1,175 @@
+package seed_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/okyws/dashboard-backend/database/seed"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+func newSeedTestDB(t *testing.T) *gorm.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	assert.NoError(t, err)
+
+	db.SetMaxOpenConns(1)
+
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	gormDB, err := gorm.Open(&sqlite.Dialector{Conn: db}, &gorm.Config{})
+	assert.NoError(t, err)
+
+	err = gormDB.AutoMigrate(&domain.User{}, &domain.Customer{}, &domain.BankAccount{}, &domain.Transaction{}, &domain.OutboxEvent{},
+		&domain.AccountNumberSequence{})
+	assert.NoError(t, err)
+
+	return gormDB
+}
+
+// assertBalancesMatchTransactions checks every balance equals the sum of the transactions of its account
+func assertBalancesMatchTransactions(t *testing.T, gormDB *gorm.DB) {
+	var accounts []domain.BankAccount
+	assert.NoError(t, gormDB.Find(&accounts).Error)
+
+	var transactions []domain.Transaction
+	assert.NoError(t, gormDB.Find(&transactions).Error)
+
+	expected := make(map[string]float64)
+	for _, transaction := range transactions {
+		expected[transaction.ToAccountNumber] += transaction.Amount
+		expected[transaction.FromAccountNumber] -= transaction.Amount
+	}
+
+	for _, account := range accounts {
+		assert.InDelta(t, expected[account.AccountNumber], account.Balance, 0.001, account.AccountNumber)
+		assert.GreaterOrEqual(t, account.Balance, 0.0, account.AccountNumber)
+	}
+}
+
+func seededNames(t *testing.T, gormDB *gorm.DB) []string {
+	var names []string
+	assert.NoError(t, gormDB.Model(&domain.Customer{}).Order("phone_number").Pluck("full_name", &names).Error)
+
+	var amounts []float64
+	assert.NoError(t, gormDB.Model(&domain.Transaction{}).Pluck("amount", &amounts).Error)
+	sort.Float64s(amounts)
+
+	for _, amount := range amounts {
+		names = append(names, strconv.FormatFloat(amount, 'f', 2, 64))
+	}
+
+	return names
+}
+
+func TestSeeder(t *testing.T) {
+	options := seed.SeedOptions{SeedProfile: seed.SeedProfile{Users: 4, AccountsPerUser: 3, TransactionsPerUser: 6}, Seed: 42}
+
+	t.Run("seeds the requested counts with consistent balances", func(t *testing.T) {
+		gormDB := newSeedTestDB(t)
+
+		summary, err := seed.NewSeeder(gormDB, domain.DefaultAccountNumberScheme, options).Run(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, &seed.SeedSummary{Users: 6, Accounts: 15, Transactions: 5 + 30}, summary)
+
+		var transactionCount int64
+		assert.NoError(t, gormDB.Model(&domain.Transaction{}).Count(&transactionCount).Error)
+		assert.Equal(t, int64(35), transactionCount)
+
+		var admin domain.User
+		assert.NoError(t, gormDB.First(&admin, "username = ?", "admin").Error)
+		assert.Equal(t, "admin", admin.Role)
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(admin.Password), []byte(seed.DefaultSeedPassword)))
+
+		assertBalancesMatchTransactions(t, gormDB)
+	})
+
+	t.Run("is reproducible with the same seed", func(t *testing.T) {
+		first, second, other := newSeedTestDB(t), newSeedTestDB(t), newSeedTestDB(t)
+
+		_, err := seed.NewSeeder(first, domain.DefaultAccountNumberScheme, options).Run(context.Background())
+		assert.NoError(t, err)
+
+		_, err = seed.NewSeeder(second, domain.DefaultAccountNumberScheme, options).Run(context.Background())
+		assert.NoError(t, err)
+
+		otherOptions := options
+		otherOptions.Seed = 7
+
+		_, err = seed.NewSeeder(other, domain.DefaultAccountNumberScheme, otherOptions).Run(context.Background())
+		assert.NoError(t, err)
+
+		assert.Equal(t, seededNames(t, first), seededNames(t, second))
+		assert.NotEqual(t, seededNames(t, first), seededNames(t, other))
+	})
+
+	t.Run("knows the named profiles", func(t *testing.T) {
+		for _, name := range []string{"minimal", "demo", "load-test"} {
+			_, err := seed.GetSeedProfile(name)
+			assert.NoError(t, err, name)
+		}
+
+		_, err := seed.GetSeedProfile("huge")
+		assert.ErrorIs(t, err, seed.ErrUnknownSeedProfile)
+	})
+}
+
+func TestFixtures(t *testing.T) {
+	t.Run("loads a YAML fixture", func(t *testing.T) {
+		gormDB := newSeedTestDB(t)
+
+		fixture, err := seed.LoadFixtureFile(filepath.Join("..", "..", "fixtures", "transfers.yaml"))
+		assert.NoError(t, err)
+
+		result, err := seed.ApplyFixture(context.Background(), gormDB, domain.DefaultAccountNumberScheme, fixture)
+		assert.NoError(t, err)
+		assert.Len(t, result.Users, 2)
+		assert.Len(t, result.Accounts, 3)
+
+		var alice, bob domain.BankAccount
+		assert.NoError(t, gormDB.First(&alice, "account_number = ?", "1010000014").Error)
+		assert.NoError(t, gormDB.First(&bob, "account_number = ?", "1010000022").Error)
+		assert.Equal(t, 700000.0, alice.Balance)
+		assert.Equal(t, 260000.0, bob.Balance)
+		assert.False(t, bob.AccountStatus)
+
+		var customer domain.Customer
+		assert.NoError(t, gormDB.First(&customer, "user_id = ?", result.Users["alice"].ID).Error)
+		assert.Equal(t, "Alice Wijaya", customer.FullName)
+	})
+
+	t.Run("loads a JSON fixture and reports failing transactions", func(t *testing.T) {
+		gormDB := newSeedTestDB(t)
+
+		path := filepath.Join(t.TempDir(), "fixture.json")
+		assert.NoError(t, os.WriteFile(path, []byte(`{
+			"users": [{"username": "carol", "email": "carol@example.com", "accounts": [{"account_type": "rekening-utama", "balance": 5000}]}],
+			"transactions": [{"type": "withdraw", "from": "missing", "amount": 10000}]
+		}`), 0o600))
+
+		fixture, err := seed.LoadFixtureFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, "carol", fixture.Users[0].Username)
+
+		_, err = seed.ApplyFixture(context.Background(), gormDB, domain.DefaultAccountNumberScheme, fixture)
+		assert.ErrorContains(t, err, "fixture transaction 1")
+	})
+
+	t.Run("rejects unknown file types", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "fixture.toml")
+		assert.NoError(t, os.WriteFile(path, []byte(""), 0o600))
+
+		_, err := seed.LoadFixtureFile(path)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,97 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/okyws/dashboard-backend/adapter/repository"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBankAccountNumberGeneration(t *testing.T) {
+	gormDB := newEventTestDB(t)
+	scheme := domain.DefaultAccountNumberScheme
+	bankRepo := repository.NewBankAccountRepositoryAdapter(gormDB, scheme)
+
+	user, err := repository.NewUserRepositoryAdapter(gormDB).Create(context.Background(), &domain.User{Username: "owner", Email: "owner@example.com", Password: "password", Role: "user"})
+	assert.NoError(t, err)
+
+	numbered := func(accountType string, serial uint64) string {
+		accountNumber, err := scheme.Generate(accountType, serial)
+		assert.NoError(t, err)
+
+		return accountNumber
+	}
+
+	t.Run("takes the next serial of the product code", func(t *testing.T) {
+		first, err := bankRepo.Create(context.Background(), &domain.BankAccount{UserID: user.ID, AccountType: "saku"})
+		assert.NoError(t, err)
+		assert.Equal(t, "1020000012", first.AccountNumber)
+
+		second, err := bankRepo.Create(context.Background(), &domain.BankAccount{UserID: user.ID, AccountType: "saku"})
+		assert.NoError(t, err)
+		assert.Equal(t, numbered("saku", 2), second.AccountNumber)
+	})
+
+	t.Run("skips numbers that are already taken", func(t *testing.T) {
+		assigned, err := bankRepo.Create(context.Background(), &domain.BankAccount{UserID: user.ID, AccountType: "celengan",
+			AccountNumber: numbered("celengan", 1)})
+		assert.NoError(t, err)
+
+		account, err := bankRepo.Create(context.Background(), &domain.BankAccount{UserID: user.ID, AccountType: "celengan"})
+		assert.NoError(t, err)
+		assert.NotEqual(t, assigned.AccountNumber, account.AccountNumber)
+		assert.Equal(t, numbered("celengan", 2), account.AccountNumber)
+	})
+
+	t.Run("gives up when the serials ran out", func(t *testing.T) {
+		assert.NoError(t, gormDB.Create(&domain.AccountNumberSequence{ProductCode: "104", LastSerial: 999999}).Error)
+
+		_, err := bankRepo.Create(context.Background(), &domain.BankAccount{UserID: user.ID, AccountType: "deposito"})
+		assert.ErrorIs(t, err, repository.ErrAccountNumberExhausted)
+
+		status, code, _, _ := middleware.MapError("en", err)
+		assert.Equal(t, http.StatusConflict, status)
+		assert.Equal(t, "account_number_exhausted", code)
+	})
+
+	t.Run("keeps an assigned number", func(t *testing.T) {
+		account, err := bankRepo.Create(context.Background(), &domain.BankAccount{UserID: user.ID, AccountType: "celengan", AccountNumber: "1030000005"})
+		assert.NoError(t, err)
+		assert.Equal(t, "1030000005", account.AccountNumber)
+	})
+}
+
+func TestAccountNumberValidation(t *testing.T) {
+	client := newAPIClient(t)
+
+	payer := client.openAccount("payer", 100000)
+
+	transfer := func(toAccountNumber string) *httptest.ResponseRecorder {
+		return client.serveOwner(payer, http.MethodPost, "/api/v2/transactions", fmt.Sprintf(
+			`{"from_account_number": "%s", "to_account_number": "%s", "transaction_type": "transfer", "amount": 10000}`,
+			payer.AccountNumber, toAccountNumber))
+	}
+
+	t.Run("rejects a typo before any lookup", func(t *testing.T) {
+		typo := []byte(payer.AccountNumber)
+		typo[3] = '0' + (typo[3]-'0'+1)%10
+
+		recorder := transfer(string(typo))
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, recorder.Body.String())
+	})
+
+	t.Run("looks up a well-formed number in the service", func(t *testing.T) {
+		unknown, err := domain.DefaultAccountNumberScheme.Generate("deposito", 999999)
+		assert.NoError(t, err)
+
+		recorder := transfer(unknown)
+		assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code, recorder.Body.String())
+		assert.Contains(t, recorder.Body.String(), "account_not_found")
+	})
+}
@@ -0,0 +1,246 @@
+package services_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/okyws/dashboard-backend/adapter/metrics"
+	"github.com/okyws/dashboard-backend/config"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/dto"
+	"github.com/okyws/dashboard-backend/middleware"
+	"github.com/okyws/dashboard-backend/ports"
+	"github.com/okyws/dashboard-backend/routes"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// apiClient serves requests of an admin on the API routes over a fresh sqlite database
+type apiClient struct {
+	t       *testing.T
+	db      *gorm.DB
+	router  *gin.Engine
+	token   string
+	workers []ports.BackgroundWorker
+}
+
+func newAPIClient(t *testing.T) *apiClient {
+	gin.SetMode(gin.TestMode)
+
+	configuration := &domain.Configuration{DBDriver: domain.DriverSQLite, DBPath: filepath.Join(t.TempDir(), "api.db"),
+		StoreDriver: domain.StoreMemory, JWTSecret: "secret", JWTExpiry: time.Hour, APIV1Sunset: "2027-06-30"}
+
+	db, err := config.NewDBConnectionENV(configuration)
+	assert.NoError(t, err)
+	t.Cleanup(func() { config.CloseDatabase(db) })
+	assert.NoError(t, config.MigrateDB(db))
+
+	router := gin.New()
+	router.Use(middleware.LanguageMiddleware(), middleware.ErrorMiddleware())
+
+	workers, err := routes.RegisterRoutes(router, db, nil, configuration, metrics.New())
+	assert.NoError(t, err)
+
+	token, _, err := config.NewJWTManager(configuration.JWTSecret, time.Hour).GenerateJWT(uuid.New(), "admin", "admin")
+	assert.NoError(t, err)
+
+	return &apiClient{t: t, db: db, router: router, token: token, workers: workers}
+}
+
+// serveAs sends the request with the content type and the headers given as name and value pairs and returns the response
+func (a *apiClient) serveAs(method, path, contentType, body string, headers ...string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set("Authorization", "Bearer "+a.token)
+	request.Header.Set("Content-Type", contentType)
+
+	for i := 0; i+1 < len(headers); i += 2 {
+		request.Header.Set(headers[i], headers[i+1])
+	}
+
+	recorder := httptest.NewRecorder()
+	a.router.ServeHTTP(recorder, request)
+
+	return recorder
+}
+
+// serve sends the request with a JSON body and returns the response
+func (a *apiClient) serve(method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	return a.serveAs(method, path, "application/json", body, headers...)
+}
+
+// etag returns the ETag the resource is served with
+func (a *apiClient) etag(path string) string {
+	recorder := a.serve(http.MethodGet, path, "")
+	assert.Equal(a.t, http.StatusOK, recorder.Code, path)
+
+	return recorder.Header().Get("ETag")
+}
+
+func TestAPIVersions(t *testing.T) {
+	client := newAPIClient(t)
+	serve := client.serve
+
+	user := func(recorder *httptest.ResponseRecorder) dto.UserDTO {
+		var resp dto.SuccessResponseDTO[dto.UserDTO]
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+
+		return resp.Data
+	}
+
+	var location string
+
+	t.Run("v2 creates with 201 and the Location of the resource", func(t *testing.T) {
+		recorder := serve(http.MethodPost, "/api/v2/users", `{"email": "alice@example.com", "username": "alice", "password": "secret1", "role": "user"}`)
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+
+		created := user(recorder)
+		location = recorder.Header().Get("Location")
+		assert.Equal(t, "/api/v2/users/"+created.ID.String(), location)
+		assert.Empty(t, recorder.Header().Get("Deprecation"))
+
+		recorder = serve(http.MethodGet, location, "")
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "alice", user(recorder).Username)
+	})
+
+	t.Run("v2 PATCH changes only the given fields", func(t *testing.T) {
+		recorder := serve(http.MethodPatch, location, `{"email": "alice@example.org"}`, "If-Match", client.etag(location))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		patched := user(recorder)
+		assert.Equal(t, "alice@example.org", patched.Email)
+		assert.Equal(t, "alice", patched.Username)
+		assert.Equal(t, "user", patched.Role)
+
+		recorder = serve(http.MethodPatch, location, `{"username": "al"}`, "If-Match", client.etag(location))
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, recorder.Body.String())
+	})
+
+	t.Run("v2 filters the collection instead of a lookup path", func(t *testing.T) {
+		var resp dto.SuccessResponseDTO[[]dto.UserDTO]
+
+		assert.NoError(t, json.Unmarshal(serve(http.MethodGet, "/api/v2/users?username=alice", "").Body.Bytes(), &resp))
+		assert.Len(t, resp.Data, 1)
+
+		assert.NoError(t, json.Unmarshal(serve(http.MethodGet, "/api/v2/users?username=bob", "").Body.Bytes(), &resp))
+		assert.Empty(t, resp.Data)
+	})
+
+	t.Run("v1 stays mounted and announces its sunset", func(t *testing.T) {
+		recorder := serve(http.MethodGet, "/api/v1/users/", "")
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "@1792368000", recorder.Header().Get("Deprecation"))
+		assert.Equal(t, "Wed, 30 Jun 2027 00:00:00 GMT", recorder.Header().Get("Sunset"))
+		assert.Equal(t, `</api/v2>; rel="successor-version"`, recorder.Header().Get("Link"))
+
+		recorder = serve(http.MethodPost, "/api/v1/users/add", `{"email": "bob@example.com", "username": "bob", "password": "secret1", "role": "user"}`)
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+		assert.Equal(t, "/api/v1/users/"+user(recorder).ID.String(), recorder.Header().Get("Location"))
+
+		recorder = serve(http.MethodDelete, "/api/v1/users/"+user(recorder).ID.String()+"/delete", "")
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "User deleted successfully")
+	})
+
+	t.Run("v2 deletes with 204", func(t *testing.T) {
+		recorder := serve(http.MethodDelete, location, "", "If-Match", client.etag(location))
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+		assert.Empty(t, recorder.Body.String())
+
+		assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, location, "").Code)
+	})
+}
+
+func TestV2AccountTransactions(t *testing.T) {
+	client := newAPIClient(t)
+
+	payer := client.openAccount("payer", 100000)
+	payee := client.openAccount("payee", 50000)
+
+	recorder := client.serveOwner(payer, http.MethodPost, "/api/v2/transactions", fmt.Sprintf(
+		`{"from_account_number": "%s", "to_account_number": "%s", "transaction_type": "transfer", "amount": 25000}`,
+		payer.AccountNumber, payee.AccountNumber))
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	transactionsOf := func(recorder *httptest.ResponseRecorder) []dto.TransactionDTO {
+		var resp dto.SuccessResponseDTO[[]dto.TransactionDTO]
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+
+		return resp.Data
+	}
+
+	t.Run("looks the account up by its ID", func(t *testing.T) {
+		recorder := client.serveOwner(payer, http.MethodGet, "/api/v2/accounts/"+payer.ID.String()+"/transactions", "")
+		assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+		transactions := transactionsOf(recorder)
+		assert.Len(t, transactions, 1)
+		assert.Equal(t, payee.AccountNumber, transactions[0].ToAccountNumber)
+	})
+
+	t.Run("answers 404 for the account of another user", func(t *testing.T) {
+		recorder := client.serveOwner(payee, http.MethodGet, "/api/v2/accounts/"+payer.ID.String()+"/transactions", "")
+		assert.Equal(t, http.StatusNotFound, recorder.Code, recorder.Body.String())
+	})
+
+	t.Run("shows the transactions of any account to an admin", func(t *testing.T) {
+		recorder := client.serve(http.MethodGet, "/api/v2/accounts/"+payee.ID.String()+"/transactions", "")
+		assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+		assert.Len(t, transactionsOf(recorder), 1)
+	})
+
+	t.Run("answers 404 for an unknown account", func(t *testing.T) {
+		recorder := client.serve(http.MethodGet, "/api/v2/accounts/"+uuid.NewString()+"/transactions", "")
+		assert.Equal(t, http.StatusNotFound, recorder.Code, recorder.Body.String())
+	})
+}
+
+func TestV2AccountOwnership(t *testing.T) {
+	client := newAPIClient(t)
+
+	owner := client.openAccount("owner", 100000)
+	other := client.openAccount("other", 100000)
+
+	var savings dto.SuccessResponseDTO[dto.BankAccountDTO]
+	recorder := client.serve(http.MethodPost, "/api/v2/accounts", fmt.Sprintf(`{"user_id": "%s", "account_type": "saku", "balance": 5000}`, owner.UserID))
+	assert.Equal(t, http.StatusCreated, recorder.Code, recorder.Body.String())
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &savings))
+
+	t.Run("lists the accounts of the caller and to admins only", func(t *testing.T) {
+		path := "/api/v2/users/" + owner.UserID.String() + "/accounts"
+
+		assert.Equal(t, http.StatusOK, client.serveOwner(owner, http.MethodGet, path, "").Code)
+		assert.Equal(t, http.StatusNotFound, client.serveOwner(other, http.MethodGet, path, "").Code)
+		assert.Equal(t, http.StatusOK, client.serve(http.MethodGet, path, "").Code)
+	})
+
+	t.Run("moves money only from the caller's own accounts", func(t *testing.T) {
+		for _, body := range []string{
+			fmt.Sprintf(`{"from_account_number": "%s", "to_account_number": "%s", "transaction_type": "transfer", "amount": 10000}`,
+				owner.AccountNumber, other.AccountNumber),
+			fmt.Sprintf(`{"from_account_number": "%s", "transaction_type": "withdraw", "amount": 10000}`, owner.AccountNumber),
+		} {
+			recorder := client.serveOwner(other, http.MethodPost, "/api/v2/transactions", body)
+			assert.Equal(t, http.StatusForbidden, recorder.Code, recorder.Body.String())
+			assert.Contains(t, recorder.Body.String(), "account_not_owned")
+		}
+
+		assert.Equal(t, 100000.0, client.balance(owner))
+		assert.Equal(t, 100000.0, client.balance(other))
+	})
+
+	t.Run("deletes only the caller's own accounts", func(t *testing.T) {
+		path := "/api/v2/accounts/" + savings.Data.ID.String()
+
+		assert.Equal(t, http.StatusNotFound, client.serveOwner(other, http.MethodDelete, path, "", "If-Match", "*").Code)
+		assert.Equal(t, http.StatusNoContent, client.serveOwner(owner, http.MethodDelete, path, "", "If-Match", "*").Code)
+	})
+}
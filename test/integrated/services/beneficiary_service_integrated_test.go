@@ -0,0 +1,144 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/okyws/dashboard-backend/adapter/metrics"
+	"github.com/okyws/dashboard-backend/adapter/repository"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/dto"
+	"github.com/okyws/dashboard-backend/middleware"
+	"github.com/okyws/dashboard-backend/services"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestBeneficiaryService(t *testing.T) {
+	gormDB := newEventTestDB(t)
+	assert.NoError(t, gormDB.AutoMigrate(&domain.TransferBatch{}, &domain.TransferBatchItem{}))
+
+	bankAccountRepository := repository.NewBankAccountRepositoryAdapter(gormDB, domain.DefaultAccountNumberScheme)
+	customerRepository := repository.NewCustomerRepositoryAdapter(gormDB)
+	transactionRepository := repository.NewTransactionRepositoryAdapter(gormDB)
+	unitOfWork := repository.NewUnitOfWork(gormDB)
+
+	beneficiaryService := services.NewBeneficiaryService(repository.NewBeneficiaryRepositoryAdapter(gormDB), bankAccountRepository, customerRepository,
+		transactionRepository, 24*time.Hour, 50000)
+	transactionValidator := services.NewTransactionValidator(transactionRepository, bankAccountRepository,
+		repository.NewOutboxRepositoryAdapter(gormDB), beneficiaryService)
+	transactionService := services.NewTransactionService(unitOfWork, transactionRepository, bankAccountRepository, transactionValidator,
+		beneficiaryService, metrics.Nop{})
+	transferBatchService := services.NewTransferBatchService(unitOfWork, repository.NewTransferBatchRepositoryAdapter(gormDB),
+		bankAccountRepository, transactionValidator, middleware.MapError, metrics.Nop{})
+
+	alice := createUserWithAccount(t, gormDB, "alice")
+	bob := createUserWithAccount(t, gormDB, "bob")
+
+	_, err := customerRepository.Create(context.Background(), &domain.Customer{UserID: bob.UserID, FullName: "Bob Santoso", PhoneNumber: "081234567890", DateOfBirth: time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)})
+	assert.NoError(t, err)
+
+	var payee *domain.Beneficiary
+
+	t.Run("Saves a verified account with the masked holder name", func(t *testing.T) {
+		payee, err = beneficiaryService.CreateBeneficiary(context.Background(), alice.UserID, "Bob", bob.AccountNumber)
+		assert.NoError(t, err)
+		assert.Equal(t, "B** S******", payee.HolderName)
+		assert.True(t, payee.CoolingOff(time.Now()))
+
+		_, err = beneficiaryService.CreateBeneficiary(context.Background(), alice.UserID, "Bob again", bob.AccountNumber)
+		assert.ErrorIs(t, err, services.ErrBeneficiaryExists)
+
+		_, err = beneficiaryService.CreateBeneficiary(context.Background(), alice.UserID, "Nobody", "0000000000")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("Skips the cooling-off period for the user's own accounts", func(t *testing.T) {
+		own, err := beneficiaryService.CreateBeneficiary(context.Background(), alice.UserID, "Savings", alice.AccountNumber)
+		assert.NoError(t, err)
+		assert.False(t, own.CoolingOff(time.Now()))
+		assert.Empty(t, own.HolderName)
+	})
+
+	t.Run("Limits the total transferred during the cooling-off period", func(t *testing.T) {
+		err := transactionService.ProcessTransaction(context.Background(), alice.AccountNumber, bob.AccountNumber, "transfer", 60000)
+		assert.ErrorIs(t, err, services.ErrBeneficiaryCoolingOff)
+
+		err = transactionService.ProcessBeneficiaryTransfer(context.Background(), alice.UserID, alice.AccountNumber, payee.ID.String(), 30000)
+		assert.NoError(t, err)
+
+		// the limit applies to the total sent to the account, whether the transfer names the beneficiary or not
+		err = transactionService.ProcessTransaction(context.Background(), alice.AccountNumber, bob.AccountNumber, "transfer", 30000)
+		assert.ErrorIs(t, err, services.ErrBeneficiaryCoolingOff)
+
+		batch, err := transferBatchService.Process(context.Background(), alice.UserID, &dto.TransferBatchCreateDTO{
+			FromAccountNumber: alice.AccountNumber,
+			Mode:              domain.TransferBatchModeBestEffort,
+			Items:             []dto.TransferBatchItemCreateDTO{{ToAccountNumber: bob.AccountNumber, Amount: 30000}},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, domain.TransferItemStatusFailed, batch.Items[0].Status)
+		assert.Equal(t, "beneficiary_cooling_off", batch.Items[0].ErrorCode)
+
+		err = transactionService.ProcessTransaction(context.Background(), alice.AccountNumber, bob.AccountNumber, "transfer", 20000)
+		assert.NoError(t, err)
+
+		account, err := bankAccountRepository.GetByAccountNumber(context.Background(), bob.AccountNumber)
+		assert.NoError(t, err)
+		assert.Equal(t, float64(150000), account.Balance)
+	})
+
+	t.Run("Keeps the period when the beneficiary is deleted and saved again", func(t *testing.T) {
+		assert.NoError(t, beneficiaryService.DeleteBeneficiary(context.Background(), alice.UserID, payee.ID.String()))
+
+		again, err := beneficiaryService.CreateBeneficiary(context.Background(), alice.UserID, "Bob", bob.AccountNumber)
+		assert.NoError(t, err)
+
+		err = transactionService.ProcessBeneficiaryTransfer(context.Background(), alice.UserID, alice.AccountNumber, again.ID.String(), 10000)
+		assert.ErrorIs(t, err, services.ErrBeneficiaryCoolingOff)
+
+		payee = again
+	})
+
+	t.Run("Limits transfers to an account that is not a beneficiary", func(t *testing.T) {
+		carol := createUserWithAccount(t, gormDB, "carol")
+
+		err := transactionService.ProcessTransaction(context.Background(), carol.AccountNumber, bob.AccountNumber, "transfer", 60000)
+		assert.ErrorIs(t, err, services.ErrBeneficiaryCoolingOff)
+
+		// the transfers of alice do not count against the limit of carol
+		err = transactionService.ProcessTransaction(context.Background(), carol.AccountNumber, bob.AccountNumber, "transfer", 50000)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Ends the period after it passed since the first transfer", func(t *testing.T) {
+		assert.NoError(t, gormDB.Model(&domain.Transaction{}).Where("to_account_number = ?", bob.AccountNumber).
+			Update("created_at", time.Now().Add(-25*time.Hour)).Error)
+
+		// alice already sent the limit, within the period this would be refused
+		err := transactionService.ProcessTransaction(context.Background(), alice.AccountNumber, bob.AccountNumber, "transfer", 10000)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Keeps the beneficiary book private to its user", func(t *testing.T) {
+		_, err := beneficiaryService.ResolveTransfer(context.Background(), bob.UserID, payee.ID.String())
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		_, err = beneficiaryService.UpdateBeneficiary(context.Background(), bob.UserID, payee.ID.String(), "Mine")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		assert.ErrorIs(t, beneficiaryService.DeleteBeneficiary(context.Background(), bob.UserID, payee.ID.String()), gorm.ErrRecordNotFound)
+	})
+
+	t.Run("Renames and deletes a beneficiary", func(t *testing.T) {
+		renamed, err := beneficiaryService.UpdateBeneficiary(context.Background(), alice.UserID, payee.ID.String(), "Bobby")
+		assert.NoError(t, err)
+		assert.Equal(t, "Bobby", renamed.Nickname)
+
+		assert.NoError(t, beneficiaryService.DeleteBeneficiary(context.Background(), alice.UserID, payee.ID.String()))
+
+		_, err = beneficiaryService.CreateBeneficiary(context.Background(), alice.UserID, "Bob", bob.AccountNumber)
+		assert.NoError(t, err)
+	})
+}
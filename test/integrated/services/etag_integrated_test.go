@@ -0,0 +1,118 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/okyws/dashboard-backend/adapter/repository"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestETags(t *testing.T) {
+	client := newAPIClient(t)
+
+	var createdUser dto.SuccessResponseDTO[dto.UserDTO]
+
+	recorder := client.serve(http.MethodPost, "/api/v2/users", `{"email": "citra@example.com", "username": "citra", "password": "secret1", "role": "customer"}`)
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Equal(t, `"1"`, recorder.Header().Get("ETag"))
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &createdUser))
+
+	recorder = client.serve(http.MethodPost, "/api/v2/customers", `{"user_id": "`+createdUser.Data.ID.String()+
+		`", "full_name": "Citra Lestari", "phone_number": "+6281234567891", "date_of_birth": "1992-03-04"}`)
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+
+	customerPath := recorder.Header().Get("Location")
+	userPath := "/api/v2/users/" + createdUser.Data.ID.String()
+
+	patch := func(path, etag, body string) int {
+		return client.serveAs(http.MethodPatch, path, "application/merge-patch+json", body, "If-Match", etag).Code
+	}
+
+	t.Run("every write gives the resource a new ETag", func(t *testing.T) {
+		assert.Equal(t, `"1"`, client.etag(customerPath))
+
+		assert.Equal(t, http.StatusOK, patch(customerPath, `"1"`, `{"address": "Jl. Sudirman 2"}`))
+		assert.Equal(t, `"2"`, client.etag(customerPath))
+	})
+
+	t.Run("the second of two admins editing the same version is refused", func(t *testing.T) {
+		etag := client.etag(customerPath)
+
+		assert.Equal(t, http.StatusOK, patch(customerPath, etag, `{"full_name": "Citra Lestari Putri"}`))
+
+		recorder := client.serveAs(http.MethodPatch, customerPath, "application/merge-patch+json", `{"full_name": "Citra L."}`, "If-Match", etag)
+		assert.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "version_mismatch")
+
+		var resp dto.SuccessResponseDTO[dto.CustomerDTO]
+		assert.NoError(t, json.Unmarshal(client.serve(http.MethodGet, customerPath, "").Body.Bytes(), &resp))
+		assert.Equal(t, "Citra Lestari Putri", resp.Data.FullName)
+	})
+
+	t.Run("v2 changes need If-Match", func(t *testing.T) {
+		recorder := client.serveAs(http.MethodPatch, userPath, "application/merge-patch+json", `{"role": "user"}`)
+		assert.Equal(t, http.StatusPreconditionRequired, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "if_match_required")
+
+		assert.Equal(t, http.StatusPreconditionRequired, client.serve(http.MethodDelete, userPath, "").Code)
+		assert.Equal(t, http.StatusPreconditionFailed, patch(userPath, `W/"1"`, `{"role": "user"}`))
+		assert.Equal(t, http.StatusOK, patch(userPath, "*", `{"role": "user"}`))
+	})
+
+	t.Run("v1 keeps writing without a precondition but honours one", func(t *testing.T) {
+		body := `{"full_name": "Citra", "phone_number": "+6281234567891", "date_of_birth": "1992-03-04"}`
+		v1Path := "/api/v1/customers/" + customerPath[len("/api/v2/customers/"):] + "/update"
+
+		assert.Equal(t, http.StatusPreconditionFailed, client.serve(http.MethodPut, v1Path, body, "If-Match", `"1"`).Code)
+
+		recorder := client.serve(http.MethodPut, v1Path, body)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, recorder.Header().Get("ETag"), client.etag(customerPath))
+	})
+
+	t.Run("deletes only the version read", func(t *testing.T) {
+		assert.Equal(t, http.StatusPreconditionFailed, client.serve(http.MethodDelete, customerPath, "", "If-Match", `"1"`).Code)
+		assert.Equal(t, http.StatusNoContent, client.serve(http.MethodDelete, customerPath, "", "If-Match", client.etag(customerPath)).Code)
+		assert.Equal(t, http.StatusNotFound, client.serve(http.MethodDelete, customerPath, "", "If-Match", `"1"`).Code)
+	})
+}
+
+func TestBankAccountLostUpdate(t *testing.T) {
+	gormDB := newEventTestDB(t)
+	bankAccountRepository := repository.NewBankAccountRepositoryAdapter(gormDB, domain.DefaultAccountNumberScheme)
+	account := createUserWithAccount(t, gormDB, "dewi")
+
+	// two deposits computed their balance from the same read
+	first, err := bankAccountRepository.GetByID(context.Background(), account.ID.String())
+	assert.NoError(t, err)
+
+	second, err := bankAccountRepository.GetByID(context.Background(), account.ID.String())
+	assert.NoError(t, err)
+
+	first.Balance += 5000
+	second.Balance += 7000
+
+	updated, err := bankAccountRepository.Update(context.Background(), first)
+	assert.NoError(t, err)
+	assert.Equal(t, account.Version+1, updated.Version)
+
+	_, err = bankAccountRepository.Update(context.Background(), second)
+	assert.ErrorIs(t, err, domain.ErrVersionMismatch)
+
+	stored, err := bankAccountRepository.GetByID(context.Background(), account.ID.String())
+	assert.NoError(t, err)
+	assert.Equal(t, account.Balance+5000, stored.Balance)
+
+	_, err = bankAccountRepository.UpdateStatus(context.Background(), account.ID.String(), account.Version, false)
+	assert.ErrorIs(t, err, domain.ErrVersionMismatch)
+
+	frozen, err := bankAccountRepository.UpdateStatus(context.Background(), account.ID.String(), stored.Version, false)
+	assert.NoError(t, err)
+	assert.False(t, frozen.AccountStatus)
+	assert.Equal(t, stored.Version+1, frozen.Version)
+}
@@ -0,0 +1,126 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/okyws/dashboard-backend/adapter/healthcheck"
+	"github.com/okyws/dashboard-backend/config"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/dto"
+	"github.com/okyws/dashboard-backend/routes"
+	"github.com/okyws/dashboard-backend/services"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// failingCheck is a dependency that is never available
+type failingCheck struct{}
+
+func (failingCheck) Name() string                  { return "broken" }
+func (failingCheck) Check(_ context.Context) error { return errors.New("connection refused") }
+
+func getJSON(t *testing.T, router *gin.Engine, path string, body any) int {
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), body))
+
+	return recorder.Code
+}
+
+func TestHealthEndpoints(t *testing.T) {
+	configuration := &domain.Configuration{AppName: "Dashboard Test", AppVersion: "1.2.3", DBDriver: domain.DriverSQLite,
+		DBPath: filepath.Join(t.TempDir(), "health.db")}
+
+	db, err := config.NewDBConnectionENV(configuration)
+	assert.NoError(t, err)
+	t.Cleanup(func() { config.CloseDatabase(db) })
+
+	redisServer := miniredis.RunT(t)
+	redisClient := redis.NewClient(&redis.Options{Addr: redisServer.Addr()})
+	t.Cleanup(func() { config.CloseRedisClient(redisClient) })
+
+	migrator, err := config.NewMigrator(db)
+	assert.NoError(t, err)
+
+	healthService := services.NewHealthService(healthcheck.NewDatabaseCheck(db), healthcheck.NewMigrationCheck(migrator),
+		healthcheck.NewRedisCheck(redisClient))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	routes.RegisterHealthRoutes(router, healthService, configuration)
+
+	t.Run("healthz reports the process alive", func(t *testing.T) {
+		var health dto.HealthResponseDTO
+		assert.Equal(t, http.StatusOK, getJSON(t, router, "/healthz", &health))
+		assert.Equal(t, dto.HealthStatusOK, health.Status)
+	})
+
+	t.Run("readyz fails while migrations are pending", func(t *testing.T) {
+		var readiness dto.ReadinessResponseDTO
+		assert.Equal(t, http.StatusServiceUnavailable, getJSON(t, router, "/readyz", &readiness))
+		assert.Equal(t, dto.HealthStatusFail, readiness.Status)
+		assert.Equal(t, dto.HealthStatusOK, readiness.Checks["database"].Status)
+		assert.Equal(t, dto.HealthStatusOK, readiness.Checks["redis"].Status)
+		assert.Equal(t, dto.HealthStatusFail, readiness.Checks["migrations"].Status)
+		assert.Contains(t, readiness.Checks["migrations"].Error, "pending migrations")
+	})
+
+	t.Run("readyz succeeds once migrated", func(t *testing.T) {
+		assert.NoError(t, config.MigrateDB(db))
+
+		var readiness dto.ReadinessResponseDTO
+		assert.Equal(t, http.StatusOK, getJSON(t, router, "/readyz", &readiness))
+		assert.Equal(t, dto.HealthStatusOK, readiness.Status)
+		assert.Len(t, readiness.Checks, 3)
+	})
+
+	t.Run("readyz reports the failing dependency", func(t *testing.T) {
+		redisServer.Close()
+		defer func() { assert.NoError(t, redisServer.Restart()) }()
+
+		var readiness dto.ReadinessResponseDTO
+		assert.Equal(t, http.StatusServiceUnavailable, getJSON(t, router, "/readyz", &readiness))
+		assert.Equal(t, dto.HealthStatusFail, readiness.Checks["redis"].Status)
+		assert.NotEmpty(t, readiness.Checks["redis"].Error)
+		assert.Equal(t, dto.HealthStatusOK, readiness.Checks["database"].Status)
+	})
+
+	t.Run("version reports the build", func(t *testing.T) {
+		var version dto.VersionResponseDTO
+		assert.Equal(t, http.StatusOK, getJSON(t, router, "/version", &version))
+		assert.Equal(t, "Dashboard Test", version.AppName)
+		assert.Equal(t, "1.2.3", version.AppVersion)
+		assert.NotEmpty(t, version.GitCommit)
+		assert.NotEmpty(t, version.BuildTime)
+		assert.NotEmpty(t, version.GoVersion)
+	})
+
+	t.Run("readyz fails while draining, healthz does not", func(t *testing.T) {
+		healthService.Drain()
+
+		var readiness dto.ReadinessResponseDTO
+		assert.Equal(t, http.StatusServiceUnavailable, getJSON(t, router, "/readyz", &readiness))
+		assert.Equal(t, dto.HealthStatusDraining, readiness.Status)
+
+		var health dto.HealthResponseDTO
+		assert.Equal(t, http.StatusOK, getJSON(t, router, "/healthz", &health))
+	})
+}
+
+func TestHealthServiceReportsEveryCheck(t *testing.T) {
+	readiness, ready := services.NewHealthService(failingCheck{}).Readiness(context.Background())
+	assert.False(t, ready)
+	assert.Equal(t, "connection refused", readiness.Checks["broken"].Error)
+
+	readiness, ready = services.NewHealthService().Readiness(context.Background())
+	assert.True(t, ready)
+	assert.Empty(t, readiness.Checks)
+}
@@ -0,0 +1,183 @@
+package services_test
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/dto"
+	"github.com/okyws/dashboard-backend/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// runImports runs the submitted imports like the import worker does
+func (a *apiClient) runImports() {
+	for _, worker := range a.workers {
+		if importService, ok := worker.(*services.ImportService); ok {
+			importService.RunOnce(context.Background())
+			return
+		}
+	}
+
+	a.t.Fatal("no import worker")
+}
+
+// importJob submits the file and returns the import once the worker ran it
+func (a *apiClient) importJob(contentType, mode, file string) (dto.ImportJobDTO, [][]string) {
+	recorder := a.serveAs(http.MethodPost, "/api/v2/imports?mode="+mode, contentType, file)
+	assert.Equal(a.t, http.StatusAccepted, recorder.Code, recorder.Body.String())
+
+	var submitted dto.SuccessResponseDTO[dto.ImportJobDTO]
+	assert.NoError(a.t, json.Unmarshal(recorder.Body.Bytes(), &submitted))
+	assert.Equal(a.t, domain.ImportStatusPending, submitted.Data.Status)
+
+	location := recorder.Header().Get("Location")
+	assert.Equal(a.t, "/api/v2/imports/"+submitted.Data.ID.String(), location)
+
+	a.runImports()
+
+	var finished dto.SuccessResponseDTO[dto.ImportJobDTO]
+	assert.NoError(a.t, json.Unmarshal(a.serve(http.MethodGet, location, "").Body.Bytes(), &finished))
+
+	recorder = a.serve(http.MethodGet, location+"/errors", "")
+	assert.Equal(a.t, http.StatusOK, recorder.Code)
+	assert.Equal(a.t, "text/csv; charset=utf-8", recorder.Header().Get("Content-Type"))
+
+	report, err := csv.NewReader(recorder.Body).ReadAll()
+	assert.NoError(a.t, err)
+	assert.Equal(a.t, []string{"line", "field", "code", "message"}, report[0])
+
+	return finished.Data, report[1:]
+}
+
+// usernameExists reports whether a user with the username was created
+func (a *apiClient) usernameExists(username string) bool {
+	var resp dto.SuccessResponseDTO[[]dto.UserDTO]
+	assert.NoError(a.t, json.Unmarshal(a.serve(http.MethodGet, "/api/v2/users?username="+username, "").Body.Bytes(), &resp))
+
+	return len(resp.Data) == 1
+}
+
+func TestImports(t *testing.T) {
+	client := newAPIClient(t)
+
+	t.Run("best effort imports the valid rows and reports the others", func(t *testing.T) {
+		file := strings.Join([]string{
+			"type,username,email,password,role,full_name,phone_number,date_of_birth,address,account_type,balance",
+			"user,ani,ani@example.com,secret1,customer,,,,,,",
+			"customer,ani,,,,Ani Wijaya,+6281234567800,1991-02-03,\"Jl. Melati 1, Bandung\",,",
+			"account,ani,,,,,,,,rekening-utama,150000",
+			"user,bad,not-an-email,secret1,customer,,,,,,",
+			"customer,nobody,,,,Nobody Here,+6281234567801,1990-01-01,,,",
+			"user,ani,ani2@example.com,secret1,customer,,,,,,",
+			"account,ani,,,,,,,,celengan,abc",
+		}, "\n")
+
+		job, report := client.importJob("text/csv", domain.ImportModeBestEffort, file)
+
+		assert.Equal(t, domain.ImportStatusCompletedWithErrors, job.Status)
+		assert.Equal(t, 7, job.TotalRows)
+		assert.Equal(t, 7, job.ProcessedRows)
+		assert.Equal(t, 3, job.ImportedRows)
+		assert.Equal(t, 4, job.FailedRows)
+		assert.NotNil(t, job.FinishedAt)
+
+		assert.Equal(t, [][]string{
+			{"5", "email", "email", "email must be an email address"},
+			{"6", "username", "user_not_found", "no user has this username"},
+			{"7", "", "username_taken", "username already exists"},
+			{"8", "balance", "type", "balance must be of type float64"},
+		}, report)
+
+		assert.True(t, client.usernameExists("ani"))
+		assert.False(t, client.usernameExists("bad"))
+	})
+
+	t.Run("all or nothing writes no row when one of them fails", func(t *testing.T) {
+		file := strings.Join([]string{
+			`{"type": "user", "username": "bayu", "email": "bayu@example.com", "password": "secret1", "role": "customer"}`,
+			``,
+			`{"type": "account", "username": "bayu", "account_type": "rekening-utama", "balance": 50000}`,
+			`{"type": "customer", "username": "ani", "full_name": "Ani Lagi", "phone_number": "+6281234567802", "date_of_birth": "1991-02-03"}`,
+		}, "\n")
+
+		job, report := client.importJob("application/jsonl", domain.ImportModeAllOrNothing, file)
+
+		assert.Equal(t, domain.ImportStatusFailed, job.Status)
+		assert.Equal(t, 3, job.ProcessedRows)
+		assert.Zero(t, job.ImportedRows)
+		assert.Equal(t, [][]string{{"4", "", "customer_exists", "user already has a customer profile"}}, report)
+
+		assert.False(t, client.usernameExists("bayu"), "the rows before the failed one are rolled back")
+	})
+
+	t.Run("all or nothing reports every invalid row before writing", func(t *testing.T) {
+		file := strings.Join([]string{
+			`{"type": "user", "username": "citra", "email": "citra@example.com", "password": "secret1", "role": "customer"}`,
+			`{"type": "robot", "username": "r2"}`,
+			`{"type": "customer", "full_name": "No Owner", "phone_number": "0812", "date_of_birth": "03-02-1991"}`,
+			`{"type": "account", "username": "citra", "account_type": "saku", "balance": "lots"}`,
+			`not json`,
+		}, "\n")
+
+		job, report := client.importJob("application/x-ndjson", domain.ImportModeAllOrNothing, file)
+
+		assert.Equal(t, domain.ImportStatusFailed, job.Status)
+		assert.Equal(t, 4, job.FailedRows)
+		assert.Equal(t, [][]string{
+			{"2", "type", "oneof", "type must be one of user customer account"},
+			{"3", "username", "required", "username is required"},
+			{"3", "phone_number", "e164", "phone_number must be a phone number such as +6281234567890"},
+			{"3", "date_of_birth", "datetime", "date_of_birth must be a date such as 1990-08-17"},
+			{"4", "balance", "type", "balance must be of type float64"},
+			{"5", "", "invalid_row", "row is not a valid JSON object"},
+		}, report)
+
+		assert.False(t, client.usernameExists("citra"))
+	})
+
+	t.Run("the report is written in the language of the submitter", func(t *testing.T) {
+		recorder := client.serveAs(http.MethodPost, "/api/v2/imports", "text/csv", "type,username\ncustomer,\n", "Accept-Language", "id")
+		assert.Equal(t, http.StatusAccepted, recorder.Code, recorder.Body.String())
+
+		client.runImports()
+
+		recorder = client.serve(http.MethodGet, recorder.Header().Get("Location")+"/errors", "")
+		assert.Contains(t, recorder.Body.String(), "username wajib diisi")
+	})
+
+	t.Run("refuses files it cannot read", func(t *testing.T) {
+		for _, tc := range []struct {
+			contentType, mode, file string
+			status                  int
+			code                    string
+		}{
+			{"text/csv", "", "type,username,nickname\nuser,dina,dee\n", http.StatusBadRequest, "invalid_import_file"},
+			{"text/csv", "", "type,username\n", http.StatusBadRequest, "invalid_import_file"},
+			{"text/csv", "", "type,\"username\nuser,dina\n", http.StatusBadRequest, "invalid_import_file"},
+			{"text/csv", "sometimes", "type,username\nuser,dina\n", http.StatusBadRequest, "invalid_import_mode"},
+			{"application/json", "", `{"type": "user"}`, http.StatusUnsupportedMediaType, "unsupported_media_type"},
+		} {
+			recorder := client.serveAs(http.MethodPost, "/api/v2/imports?mode="+tc.mode, tc.contentType, tc.file)
+			assert.Equal(t, tc.status, recorder.Code, tc.file)
+			assert.Contains(t, recorder.Body.String(), tc.code, tc.file)
+		}
+
+		recorder := client.serveAs(http.MethodPost, "/api/v2/imports", "text/csv", "type\n"+strings.Repeat("user\n", 3<<20))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+	})
+
+	t.Run("lists the imports and knows no other", func(t *testing.T) {
+		var resp dto.SuccessResponseDTO[[]dto.ImportJobDTO]
+		assert.NoError(t, json.Unmarshal(client.serve(http.MethodGet, "/api/v2/imports?limit=2", "").Body.Bytes(), &resp))
+		assert.Len(t, resp.Data, 2)
+
+		recorder := client.serve(http.MethodGet, "/api/v2/imports/00000000-0000-0000-0000-000000000000/errors", "")
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "import_not_found")
+	})
+}
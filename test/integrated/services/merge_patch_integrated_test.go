@@ -0,0 +1,118 @@
+package services_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/okyws/dashboard-backend/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergePatch(t *testing.T) {
+	client := newAPIClient(t)
+
+	patch := func(path, body string) (int, []byte) {
+		recorder := client.serveAs(http.MethodPatch, path, "application/merge-patch+json", body, "If-Match", client.etag(path))
+		return recorder.Code, recorder.Body.Bytes()
+	}
+
+	var createdUser dto.SuccessResponseDTO[dto.UserDTO]
+
+	recorder := client.serve(http.MethodPost, "/api/v2/users", `{"email": "budi@example.com", "username": "budi", "password": "secret1", "role": "customer"}`)
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &createdUser))
+
+	var createdCustomer dto.SuccessResponseDTO[dto.CustomerDTO]
+
+	recorder = client.serve(http.MethodPost, "/api/v2/customers", `{"user_id": "`+createdUser.Data.ID.String()+
+		`", "full_name": "Budi Santoso", "phone_number": "+6281234567890", "date_of_birth": "1990-08-17", "address": "Jl. Merdeka 1"}`)
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &createdCustomer))
+
+	userPath := "/api/v2/users/" + createdUser.Data.ID.String()
+	customerPath := "/api/v2/customers/" + createdCustomer.Data.ID.String()
+
+	customer := func(body []byte) dto.CustomerDTO {
+		var resp dto.SuccessResponseDTO[dto.CustomerDTO]
+		assert.NoError(t, json.Unmarshal(body, &resp))
+
+		return resp.Data
+	}
+
+	t.Run("sets the patched members and leaves the others untouched", func(t *testing.T) {
+		status, body := patch(customerPath, `{"full_name": "Budi Santoso Wijaya", "date_of_birth": "1991-01-02"}`)
+		assert.Equal(t, http.StatusOK, status, string(body))
+
+		patched := customer(body)
+		assert.Equal(t, "Budi Santoso Wijaya", patched.FullName)
+		assert.Equal(t, time.Date(1991, time.January, 2, 0, 0, 0, 0, time.UTC), patched.DateOfBirth.UTC())
+		assert.Equal(t, "+6281234567890", patched.PhoneNumber)
+		assert.Equal(t, "Jl. Merdeka 1", patched.Address)
+	})
+
+	t.Run("null clears an optional member", func(t *testing.T) {
+		status, body := patch(customerPath, `{"address": null}`)
+		assert.Equal(t, http.StatusOK, status, string(body))
+		assert.Empty(t, customer(body).Address)
+
+		// stored, not only answered
+		recorder := client.serve(http.MethodGet, customerPath, "")
+		assert.Empty(t, customer(recorder.Body.Bytes()).Address)
+		assert.Equal(t, "Budi Santoso Wijaya", customer(recorder.Body.Bytes()).FullName)
+	})
+
+	t.Run("an empty patch changes nothing", func(t *testing.T) {
+		status, body := patch(customerPath, `{}`)
+		assert.Equal(t, http.StatusOK, status, string(body))
+		assert.Equal(t, "Budi Santoso Wijaya", customer(body).FullName)
+	})
+
+	t.Run("null on a required member fails its validation", func(t *testing.T) {
+		for path, member := range map[string]string{customerPath: "phone_number", userPath: "email"} {
+			status, body := patch(path, `{"`+member+`": null}`)
+			assert.Equal(t, http.StatusBadRequest, status)
+
+			var resp dto.ErrorResponseDTO
+			assert.NoError(t, json.Unmarshal(body, &resp))
+			assert.Equal(t, "validation_failed", resp.ErrorCode)
+			assert.Equal(t, []dto.FieldErrorDTO{{Field: member, Code: "required", Message: member + " is required"}}, resp.Details)
+		}
+	})
+
+	t.Run("only the patched members are validated", func(t *testing.T) {
+		status, body := patch(customerPath, `{"full_name": "Bu", "date_of_birth": "17-08-1990"}`)
+		assert.Equal(t, http.StatusBadRequest, status)
+
+		var resp dto.ErrorResponseDTO
+		assert.NoError(t, json.Unmarshal(body, &resp))
+		assert.Len(t, resp.Details, 2)
+		assert.Equal(t, "full_name", resp.Details[0].Field)
+		assert.Equal(t, "date_of_birth", resp.Details[1].Field)
+	})
+
+	t.Run("users keep their password unless it is patched", func(t *testing.T) {
+		status, body := patch(userPath, `{"role": "user", "password": "another1"}`)
+		assert.Equal(t, http.StatusOK, status, string(body))
+
+		var resp dto.SuccessResponseDTO[dto.UserDTO]
+		assert.NoError(t, json.Unmarshal(body, &resp))
+		assert.Equal(t, "user", resp.Data.Role)
+		assert.Equal(t, "budi@example.com", resp.Data.Email)
+
+		status, _ = patch(userPath, `{"password": null}`)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("rejects patches that are not merge patch objects", func(t *testing.T) {
+		status, body := patch(userPath, `["role", "admin"]`)
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Contains(t, string(body), "invalid_body")
+
+		recorder := client.serveAs(http.MethodPatch, userPath, "application/json-patch+json", `[{"op": "remove", "path": "/role"}]`,
+			"If-Match", client.etag(userPath))
+		assert.Equal(t, http.StatusUnsupportedMediaType, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "unsupported_media_type")
+	})
+}
@@ -0,0 +1,120 @@
+package services_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/okyws/dashboard-backend/adapter/metrics"
+	"github.com/okyws/dashboard-backend/adapter/repository"
+	"github.com/okyws/dashboard-backend/config"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/middleware"
+	"github.com/okyws/dashboard-backend/routes"
+	"github.com/okyws/dashboard-backend/services"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func scrape(t *testing.T, router *gin.Engine, token string) (int, string) {
+	request := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	body, err := io.ReadAll(recorder.Body)
+	assert.NoError(t, err)
+
+	return recorder.Code, string(body)
+}
+
+func TestMetrics(t *testing.T) {
+	appMetrics := metrics.New()
+
+	configuration := &domain.Configuration{DBDriver: domain.DriverSQLite, DBPath: filepath.Join(t.TempDir(), "metrics.db")}
+	db, err := config.NewDBConnectionENV(configuration)
+	assert.NoError(t, err)
+	t.Cleanup(func() { config.CloseDatabase(db) })
+
+	assert.NoError(t, appMetrics.InstrumentDB(db))
+	assert.NoError(t, config.MigrateDB(db))
+
+	redisClient := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { config.CloseRedisClient(redisClient) })
+	appMetrics.InstrumentRedis(redisClient)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.MetricsMiddleware(appMetrics))
+	router.GET("/api/v1/users/:id", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	routes.RegisterMetricsRoute(router, appMetrics.Handler(), "scrape-token")
+
+	for _, path := range []string{"/api/v1/users/1", "/api/v1/users/2", "/unknown"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	userRepository := repository.NewUserRepositoryAdapter(db)
+	_, err = userRepository.Create(context.Background(), &domain.User{Username: "alice", Email: "alice@example.com", Password: "password", Role: "user"})
+	assert.NoError(t, err)
+
+	authService := services.NewAuthService(repository.NewAuthRepositoryRedis(redisClient), userRepository,
+		repository.NewOutboxRepositoryAdapter(db), config.NewJWTManager("secret", time.Hour), appMetrics)
+
+	_, err = authService.LoginAccount(context.Background(), "alice", "password", "192.0.2.1")
+	assert.NoError(t, err)
+
+	_, err = authService.LoginAccount(context.Background(), "alice", "wrong-password", "192.0.2.1")
+	assert.Error(t, err)
+
+	bankRepository := repository.NewBankAccountRepositoryAdapter(db, domain.DefaultAccountNumberScheme)
+	transactionRepository := repository.NewTransactionRepositoryAdapter(db)
+	transactionService := services.NewTransactionService(repository.NewUnitOfWork(db), transactionRepository, bankRepository,
+		services.NewTransactionValidator(transactionRepository, bankRepository, repository.NewOutboxRepositoryAdapter(db), nil), nil, appMetrics)
+
+	user, err := userRepository.GetUserByUsername(context.Background(), "alice")
+	assert.NoError(t, err)
+
+	account, err := bankRepository.Create(context.Background(), &domain.BankAccount{UserID: user.ID, AccountType: "saku"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, transactionService.ProcessTransaction(context.Background(), "", account.AccountNumber, "deposit", 150))
+	assert.Error(t, transactionService.ProcessTransaction(context.Background(), account.AccountNumber, "", "withdraw", 1000))
+
+	assert.ErrorIs(t, redisClient.Get(context.Background(), "missing").Err(), redis.Nil)
+
+	t.Run("requires the token", func(t *testing.T) {
+		code, _ := scrape(t, router, "wrong")
+		assert.Equal(t, http.StatusUnauthorized, code)
+	})
+
+	code, body := scrape(t, router, "scrape-token")
+	assert.Equal(t, http.StatusOK, code)
+
+	for _, expected := range []string{
+		`dashboard_http_requests_total{method="GET",route="/api/v1/users/:id",status="204"} 2`,
+		`dashboard_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`dashboard_http_request_duration_seconds_count{method="GET",route="/api/v1/users/:id"} 2`,
+		`dashboard_db_query_duration_seconds_count{operation="create",status="ok",table="users"}`,
+		`dashboard_db_query_duration_seconds_count{operation="query",status="ok",table="users"}`,
+		`dashboard_redis_command_duration_seconds_count{command="pipeline",status="ok"} 1`,
+		`dashboard_redis_command_duration_seconds_count{command="get",status="ok"} 1`,
+		`go_sql_open_connections{db_name="sqlite"}`,
+		`dashboard_logins_total{result="success"} 1`,
+		`dashboard_logins_total{result="failure"} 1`,
+		`dashboard_transactions_total{status="success",type="deposit"} 1`,
+		`dashboard_transactions_total{status="failed",type="withdraw"} 1`,
+		`dashboard_transaction_amount_total{type="deposit"} 150`,
+		`go_goroutines`,
+	} {
+		assert.Contains(t, body, expected)
+	}
+
+	assert.NotContains(t, body, `dashboard_transaction_amount_total{type="withdraw"}`)
+}
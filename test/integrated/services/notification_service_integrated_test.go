@@ -0,0 +1,144 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/okyws/dashboard-backend/adapter/metrics"
+	"github.com/okyws/dashboard-backend/adapter/notifier"
+	"github.com/okyws/dashboard-backend/adapter/repository"
+	"github.com/okyws/dashboard-backend/config"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func notificationCategories(notifications []domain.Notification) []string {
+	categories := make([]string, len(notifications))
+	for i := range notifications {
+		categories[i] = notifications[i].Category
+	}
+
+	return categories
+}
+
+func TestNotificationService(t *testing.T) {
+	gormDB := newEventTestDB(t)
+	outboxRepository := repository.NewOutboxRepositoryAdapter(gormDB)
+	userRepository := repository.NewUserRepositoryAdapter(gormDB)
+	bankAccountRepository := repository.NewBankAccountRepositoryAdapter(gormDB, domain.DefaultAccountNumberScheme)
+	notificationRepository := repository.NewNotificationRepositoryAdapter(gormDB)
+	transactionValidator := services.NewTransactionValidator(repository.NewTransactionRepositoryAdapter(gormDB), bankAccountRepository,
+		repository.NewOutboxRepositoryAdapter(gormDB), nil)
+
+	emailChannel := notifier.NewFakeEmailChannel()
+	smsChannel := notifier.NewFakeSMSChannel()
+
+	notificationService := services.NewNotificationService(notificationRepository, userRepository, repository.NewCustomerRepositoryAdapter(gormDB),
+		bankAccountRepository, notifier.NewInAppChannel(notificationRepository), emailChannel, smsChannel)
+	notificationService.LargeWithdrawalThreshold = 100000
+
+	alice := createUserWithAccount(t, gormDB, "alice")
+	bob := createUserWithAccount(t, gormDB, "bob")
+
+	_, err := notificationService.UpdatePreferences(context.Background(), alice.UserID, []domain.NotificationPreference{
+		{Category: domain.NotificationDeposit, InApp: true, Email: true},
+		{Category: domain.NotificationLoginFailed, InApp: true, SMS: true},
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, transactionValidator.ProcessTransaction(context.Background(), &domain.Transaction{ToAccountNumber: alice.AccountNumber, Amount: 50000, TransactionType: "deposit"}))
+	assert.NoError(t, transactionValidator.ProcessTransaction(context.Background(), &domain.Transaction{FromAccountNumber: alice.AccountNumber, ToAccountNumber: bob.AccountNumber, Amount: 20000, TransactionType: "transfer"}))
+	assert.NoError(t, transactionValidator.ProcessTransaction(context.Background(), &domain.Transaction{FromAccountNumber: bob.AccountNumber, Amount: 10000, TransactionType: "withdraw"}))
+	assert.NoError(t, transactionValidator.ProcessTransaction(context.Background(), &domain.Transaction{FromAccountNumber: alice.AccountNumber, Amount: 100000, TransactionType: "withdraw"}))
+
+	_, err = services.NewBankAccountService(repository.NewUnitOfWork(gormDB), userRepository, bankAccountRepository, outboxRepository,
+		services.NewAccountValidator(userRepository, bankAccountRepository)).UpdateBankAccountStatus(context.Background(), bob.ID.String(), 0, false)
+	assert.NoError(t, err)
+
+	_, err = services.NewAuthService(nil, userRepository, outboxRepository, config.NewJWTManager("secret", time.Hour),
+		metrics.Nop{}).LoginAccount(context.Background(), "alice", "wrong-password", "192.0.2.1")
+	assert.Error(t, err)
+
+	eventBus := services.NewEventBus()
+	eventBus.Subscribe(services.AllEvents, "notifications", notificationService.HandleEvent)
+	services.NewOutboxRelay(outboxRepository, eventBus).RunOnce(context.Background())
+
+	t.Run("Creates notifications from the events", func(t *testing.T) {
+		aliceNotifications, err := notificationService.GetNotifications(context.Background(), alice.UserID, false, 10, 0)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{domain.NotificationDeposit, domain.NotificationLargeWithdrawal, domain.NotificationLoginFailed},
+			notificationCategories(aliceNotifications))
+
+		bobNotifications, err := notificationService.GetNotifications(context.Background(), bob.UserID, false, 10, 0)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{domain.NotificationIncomingTransfer, domain.NotificationAccountStatus},
+			notificationCategories(bobNotifications))
+	})
+
+	t.Run("Sends on the channels enabled in the preferences", func(t *testing.T) {
+		sent := emailChannel.Sent()
+		assert.Len(t, sent, 1)
+		assert.Equal(t, "alice@example.com", sent[0].To)
+		assert.Equal(t, "Deposit received", sent[0].Title)
+
+		// alice has no customer profile, so there is no phone number to send the SMS to
+		assert.Empty(t, smsChannel.Sent())
+	})
+
+	t.Run("Does not notify twice for the same event", func(t *testing.T) {
+		notifications, err := notificationService.GetNotifications(context.Background(), alice.UserID, false, 10, 0)
+		assert.NoError(t, err)
+
+		deposit := &domain.Notification{}
+		for i := range notifications {
+			if notifications[i].Category == domain.NotificationDeposit {
+				deposit = &notifications[i]
+			}
+		}
+
+		duplicate := &domain.Notification{UserID: alice.UserID, EventID: deposit.EventID, Category: domain.NotificationDeposit, Title: "Duplicate", Message: "Duplicate"}
+		assert.NoError(t, notificationRepository.Create(context.Background(), duplicate))
+
+		notifications, err = notificationService.GetNotifications(context.Background(), alice.UserID, false, 10, 0)
+		assert.NoError(t, err)
+		assert.Len(t, notifications, 3)
+	})
+
+	t.Run("Marks notifications as read", func(t *testing.T) {
+		notifications, err := notificationService.GetNotifications(context.Background(), alice.UserID, true, 10, 0)
+		assert.NoError(t, err)
+		assert.Len(t, notifications, 3)
+
+		read, err := notificationService.MarkRead(context.Background(), alice.UserID, notifications[0].ID.String())
+		assert.NoError(t, err)
+		assert.NotNil(t, read.ReadAt)
+
+		_, err = notificationService.MarkRead(context.Background(), bob.UserID, notifications[1].ID.String())
+		assert.Error(t, err)
+
+		updated, err := notificationService.MarkAllRead(context.Background(), alice.UserID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), updated)
+
+		unread, err := notificationService.GetNotifications(context.Background(), alice.UserID, true, 10, 0)
+		assert.NoError(t, err)
+		assert.Empty(t, unread)
+	})
+
+	t.Run("Returns the default preference for unchanged categories", func(t *testing.T) {
+		preferences, err := notificationService.GetPreferences(context.Background(), alice.UserID)
+		assert.NoError(t, err)
+		assert.Len(t, preferences, len(domain.NotificationCategories))
+
+		for _, preference := range preferences {
+			assert.True(t, preference.InApp)
+			assert.Equal(t, preference.Category == domain.NotificationDeposit, preference.Email)
+			assert.Equal(t, preference.Category == domain.NotificationLoginFailed, preference.SMS)
+		}
+
+		_, err = notificationService.UpdatePreferences(context.Background(), alice.UserID, []domain.NotificationPreference{{Category: "unknown"}})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,96 @@
+package services_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/okyws/dashboard-backend/adapter/metrics"
+	"github.com/okyws/dashboard-backend/adapter/openapi"
+	"github.com/okyws/dashboard-backend/config"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/routes"
+	"github.com/okyws/dashboard-backend/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// metrics on the API port so every route that can be registered is
+	configuration := &domain.Configuration{AppName: "Dashboard Test", AppVersion: "1.2.3", ClientURL: "http://localhost:3000",
+		DBDriver: domain.DriverSQLite, DBPath: filepath.Join(t.TempDir(), "openapi.db"), StoreDriver: domain.StoreMemory,
+		MetricsToken: "metrics-token", JWTSecret: "secret"}
+
+	db, err := config.NewDBConnectionENV(configuration)
+	assert.NoError(t, err)
+	t.Cleanup(func() { config.CloseDatabase(db) })
+
+	// the routes of SetupRouter without its tracer provider, which would replace the global one of TestTracing
+	router := gin.New()
+	appMetrics := metrics.New()
+	document := routes.NewAPIDocument(configuration)
+
+	routes.RegisterMetricsRoute(router, appMetrics.Handler(), configuration.MetricsToken)
+	routes.RegisterHealthRoutes(router, services.NewHealthService(), configuration)
+	routes.RegisterDocsRoutes(router, document)
+
+	_, err = routes.RegisterRoutes(router, db, nil, configuration, appMetrics)
+	assert.NoError(t, err)
+
+	t.Run("Every registered route has a spec entry", func(t *testing.T) {
+		registered := make([]string, 0)
+		for _, route := range router.Routes() {
+			registered = append(registered, route.Method+" "+openapi.Path(route.Path))
+		}
+
+		operations := document.Operations()
+		assert.NotEmpty(t, registered)
+
+		for _, route := range registered {
+			assert.Contains(t, operations, route, "route without an OpenAPI entry")
+		}
+
+		for _, operation := range operations {
+			assert.Contains(t, registered, operation, "OpenAPI entry without a route")
+		}
+	})
+
+	t.Run("The document describes the DTOs", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var served map[string]any
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &served))
+		assert.Equal(t, openapi.Version, served["openapi"])
+
+		userCreate := document.Components.Schemas["UserCreateDTO"]
+		assert.ElementsMatch(t, []string{"email", "username", "password", "role"}, userCreate.Required)
+		assert.Equal(t, []string{"admin", "user", "customer"}, userCreate.Properties["role"].Enum)
+		assert.Equal(t, "email", userCreate.Properties["email"].Format)
+		assert.Equal(t, 3, *userCreate.Properties["username"].MinLength)
+
+		webhookCreate := document.Components.Schemas["WebhookEndpointCreateDTO"]
+		assert.Equal(t, 1, *webhookCreate.Properties["event_types"].MinItems)
+		assert.Contains(t, webhookCreate.Properties["event_types"].Items.Enum, "transaction.posted")
+
+		operation := document.Paths["/api/v1/users/{id}/update"]["put"]
+		assert.Equal(t, "id", operation.Parameters[0].Name)
+		assert.Equal(t, "#/components/schemas/UserUpdateDTO", operation.RequestBody.Content["application/json"].Schema.Ref)
+		assert.Contains(t, operation.Responses, "401")
+		assert.Equal(t, "#/components/schemas/UserDTO", operation.Responses["200"].Content["application/json"].Schema.Properties["data"].Ref)
+	})
+
+	t.Run("Swagger UI loads the document", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/docs", nil))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Contains(t, recorder.Header().Get("Content-Type"), "text/html")
+		assert.Contains(t, recorder.Body.String(), `url: "/openapi.json"`)
+	})
+}
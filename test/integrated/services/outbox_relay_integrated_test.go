@@ -0,0 +1,156 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/okyws/dashboard-backend/adapter/eventsink"
+	"github.com/okyws/dashboard-backend/adapter/repository"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/services"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingSink struct{}
+
+func (failingSink) Name() string { return "failing" }
+
+func (failingSink) Publish(_ context.Context, _ *domain.OutboxEvent) error {
+	return errors.New("sink unavailable")
+}
+
+// recoveringSink fails the first deliveries and accepts the next ones
+type recoveringSink struct {
+	failures  int
+	published int
+}
+
+func (*recoveringSink) Name() string { return "recovering" }
+
+func (s *recoveringSink) Publish(_ context.Context, _ *domain.OutboxEvent) error {
+	s.published++
+
+	if s.published <= s.failures {
+		return errors.New("sink unavailable")
+	}
+
+	return nil
+}
+
+func TestOutboxRelay(t *testing.T) {
+	t.Run("Publishes typed events to the bus and sinks", func(t *testing.T) {
+		gormDB := newEventTestDB(t)
+		outboxRepository := repository.NewOutboxRepositoryAdapter(gormDB)
+
+		userService := services.NewUserService(repository.NewUnitOfWork(gormDB), repository.NewUserRepositoryAdapter(gormDB),
+			repository.NewOutboxRepositoryAdapter(gormDB))
+		user, err := userService.CreateUser(context.Background(), &domain.User{Username: "relay", Email: "relay@example.com", Password: "password", Role: "user"})
+		assert.NoError(t, err)
+
+		var received []domain.Event
+
+		eventBus := services.NewEventBus()
+		eventBus.Subscribe(domain.EventUserCreated, "recorder", func(_ context.Context, envelope *domain.EventEnvelope) error {
+			received = append(received, envelope.Event)
+			return nil
+		})
+
+		var output bytes.Buffer
+
+		relay := services.NewOutboxRelay(outboxRepository, eventBus, eventsink.NewLogSink(zerolog.New(&output)))
+		relay.RunOnce(context.Background())
+
+		assert.Len(t, received, 1)
+
+		created, ok := received[0].(domain.UserCreated)
+		assert.True(t, ok)
+		assert.Equal(t, user.ID, created.AggregateID())
+		assert.Equal(t, "relay", created.Username)
+		assert.Contains(t, output.String(), `"event_type":"user.created"`)
+
+		pending := pendingEvents(t, gormDB)
+		assert.Empty(t, pending)
+	})
+
+	t.Run("Keeps events pending while a sink fails", func(t *testing.T) {
+		gormDB := newEventTestDB(t)
+		outboxRepository := repository.NewOutboxRepositoryAdapter(gormDB)
+
+		userService := services.NewUserService(repository.NewUnitOfWork(gormDB), repository.NewUserRepositoryAdapter(gormDB),
+			repository.NewOutboxRepositoryAdapter(gormDB))
+		_, err := userService.CreateUser(context.Background(), &domain.User{Username: "pending", Email: "pending@example.com", Password: "password", Role: "user"})
+		assert.NoError(t, err)
+
+		relay := services.NewOutboxRelay(outboxRepository, services.NewEventBus(), failingSink{})
+		relay.RunOnce(context.Background())
+
+		pending := pendingEvents(t, gormDB)
+		assert.Len(t, pending, 1)
+		assert.Equal(t, domain.EventUserCreated, pending[0].EventType)
+	})
+
+	t.Run("Rolls back the event with the failed change", func(t *testing.T) {
+		gormDB := newEventTestDB(t)
+		outboxRepository := repository.NewOutboxRepositoryAdapter(gormDB)
+		userService := services.NewUserService(repository.NewUnitOfWork(gormDB), repository.NewUserRepositoryAdapter(gormDB), outboxRepository)
+
+		_, err := userService.CreateUser(context.Background(), &domain.User{Username: "dup", Email: "dup@example.com", Password: "password", Role: "user"})
+		assert.NoError(t, err)
+
+		// the username is free, the email is not
+		_, err = userService.CreateUser(context.Background(), &domain.User{Username: "other", Email: "dup@example.com", Password: "password", Role: "user"})
+		assert.Error(t, err)
+
+		pending := pendingEvents(t, gormDB)
+		assert.Len(t, pending, 1)
+	})
+
+	t.Run("Retries only the subscribers and sinks that did not get the event", func(t *testing.T) {
+		gormDB := newEventTestDB(t)
+		outboxRepository := repository.NewOutboxRepositoryAdapter(gormDB)
+		userService := services.NewUserService(repository.NewUnitOfWork(gormDB), repository.NewUserRepositoryAdapter(gormDB), outboxRepository)
+
+		_, err := userService.CreateUser(context.Background(), &domain.User{Username: "retry", Email: "retry@example.com", Password: "password", Role: "user"})
+		assert.NoError(t, err)
+
+		handled := 0
+
+		eventBus := services.NewEventBus()
+		eventBus.Subscribe(domain.EventUserCreated, "counter", func(_ context.Context, _ *domain.EventEnvelope) error {
+			handled++
+			return nil
+		})
+
+		sink := &recoveringSink{failures: 1}
+
+		relay := services.NewOutboxRelay(outboxRepository, eventBus, sink)
+		relay.ClaimLease = 0
+		relay.RunOnce(context.Background())
+		relay.RunOnce(context.Background())
+
+		assert.Equal(t, 1, handled, "the subscriber is not given the event again")
+		assert.Equal(t, 2, sink.published)
+
+		pending := pendingEvents(t, gormDB)
+		assert.Empty(t, pending)
+	})
+
+	t.Run("Relays a claimed event from a single relay", func(t *testing.T) {
+		gormDB := newEventTestDB(t)
+		outboxRepository := repository.NewOutboxRepositoryAdapter(gormDB)
+		assert.NoError(t, outboxRepository.Record(context.Background(), domain.LoginFailed{UserID: uuid.New()}))
+
+		claimed, err := outboxRepository.ClaimPending(context.Background(), 10, time.Minute)
+		assert.NoError(t, err)
+		assert.Len(t, claimed, 1)
+
+		claimed, err = outboxRepository.ClaimPending(context.Background(), 10, time.Minute)
+		assert.NoError(t, err)
+		assert.Empty(t, claimed, "another relay leaves the claimed event alone")
+	})
+}
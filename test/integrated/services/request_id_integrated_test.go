@@ -0,0 +1,86 @@
+package services_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/okyws/dashboard-backend/middleware"
+	"github.com/okyws/dashboard-backend/utils"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	var output bytes.Buffer
+
+	global := log.Logger
+	t.Cleanup(func() { log.Logger = global })
+	log.Logger = zerolog.New(utils.NewRedactWriter(&output))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RequestIDMiddleware(), middleware.ZerologMiddleware())
+	router.POST("/login", func(c *gin.Context) {
+		utils.Logger(c.Request.Context(), "services").Info().Str("password", "hunter2").Msg("Logging in")
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid credentials")
+	})
+
+	serve := func(requestID string) (*httptest.ResponseRecorder, []map[string]any) {
+		output.Reset()
+
+		request := httptest.NewRequest(http.MethodPost, "/login", nil)
+		if requestID != "" {
+			request.Header.Set(middleware.RequestIDHeader, requestID)
+		}
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+
+		var lines []map[string]any
+
+		for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+			var fields map[string]any
+			assert.NoError(t, json.Unmarshal([]byte(line), &fields))
+
+			lines = append(lines, fields)
+		}
+
+		return response, lines
+	}
+
+	t.Run("propagates the request ID to the response and every log line", func(t *testing.T) {
+		response, lines := serve("upstream-42")
+		assert.Equal(t, "upstream-42", response.Header().Get(middleware.RequestIDHeader))
+		assert.Len(t, lines, 2)
+
+		for _, line := range lines {
+			assert.Equal(t, "upstream-42", line["request_id"])
+		}
+
+		assert.Equal(t, "services", lines[0]["component"])
+		assert.Equal(t, "[REDACTED]", lines[0]["password"])
+
+		assert.Equal(t, "http", lines[1]["component"])
+		assert.Equal(t, "warn", lines[1]["level"])
+		assert.Equal(t, "/login", lines[1]["route"])
+		assert.Equal(t, float64(http.StatusUnauthorized), lines[1]["status"])
+		assert.Equal(t, "Invalid credentials", lines[1]["response"])
+	})
+
+	t.Run("generates a request ID when none or an invalid one is sent", func(t *testing.T) {
+		for _, requestID := range []string{"", "forged\"id", strings.Repeat("a", 129)} {
+			response, lines := serve(requestID)
+
+			generated := response.Header().Get(middleware.RequestIDHeader)
+			assert.Len(t, generated, 36)
+			assert.NotEqual(t, requestID, generated)
+			assert.Equal(t, generated, lines[1]["request_id"])
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package services_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/okyws/dashboard-backend/adapter/repository"
+	"github.com/okyws/dashboard-backend/config"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/middleware"
+	"github.com/okyws/dashboard-backend/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	configuration := &domain.Configuration{DBDriver: domain.DriverSQLite, DBPath: filepath.Join(t.TempDir(), "timeout.db")}
+	db, err := config.NewDBConnectionENV(configuration)
+	assert.NoError(t, err)
+	t.Cleanup(func() { config.CloseDatabase(db) })
+
+	assert.NoError(t, config.MigrateDB(db))
+
+	userRepository := repository.NewUserRepositoryAdapter(db)
+	user, err := userRepository.Create(context.Background(), &domain.User{Username: "alice", Email: "alice@example.com", Password: "password", Role: "user"})
+	assert.NoError(t, err)
+
+	t.Run("Cancelled contexts abort the queries", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := userRepository.GetUserByUsername(ctx, "alice")
+		assert.ErrorIs(t, err, context.Canceled)
+
+		_, err = repository.NewNotificationRepositoryAdapter(db).GetByUserID(ctx, user.ID, false, 10, 0)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.TimeoutMiddleware(50 * time.Millisecond))
+	router.GET("/fast", func(c *gin.Context) {
+		_, err := userRepository.GetUserByUsername(c.Request.Context(), "alice")
+		assert.NoError(t, err)
+
+		c.Status(http.StatusOK)
+	})
+	router.GET("/silent", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+	})
+	router.GET("/failing", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+
+		_, err := userRepository.GetUserByUsername(c.Request.Context(), "alice")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get user")
+	})
+
+	serve := func(path string) int {
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, path, nil))
+
+		return response.Code
+	}
+
+	t.Run("Requests within the deadline are answered by the handler", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("/fast"))
+	})
+
+	t.Run("Requests past the deadline are answered with 504", func(t *testing.T) {
+		assert.Equal(t, http.StatusGatewayTimeout, serve("/silent"))
+		assert.Equal(t, http.StatusGatewayTimeout, serve("/failing"))
+	})
+
+	t.Run("A zero timeout disables the deadline", func(t *testing.T) {
+		router := gin.New()
+		router.Use(middleware.TimeoutMiddleware(0))
+		router.GET("/", func(c *gin.Context) {
+			_, ok := c.Request.Context().Deadline()
+			assert.False(t, ok)
+
+			c.Status(http.StatusOK)
+		})
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+}
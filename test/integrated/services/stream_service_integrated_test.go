@@ -0,0 +1,129 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/okyws/dashboard-backend/adapter/broker"
+	"github.com/okyws/dashboard-backend/adapter/repository"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/services"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// subscribedBroker signals when the stream service is listening so the test does not publish too early
+type subscribedBroker struct {
+	*broker.MemoryBroker
+	subscribed chan struct{}
+}
+
+func (b *subscribedBroker) Subscribe(ctx context.Context) (<-chan *domain.StreamMessage, error) {
+	messages, err := b.MemoryBroker.Subscribe(ctx)
+	close(b.subscribed)
+
+	return messages, err
+}
+
+func createUserWithAccount(t *testing.T, gormDB *gorm.DB, username string) *domain.BankAccount {
+	work := repository.NewUnitOfWork(gormDB)
+	userRepository := repository.NewUserRepositoryAdapter(gormDB)
+	bankAccountRepository := repository.NewBankAccountRepositoryAdapter(gormDB, domain.DefaultAccountNumberScheme)
+	outboxRepository := repository.NewOutboxRepositoryAdapter(gormDB)
+
+	user, err := services.NewUserService(work, userRepository, outboxRepository).CreateUser(context.Background(),
+		&domain.User{Username: username, Email: username + "@example.com", Password: "password", Role: "user"})
+	assert.NoError(t, err)
+
+	account, err := services.NewBankAccountService(work, userRepository, bankAccountRepository, outboxRepository,
+		services.NewAccountValidator(userRepository, bankAccountRepository)).CreateBankAccount(context.Background(),
+		&domain.BankAccount{UserID: user.ID, AccountType: "rekening-utama", Balance: 100000})
+	assert.NoError(t, err)
+
+	return account
+}
+
+func receiveTypes(subscription *services.StreamSubscription) []string {
+	var types []string
+
+	for {
+		select {
+		case message := <-subscription.Messages():
+			types = append(types, message.Type)
+		case <-time.After(200 * time.Millisecond):
+			return types
+		}
+	}
+}
+
+func TestStreamService(t *testing.T) {
+	gormDB := newEventTestDB(t)
+	outboxRepository := repository.NewOutboxRepositoryAdapter(gormDB)
+	bankAccountRepository := repository.NewBankAccountRepositoryAdapter(gormDB, domain.DefaultAccountNumberScheme)
+	transactionValidator := services.NewTransactionValidator(repository.NewTransactionRepositoryAdapter(gormDB), bankAccountRepository,
+		repository.NewOutboxRepositoryAdapter(gormDB), nil)
+
+	alice := createUserWithAccount(t, gormDB, "alice")
+	bob := createUserWithAccount(t, gormDB, "bob")
+
+	first := pendingEvents(t, gormDB)
+
+	assert.NoError(t, transactionValidator.ProcessTransaction(context.Background(), &domain.Transaction{ToAccountNumber: alice.AccountNumber, Amount: 50000, TransactionType: "deposit"}))
+	assert.NoError(t, transactionValidator.ProcessTransaction(context.Background(), &domain.Transaction{FromAccountNumber: alice.AccountNumber, ToAccountNumber: bob.AccountNumber, Amount: 20000, TransactionType: "transfer"}))
+
+	streamBroker := &subscribedBroker{MemoryBroker: broker.NewMemoryBroker(), subscribed: make(chan struct{})}
+	streamService := services.NewStreamService(streamBroker, outboxRepository, bankAccountRepository)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go streamService.Start(ctx)
+	<-streamBroker.subscribed
+
+	aliceSubscription := streamService.Subscribe(alice.UserID, false)
+	bobSubscription := streamService.Subscribe(bob.UserID, false)
+	firehose := streamService.Subscribe(uuid.New(), true)
+
+	eventBus := services.NewEventBus()
+	eventBus.Subscribe(services.AllEvents, "stream", streamService.HandleEvent)
+	services.NewOutboxRelay(outboxRepository, eventBus).RunOnce(ctx)
+
+	t.Run("Pushes the events of the caller's own accounts", func(t *testing.T) {
+		assert.Equal(t, []string{
+			domain.EventUserCreated, domain.EventAccountCreated,
+			domain.EventBalanceChanged, domain.EventTransactionPosted,
+			domain.EventBalanceChanged, domain.EventTransactionPosted,
+		}, receiveTypes(aliceSubscription))
+
+		assert.Equal(t, []string{
+			domain.EventUserCreated, domain.EventAccountCreated,
+			domain.EventBalanceChanged, domain.EventTransactionPosted,
+		}, receiveTypes(bobSubscription))
+	})
+
+	t.Run("Pushes every event on the firehose", func(t *testing.T) {
+		assert.Len(t, receiveTypes(firehose), 9)
+	})
+
+	t.Run("Replays the events after the last event ID", func(t *testing.T) {
+		missed, err := streamService.Replay(context.Background(), first[0].ID, aliceSubscription)
+		assert.NoError(t, err)
+		assert.Len(t, missed, 5)
+
+		for _, message := range missed {
+			assert.True(t, message.VisibleTo(alice.UserID))
+		}
+
+		_, err = streamService.Replay(context.Background(), uuid.New(), aliceSubscription)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("Closes the subscriptions when stopped", func(t *testing.T) {
+		cancel()
+
+		_, ok := <-aliceSubscription.Messages()
+		assert.False(t, ok)
+	})
+}
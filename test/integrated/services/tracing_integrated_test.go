@@ -0,0 +1,150 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/okyws/dashboard-backend/adapter/metrics"
+	"github.com/okyws/dashboard-backend/adapter/repository"
+	"github.com/okyws/dashboard-backend/adapter/tracing"
+	"github.com/okyws/dashboard-backend/config"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/middleware"
+	"github.com/okyws/dashboard-backend/services"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// incomingTraceparent is the W3C trace context of a caller that already started the trace
+const incomingTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestTracing(t *testing.T) {
+	provider, err := tracing.NewProvider(&domain.Configuration{AppName: "dashboard", TracingExporter: domain.TracingNone, TracingSampleRatio: 1})
+	assert.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, provider.Shutdown(context.Background())) })
+
+	recorder := tracetest.NewSpanRecorder()
+	provider.RegisterSpanProcessor(recorder)
+
+	configuration := &domain.Configuration{DBDriver: domain.DriverSQLite, DBPath: filepath.Join(t.TempDir(), "tracing.db")}
+	db, err := config.NewDBConnectionENV(configuration)
+	assert.NoError(t, err)
+	t.Cleanup(func() { config.CloseDatabase(db) })
+
+	assert.NoError(t, config.MigrateDB(db))
+	assert.NoError(t, tracing.InstrumentDB(db))
+
+	redisClient := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { config.CloseRedisClient(redisClient) })
+	tracing.InstrumentRedis(redisClient)
+
+	userRepository := repository.NewUserRepositoryAdapter(db)
+	user, err := userRepository.Create(context.Background(), &domain.User{Username: "alice", Email: "alice@example.com", Password: "password", Role: "user"})
+	assert.NoError(t, err)
+
+	bankRepository := repository.NewBankAccountRepositoryAdapter(db, domain.DefaultAccountNumberScheme)
+	account, err := bankRepository.Create(context.Background(), &domain.BankAccount{UserID: user.ID, AccountType: "saku"})
+	assert.NoError(t, err)
+
+	transactionRepository := repository.NewTransactionRepositoryAdapter(db)
+	transactionService := services.NewTransactionService(repository.NewUnitOfWork(db), transactionRepository, bankRepository,
+		services.NewTransactionValidator(transactionRepository, bankRepository, repository.NewOutboxRepositoryAdapter(db), nil), nil, metrics.Nop{})
+	authService := services.NewAuthService(repository.NewAuthRepositoryRedis(redisClient), userRepository,
+		repository.NewOutboxRepositoryAdapter(db), config.NewJWTManager("secret", time.Hour), metrics.Nop{})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.TracingMiddleware())
+	router.POST("/deposit/:account", func(c *gin.Context) {
+		if err := transactionService.ProcessTransaction(c.Request.Context(), "", c.Param("account"), "deposit", 100); err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+
+		c.Status(http.StatusOK)
+	})
+	router.POST("/login", func(c *gin.Context) {
+		if _, err := authService.LoginAccount(c.Request.Context(), "alice", "password", c.ClientIP()); err != nil {
+			c.Status(http.StatusUnauthorized)
+			return
+		}
+
+		c.Status(http.StatusOK)
+	})
+
+	t.Run("continues the incoming trace down to the queries", func(t *testing.T) {
+		recorder.Reset()
+
+		request := httptest.NewRequest(http.MethodPost, "/deposit/"+account.AccountNumber, nil)
+		request.Header.Set("traceparent", incomingTraceparent)
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+		assert.Equal(t, http.StatusOK, response.Code)
+
+		spans := recorder.Ended()
+		names := make(map[string]trace.SpanContext)
+
+		for _, span := range spans {
+			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String(), span.Name())
+			names[span.Name()] = span.SpanContext()
+		}
+
+		assert.Contains(t, names, "POST /deposit/:account")
+		assert.Contains(t, names, "TransactionService.ProcessTransaction")
+		assert.Contains(t, names, "gorm.query bank_accounts")
+		assert.Contains(t, names, "gorm.create transactions")
+		assert.Contains(t, response.Header().Get("traceparent"), "4bf92f3577b34da6a3ce929d0e0e4736")
+
+		for _, span := range spans {
+			if span.Name() == "TransactionService.ProcessTransaction" {
+				assert.Equal(t, names["POST /deposit/:account"].SpanID(), span.Parent().SpanID())
+			}
+		}
+	})
+
+	t.Run("traces bcrypt and redis under the login", func(t *testing.T) {
+		recorder.Reset()
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/login", nil))
+		assert.Equal(t, http.StatusOK, response.Code)
+
+		traceIDs := make(map[string]string)
+		for _, span := range recorder.Ended() {
+			traceIDs[span.Name()] = span.SpanContext().TraceID().String()
+		}
+
+		assert.Contains(t, traceIDs, "bcrypt.CompareHashAndPassword")
+		assert.Contains(t, traceIDs, "gorm.query users")
+		assert.Contains(t, traceIDs, "redis.pipeline")
+		assert.Equal(t, traceIDs["POST /login"], traceIDs["bcrypt.CompareHashAndPassword"])
+		assert.Equal(t, traceIDs["POST /login"], traceIDs["redis.pipeline"])
+	})
+
+	t.Run("adds the trace IDs to log events", func(t *testing.T) {
+		var output bytes.Buffer
+
+		logger := zerolog.New(&output).Hook(tracing.LogHook{})
+		ctx, span := provider.Tracer("test").Start(context.Background(), "log")
+
+		logger.Info().Ctx(ctx).Msg("traced")
+		logger.Info().Msg("untraced")
+		span.End()
+
+		lines := bytes.Split(bytes.TrimSpace(output.Bytes()), []byte("\n"))
+		assert.Contains(t, string(lines[0]), `"trace_id":"`+span.SpanContext().TraceID().String()+`"`)
+		assert.Contains(t, string(lines[0]), `"span_id":"`+span.SpanContext().SpanID().String()+`"`)
+		assert.NotContains(t, string(lines[1]), "trace_id")
+	})
+}
@@ -0,0 +1,346 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/okyws/dashboard-backend/adapter/metrics"
+	"github.com/okyws/dashboard-backend/adapter/repository"
+	"github.com/okyws/dashboard-backend/config"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/dto"
+	"github.com/okyws/dashboard-backend/middleware"
+	"github.com/okyws/dashboard-backend/ports"
+	"github.com/okyws/dashboard-backend/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// openAccount creates a user with a main account holding the balance
+func (a *apiClient) openAccount(username string, balance float64) dto.BankAccountDTO {
+	var user dto.SuccessResponseDTO[dto.UserDTO]
+	recorder := a.serve(http.MethodPost, "/api/v2/users",
+		fmt.Sprintf(`{"email": "%s@example.com", "username": "%s", "password": "secret1", "role": "user"}`, username, username))
+	assert.NoError(a.t, json.Unmarshal(recorder.Body.Bytes(), &user))
+
+	var account dto.SuccessResponseDTO[dto.BankAccountDTO]
+	recorder = a.serve(http.MethodPost, "/api/v2/accounts",
+		fmt.Sprintf(`{"user_id": "%s", "account_type": "rekening-utama", "balance": %v}`, user.Data.ID, balance))
+	assert.Equal(a.t, http.StatusCreated, recorder.Code, recorder.Body.String())
+	assert.NoError(a.t, json.Unmarshal(recorder.Body.Bytes(), &account))
+
+	return account.Data
+}
+
+// balance returns the current balance of the account
+func (a *apiClient) balance(account dto.BankAccountDTO) float64 {
+	var resp dto.SuccessResponseDTO[dto.BankAccountDTO]
+	assert.NoError(a.t, json.Unmarshal(a.serve(http.MethodGet, "/api/v2/accounts/"+account.ID.String(), "").Body.Bytes(), &resp))
+
+	return resp.Data.Balance
+}
+
+// serveOwner sends the request as the user owning the account
+func (a *apiClient) serveOwner(owner dto.BankAccountDTO, method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	admin := a.token
+	defer func() { a.token = admin }()
+
+	token, _, err := config.NewJWTManager("secret", time.Hour).GenerateJWT(owner.UserID, "owner", "user")
+	assert.NoError(a.t, err)
+	a.token = token
+
+	return a.serve(method, path, body, headers...)
+}
+
+// submitBatch sends the batch as the user owning the account and returns the response and the batch it answered with
+func (a *apiClient) submitBatch(payer dto.BankAccountDTO, body string, headers ...string) (int, dto.TransferBatchDTO, string) {
+	recorder := a.serveOwner(payer, http.MethodPost, "/api/v2/transactions/batch", body, headers...)
+
+	var resp dto.SuccessResponseDTO[dto.TransferBatchDTO]
+	_ = json.Unmarshal(recorder.Body.Bytes(), &resp)
+
+	return recorder.Code, resp.Data, recorder.Body.String()
+}
+
+func TestTransferBatches(t *testing.T) {
+	client := newAPIClient(t)
+
+	payer := client.openAccount("payer", 1000000)
+	ani := client.openAccount("ani", 50000)
+	bayu := client.openAccount("bayu", 50000)
+	frozen := client.openAccount("frozen", 50000)
+
+	recorder := client.serve(http.MethodPatch, "/api/v2/accounts/"+frozen.ID.String(), `{"account_status": false}`, "If-Match", "*")
+	assert.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	unknown, err := domain.DefaultAccountNumberScheme.Generate("rekening-utama", 999999)
+	assert.NoError(t, err)
+
+	t.Run("all or nothing makes every transfer", func(t *testing.T) {
+		status, batch, body := client.submitBatch(payer, fmt.Sprintf(`{"from_account_number": "%s", "items": [
+			{"to_account_number": "%s", "amount": 100000, "reference": "salary ani"},
+			{"to_account_number": "%s", "amount": 250000, "reference": "salary bayu"}]}`,
+			payer.AccountNumber, ani.AccountNumber, bayu.AccountNumber))
+		assert.Equal(t, http.StatusCreated, status, body)
+
+		assert.Equal(t, domain.TransferBatchModeAllOrNothing, batch.Mode)
+		assert.Equal(t, domain.TransferBatchStatusCompleted, batch.Status)
+		assert.Equal(t, 350000.0, batch.TotalAmount)
+		assert.Equal(t, 350000.0, batch.TransferredAmount)
+		assert.Equal(t, 2, batch.SucceededItems)
+		assert.Equal(t, "salary bayu", batch.Items[1].Reference)
+		assert.Equal(t, domain.TransferItemStatusSucceeded, batch.Items[1].Status)
+
+		assert.Equal(t, 650000.0, client.balance(payer))
+		assert.Equal(t, 150000.0, client.balance(ani))
+		assert.Equal(t, 300000.0, client.balance(bayu))
+
+		var fetched dto.SuccessResponseDTO[dto.TransferBatchDTO]
+		assert.NoError(t, json.Unmarshal(client.serve(http.MethodGet, "/api/v2/transactions/batch/"+batch.ID.String(), "").Body.Bytes(), &fetched))
+		assert.Equal(t, batch.Items, fetched.Data.Items)
+	})
+
+	t.Run("all or nothing refuses a batch with transfers that cannot be made", func(t *testing.T) {
+		status, _, body := client.submitBatch(payer, fmt.Sprintf(`{"from_account_number": "%s", "items": [
+			{"to_account_number": "%s", "amount": 10000},
+			{"to_account_number": "%s", "amount": 10000},
+			{"to_account_number": "%s", "amount": 10000},
+			{"to_account_number": "%s", "amount": 10000}]}`,
+			payer.AccountNumber, ani.AccountNumber, unknown, frozen.AccountNumber, payer.AccountNumber))
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+
+		var resp dto.ErrorResponseDTO
+		assert.NoError(t, json.Unmarshal([]byte(body), &resp))
+		assert.Equal(t, "invalid_transfer_batch", resp.ErrorCode)
+		assert.Equal(t, []dto.FieldErrorDTO{
+			{Field: "items[1].to_account_number", Code: "account_not_found", Message: "account number not found"},
+			{Field: "items[2].to_account_number", Code: "account_frozen", Message: "account is frozen"},
+			{Field: "items[3].to_account_number", Code: "same_account", Message: "cannot transfer to the same account"},
+		}, resp.Details)
+
+		assert.Equal(t, 650000.0, client.balance(payer))
+		assert.Equal(t, 150000.0, client.balance(ani))
+	})
+
+	t.Run("refuses a batch the balance does not cover", func(t *testing.T) {
+		status, _, body := client.submitBatch(payer, fmt.Sprintf(`{"from_account_number": "%s", "mode": "best_effort", "items": [
+			{"to_account_number": "%s", "amount": 400000},
+			{"to_account_number": "%s", "amount": 250000}]}`,
+			payer.AccountNumber, ani.AccountNumber, bayu.AccountNumber))
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		assert.Contains(t, body, "insufficient_funds")
+
+		assert.Equal(t, 650000.0, client.balance(payer))
+	})
+
+	t.Run("refuses a frozen source", func(t *testing.T) {
+		status, _, body := client.submitBatch(frozen, fmt.Sprintf(`{"from_account_number": "%s", "items": [{"to_account_number": "%s", "amount": 10000}]}`,
+			frozen.AccountNumber, ani.AccountNumber))
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+		assert.Contains(t, body, `"field":"from_account_number"`)
+	})
+
+	t.Run("refuses a source account of another user", func(t *testing.T) {
+		recorder := client.serveOwner(ani, http.MethodPost, "/api/v2/transactions/batch", fmt.Sprintf(
+			`{"from_account_number": "%s", "items": [{"to_account_number": "%s", "amount": 10000}]}`, payer.AccountNumber, ani.AccountNumber))
+		assert.Equal(t, http.StatusForbidden, recorder.Code, recorder.Body.String())
+		assert.Contains(t, recorder.Body.String(), `"field":"from_account_number"`)
+		assert.Contains(t, recorder.Body.String(), "account_not_owned")
+
+		assert.Equal(t, 650000.0, client.balance(payer))
+		assert.Equal(t, 150000.0, client.balance(ani))
+	})
+
+	t.Run("best effort makes the transfers it can and reports the others", func(t *testing.T) {
+		status, batch, body := client.submitBatch(payer, fmt.Sprintf(`{"from_account_number": "%s", "mode": "best_effort", "items": [
+			{"to_account_number": "%s", "amount": 20000},
+			{"to_account_number": "%s", "amount": 30000},
+			{"to_account_number": "%s", "amount": 40000}]}`,
+			payer.AccountNumber, ani.AccountNumber, unknown, bayu.AccountNumber), "Accept-Language", "id")
+		assert.Equal(t, http.StatusCreated, status, body)
+
+		assert.Equal(t, domain.TransferBatchStatusCompletedWithErrors, batch.Status)
+		assert.Equal(t, 90000.0, batch.TotalAmount)
+		assert.Equal(t, 60000.0, batch.TransferredAmount)
+		assert.Equal(t, 2, batch.SucceededItems)
+		assert.Equal(t, 1, batch.FailedItems)
+		assert.Equal(t, dto.TransferBatchItemDTO{Position: 1, ToAccountNumber: unknown, Amount: 30000, Status: domain.TransferItemStatusFailed,
+			ErrorCode: "account_not_found", Error: "nomor rekening tidak ditemukan"}, batch.Items[1])
+
+		assert.Equal(t, 590000.0, client.balance(payer))
+		assert.Equal(t, 170000.0, client.balance(ani))
+		assert.Equal(t, 340000.0, client.balance(bayu))
+	})
+
+	t.Run("validates the request", func(t *testing.T) {
+		for _, body := range []string{
+			fmt.Sprintf(`{"from_account_number": "%s", "items": []}`, payer.AccountNumber),
+			fmt.Sprintf(`{"from_account_number": "%s", "mode": "sometimes", "items": [{"to_account_number": "%s", "amount": 10000}]}`,
+				payer.AccountNumber, ani.AccountNumber),
+			fmt.Sprintf(`{"from_account_number": "%s", "items": [{"to_account_number": "%s", "amount": 500}]}`,
+				payer.AccountNumber, ani.AccountNumber),
+		} {
+			status, _, _ := client.submitBatch(payer, body)
+			assert.Equal(t, http.StatusBadRequest, status, body)
+		}
+	})
+
+	t.Run("a batch is only shown to the user who submitted it and the admins", func(t *testing.T) {
+		_, batch, _ := client.submitBatch(payer, fmt.Sprintf(`{"from_account_number": "%s", "items": [{"to_account_number": "%s", "amount": 10000}]}`,
+			payer.AccountNumber, ani.AccountNumber))
+
+		recorder := client.serveOwner(ani, http.MethodGet, "/api/v2/transactions/batch/"+batch.ID.String(), "")
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "transfer_batch_not_found")
+
+		recorder = client.serve(http.MethodGet, "/api/v2/transactions/batch/"+batch.ID.String(), "")
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+}
+
+// failingAccounts fails the update of an account, like a transfer that lost a race with another change
+type failingAccounts struct {
+	ports.BankAccountRepository
+	accountNumber string
+}
+
+func (r failingAccounts) Update(ctx context.Context, entity *domain.BankAccount) (*domain.BankAccount, error) {
+	if entity.AccountNumber == r.accountNumber {
+		return nil, errors.New("connection reset")
+	}
+
+	return r.BankAccountRepository.Update(ctx, entity)
+}
+
+func TestTransferBatchRollback(t *testing.T) {
+	gormDB := newEventTestDB(t)
+	assert.NoError(t, gormDB.AutoMigrate(&domain.TransferBatch{}, &domain.TransferBatchItem{}))
+
+	payer := createUserWithAccount(t, gormDB, "payer")
+	ani := createUserWithAccount(t, gormDB, "ani")
+	bayu := createUserWithAccount(t, gormDB, "bayu")
+
+	bankAccountRepository := repository.NewBankAccountRepositoryAdapter(gormDB, domain.DefaultAccountNumberScheme)
+	validator := services.NewTransactionValidator(repository.NewTransactionRepositoryAdapter(gormDB),
+		failingAccounts{BankAccountRepository: bankAccountRepository, accountNumber: bayu.AccountNumber},
+		repository.NewOutboxRepositoryAdapter(gormDB), nil)
+	service := services.NewTransferBatchService(repository.NewUnitOfWork(gormDB), repository.NewTransferBatchRepositoryAdapter(gormDB),
+		bankAccountRepository, validator, middleware.MapError, metrics.New())
+
+	batch, err := service.Process(context.Background(), payer.UserID, &dto.TransferBatchCreateDTO{
+		FromAccountNumber: payer.AccountNumber,
+		Items: []dto.TransferBatchItemCreateDTO{
+			{ToAccountNumber: ani.AccountNumber, Amount: 20000},
+			{ToAccountNumber: bayu.AccountNumber, Amount: 30000},
+		},
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, domain.TransferBatchStatusFailed, batch.Status)
+	assert.Zero(t, batch.TransferredAmount)
+	assert.Equal(t, domain.TransferItemStatusCancelled, batch.Items[0].Status)
+	assert.Equal(t, domain.TransferItemStatusFailed, batch.Items[1].Status)
+	assert.Equal(t, "internal_error", batch.Items[1].ErrorCode)
+
+	for _, account := range []*domain.BankAccount{payer, ani, bayu} {
+		stored, err := repository.NewBankAccountRepositoryAdapter(gormDB, domain.DefaultAccountNumberScheme).GetByAccountNumber(context.Background(), account.AccountNumber)
+		assert.NoError(t, err)
+		assert.Equal(t, 100000.0, stored.Balance, "the first transfer is rolled back")
+	}
+
+	var transactions int64
+	assert.NoError(t, gormDB.Model(&domain.Transaction{}).Count(&transactions).Error)
+	assert.Zero(t, transactions)
+
+	var events int64
+	assert.NoError(t, gormDB.Model(&domain.OutboxEvent{}).Where("event_type IN ?", []string{domain.EventBalanceChanged,
+		domain.EventTransactionPosted}).Count(&events).Error)
+	assert.Zero(t, events, "the events of the rolled back transfer are rolled back with it")
+
+	stored, err := service.GetBatch(context.Background(), batch.ID.String(), payer.UserID, "user")
+	assert.NoError(t, err)
+	assert.Len(t, stored.Items, 2)
+}
+
+func TestTransferRollback(t *testing.T) {
+	gormDB := newEventTestDB(t)
+
+	payer := createUserWithAccount(t, gormDB, "payer")
+	ani := createUserWithAccount(t, gormDB, "ani")
+
+	transactionRepository := repository.NewTransactionRepositoryAdapter(gormDB)
+	bankAccountRepository := repository.NewBankAccountRepositoryAdapter(gormDB, domain.DefaultAccountNumberScheme)
+	validator := services.NewTransactionValidator(transactionRepository,
+		failingAccounts{BankAccountRepository: bankAccountRepository, accountNumber: ani.AccountNumber},
+		repository.NewOutboxRepositoryAdapter(gormDB), nil)
+	service := services.NewTransactionService(repository.NewUnitOfWork(gormDB), transactionRepository, bankAccountRepository,
+		validator, nil, metrics.New())
+
+	err := service.ProcessTransaction(context.Background(), payer.AccountNumber, ani.AccountNumber, "transfer", 20000)
+	assert.Error(t, err)
+
+	stored, err := bankAccountRepository.GetByAccountNumber(context.Background(), payer.AccountNumber)
+	assert.NoError(t, err)
+	assert.Equal(t, 100000.0, stored.Balance, "the debit is rolled back with the failed credit")
+
+	var events int64
+	assert.NoError(t, gormDB.Model(&domain.OutboxEvent{}).Where("event_type = ?", domain.EventBalanceChanged).Count(&events).Error)
+	assert.Zero(t, events)
+}
+
+// conflictingAccounts loses the race against a concurrent transaction on the first updates
+type conflictingAccounts struct {
+	ports.BankAccountRepository
+	conflicts int
+}
+
+func (r *conflictingAccounts) Update(ctx context.Context, entity *domain.BankAccount) (*domain.BankAccount, error) {
+	if r.conflicts > 0 {
+		r.conflicts--
+		return nil, domain.ErrVersionMismatch
+	}
+
+	return r.BankAccountRepository.Update(ctx, entity)
+}
+
+func TestTransferConflicts(t *testing.T) {
+	gormDB := newEventTestDB(t)
+
+	payer := createUserWithAccount(t, gormDB, "payer")
+	ani := createUserWithAccount(t, gormDB, "ani")
+
+	transactionRepository := repository.NewTransactionRepositoryAdapter(gormDB)
+	bankAccountRepository := repository.NewBankAccountRepositoryAdapter(gormDB, domain.DefaultAccountNumberScheme)
+	accounts := &conflictingAccounts{BankAccountRepository: bankAccountRepository}
+	service := services.NewTransactionService(repository.NewUnitOfWork(gormDB), transactionRepository, bankAccountRepository,
+		services.NewTransactionValidator(transactionRepository, accounts, repository.NewOutboxRepositoryAdapter(gormDB), nil), nil, metrics.New())
+
+	t.Run("Retries a transfer that conflicted with a concurrent one", func(t *testing.T) {
+		accounts.conflicts = 1
+
+		assert.NoError(t, service.ProcessTransaction(context.Background(), payer.AccountNumber, ani.AccountNumber, "transfer", 20000))
+
+		stored, err := bankAccountRepository.GetByAccountNumber(context.Background(), payer.AccountNumber)
+		assert.NoError(t, err)
+		assert.Equal(t, 80000.0, stored.Balance)
+	})
+
+	t.Run("Answers 409 when the conflicts do not stop", func(t *testing.T) {
+		accounts.conflicts = 100
+
+		err := service.ProcessTransaction(context.Background(), payer.AccountNumber, ani.AccountNumber, "transfer", 20000)
+		assert.ErrorIs(t, err, services.ErrTransactionConflict)
+
+		status, _, _, _ := middleware.MapError("en", err)
+		assert.Equal(t, http.StatusConflict, status)
+
+		stored, err := bankAccountRepository.GetByAccountNumber(context.Background(), payer.AccountNumber)
+		assert.NoError(t, err)
+		assert.Equal(t, 80000.0, stored.Balance)
+	})
+}
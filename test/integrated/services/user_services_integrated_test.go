@@ -1,19 +1,20 @@
 package services_test
 
 import (
+	"context"
 	"database/sql"
 	"testing"
 
+	"github.com/glebarez/sqlite"
 	"github.com/okyws/dashboard-backend/adapter/repository"
 	"github.com/okyws/dashboard-backend/domain"
 	"github.com/okyws/dashboard-backend/services"
 	"github.com/stretchr/testify/assert"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
 func TestCreateUser(t *testing.T) {
-	db, err := sql.Open("sqlite3", ":memory:")
+	db, err := sql.Open("sqlite", ":memory:")
 	assert.NoError(t, err)
 	assert.NotNil(t, db)
 
@@ -24,16 +25,16 @@ func TestCreateUser(t *testing.T) {
 		}
 	}()
 
-	gormDB, err := gorm.Open(sqlite.New(sqlite.Config{Conn: db}), &gorm.Config{})
+	gormDB, err := gorm.Open(&sqlite.Dialector{Conn: db}, &gorm.Config{})
 	assert.NoError(t, err)
 	assert.NotNil(t, gormDB)
 
 	userRepository := repository.NewUserRepositoryAdapter(gormDB)
-	userService := services.NewUserService(userRepository)
+	userService := services.NewUserService(repository.NewUnitOfWork(gormDB), userRepository, repository.NewOutboxRepositoryAdapter(gormDB))
 
 	t.Run("Empty password", func(t *testing.T) {
 		user := &domain.User{Username: "testuser", Password: ""}
-		_, err := userService.CreateUser(user)
+		_, err := userService.CreateUser(context.Background(), user)
 
 		assert.Error(t, err)
 		assert.Equal(t, "password cannot be empty", err.Error())
@@ -41,14 +42,14 @@ func TestCreateUser(t *testing.T) {
 
 	t.Run("Existing username", func(t *testing.T) {
 		gormDB.Exec("DROP TABLE users")
-		err := gormDB.AutoMigrate(&domain.User{})
+		err := gormDB.AutoMigrate(&domain.User{}, &domain.OutboxEvent{})
 		assert.NoError(t, err)
 
 		existingUser := &domain.User{Username: "testuser", Password: "testpassword"}
 		gormDB.Create(existingUser)
 
 		user := &domain.User{Username: "testuser", Password: "testpassword"}
-		_, err = userService.CreateUser(user)
+		_, err = userService.CreateUser(context.Background(), user)
 
 		assert.Error(t, err)
 		assert.Equal(t, "username already exists", err.Error())
@@ -56,11 +57,11 @@ func TestCreateUser(t *testing.T) {
 
 	t.Run("Successful user creation", func(t *testing.T) {
 		gormDB.Exec("DROP TABLE users")
-		err := gormDB.AutoMigrate(&domain.User{})
+		err := gormDB.AutoMigrate(&domain.User{}, &domain.OutboxEvent{})
 		assert.NoError(t, err)
 
 		user := &domain.User{Username: "testuser", Password: "testpassword"}
-		createdUser, err := userService.CreateUser(user)
+		createdUser, err := userService.CreateUser(context.Background(), user)
 		assert.NoError(t, err)
 		assert.NotNil(t, createdUser)
 		assert.Equal(t, "", createdUser.Password)
@@ -70,7 +71,7 @@ func TestCreateUser(t *testing.T) {
 		gormDB.Exec("DROP TABLE users")
 
 		user := &domain.User{Username: "testuser", Password: "testpassword"}
-		createdUser, err := userService.CreateUser(user)
+		createdUser, err := userService.CreateUser(context.Background(), user)
 		assert.Error(t, err)
 		assert.Nil(t, createdUser)
 		assert.Contains(t, err.Error(), "no such table: users")
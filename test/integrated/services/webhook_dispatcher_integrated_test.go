@@ -0,0 +1,195 @@
+package services_test
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/okyws/dashboard-backend/adapter/repository"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/services"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newEventTestDB(t *testing.T) *gorm.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	assert.NoError(t, err)
+
+	// a single connection keeps the in-memory database shared with the dispatcher goroutine
+	db.SetMaxOpenConns(1)
+
+	t.Cleanup(func() {
+		assert.NoError(t, db.Close())
+	})
+
+	gormDB, err := gorm.Open(&sqlite.Dialector{Conn: db}, &gorm.Config{})
+	assert.NoError(t, err)
+
+	err = gormDB.AutoMigrate(&domain.User{}, &domain.Customer{}, &domain.BankAccount{}, &domain.Transaction{}, &domain.OutboxEvent{},
+		&domain.OutboxDelivery{}, &domain.WebhookEndpoint{}, &domain.WebhookDelivery{}, &domain.Notification{}, &domain.NotificationPreference{},
+		&domain.Beneficiary{}, &domain.AccountNumberSequence{})
+	assert.NoError(t, err)
+
+	return gormDB
+}
+
+// pendingEvents returns the events of the outbox that were not dispatched yet, oldest first
+func pendingEvents(t *testing.T, gormDB *gorm.DB) []domain.OutboxEvent {
+	var events []domain.OutboxEvent
+
+	assert.NoError(t, gormDB.Where("dispatched_at IS NULL").Order("created_at ASC").Find(&events).Error)
+
+	return events
+}
+
+func relayEvents(t *testing.T, gormDB *gorm.DB, dispatcher *services.WebhookDispatcher) {
+	eventBus := services.NewEventBus()
+	eventBus.Subscribe(services.AllEvents, "webhooks", dispatcher.HandleEvent)
+
+	outboxRepository := repository.NewOutboxRepositoryAdapter(gormDB)
+	services.NewOutboxRelay(outboxRepository, eventBus).RunOnce(context.Background())
+
+	assert.Empty(t, pendingEvents(t, gormDB))
+}
+
+func TestWebhookDispatcher(t *testing.T) {
+	t.Run("Delivers signed event", func(t *testing.T) {
+		gormDB := newEventTestDB(t)
+
+		received := make(chan *http.Request, 1)
+		bodies := make(chan string, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			received <- r
+			bodies <- string(body)
+
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		webhookRepository := repository.NewWebhookRepositoryAdapter(gormDB)
+		webhookService := services.NewWebhookService(webhookRepository)
+
+		endpoint, err := webhookService.CreateEndpoint(context.Background(), &domain.WebhookEndpoint{URL: server.URL, EventTypes: domain.EventUserCreated})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, endpoint.Secret)
+
+		userService := services.NewUserService(repository.NewUnitOfWork(gormDB), repository.NewUserRepositoryAdapter(gormDB),
+			repository.NewOutboxRepositoryAdapter(gormDB))
+		_, err = userService.CreateUser(context.Background(), &domain.User{Username: "webhook", Email: "webhook@example.com", Password: "password", Role: "user"})
+		assert.NoError(t, err)
+
+		dispatcher := services.NewWebhookDispatcher(webhookRepository)
+		relayEvents(t, gormDB, dispatcher)
+		dispatcher.RunOnce(context.Background())
+
+		select {
+		case req := <-received:
+			body := <-bodies
+			timestamp := req.Header.Get(services.HeaderWebhookTimestamp)
+
+			assert.Equal(t, domain.EventUserCreated, req.Header.Get(services.HeaderWebhookEvent))
+			assert.Equal(t, "sha256="+services.SignWebhookPayload(endpoint.Secret, timestamp, body), req.Header.Get(services.HeaderWebhookSignature))
+			assert.Contains(t, body, `"username":"webhook"`)
+		case <-time.After(time.Second):
+			t.Fatal("webhook was not delivered")
+		}
+
+		deliveries, err := webhookService.GetDeliveriesByEndpointID(context.Background(), endpoint.ID.String(), 10, 0)
+		assert.NoError(t, err)
+		assert.Len(t, deliveries, 1)
+		assert.Equal(t, domain.DeliveryStatusDelivered, deliveries[0].Status)
+	})
+
+	t.Run("Dead letters after the last attempt and redelivers", func(t *testing.T) {
+		gormDB := newEventTestDB(t)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		webhookRepository := repository.NewWebhookRepositoryAdapter(gormDB)
+		webhookService := services.NewWebhookService(webhookRepository)
+
+		endpoint, err := webhookService.CreateEndpoint(context.Background(), &domain.WebhookEndpoint{URL: server.URL, EventTypes: domain.EventUserCreated})
+		assert.NoError(t, err)
+
+		userService := services.NewUserService(repository.NewUnitOfWork(gormDB), repository.NewUserRepositoryAdapter(gormDB),
+			repository.NewOutboxRepositoryAdapter(gormDB))
+		_, err = userService.CreateUser(context.Background(), &domain.User{Username: "failing", Email: "failing@example.com", Password: "password", Role: "user"})
+		assert.NoError(t, err)
+
+		dispatcher := services.NewWebhookDispatcher(webhookRepository)
+		dispatcher.MaxAttempts = 1
+		relayEvents(t, gormDB, dispatcher)
+		dispatcher.RunOnce(context.Background())
+
+		deliveries, err := webhookService.GetDeliveriesByEndpointID(context.Background(), endpoint.ID.String(), 10, 0)
+		assert.NoError(t, err)
+		assert.Len(t, deliveries, 1)
+		assert.Equal(t, domain.DeliveryStatusDead, deliveries[0].Status)
+		assert.Equal(t, http.StatusInternalServerError, deliveries[0].ResponseCode)
+
+		redelivered, err := webhookService.Redeliver(context.Background(), deliveries[0].ID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, domain.DeliveryStatusPending, redelivered.Status)
+
+		_, err = webhookService.Redeliver(context.Background(), deliveries[0].ID.String())
+		assert.ErrorIs(t, err, services.ErrDeliveryAlreadyScheduled)
+	})
+	t.Run("Sends a due delivery from a single dispatcher", func(t *testing.T) {
+		gormDB := newEventTestDB(t)
+
+		var received atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			received.Add(1)
+
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		webhookRepository := repository.NewWebhookRepositoryAdapter(gormDB)
+		webhookService := services.NewWebhookService(webhookRepository)
+
+		endpoint, err := webhookService.CreateEndpoint(context.Background(), &domain.WebhookEndpoint{URL: server.URL, EventTypes: domain.EventLoginFailed})
+		assert.NoError(t, err)
+		assert.NoError(t, webhookRepository.CreateDeliveries(context.Background(), []domain.WebhookDelivery{{
+			EndpointID: endpoint.ID, EventID: uuid.New(), EventType: domain.EventLoginFailed, Payload: "{}", NextAttemptAt: time.Now(),
+		}}))
+
+		claimed, err := webhookRepository.ClaimDueDeliveries(context.Background(), time.Now(), 10, time.Minute)
+		assert.NoError(t, err)
+		assert.Len(t, claimed, 1)
+
+		// a second replica leaves the claimed delivery alone
+		services.NewWebhookDispatcher(webhookRepository).RunOnce(context.Background())
+		assert.Equal(t, int32(0), received.Load())
+
+		delivery, err := webhookRepository.GetDeliveryByID(context.Background(), claimed[0].ID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, domain.DeliveryStatusPending, delivery.Status)
+
+		// once the claim expired the delivery is sent by another replica, and only once
+		expired := time.Now().Add(-time.Second)
+		assert.NoError(t, gormDB.Model(&domain.WebhookDelivery{}).Where("id = ?", delivery.ID).Update("claimed_until", expired).Error)
+
+		first, second := services.NewWebhookDispatcher(webhookRepository), services.NewWebhookDispatcher(webhookRepository)
+		first.RunOnce(context.Background())
+		second.RunOnce(context.Background())
+		assert.Equal(t, int32(1), received.Load())
+
+		delivery, err = webhookRepository.GetDeliveryByID(context.Background(), delivery.ID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, domain.DeliveryStatusDelivered, delivery.Status)
+		assert.Nil(t, delivery.ClaimedBy)
+	})
+}
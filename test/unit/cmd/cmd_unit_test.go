@@ -0,0 +1,105 @@
+package cmd_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/okyws/dashboard-backend/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func run(args ...string) (string, error) {
+	var out bytes.Buffer
+
+	root := cmd.NewRootCommand()
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SetArgs(args)
+
+	err := root.Execute()
+
+	return out.String(), err
+}
+
+func writeEnvFile(t *testing.T, content string) string {
+	envFile := filepath.Join(t.TempDir(), "test.env")
+	assert.NoError(t, os.WriteFile(envFile, []byte(content), 0o600))
+
+	return envFile
+}
+
+func TestConfigPrint(t *testing.T) {
+	envFile := writeEnvFile(t, "APP_NAME=Dashboard Test\nJWT_SECRET_KEY=super-secret\nDB_USER=test\nDB_NAME=test\nDB_PASSWORD=\n")
+
+	out, err := run("config", "print", "--env-file", envFile, "--set", "server_port=9090")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "APP_NAME=Dashboard Test\n")
+	assert.Contains(t, out, "JWT_SECRET_KEY=********\n")
+	assert.Contains(t, out, "DB_PASSWORD=\n")
+	assert.Contains(t, out, "SERVER_PORT=9090\n")
+	assert.Contains(t, out, "JWT_EXPIRY=2h0m0s\n")
+	assert.NotContains(t, out, "super-secret")
+}
+
+func TestConfigValidate(t *testing.T) {
+	envFile := writeEnvFile(t, "JWT_SECRET_KEY=super-secret\nDB_USER=test\nDB_NAME=test\n")
+
+	out, err := run("config", "validate", "--env-file", envFile)
+	assert.NoError(t, err)
+	assert.Contains(t, out, "configuration is valid")
+
+	_, err = run("config", "validate", "--env-file", envFile, "--set", "SERVER_PORT=0", "--set", "EVENT_SINKS=kafka")
+	assert.ErrorContains(t, err, "SERVER_PORT must be between 1 and 65535, got 0")
+	assert.ErrorContains(t, err, `EVENT_SINKS must only contain log, redis, got "kafka"`)
+}
+
+func TestDBImport(t *testing.T) {
+	dir := t.TempDir()
+	envFile := writeEnvFile(t, "JWT_SECRET_KEY=super-secret\nDB_DRIVER=sqlite\nDB_PATH="+filepath.Join(dir, "import.db")+"\nSTORE_DRIVER=memory\n")
+
+	_, err := run("db", "migrate", "up", "--env-file", envFile)
+	assert.NoError(t, err)
+
+	file := filepath.Join(dir, "branch.csv")
+	assert.NoError(t, os.WriteFile(file, []byte("type,username,email,password,role\nuser,eka,eka@example.com,secret1,customer\nuser,eka,eka@example.org,secret1,customer\n"), 0o600))
+
+	report := filepath.Join(dir, "errors.csv")
+
+	out, err := run("db", "import", file, "--report", report, "--env-file", envFile)
+	assert.NoError(t, err)
+	assert.Contains(t, out, "wrote 1 problems to "+report)
+
+	written, err := os.ReadFile(report)
+	assert.NoError(t, err)
+	assert.Equal(t, "line,field,code,message\n3,,username_taken,username already exists\n", string(written))
+
+	_, err = run("db", "import", file, "--mode", "all_or_nothing", "--env-file", envFile)
+	assert.ErrorContains(t, err, "0 of 2 rows were imported")
+}
+
+func TestCommandFailures(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.env")
+	insecure := writeEnvFile(t, "DB_USER=test\nDB_NAME=test\n")
+
+	tests := map[string][]string{
+		"missing env file":     {"config", "print", "--env-file", missing},
+		"missing config file":  {"config", "print", "--config", missing + ".yaml"},
+		"missing jwt secret":   {"config", "print", "--env-file", insecure},
+		"malformed setting":    {"config", "print", "--set", "SERVER_PORT"},
+		"invalid log level":    {"config", "print", "--log-level", "loud"},
+		"invalid down steps":   {"db", "migrate", "down", "0"},
+		"missing admin flags":  {"user", "create-admin", "--username", "root"},
+		"short admin password": {"user", "create-admin", "--username", "root", "--email", "root@example.com", "--password", "123"},
+		"missing import file":  {"db", "import", missing},
+		"unknown command":      {"frobnicate"},
+	}
+
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := run(args...)
+			assert.Error(t, err)
+		})
+	}
+}
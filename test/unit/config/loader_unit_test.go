@@ -0,0 +1,314 @@
+package config_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/okyws/dashboard-backend/config"
+	"github.com/okyws/dashboard-backend/utils"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestLoadLayers(t *testing.T) {
+	configFile := writeFile(t, "config.yaml", `
+app_name: From YAML
+server_port: 7000
+db_user: yaml
+db_name: bank
+jwt_secret_key: yaml-secret
+event_sinks: [log, redis]
+beneficiary_cooling_off: 24h
+`)
+	envFile := writeFile(t, ".env", "SERVER_PORT=7001\nDB_USER=dotenv\nREDIS_PASS=legacy\nPOSTGRES_DB=ignored\n")
+
+	t.Setenv("DB_USER", "environment")
+	t.Setenv("JWT_EXPIRY", "15m")
+
+	configuration, err := config.Load(config.LoadOptions{
+		ConfigFile: configFile,
+		EnvFile:    envFile,
+		Overrides:  map[string]string{"jwt_secret_key": "flag-secret"},
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "From YAML", configuration.AppName)
+	assert.Equal(t, 7001, configuration.ServerPort)
+	assert.Equal(t, "environment", configuration.DBUser)
+	assert.Equal(t, "flag-secret", configuration.JWTSecret)
+	assert.Equal(t, 15*time.Minute, configuration.JWTExpiry)
+	assert.Equal(t, []string{"log", "redis"}, configuration.EventSinks)
+	assert.Equal(t, 24*time.Hour, configuration.BeneficiaryCoolingOff)
+	assert.Equal(t, "legacy", configuration.REDISPass)
+
+	// defaults
+	assert.Equal(t, "development", configuration.AppEnv)
+	assert.Equal(t, 5432, configuration.DBPort)
+	assert.Equal(t, "luhn", configuration.AccountNumberScheme)
+	assert.Equal(t, 1000000.0, configuration.BeneficiaryCoolingOffLimit)
+	assert.Equal(t, "http://localhost:3000", configuration.ClientURL)
+}
+
+func TestLoadTOML(t *testing.T) {
+	configFile := writeFile(t, "config.toml", "jwt_secret_key = \"toml-secret\"\ndb_user = \"toml\"\ndb_name = \"bank\"\nredis_db = 2\n")
+
+	configuration, err := config.Load(config.LoadOptions{ConfigFile: configFile})
+	assert.NoError(t, err)
+	assert.Equal(t, "toml-secret", configuration.JWTSecret)
+	assert.Equal(t, 2, configuration.REDISDB)
+}
+
+func TestLoadMissingEnvFile(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), ".env")
+	overrides := map[string]string{"JWT_SECRET_KEY": "secret", "DB_USER": "user", "DB_NAME": "bank"}
+
+	_, err := config.Load(config.LoadOptions{EnvFile: missing, Overrides: overrides})
+	assert.NoError(t, err)
+
+	_, err = config.Load(config.LoadOptions{EnvFile: missing, EnvFileRequired: true, Overrides: overrides})
+	assert.ErrorIs(t, err, config.ErrInvalidConfiguration)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestLoadParseErrors(t *testing.T) {
+	_, err := config.Load(config.LoadOptions{Overrides: map[string]string{
+		"SERVER_PORT":                   "eighty",
+		"JWT_EXPIRY":                    "2 hours",
+		"BENEFICIARY_COOLING_OFF_LIMIT": "lots",
+	}})
+	assert.ErrorIs(t, err, config.ErrInvalidConfiguration)
+	assert.ErrorContains(t, err, `SERVER_PORT: "eighty" is not a whole number`)
+	assert.ErrorContains(t, err, `JWT_EXPIRY: "2 hours" is not a duration`)
+	assert.ErrorContains(t, err, `BENEFICIARY_COOLING_OFF_LIMIT: "lots" is not a number`)
+}
+
+func TestLoadValidationErrors(t *testing.T) {
+	_, err := config.Load(config.LoadOptions{Overrides: map[string]string{
+		"APP_ENV":               "staging",
+		"ACCOUNT_NUMBER_SCHEME": "crc",
+		"REDIS_PORT":            "70000",
+	}})
+	assert.ErrorIs(t, err, config.ErrInvalidConfiguration)
+	assert.ErrorContains(t, err, "JWT_SECRET_KEY is required")
+	assert.ErrorContains(t, err, "DB_USER is required")
+	assert.ErrorContains(t, err, "DB_NAME is required")
+	assert.ErrorContains(t, err, `APP_ENV must be one of development, test, production, got "staging"`)
+	assert.ErrorContains(t, err, `ACCOUNT_NUMBER_SCHEME must be one of luhn, mod97, got "crc"`)
+	assert.ErrorContains(t, err, "REDIS_PORT must be between 1 and 65535, got 70000")
+}
+
+func TestLoadUnknownSettings(t *testing.T) {
+	configFile := writeFile(t, "config.yaml", "sever_port: 8080\n")
+
+	_, err := config.Load(config.LoadOptions{ConfigFile: configFile})
+	assert.ErrorContains(t, err, `unknown setting "sever_port"`)
+
+	_, err = config.Load(config.LoadOptions{Overrides: map[string]string{"JWT_SECRET": "secret"}})
+	assert.ErrorContains(t, err, `unknown setting "JWT_SECRET"`)
+
+	_, err = config.Load(config.LoadOptions{ConfigFile: writeFile(t, "config.ini", "")})
+	assert.ErrorContains(t, err, "must be a .yaml, .yml or .toml file")
+}
+
+func TestSettingsRedactSecrets(t *testing.T) {
+	configuration, err := config.Load(config.LoadOptions{Overrides: map[string]string{
+		"JWT_SECRET_KEY": "secret", "DB_USER": "user", "DB_NAME": "bank", "EVENT_SINKS": "log, redis",
+	}})
+	assert.NoError(t, err)
+
+	values := make(map[string]string)
+	for _, setting := range config.Settings(configuration) {
+		values[setting.Name] = setting.Value
+	}
+
+	assert.Equal(t, config.Redacted, values["JWT_SECRET_KEY"])
+	assert.Equal(t, "", values["DB_PASSWORD"])
+	assert.Equal(t, "log,redis", values["EVENT_SINKS"])
+	assert.Equal(t, "1000000", values["BENEFICIARY_COOLING_OFF_LIMIT"])
+	assert.Equal(t, "8080", values["SERVER_PORT"])
+}
+
+func TestLoadDatabaseDrivers(t *testing.T) {
+	configuration, err := config.Load(config.LoadOptions{Overrides: map[string]string{"JWT_SECRET_KEY": "secret", "DB_DRIVER": "sqlite"}})
+	assert.NoError(t, err, "sqlite needs no server settings")
+	assert.Equal(t, "dashboard.db", configuration.DBPath)
+	assert.Contains(t, configuration.GetDatabaseConfig(), "file:dashboard.db?")
+
+	_, err = config.Load(config.LoadOptions{Overrides: map[string]string{"JWT_SECRET_KEY": "secret", "DB_DRIVER": "sqlite", "DB_PATH": ""}})
+	assert.ErrorContains(t, err, "DB_PATH is required")
+
+	_, err = config.Load(config.LoadOptions{Overrides: map[string]string{"JWT_SECRET_KEY": "secret", "DB_DRIVER": "mysql"}})
+	assert.ErrorContains(t, err, `DB_DRIVER must be one of postgres, sqlite, got "mysql"`)
+}
+
+func TestLoadStoreDrivers(t *testing.T) {
+	memory := map[string]string{"JWT_SECRET_KEY": "secret", "DB_DRIVER": "sqlite", "STORE_DRIVER": "memory", "REDIS_HOST": "", "REDIS_PORT": "0"}
+
+	configuration, err := config.Load(config.LoadOptions{Overrides: memory})
+	assert.NoError(t, err, "the memory stores need no redis settings")
+	assert.Equal(t, "memory", configuration.StoreDriver)
+
+	memory["EVENT_SINKS"] = "log,redis"
+
+	_, err = config.Load(config.LoadOptions{Overrides: memory})
+	assert.ErrorContains(t, err, "EVENT_SINKS must not contain redis when STORE_DRIVER is memory")
+
+	_, err = config.Load(config.LoadOptions{Overrides: map[string]string{"JWT_SECRET_KEY": "secret", "DB_DRIVER": "sqlite", "STORE_DRIVER": "memcached"}})
+	assert.ErrorContains(t, err, `STORE_DRIVER must be one of redis, memory, got "memcached"`)
+}
+
+func TestLoadMetricsPort(t *testing.T) {
+	overrides := map[string]string{"JWT_SECRET_KEY": "secret", "DB_DRIVER": "sqlite"}
+
+	configuration, err := config.Load(config.LoadOptions{Overrides: overrides})
+	assert.NoError(t, err, "the admin port needs no token")
+	assert.Equal(t, 8081, configuration.MetricsPort)
+
+	overrides["METRICS_PORT"] = "0"
+
+	_, err = config.Load(config.LoadOptions{Overrides: overrides})
+	assert.ErrorContains(t, err, "METRICS_TOKEN is required when METRICS_PORT is 0")
+
+	overrides["METRICS_TOKEN"] = "scrape-token"
+
+	_, err = config.Load(config.LoadOptions{Overrides: overrides})
+	assert.NoError(t, err)
+
+	overrides["METRICS_PORT"] = "8080"
+
+	_, err = config.Load(config.LoadOptions{Overrides: overrides})
+	assert.ErrorContains(t, err, "METRICS_PORT must differ from SERVER_PORT 8080")
+
+	overrides["METRICS_PORT"] = "70000"
+
+	_, err = config.Load(config.LoadOptions{Overrides: overrides})
+	assert.ErrorContains(t, err, "METRICS_PORT must be between 1 and 65535, got 70000")
+}
+
+func TestLoadTracing(t *testing.T) {
+	overrides := map[string]string{"JWT_SECRET_KEY": "secret", "DB_DRIVER": "sqlite"}
+
+	configuration, err := config.Load(config.LoadOptions{Overrides: overrides})
+	assert.NoError(t, err)
+	assert.Equal(t, "none", configuration.TracingExporter)
+	assert.Equal(t, 1.0, configuration.TracingSampleRatio)
+
+	overrides["TRACING_EXPORTER"] = "otlp"
+	overrides["OTEL_EXPORTER_OTLP_ENDPOINT"] = "collector:4318"
+
+	_, err = config.Load(config.LoadOptions{Overrides: overrides})
+	assert.ErrorContains(t, err, `OTEL_EXPORTER_OTLP_ENDPOINT must be a URL such as http://localhost:4318, got "collector:4318"`)
+
+	overrides["OTEL_EXPORTER_OTLP_ENDPOINT"] = "http://collector:4318"
+
+	_, err = config.Load(config.LoadOptions{Overrides: overrides})
+	assert.NoError(t, err)
+
+	overrides["TRACING_EXPORTER"] = "jaeger"
+	overrides["TRACING_SAMPLE_RATIO"] = "1.5"
+
+	_, err = config.Load(config.LoadOptions{Overrides: overrides})
+	assert.ErrorContains(t, err, `TRACING_EXPORTER must be one of none, stdout, otlp, got "jaeger"`)
+	assert.ErrorContains(t, err, "TRACING_SAMPLE_RATIO must be between 0 and 1, got 1.5")
+}
+
+func TestLoadRequestTimeout(t *testing.T) {
+	overrides := map[string]string{"JWT_SECRET_KEY": "secret", "DB_DRIVER": "sqlite"}
+
+	configuration, err := config.Load(config.LoadOptions{Overrides: overrides})
+	assert.NoError(t, err)
+	assert.Equal(t, 10*time.Second, configuration.RequestTimeout)
+
+	overrides["REQUEST_TIMEOUT"] = "0s"
+
+	configuration, err = config.Load(config.LoadOptions{Overrides: overrides})
+	assert.NoError(t, err)
+	assert.Zero(t, configuration.RequestTimeout)
+
+	overrides["REQUEST_TIMEOUT"] = "-1s"
+
+	_, err = config.Load(config.LoadOptions{Overrides: overrides})
+	assert.ErrorContains(t, err, "REQUEST_TIMEOUT must not be negative, got -1s")
+}
+
+func TestLoadAPIV1Sunset(t *testing.T) {
+	overrides := map[string]string{"JWT_SECRET_KEY": "secret", "DB_DRIVER": "sqlite"}
+
+	configuration, err := config.Load(config.LoadOptions{Overrides: overrides})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2027, time.June, 30, 0, 0, 0, 0, time.UTC), configuration.V1SunsetDate())
+
+	overrides["API_V1_SUNSET"] = "30/06/2027"
+
+	_, err = config.Load(config.LoadOptions{Overrides: overrides})
+	assert.ErrorContains(t, err, `API_V1_SUNSET must be a date such as 2027-06-30, got "30/06/2027"`)
+}
+
+func TestLoadLogging(t *testing.T) {
+	overrides := map[string]string{"JWT_SECRET_KEY": "secret", "DB_DRIVER": "sqlite"}
+
+	configuration, err := config.Load(config.LoadOptions{Overrides: overrides})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"stdout"}, configuration.LogOutputs)
+	assert.Empty(t, configuration.LogLevels)
+
+	overrides["LOG_OUTPUTS"] = "console,file"
+	overrides["LOG_LEVELS"] = "services=debug, repository=warn"
+
+	configuration, err = config.Load(config.LoadOptions{Overrides: overrides})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"services": "debug", "repository": "warn"}, configuration.ComponentLogLevels())
+
+	overrides["LOG_OUTPUTS"] = "syslog,file"
+	overrides["LOG_FILE_MAX_SIZE"] = "0"
+	overrides["LOG_LEVELS"] = "services,cache=debug,http=loud"
+
+	_, err = config.Load(config.LoadOptions{Overrides: overrides})
+	assert.ErrorContains(t, err, `LOG_OUTPUTS must only contain stdout, console, file, got "syslog"`)
+	assert.ErrorContains(t, err, "LOG_FILE_MAX_SIZE must be at least 1 megabyte, got 0")
+	assert.ErrorContains(t, err, `LOG_LEVELS entries must be component=level, got "services"`)
+	assert.ErrorContains(t, err, `LOG_LEVELS components must be one of http, handler, services, repository, broker, notifier, got "cache"`)
+	assert.ErrorContains(t, err, `LOG_LEVELS levels must be one of trace, debug, info, warn, error, disabled, got "loud"`)
+}
+
+func TestInitiateLog(t *testing.T) {
+	global, level := log.Logger, zerolog.GlobalLevel()
+	t.Cleanup(func() {
+		config.CloseLog()
+		log.Logger = global
+		assert.NoError(t, config.SetLogLevel(level.String()))
+		utils.SetComponentLevels(map[string]zerolog.Level{})
+	})
+
+	logFile := filepath.Join(t.TempDir(), "log", "app.log")
+	configuration, err := config.Load(config.LoadOptions{Overrides: map[string]string{
+		"JWT_SECRET_KEY": "secret", "DB_DRIVER": "sqlite", "LOG_OUTPUTS": "file", "LOG_FILE": logFile, "LOG_LEVELS": "services=debug",
+	}})
+	assert.NoError(t, err)
+
+	assert.NoError(t, config.SetLogLevel("warn"))
+	assert.NoError(t, config.InitiateLog(configuration))
+
+	log.Info().Msg("below the level")
+	log.Warn().Str("token", "secret-token").Msg("above the level")
+	utils.Logger(context.Background(), "services").Debug().Msg("services debug")
+	config.CloseLog()
+
+	content, err := os.ReadFile(logFile)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(content), "below the level")
+	assert.Contains(t, string(content), `"token":"[REDACTED]"`)
+	assert.NotContains(t, string(content), "secret-token")
+	assert.Contains(t, string(content), "services debug")
+}
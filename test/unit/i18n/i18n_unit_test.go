@@ -0,0 +1,141 @@
+package i18n_test
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/okyws/dashboard-backend/constants"
+	"github.com/okyws/dashboard-backend/i18n"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := map[string]string{
+		"":                          i18n.English,
+		"id":                        i18n.Indonesian,
+		"id-ID,id;q=0.9,en;q=0.8":   i18n.Indonesian,
+		"en-US,en;q=0.9,id;q=0.8":   i18n.English,
+		"fr-FR,fr;q=0.9":            i18n.English,
+		"fr;q=1, id;q=0.5":          i18n.Indonesian,
+		"en;q=0.2, ID;q=0.7":        i18n.Indonesian,
+		"id;q=oops, en":             i18n.English,
+		"*":                         i18n.English,
+		"de, id-ID;q=0.1, en;q=0.0": i18n.Indonesian,
+	}
+
+	for header, expected := range tests {
+		assert.Equal(t, expected, i18n.Negotiate(header), header)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	assert.Equal(t, "Resource not found", i18n.Translate(i18n.English, constants.MsgNotFound))
+	assert.Equal(t, "Data tidak ditemukan", i18n.Translate(i18n.Indonesian, constants.MsgNotFound))
+
+	t.Run("falls back to English and to the key", func(t *testing.T) {
+		assert.Equal(t, "IDR {amount}", i18n.Translate("fr", "format.amount"))
+		assert.Equal(t, "not in any catalog", i18n.Translate(i18n.Indonesian, "not in any catalog"))
+	})
+
+	t.Run("replaces the placeholders", func(t *testing.T) {
+		assert.Equal(t, "nickname wajib diisi", i18n.Translate(i18n.Indonesian, "validation.required", "{field}", "nickname"))
+		assert.Equal(t, "amount must be at least 10000",
+			i18n.Translate(i18n.English, "validation.min", "{field}", "amount", "{param}", "10000"))
+	})
+
+	t.Run("carries the language in the context", func(t *testing.T) {
+		assert.Equal(t, i18n.Default, i18n.FromContext(context.Background()))
+		assert.Equal(t, i18n.Indonesian, i18n.FromContext(i18n.WithLanguage(context.Background(), i18n.Indonesian)))
+	})
+}
+
+func TestFormat(t *testing.T) {
+	assert.Equal(t, "Rp 1.500.000,50", i18n.FormatAmount(i18n.Indonesian, 1500000.5))
+	assert.Equal(t, "IDR 1,500,000.50", i18n.FormatAmount(i18n.English, 1500000.5))
+	assert.Equal(t, "Rp 999,00", i18n.FormatAmount(i18n.Indonesian, 999))
+	assert.Equal(t, "-IDR 12,345.00", i18n.FormatAmount(i18n.English, -12345))
+
+	date := time.Date(2025, time.August, 17, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, "17 Agustus 2025", i18n.FormatDate(i18n.Indonesian, date))
+	assert.Equal(t, "August 17, 2025", i18n.FormatDate(i18n.English, date))
+}
+
+func TestCatalogs(t *testing.T) {
+	english := i18n.Messages(i18n.English)
+
+	assert.Equal(t, []string{i18n.English, i18n.Indonesian}, i18n.Languages())
+
+	for _, language := range i18n.Languages() {
+		messages := i18n.Messages(language)
+
+		for key := range english {
+			assert.Contains(t, messages, key, "%s misses the template %s", language, key)
+		}
+	}
+
+	// every message the API answers with is a key of the catalogs, English messages are their own key
+	indonesian := i18n.Messages(i18n.Indonesian)
+
+	messages := []string{
+		constants.MsgSuccess, constants.MsgInternalError, constants.MsgBadRequest, constants.MsgUnauthorized,
+		constants.MsgForbidden, constants.MsgNotFound, constants.MsgNotAllowed, constants.MsgUnprocessable, constants.MsgConflict,
+	}
+	messages = append(messages, responseMessages(t, "adapter/handler", "middleware", "services", "utils")...)
+
+	for _, message := range messages {
+		assert.Contains(t, indonesian, message, "missing Indonesian translation")
+	}
+}
+
+// responseMessages returns the string literals passed as messages to the response helpers and domain errors
+func responseMessages(t *testing.T, dirs ...string) []string {
+	// argument position of the message in the calls
+	positions := map[string]int{"ResponseJSON": 3, "ErrorResponse": 2, "DetailedErrorResponse": 3, "NewError": 2, "WithMessage": 0, "ResponseDeleted": 1}
+
+	var messages []string
+
+	for _, dir := range dirs {
+		files, err := filepath.Glob(filepath.Join("..", "..", "..", dir, "*.go"))
+		assert.NoError(t, err)
+		assert.NotEmpty(t, files, dir)
+
+		for _, path := range files {
+			file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+			assert.NoError(t, err)
+
+			ast.Inspect(file, func(node ast.Node) bool {
+				call, ok := node.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+
+				selector, ok := call.Fun.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+
+				position, ok := positions[selector.Sel.Name]
+				if !ok || len(call.Args) <= position {
+					return true
+				}
+
+				if literal, ok := call.Args[position].(*ast.BasicLit); ok && literal.Kind == token.STRING {
+					message, err := strconv.Unquote(literal.Value)
+					assert.NoError(t, err)
+
+					messages = append(messages, message)
+				}
+
+				return true
+			})
+		}
+	}
+
+	return messages
+}
@@ -0,0 +1,157 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/dto"
+	"github.com/okyws/dashboard-backend/middleware"
+	"github.com/okyws/dashboard-backend/services"
+	"github.com/okyws/dashboard-backend/utils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestErrorMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	dto.RegisterJSONFieldNames()
+
+	serve := func(handler gin.HandlerFunc, body string) (int, dto.ErrorResponseDTO) {
+		router := gin.New()
+		router.Use(middleware.ErrorMiddleware())
+		router.POST("/", handler)
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+
+		var resp dto.ErrorResponseDTO
+		assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &resp))
+		assert.Equal(t, "error", resp.Status)
+		assert.Equal(t, response.Code, resp.Code)
+
+		return response.Code, resp
+	}
+
+	failWith := func(err error) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			utils.HandleError(c, err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		err       error
+		status    int
+		errorCode string
+		message   string
+	}{
+		{"validation", services.ErrInvalidTransactionType, http.StatusBadRequest, "invalid_transaction_type", "invalid transaction type"},
+		{"unauthorized", services.ErrInvalidCredentials, http.StatusUnauthorized, "invalid_credentials", "username or password is incorrect"},
+		{"forbidden", services.ErrBeneficiaryCoolingOff, http.StatusForbidden, "beneficiary_cooling_off", services.ErrBeneficiaryCoolingOff.Message},
+		{"not found", services.ErrCustomerNotFound, http.StatusNotFound, "customer_not_found", "customer not found"},
+		{"conflict", services.ErrUsernameTaken, http.StatusConflict, "username_taken", "username already exists"},
+		{"insufficient funds", services.ErrInsufficientFunds, http.StatusUnprocessableEntity, "insufficient_funds", "insufficient balance"},
+		{"unprocessable", services.ErrAccountNotFound.WithCause(gorm.ErrRecordNotFound), http.StatusUnprocessableEntity, "account_not_found", "account number not found"},
+		{"wrapped domain error", errors.Join(errors.New("saving"), services.ErrMainAccountExists), http.StatusConflict, "main_account_exists", "user already has a main bank account"},
+		{"record not found", gorm.ErrRecordNotFound, http.StatusNotFound, "not_found", "Resource not found"},
+		{"duplicated key", gorm.ErrDuplicatedKey, http.StatusConflict, "already_exists", "Resource already exists"},
+		{"foreign key", gorm.ErrForeignKeyViolated, http.StatusUnprocessableEntity, "reference_not_found", "Referenced resource not found"},
+		{"deadline", context.DeadlineExceeded, http.StatusGatewayTimeout, "timeout", "Request timed out"},
+		{"cancelled", context.Canceled, middleware.StatusClientClosedRequest, "request_cancelled", "Request cancelled"},
+		{"unexpected", errors.New("pq: connection refused"), http.StatusInternalServerError, "internal_error", "Internal server error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := serve(failWith(tt.err), "")
+
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, tt.errorCode, resp.ErrorCode)
+			assert.Equal(t, tt.message, resp.Message)
+		})
+	}
+
+	t.Run("details of domain errors", func(t *testing.T) {
+		err := services.ErrUnknownCategory.WithDetails(domain.FieldError{Field: "category", Code: "oneof", Message: "must be a notification category"})
+
+		status, resp := serve(failWith(err), "")
+
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Equal(t, []dto.FieldErrorDTO{{Field: "category", Code: "oneof", Message: "must be a notification category"}}, resp.Details)
+	})
+
+	bind := func(c *gin.Context) {
+		var req dto.BeneficiaryUpdateDTO
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.HandleError(c, err)
+			return
+		}
+
+		c.Status(http.StatusOK)
+	}
+
+	t.Run("validation errors name the JSON fields", func(t *testing.T) {
+		status, resp := serve(bind, `{"nickname": ""}`)
+
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Equal(t, "validation_failed", resp.ErrorCode)
+		assert.Len(t, resp.Details, 1)
+		assert.Equal(t, "nickname", resp.Details[0].Field)
+		assert.Equal(t, "required", resp.Details[0].Code)
+	})
+
+	t.Run("malformed bodies", func(t *testing.T) {
+		for _, body := range []string{"", `{"nickname":`, `{"nickname": 42}`} {
+			status, resp := serve(bind, body)
+
+			assert.Equal(t, http.StatusBadRequest, status, body)
+			assert.Equal(t, "invalid_body", resp.ErrorCode, body)
+		}
+	})
+
+	t.Run("messages in the language of the request", func(t *testing.T) {
+		router := gin.New()
+		router.Use(middleware.LanguageMiddleware(), middleware.ErrorMiddleware())
+		router.POST("/", bind)
+		router.POST("/funds", failWith(services.ErrInsufficientFunds))
+
+		request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"nickname": ""}`))
+		request.Header.Set("Accept-Language", "id-ID,id;q=0.9,en;q=0.8")
+
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+
+		var resp dto.ErrorResponseDTO
+		assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &resp))
+		assert.Equal(t, "id", response.Header().Get("Content-Language"))
+		assert.Equal(t, "Parameter permintaan tidak valid", resp.Message)
+		assert.Equal(t, "nickname wajib diisi", resp.Details[0].Message)
+
+		request = httptest.NewRequest(http.MethodPost, "/funds", nil)
+		request.Header.Set("Accept-Language", "id")
+
+		response = httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+
+		assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &resp))
+		assert.Equal(t, "insufficient_funds", resp.ErrorCode)
+		assert.Equal(t, "saldo tidak mencukupi", resp.Message)
+	})
+
+	t.Run("responses written by the handler are kept", func(t *testing.T) {
+		status, resp := serve(func(c *gin.Context) {
+			_ = c.Error(errors.New("logged only"))
+			utils.ErrorResponse(c, http.StatusMethodNotAllowed, "Method not allowed")
+		}, "")
+
+		assert.Equal(t, http.StatusMethodNotAllowed, status)
+		assert.Equal(t, "method_not_allowed", resp.ErrorCode)
+	})
+}
@@ -1,20 +1,24 @@
 package services_test
 
 import (
+	"context"
 	"errors"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/ports"
 	"github.com/okyws/dashboard-backend/services"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type MockUserRepository struct {
 	mock.Mock
 }
 
-func (m *MockUserRepository) GetUserByEmail(email string) (*domain.User, error) {
+func (m *MockUserRepository) GetUserByEmail(_ context.Context, email string) (*domain.User, error) {
 	args := m.Called(email)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -23,7 +27,7 @@ func (m *MockUserRepository) GetUserByEmail(email string) (*domain.User, error)
 	return args.Get(0).(*domain.User), args.Error(1)
 }
 
-func (m *MockUserRepository) GetUserByUsername(username string) (*domain.User, error) {
+func (m *MockUserRepository) GetUserByUsername(_ context.Context, username string) (*domain.User, error) {
 	args := m.Called(username)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -32,7 +36,7 @@ func (m *MockUserRepository) GetUserByUsername(username string) (*domain.User, e
 	return args.Get(0).(*domain.User), args.Error(1)
 }
 
-func (m *MockUserRepository) Create(user *domain.User) (*domain.User, error) {
+func (m *MockUserRepository) Create(_ context.Context, user *domain.User) (*domain.User, error) {
 	args := m.Called(user)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -41,7 +45,7 @@ func (m *MockUserRepository) Create(user *domain.User) (*domain.User, error) {
 	return args.Get(0).(*domain.User), args.Error(1)
 }
 
-func (m *MockUserRepository) Update(user *domain.User) (*domain.User, error) {
+func (m *MockUserRepository) Update(_ context.Context, user *domain.User) (*domain.User, error) {
 	args := m.Called(user)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -50,13 +54,28 @@ func (m *MockUserRepository) Update(user *domain.User) (*domain.User, error) {
 	return args.Get(0).(*domain.User), args.Error(1)
 }
 
-func (m *MockUserRepository) Delete(id string) error {
+func (m *MockUserRepository) Patch(_ context.Context, id string, version uint, changes map[string]any) (*domain.User, error) {
+	args := m.Called(id, version, changes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *MockUserRepository) Delete(_ context.Context, id string) error {
 	args := m.Called(id)
 
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) GetAll(limit, offset int) ([]domain.User, error) {
+func (m *MockUserRepository) DeleteVersion(_ context.Context, id string, version uint) error {
+	args := m.Called(id, version)
+
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetAll(_ context.Context, limit, offset int) ([]domain.User, error) {
 	args := m.Called(limit, offset)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -65,7 +84,7 @@ func (m *MockUserRepository) GetAll(limit, offset int) ([]domain.User, error) {
 	return args.Get(0).([]domain.User), args.Error(1)
 }
 
-func (m *MockUserRepository) GetByID(id string) (*domain.User, error) {
+func (m *MockUserRepository) GetByID(_ context.Context, id string) (*domain.User, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -74,13 +93,33 @@ func (m *MockUserRepository) GetByID(id string) (*domain.User, error) {
 	return args.Get(0).(*domain.User), args.Error(1)
 }
 
+// directUnitOfWork runs the changes of a unit of work without a transaction
+type directUnitOfWork struct{}
+
+func (directUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// recordedEvents keeps the events recorded in the outbox
+type recordedEvents struct {
+	ports.OutboxRepository
+	events []domain.Event
+}
+
+func (r *recordedEvents) Record(_ context.Context, event domain.Event) error {
+	r.events = append(r.events, event)
+
+	return nil
+}
+
 func TestCreateUserService(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	userService := services.NewUserService(mockRepo)
+	outbox := &recordedEvents{}
+	userService := services.NewUserService(directUnitOfWork{}, mockRepo, outbox)
 
 	t.Run("Empty password", func(t *testing.T) {
 		user := &domain.User{Username: "testuser", Password: ""}
-		_, err := userService.CreateUser(user)
+		_, err := userService.CreateUser(context.Background(), user)
 		assert.Error(t, err)
 		assert.Equal(t, "password cannot be empty", err.Error())
 	})
@@ -90,7 +129,7 @@ func TestCreateUserService(t *testing.T) {
 		mockRepo.On("GetUserByUsername", existingUser.Username).Return(existingUser, nil)
 
 		user := &domain.User{Username: "testuser", Password: "newpassword"}
-		_, err := userService.CreateUser(user)
+		_, err := userService.CreateUser(context.Background(), user)
 
 		assert.Error(t, err)
 		assert.Equal(t, "username already exists", err.Error())
@@ -103,12 +142,14 @@ func TestCreateUserService(t *testing.T) {
 		mockRepo.On("GetUserByUsername", "newuser").Return(nil, nil)
 		mockRepo.On("Create", user).Return(user, nil)
 
-		createdUser, err := userService.CreateUser(user)
+		createdUser, err := userService.CreateUser(context.Background(), user)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, createdUser)
 		assert.Equal(t, "newuser", createdUser.Username)
 		assert.Equal(t, "", createdUser.Password)
+		assert.Len(t, outbox.events, 1)
+		assert.Equal(t, domain.EventUserCreated, outbox.events[0].EventType())
 		mockRepo.AssertExpectations(t)
 	})
 
@@ -118,7 +159,7 @@ func TestCreateUserService(t *testing.T) {
 		mockRepo.On("GetUserByUsername", "newuser").Return(nil, nil)
 		mockRepo.On("Create", user).Return(nil, errors.New("database error"))
 
-		createdUser, err := userService.CreateUser(user)
+		createdUser, err := userService.CreateUser(context.Background(), user)
 
 		assert.Error(t, err)
 		assert.Nil(t, createdUser)
@@ -126,3 +167,33 @@ func TestCreateUserService(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestPatchUserService(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	userService := services.NewUserService(directUnitOfWork{}, mockRepo, &recordedEvents{})
+	id := uuid.New()
+
+	t.Run("Empty password", func(t *testing.T) {
+		_, err := userService.PatchUser(context.Background(), id.String(), 0, map[string]any{"password": ""})
+		assert.ErrorIs(t, err, services.ErrPasswordRequired)
+	})
+
+	t.Run("Username of another user", func(t *testing.T) {
+		mockRepo.On("GetUserByUsername", "taken").Return(&domain.User{ID: uuid.New(), Username: "taken"}, nil)
+
+		_, err := userService.PatchUser(context.Background(), id.String(), 0, map[string]any{"username": "taken"})
+		assert.ErrorIs(t, err, services.ErrUsernameTaken)
+	})
+
+	t.Run("Password is hashed", func(t *testing.T) {
+		mockRepo.On("Patch", id.String(), uint(0), mock.MatchedBy(func(changes map[string]any) bool {
+			hash, ok := changes["password"].(string)
+			return ok && bcrypt.CompareHashAndPassword([]byte(hash), []byte("newpassword")) == nil
+		})).Return(&domain.User{ID: id, Username: "alice", Password: "hash"}, nil)
+
+		patchedUser, err := userService.PatchUser(context.Background(), id.String(), 0, map[string]any{"password": "newpassword"})
+		assert.NoError(t, err)
+		assert.Equal(t, "", patchedUser.Password)
+		mockRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,67 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/okyws/dashboard-backend/domain"
+	"github.com/okyws/dashboard-backend/ports"
+	"github.com/okyws/dashboard-backend/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountNumberSchemes(t *testing.T) {
+	schemes := map[string]ports.AccountNumberScheme{
+		"luhn":  utils.NewLuhnAccountNumberScheme(domain.AccountProductCodes),
+		"mod97": utils.NewMod97AccountNumberScheme(domain.AccountProductCodes),
+	}
+
+	for name, scheme := range schemes {
+		t.Run(name+" generates valid numbers with the product code and the serial", func(t *testing.T) {
+			for _, serial := range []uint64{1, 42, 9999, 99999} {
+				accountNumber, err := scheme.Generate("saku", serial)
+				assert.NoError(t, err)
+				assert.Len(t, accountNumber, utils.AccountNumberLength)
+				assert.Equal(t, "102", accountNumber[:3])
+				assert.Equal(t, "102", scheme.ProductCode("saku"))
+				assert.True(t, scheme.Valid(accountNumber), accountNumber)
+			}
+		})
+
+		t.Run(name+" uses the default product code for unknown types", func(t *testing.T) {
+			accountNumber, err := scheme.Generate("unknown", 1)
+			assert.NoError(t, err)
+			assert.Equal(t, utils.DefaultProductCode, accountNumber[:3])
+		})
+
+		t.Run(name+" rejects serials that do not fit", func(t *testing.T) {
+			_, err := scheme.Generate("saku", 0)
+			assert.ErrorIs(t, err, utils.ErrSerialOutOfRange)
+
+			_, err = scheme.Generate("saku", 1000000)
+			assert.ErrorIs(t, err, utils.ErrSerialOutOfRange)
+		})
+
+		t.Run(name+" rejects a single digit typo", func(t *testing.T) {
+			accountNumber, err := scheme.Generate("deposito", 73512)
+			assert.NoError(t, err)
+
+			for i := range accountNumber {
+				typo := []byte(accountNumber)
+				typo[i] = '0' + (typo[i]-'0'+1)%10
+
+				assert.False(t, scheme.Valid(string(typo)), string(typo))
+			}
+		})
+
+		t.Run(name+" rejects malformed numbers", func(t *testing.T) {
+			assert.False(t, scheme.Valid(""))
+			assert.False(t, scheme.Valid("12345"))
+			assert.False(t, scheme.Valid("10200000a1"))
+		})
+	}
+}
+
+func TestLuhnCheckDigit(t *testing.T) {
+	assert.Equal(t, 3, utils.LuhnCheckDigit("7992739871"))
+	assert.True(t, utils.NewLuhnAccountNumberScheme(nil).Valid("1020000004"))
+}
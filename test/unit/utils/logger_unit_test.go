@@ -0,0 +1,73 @@
+package utils_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/okyws/dashboard-backend/utils"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactWriter(t *testing.T) {
+	var output bytes.Buffer
+
+	logger := zerolog.New(utils.NewRedactWriter(&output))
+	logger.Info().Str("username", "alice").Str("password", "hunter2").Str("access_token", `a"b`).
+		Str("Authorization", "Bearer abc").Str("apiKey", "key").Int("token_count", 3).Msg("login")
+
+	line := output.String()
+	assert.Contains(t, line, `"username":"alice"`)
+	assert.Contains(t, line, `"password":"[REDACTED]"`)
+	assert.Contains(t, line, `"access_token":"[REDACTED]"`)
+	assert.Contains(t, line, `"Authorization":"[REDACTED]"`)
+	assert.Contains(t, line, `"apiKey":"[REDACTED]"`)
+	assert.Contains(t, line, `"token_count":3`)
+	assert.NotContains(t, line, "hunter2")
+	assert.NotContains(t, line, "Bearer")
+}
+
+func TestLogger(t *testing.T) {
+	var output bytes.Buffer
+
+	global := log.Logger
+	t.Cleanup(func() {
+		log.Logger = global
+		utils.SetComponentLevels(map[string]zerolog.Level{})
+	})
+
+	log.Logger = zerolog.New(&output).Level(zerolog.InfoLevel)
+	utils.SetComponentLevels(map[string]zerolog.Level{"repository": zerolog.DebugLevel, "services": zerolog.WarnLevel})
+
+	t.Run("uses the request logger of the context", func(t *testing.T) {
+		output.Reset()
+
+		ctx := log.Logger.With().Str("request_id", "abc").Logger().WithContext(context.Background())
+		utils.Logger(ctx, "handler").Info().Msg("handled")
+
+		assert.Contains(t, output.String(), `"request_id":"abc"`)
+		assert.Contains(t, output.String(), `"component":"handler"`)
+	})
+
+	t.Run("falls back to the global logger", func(t *testing.T) {
+		output.Reset()
+
+		utils.Logger(context.Background(), "handler").Info().Msg("handled")
+
+		assert.Contains(t, output.String(), `"component":"handler"`)
+		assert.NotContains(t, output.String(), "request_id")
+	})
+
+	t.Run("applies the level of the component", func(t *testing.T) {
+		output.Reset()
+
+		utils.Logger(context.Background(), "repository").Debug().Msg("query")
+		utils.Logger(context.Background(), "services").Info().Msg("hidden")
+		utils.Logger(context.Background(), "handler").Debug().Msg("hidden")
+
+		assert.Contains(t, output.String(), "query")
+		assert.NotContains(t, output.String(), "hidden")
+	})
+}
@@ -0,0 +1,49 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/okyws/dashboard-backend/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergePatch(t *testing.T) {
+	// the examples of RFC 7396 appendix A
+	tests := []struct {
+		target, patch, result string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		result, err := utils.MergePatch([]byte(tt.target), []byte(tt.patch))
+		assert.NoError(t, err)
+		assert.JSONEq(t, tt.result, string(result), "%s merged with %s", tt.target, tt.patch)
+	}
+
+	t.Run("patches of a resource are objects", func(t *testing.T) {
+		members, err := utils.ParseMergePatch([]byte(`{"address": null, "full_name": "Budi"}`))
+		assert.NoError(t, err)
+		assert.Equal(t, "null", string(members["address"]))
+		assert.Len(t, members, 2)
+
+		for _, patch := range []string{`null`, `["a"]`, `"a"`} {
+			_, err := utils.ParseMergePatch([]byte(patch))
+			assert.Error(t, err, patch)
+		}
+	})
+}
@@ -0,0 +1,147 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// AccountNumberLength is the number of digits of every account number
+const AccountNumberLength = 10
+
+// DefaultProductCode is used for account types without a product code
+const DefaultProductCode = "900"
+
+// ErrSerialOutOfRange is returned when a serial does not fit in the account numbers of the scheme
+var ErrSerialOutOfRange = errors.New("account number serial out of range")
+
+// LuhnAccountNumberScheme builds account numbers as a 3 digit product code, a 6 digit serial and a Luhn check digit
+type LuhnAccountNumberScheme struct {
+	ProductCodes map[string]string
+}
+
+// NewLuhnAccountNumberScheme creates a new Luhn account number scheme
+func NewLuhnAccountNumberScheme(productCodes map[string]string) *LuhnAccountNumberScheme {
+	return &LuhnAccountNumberScheme{ProductCodes: productCodes}
+}
+
+// ProductCode returns the product code prefixing the account numbers of the account type
+func (s *LuhnAccountNumberScheme) ProductCode(accountType string) string {
+	return productCode(s.ProductCodes, accountType)
+}
+
+// Generate returns the account number of the account type with the serial
+func (s *LuhnAccountNumberScheme) Generate(accountType string, serial uint64) (string, error) {
+	body, err := numberBody(s.ProductCode(accountType), serial, AccountNumberLength-len(DefaultProductCode)-1)
+	if err != nil {
+		return "", err
+	}
+
+	return body + strconv.Itoa(LuhnCheckDigit(body)), nil
+}
+
+// Valid reports whether the account number has the right length and a correct Luhn check digit
+func (s *LuhnAccountNumberScheme) Valid(accountNumber string) bool {
+	if !isDigits(accountNumber, AccountNumberLength) {
+		return false
+	}
+
+	last := len(accountNumber) - 1
+
+	return LuhnCheckDigit(accountNumber[:last]) == int(accountNumber[last]-'0')
+}
+
+// Mod97AccountNumberScheme builds account numbers as a 3 digit product code, a 5 digit serial and
+// two ISO 7064 MOD 97-10 check digits, which also catch swapped digits
+type Mod97AccountNumberScheme struct {
+	ProductCodes map[string]string
+}
+
+// NewMod97AccountNumberScheme creates a new mod-97 account number scheme
+func NewMod97AccountNumberScheme(productCodes map[string]string) *Mod97AccountNumberScheme {
+	return &Mod97AccountNumberScheme{ProductCodes: productCodes}
+}
+
+// ProductCode returns the product code prefixing the account numbers of the account type
+func (s *Mod97AccountNumberScheme) ProductCode(accountType string) string {
+	return productCode(s.ProductCodes, accountType)
+}
+
+// Generate returns the account number of the account type with the serial
+func (s *Mod97AccountNumberScheme) Generate(accountType string, serial uint64) (string, error) {
+	body, err := numberBody(s.ProductCode(accountType), serial, AccountNumberLength-len(DefaultProductCode)-2)
+	if err != nil {
+		return "", err
+	}
+
+	value, _ := strconv.ParseUint(body, 10, 64)
+
+	return fmt.Sprintf("%s%02d", body, 98-(value*100)%97), nil
+}
+
+// Valid reports whether the account number has the right length and leaves a remainder of 1 modulo 97
+func (s *Mod97AccountNumberScheme) Valid(accountNumber string) bool {
+	if !isDigits(accountNumber, AccountNumberLength) {
+		return false
+	}
+
+	value, err := strconv.ParseUint(accountNumber, 10, 64)
+
+	return err == nil && value%97 == 1
+}
+
+// LuhnCheckDigit returns the digit that makes the number pass the Luhn check when appended
+func LuhnCheckDigit(number string) int {
+	sum := 0
+	double := true
+
+	for i := len(number) - 1; i >= 0; i-- {
+		digit := int(number[i] - '0')
+
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+
+		sum += digit
+		double = !double
+	}
+
+	return (10 - sum%10) % 10
+}
+
+// productCode returns the product code of the account type
+func productCode(productCodes map[string]string, accountType string) string {
+	if code, ok := productCodes[accountType]; ok {
+		return code
+	}
+
+	return DefaultProductCode
+}
+
+// numberBody returns the product code followed by the serial padded to the digits, serials start at 1
+func numberBody(productCode string, serial uint64, digits int) (string, error) {
+	serialDigits := strconv.FormatUint(serial, 10)
+	if serial == 0 || len(serialDigits) > digits {
+		return "", ErrSerialOutOfRange
+	}
+
+	return fmt.Sprintf("%s%0*d", productCode, digits, serial), nil
+}
+
+// isDigits reports whether the value consists of exactly length decimal digits
+func isDigits(value string, length int) bool {
+	if len(value) != length {
+		return false
+	}
+
+	for i := range value {
+		if value[i] < '0' || value[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIVersionKey is the key of the API version of the route in the gin context
+const APIVersionKey = "api_version"
+
+// API versions, the handlers answer the same on both except for the status codes and headers of the v2 conventions
+const (
+	APIV1 = "v1"
+	APIV2 = "v2"
+)
+
+// APIVersion returns the API version set by the route group, v1 when the group does not set one
+func APIVersion(c *gin.Context) string {
+	if version := c.GetString(APIVersionKey); version != "" {
+		return version
+	}
+
+	return APIV1
+}
+
+// SetLocation sets the Location header to the URL of the resource created by the request, the v1 routes create
+// under <collection>/add and the v2 routes on the collection itself
+func SetLocation(c *gin.Context, id string) {
+	collection := strings.TrimSuffix(strings.TrimSuffix(c.FullPath(), "/"), "/add")
+
+	c.Header("Location", collection+"/"+id)
+}
+
+// ResponseDeleted answers a deletion with 204 No Content on v2 and with the message on v1
+func ResponseDeleted(c *gin.Context, message string) {
+	if APIVersion(c) == APIV1 {
+		ResponseJSON(c, nil, http.StatusOK, message)
+		return
+	}
+
+	c.Set(ResponseMessageKey, message)
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	componentLevelsMu sync.RWMutex
+	componentLevels   = make(map[string]zerolog.Level)
+)
+
+// SetComponentLevels sets the minimum level of the components, components without a level log at the level of the
+// global logger
+func SetComponentLevels(levels map[string]zerolog.Level) {
+	componentLevelsMu.Lock()
+	defer componentLevelsMu.Unlock()
+
+	componentLevels = levels
+}
+
+// Logger returns the logger of the request in the context, which carries its request ID, for the component. Without a
+// request logger the global logger is used. Events of the logger are given the context, so traced requests log the
+// trace and span IDs.
+func Logger(ctx context.Context, component string) *zerolog.Logger {
+	parent := &log.Logger
+	if logger := zerolog.Ctx(ctx); logger.GetLevel() != zerolog.Disabled && logger != zerolog.DefaultContextLogger {
+		parent = logger
+	}
+
+	logger := parent.With().Str("component", component).Ctx(ctx).Logger()
+
+	componentLevelsMu.RLock()
+	level, ok := componentLevels[component]
+	componentLevelsMu.RUnlock()
+
+	if ok {
+		logger = logger.Level(level)
+	}
+
+	return &logger
+}
@@ -0,0 +1,15 @@
+package utils
+
+import "strings"
+
+// MaskName keeps the first letter of every word and masks the rest, e.g. "Budi Santoso" becomes "B*** S******"
+func MaskName(name string) string {
+	words := strings.Fields(name)
+
+	for i, word := range words {
+		letters := []rune(word)
+		words[i] = string(letters[0]) + strings.Repeat("*", len(letters)-1)
+	}
+
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// MergePatchContentType is the media type of JSON Merge Patch documents (RFC 7396)
+const MergePatchContentType = "application/merge-patch+json"
+
+// ErrPatchNotObject is returned for merge patches that are not a JSON object, they would replace the whole resource
+var ErrPatchNotObject = errors.New("merge patch must be a JSON object")
+
+// ParseMergePatch returns the members of a merge patch, a member holding null removes the member of the target
+func ParseMergePatch(patch []byte) (map[string]json.RawMessage, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &members); err != nil {
+		return nil, err
+	}
+
+	if members == nil {
+		return nil, ErrPatchNotObject
+	}
+
+	return members, nil
+}
+
+// MergePatch applies the merge patch to the target document as described in RFC 7396: null removes a member, objects
+// are merged recursively and any other value replaces the member
+func MergePatch(target, patch []byte) ([]byte, error) {
+	var targetValue, patchValue any
+
+	if err := json.Unmarshal(target, &targetValue); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(patch, &patchValue); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(mergeValue(targetValue, patchValue))
+}
+
+// mergeValue merges the decoded patch into the decoded target
+func mergeValue(target, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = make(map[string]any)
+	}
+
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+			continue
+		}
+
+		targetObject[name] = mergeValue(targetObject[name], value)
+	}
+
+	return targetObject
+}
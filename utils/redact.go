@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"io"
+	"regexp"
+)
+
+// Redacted replaces the values of secret fields in the log
+const Redacted = "[REDACTED]"
+
+// secretField matches the JSON string fields whose name mentions a password, token, secret, API key or authorization
+var secretField = regexp.MustCompile(`(?i)("[^"]*(?:password|passwd|token|secret|api_?key|authorization)[^"]*"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+// RedactWriter writes JSON log lines with the values of secret fields replaced, so a password or token logged by
+// mistake never reaches the outputs
+type RedactWriter struct {
+	Out io.Writer
+}
+
+// NewRedactWriter creates a writer redacting the lines before writing them to out
+func NewRedactWriter(out io.Writer) *RedactWriter {
+	return &RedactWriter{Out: out}
+}
+
+// Write redacts the line and writes it, reporting the length of the original line as written
+func (w *RedactWriter) Write(p []byte) (int, error) {
+	if _, err := w.Out.Write(secretField.ReplaceAll(p, []byte(`${1}"`+Redacted+`"`))); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
@@ -2,41 +2,103 @@
 package utils
 
 import (
+	"context"
+	"errors"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 	"github.com/okyws/dashboard-backend/dto"
-	"github.com/rs/zerolog/log"
+	"github.com/okyws/dashboard-backend/i18n"
 )
 
-// ResponseJSON sends a successful JSON response in Gin
+// ResponseMessageKey is the key of the message of the response in the gin context
+const ResponseMessageKey = "response_message"
+
+// Language returns the response language negotiated for the request
+func Language(c *gin.Context) string {
+	if c.Request == nil {
+		return i18n.Default
+	}
+
+	return i18n.FromContext(c.Request.Context())
+}
+
+// ResponseJSON sends a successful JSON response in Gin, the message is a key of the message catalogs
 func ResponseJSON(c *gin.Context, data interface{}, code int, message string) {
 	resp := dto.SuccessResponseDTO[interface{}]{
 		Status:  "success",
 		Code:    code,
-		Message: message,
+		Message: i18n.Translate(Language(c), message),
 		Data:    data,
 	}
 
 	sendJSON(c, resp, code, message)
 }
 
-// ErrorResponse sends an error JSON response in Gin
+// statusErrorCodes are the error codes of the responses that do not name a more specific one
+var statusErrorCodes = map[int]string{
+	http.StatusBadRequest:          "bad_request",
+	http.StatusUnauthorized:        "unauthorized",
+	http.StatusForbidden:           "forbidden",
+	http.StatusNotFound:            "not_found",
+	http.StatusMethodNotAllowed:    "method_not_allowed",
+	http.StatusConflict:            "conflict",
+	http.StatusUnprocessableEntity: "unprocessable",
+	http.StatusTooManyRequests:     "too_many_requests",
+	http.StatusServiceUnavailable:  "unavailable",
+	http.StatusGatewayTimeout:      "timeout",
+}
+
+// ErrorResponse sends an error JSON response in Gin with the error code of the status
 func ErrorResponse(c *gin.Context, code int, message string) {
+	DetailedErrorResponse(c, code, "", message, nil)
+}
+
+// DetailedErrorResponse sends an error JSON response in Gin with the error code and the problems of the single
+// fields, the code of the status is used when errorCode is empty. The messages are keys of the message catalogs. Server
+// errors of a request that ran past its deadline are sent as 504 since the failure is most likely the aborted query.
+func DetailedErrorResponse(c *gin.Context, code int, errorCode, message string, details []dto.FieldErrorDTO) {
+	if code >= http.StatusInternalServerError && c.Request != nil && errors.Is(c.Request.Context().Err(), context.DeadlineExceeded) {
+		code, errorCode, message = http.StatusGatewayTimeout, "", "Request timed out"
+	}
+
+	if errorCode == "" {
+		errorCode = statusErrorCodes[code]
+	}
+
+	if errorCode == "" {
+		errorCode = "internal_error"
+	}
+
+	language := Language(c)
+
+	translated := make([]dto.FieldErrorDTO, len(details))
+	for i, detail := range details {
+		translated[i] = detail
+		translated[i].Message = i18n.Translate(language, detail.Message)
+	}
+
 	resp := dto.ErrorResponseDTO{
-		Status:  "error",
-		Code:    code,
-		Message: message,
+		Status:    "error",
+		Code:      code,
+		ErrorCode: errorCode,
+		Message:   i18n.Translate(language, message),
+		Details:   translated,
 	}
 
 	sendJSON(c, resp, code, message)
 }
 
-// sendJSON is a helper function for sending JSON responses
+// HandleError hands the error to the error middleware, which answers with the status and error code of its kind and
+// hides the text of unexpected errors from the client
+func HandleError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// sendJSON is a helper function for sending JSON responses, the message is logged with the request by the logging
+// middleware
 func sendJSON(c *gin.Context, resp interface{}, code int, message string) {
+	c.Set(ResponseMessageKey, message)
 	c.JSON(code, resp)
-
-	// Log response
-	log.Info().
-		Int("code", code).
-		Str("message", message).
-		Msg("Response sent successfully")
 }